@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// AchievementResponse describes an achievement and when the current user earned it
+type AchievementResponse struct {
+	types.Achievement
+	GrantedAt *time.Time `json:"granted_at,omitempty"`
+}
+
+// HandleUserAchievements lists every achievement, with the grant time of those the current user has earned
+func (h *GoogleAuthHandler) HandleUserAchievements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := authenticateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(achievementResponses(user.Achievements))
+}
+
+// achievementResponses pairs every known achievement with the user's grant, if any
+func achievementResponses(granted []db.Achievement) []AchievementResponse {
+	grantedAt := make(map[string]time.Time, len(granted))
+	for _, achievement := range granted {
+		grantedAt[achievement.ID] = achievement.GrantedAt
+	}
+
+	responses := make([]AchievementResponse, 0, len(types.Achievements))
+	for _, achievement := range types.Achievements {
+		response := AchievementResponse{Achievement: achievement}
+		if at, ok := grantedAt[achievement.ID]; ok {
+			response.GrantedAt = &at
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
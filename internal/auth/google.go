@@ -5,13 +5,16 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/besuhoff/dungeon-game-go/internal/config"
 	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -52,7 +55,7 @@ func (h *GoogleAuthHandler) HandleGetAuthURL(w http.ResponseWriter, r *http.Requ
 	// Generate random state for CSRF protection
 	state, err := generateRandomState()
 	if err != nil {
-		http.Error(w, "Failed to generate state", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to generate state")
 		return
 	}
 
@@ -71,7 +74,7 @@ func (h *GoogleAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Reques
 	state := r.URL.Query().Get("state")
 
 	if code == "" || state == "" {
-		http.Error(w, "Missing code or state", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Missing code or state")
 		return
 	}
 
@@ -79,14 +82,14 @@ func (h *GoogleAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Reques
 	ctx := context.Background()
 	token, err := h.config.Exchange(ctx, code)
 	if err != nil {
-		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to exchange token")
 		return
 	}
 
 	// Get user info from Google
 	userInfo, err := h.getUserInfo(ctx, token)
 	if err != nil {
-		http.Error(w, "Failed to get user info", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get user info")
 		return
 	}
 
@@ -115,11 +118,11 @@ func (h *GoogleAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Reques
 			}
 
 			if err := h.userRepo.Create(ctx, user); err != nil {
-				http.Error(w, "Failed to create user", http.StatusInternalServerError)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create user")
 				return
 			}
 		} else {
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Database error")
 			return
 		}
 	}
@@ -127,7 +130,7 @@ func (h *GoogleAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Reques
 	// Generate JWT token
 	jwtToken, err := GenerateToken(user.ID)
 	if err != nil {
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
@@ -178,39 +181,37 @@ func generateRandomState() (string, error) {
 
 // HandleGetUser returns the current authenticated user's information
 func (h *GoogleAuthHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
-	// Extract token from Authorization header
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+	user, ok := authenticateRequest(w, r)
+	if !ok {
 		return
 	}
 
-	// Remove "Bearer " prefix
-	token := authHeader
-	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-		token = authHeader[7:]
-	}
+	// Return user info, leaving out provider IDs and other internal fields
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NewCurrentUserResponse(user))
+}
 
-	// Validate JWT token
-	userID, err := ValidateToken(token)
-	if err != nil {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
+// authenticateRequest returns the active user whose token the request carries,
+// or writes the error response and reports false
+func authenticateRequest(w http.ResponseWriter, r *http.Request) (*db.User, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Missing authorization header")
+		return nil, false
 	}
 
-	// Fetch user from database
-	ctx := context.Background()
-	user, err := h.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			http.Error(w, "User not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Database error", http.StatusInternalServerError)
-		}
-		return
+	user, err := AuthenticateActiveUser(context.Background(), strings.TrimPrefix(authHeader, "Bearer "))
+	switch {
+	case err == nil:
+		return user, true
+	case errors.Is(err, ErrInvalidToken):
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Invalid token")
+	case errors.Is(err, ErrUserInactive):
+		utils.WriteJSONError(w, http.StatusForbidden, "Forbidden: user is inactive")
+	case errors.Is(err, mongo.ErrNoDocuments):
+		utils.WriteJSONError(w, http.StatusNotFound, "User not found")
+	default:
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Database error")
 	}
-
-	// Return user info
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	return nil, false
 }
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+func TestUserHandlersForbidDeactivatedUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	config.AppConfig = &config.Config{SecretKey: "test-secret"}
+	h := &GoogleAuthHandler{}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		path    string
+		body    string
+	}{
+		{"get user", h.HandleGetUser, http.MethodGet, "/api/v1/auth/user", ""},
+		{"get settings", h.HandleUserSettings, http.MethodGet, "/api/v1/me/settings", ""},
+		{"put settings", h.HandleUserSettings, http.MethodPut, "/api/v1/me/settings", `{"control_scheme":"arrows"}`},
+		{"get achievements", h.HandleUserAchievements, http.MethodGet, "/api/v1/me/achievements", ""},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			userID := primitive.NewObjectID()
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: userID}, {Key: "username", Value: "player"}, {Key: "is_active", Value: false}},
+			))
+			now := time.Now()
+			token := signTestToken(t, userID, now.Add(-time.Minute), now.Add(time.Hour))
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				mt.Errorf("status = %d, want %d for a deactivated user", rec.Code, http.StatusForbidden)
+			}
+			if tt.method == http.MethodPut && len(mt.GetAllStartedEvents()) != 1 {
+				mt.Errorf("expected only the user lookup, got %d database commands", len(mt.GetAllStartedEvents()))
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrUserInactive is returned for a valid token of a user that has been deactivated
+var ErrUserInactive = errors.New("user is inactive")
+
+// ErrInvalidToken wraps the reason a JWT token was rejected
+var ErrInvalidToken = errors.New("invalid token")
+
 // Claims represents the JWT claims
 type Claims struct {
 	UserID string `json:"sub"`
@@ -31,7 +40,8 @@ func GenerateToken(userID primitive.ObjectID) (string, error) {
 	return token.SignedString([]byte(config.AppConfig.SecretKey))
 }
 
-// ValidateToken validates a JWT token and returns the user ID
+// ValidateToken validates a JWT token and returns the user ID. Tokens issued in
+// the future are rejected, and both checks allow the configured clock skew.
 func ValidateToken(tokenString string) (primitive.ObjectID, error) {
 	claims := &Claims{}
 
@@ -40,7 +50,7 @@ func ValidateToken(tokenString string) (primitive.ObjectID, error) {
 			return nil, errors.New("invalid signing method")
 		}
 		return []byte(config.AppConfig.SecretKey), nil
-	})
+	}, jwt.WithLeeway(config.AppConfig.JWTLeeway), jwt.WithIssuedAt())
 
 	if err != nil {
 		return primitive.NilObjectID, err
@@ -57,3 +67,23 @@ func ValidateToken(tokenString string) (primitive.ObjectID, error) {
 
 	return userID, nil
 }
+
+// AuthenticateActiveUser validates a JWT token and returns its user, as long as they are still active.
+// Returns ErrUserInactive for deactivated users and ErrInvalidToken for bad tokens, so callers can tell them apart.
+func AuthenticateActiveUser(ctx context.Context, tokenString string) (*db.User, error) {
+	userID, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	user, err := db.NewUserRepository().FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	return user, nil
+}
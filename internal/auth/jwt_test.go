@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+// signTestToken signs a token for userID with the given issue and expiry times
+func signTestToken(t *testing.T, userID primitive.ObjectID, issuedAt, expiresAt time.Time) string {
+	t.Helper()
+
+	claims := &Claims{
+		UserID: userID.Hex(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConfig.SecretKey))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return token
+}
+
+func TestValidateTokenClockSkew(t *testing.T) {
+	userID := primitive.NewObjectID()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		leeway    time.Duration
+		issuedAt  time.Time
+		expiresAt time.Time
+		wantValid bool
+	}{
+		{"valid", 0, now.Add(-time.Minute), now.Add(time.Minute), true},
+		{"just expired without leeway", 0, now.Add(-time.Hour), now.Add(-5 * time.Second), false},
+		{"just expired within leeway", 30 * time.Second, now.Add(-time.Hour), now.Add(-5 * time.Second), true},
+		{"expired beyond leeway", 30 * time.Second, now.Add(-time.Hour), now.Add(-time.Minute), false},
+		{"issued in the future without leeway", 0, now.Add(10 * time.Second), now.Add(time.Hour), false},
+		{"issued in the future within leeway", 30 * time.Second, now.Add(10 * time.Second), now.Add(time.Hour), true},
+		{"issued beyond leeway", 30 * time.Second, now.Add(time.Minute), now.Add(time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig = &config.Config{SecretKey: "test-secret", JWTLeeway: tt.leeway}
+			token := signTestToken(t, userID, tt.issuedAt, tt.expiresAt)
+
+			got, err := ValidateToken(token)
+			if tt.wantValid {
+				if err != nil {
+					t.Fatalf("ValidateToken() error = %v", err)
+				}
+				if got != userID {
+					t.Errorf("ValidateToken() = %s, want %s", got.Hex(), userID.Hex())
+				}
+			} else if err == nil {
+				t.Error("expected ValidateToken() to reject the token")
+			}
+		})
+	}
+}
+
+func TestAuthenticateActiveUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	config.AppConfig = &config.Config{SecretKey: "test-secret"}
+	now := time.Now()
+
+	authenticate := func(mt *mtest.T, isActive bool) (*db.User, error) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		userID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: userID}, {Key: "username", Value: "player"}, {Key: "is_active", Value: isActive}},
+		))
+		token := signTestToken(t, userID, now.Add(-time.Minute), now.Add(time.Hour))
+		return AuthenticateActiveUser(context.Background(), token)
+	}
+
+	mt.Run("active user", func(mt *mtest.T) {
+		user, err := authenticate(mt, true)
+		if err != nil {
+			mt.Fatalf("AuthenticateActiveUser() error = %v", err)
+		}
+		if user.Username != "player" {
+			mt.Errorf("user = %+v, want the token's user", user)
+		}
+	})
+
+	mt.Run("deactivated user", func(mt *mtest.T) {
+		if _, err := authenticate(mt, false); !errors.Is(err, ErrUserInactive) {
+			mt.Errorf("AuthenticateActiveUser() error = %v, want %v", err, ErrUserInactive)
+		}
+	})
+
+	mt.Run("invalid token", func(mt *mtest.T) {
+		_, err := AuthenticateActiveUser(context.Background(), "not-a-token")
+		if !errors.Is(err, ErrInvalidToken) {
+			mt.Errorf("AuthenticateActiveUser() error = %v, want a token error", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+const (
+	maxSettingsBodySize    = 16 << 10
+	maxControlSchemeLength = 32
+	maxSettingsColors      = 16
+	maxColorValueLength    = 32
+	maxKeyBindings         = 64
+	maxDisplaySettings     = 32
+	maxSettingsValueLength = 32
+)
+
+// HandleUserSettings returns (GET) or replaces (PUT) the current user's settings
+func (h *GoogleAuthHandler) HandleUserSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := authenticateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var settings db.UserSettings
+		r.Body = http.MaxBytesReader(w, r.Body, maxSettingsBodySize)
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Settings payload too large")
+			} else {
+				utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body")
+			}
+			return
+		}
+
+		if err := validateSettings(&settings); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		user.Settings = settings
+		if err := h.userRepo.Update(context.Background(), user); err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save settings")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.Settings)
+}
+
+// validateSettings rejects unknown protocols and oversized values
+func validateSettings(settings *db.UserSettings) error {
+	switch settings.PreferredProtocol {
+	case "", "json", "binary":
+	default:
+		return errors.New("preferred_protocol must be either json or binary")
+	}
+
+	if len(settings.ControlScheme) > maxControlSchemeLength {
+		return errors.New("control_scheme is too long")
+	}
+
+	if len(settings.Colors) > maxSettingsColors {
+		return errors.New("too many colors")
+	}
+
+	for name, value := range settings.Colors {
+		if name == "" || len(name) > maxColorValueLength || len(value) > maxColorValueLength {
+			return errors.New("invalid color entry")
+		}
+	}
+
+	if len(settings.KeyBindings) > maxKeyBindings {
+		return errors.New("too many key bindings")
+	}
+
+	for action, key := range settings.KeyBindings {
+		if action == "" || key == "" || len(action) > maxSettingsValueLength || len(key) > maxSettingsValueLength {
+			return errors.New("invalid key binding")
+		}
+	}
+
+	if len(settings.Display) > maxDisplaySettings {
+		return errors.New("too many display settings")
+	}
+
+	for name, value := range settings.Display {
+		if name == "" || len(name) > maxSettingsValueLength || len(value) > maxSettingsValueLength {
+			return errors.New("invalid display setting")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings db.UserSettings
+		wantErr  bool
+	}{
+		{
+			name:     "empty settings",
+			settings: db.UserSettings{},
+			wantErr:  false,
+		},
+		{
+			name: "valid settings",
+			settings: db.UserSettings{
+				PreferredProtocol: "binary",
+				ControlScheme:     "arrows",
+				Colors:            map[string]string{"player": "#00ff00"},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "unknown protocol",
+			settings: db.UserSettings{PreferredProtocol: "xml"},
+			wantErr:  true,
+		},
+		{
+			name:     "control scheme too long",
+			settings: db.UserSettings{ControlScheme: strings.Repeat("a", maxControlSchemeLength+1)},
+			wantErr:  true,
+		},
+		{
+			name:     "empty color name",
+			settings: db.UserSettings{Colors: map[string]string{"": "#000000"}},
+			wantErr:  true,
+		},
+		{
+			name: "key bindings and display",
+			settings: db.UserSettings{
+				KeyBindings: map[string]string{"shoot": "Space", "sprint": "ShiftLeft"},
+				Display:     map[string]string{"minimap": "on", "ui_scale": "1.25"},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "unbound key",
+			settings: db.UserSettings{KeyBindings: map[string]string{"shoot": ""}},
+			wantErr:  true,
+		},
+		{
+			name:     "too many key bindings",
+			settings: db.UserSettings{KeyBindings: manyEntries(maxKeyBindings + 1)},
+			wantErr:  true,
+		},
+		{
+			name:     "display value too long",
+			settings: db.UserSettings{Display: map[string]string{"theme": strings.Repeat("a", maxSettingsValueLength+1)}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSettings(&tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func manyEntries(n int) map[string]string {
+	entries := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		entries[fmt.Sprintf("action%d", i)] = "KeyA"
+	}
+	return entries
+}
+
+func TestHandleUserSettingsErrorsAreJSON(t *testing.T) {
+	h := &GoogleAuthHandler{}
+
+	rec := httptest.NewRecorder()
+	h.HandleUserSettings(rec, httptest.NewRequest(http.MethodGet, "/api/v1/me/settings", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	var body utils.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("error body is not valid JSON: %v", err)
+	}
+	if body.Error.Code != "unauthorized" || body.Error.Message != "Missing authorization header" {
+		t.Errorf("error = %+v, want unauthorized with the missing header message", body.Error)
+	}
+}
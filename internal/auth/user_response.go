@@ -0,0 +1,41 @@
+package auth
+
+import "github.com/besuhoff/dungeon-game-go/internal/db"
+
+// UserResponse is the public view of a user. Fields are copied explicitly, so
+// provider IDs and anything added to db.User later stay out of API responses.
+type UserResponse struct {
+	ID             string `json:"id"`
+	Email          string `json:"email"`
+	Username       string `json:"username"`
+	IsActive       bool   `json:"is_active"`
+	CurrentSession string `json:"current_session,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// NewUserResponse converts a user to its public view
+func NewUserResponse(user *db.User) UserResponse {
+	return UserResponse{
+		ID:             user.ID.Hex(),
+		Email:          user.Email,
+		Username:       user.Username,
+		IsActive:       user.IsActive,
+		CurrentSession: user.CurrentSession,
+		CreatedAt:      user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// CurrentUserResponse is what users see of themselves, their bank balance included.
+// Other users, e.g. a session's host, are shown as a UserResponse.
+type CurrentUserResponse struct {
+	UserResponse
+	BankedMoney int `json:"banked_money"`
+}
+
+// NewCurrentUserResponse converts the signed in user to their own view
+func NewCurrentUserResponse(user *db.User) CurrentUserResponse {
+	return CurrentUserResponse{
+		UserResponse: NewUserResponse(user),
+		BankedMoney:  user.BankedMoney,
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserResponseHidesInternalFields(t *testing.T) {
+	user := &db.User{
+		ID:             primitive.NewObjectID(),
+		Email:          "user@example.com",
+		GoogleID:       "google-123",
+		Username:       "player",
+		IsActive:       true,
+		CreatedAt:      time.Now(),
+		CurrentSession: "session",
+		Settings:       db.UserSettings{ControlScheme: "wasd"},
+		Achievements:   []db.Achievement{{ID: "first_kill", GrantedAt: time.Now()}},
+	}
+
+	encoded, err := json.Marshal(NewUserResponse(user))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if _, exists := fields["google_id"]; exists {
+		t.Error("expected the response to leave out the Google ID")
+	}
+
+	// New fields have to be added here on purpose, so nothing leaks by accident
+	public := map[string]bool{"id": true, "email": true, "username": true, "is_active": true, "current_session": true, "created_at": true}
+	for name := range fields {
+		if !public[name] {
+			t.Errorf("response exposes unexpected field %q", name)
+		}
+	}
+	if fields["id"] != user.ID.Hex() || fields["username"] != "player" {
+		t.Errorf("response = %v, want the user's ID and username", fields)
+	}
+}
+
+func TestOnlyUsersSeeTheirOwnBankedMoney(t *testing.T) {
+	user := &db.User{ID: primitive.NewObjectID(), Username: "host", BankedMoney: 250}
+
+	encoded, err := json.Marshal(NewUserResponse(user))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if _, exists := fields["banked_money"]; exists {
+		t.Error("expected the bank balance to stay out of the view other users get")
+	}
+
+	encoded, err = json.Marshal(NewCurrentUserResponse(user))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	fields = nil
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if fields["banked_money"] != 250.0 || fields["username"] != "host" {
+		t.Errorf("response = %v, want the user's own bank balance with their profile", fields)
+	}
+}
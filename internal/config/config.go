@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -24,10 +25,112 @@ type Config struct {
 	TLSCert                  string
 	TLSKey                   string
 	EngineDebugMode          bool
+	ClientPredictionEnabled  bool
+	SlowTickThreshold        time.Duration
+	AssistsEnabled           bool
+	AssistWindow             time.Duration
+	AssistRewardFraction     float64
+	BulletLODEnabled         bool
+	BulletLODDistance        float64
+	BulletLODInterval        int
+	SprintEnabled            bool
+	ChestInvulnerability     time.Duration
+	StealthEnabled           bool
+	RevealAllShops           bool
+	EnemyLookAhead           float64
+	MaxMoney                 int
+	MaxScore                 int
+	DBWorkers                int
+	InstantEnemyRemoval      bool
+	JoinReservationTTL       time.Duration
+	LeaderboardFlush         time.Duration
+	WallThickness            float64
+	ShopPriceVariation       float64
+	BulletTrails             bool
+	ZonesEnabled             bool
+	TeammatePositions        bool
+	RespawnDelay             time.Duration
+	ShootableEnemyBullets    bool
+	MinShopDistance          float64
+	AutoEquipWeapons         string
+	AutoSessionNames         bool
+	EnemyAggroRadius         float64
+	SummonerChance           float64
+	SummonInterval           time.Duration
+	SummonerMinionCap        int
+	RailgunPenetration       int
+	MaxLoadedSessions        int
+	SessionKeepAlive         time.Duration
+	JWTLeeway                time.Duration
+	EnemyFleeThreshold       float64
+	LockedRoomChance         float64
+	GameLoopInterval         time.Duration
+	DeltaThrottleThreshold   float64
+	DeltaThrottleMaxInterval int
+	AutoRejoinSession        bool
+	TeamDroppedChests        bool
+	ArmoredEnemyChance       float64
+	EnemyBulletSpread        int
+	BankingEnabled           bool
+	HardcoreMode             bool
+	ClientDebugEnabled       bool
+	EnemyAimInaccuracy       float64
+	EnemyAimInaccuracyPer100 float64
+	AidKitPickupRadius       float64
+	GogglesPickupRadius      float64
+	ChestPickupRadius        float64
+	KeyPickupRadius          float64
+	PowerUpPickupRadius      float64
+	EnemyAwarenessRadius     float64
+	RailgunRange             float64
+	JoinLeaveInDeltas        bool
+	MaxBulletsPerChunk       int
+	PoisonWeapons            []string
+	PoisonDamage             float64
+	PoisonDuration           time.Duration
+	GatekeeperChance         float64
+	ThiefChance              float64
+	PortalLifetime           time.Duration
+	RateLimitPerMinute       int
+	RateLimitJoinPerMinute   int
+	RateLimitTrustProxy      bool
+	RecordInputs             bool
+	ReconnectGracePeriod     time.Duration
+	MaxRotationPerTick       float64
+	KillCamDuration          time.Duration
+	GunJamChance             map[string]float64
+	EnemyArchetypes          bool
+	SessionWaitlistTTL       time.Duration
+	EnemyMeleeDamage         float64
+	EnemyMeleeRange          float64
+	EnemyMeleeInterval       time.Duration
+	SpawnWeapons             map[string]int32
+	SessionActivityEnabled   bool
+	EnemyDeathFade           bool
+	DebugStatsEnabled        bool
+	DebugStatsToken          string
+	FlasherFlashRadius       float64
+	FlasherBlindTime         time.Duration
+	FlasherChance            float64
+	BulletWallTolerance      float64
+	PowerUpDropChance        float64
+	PowerUpDamageChance      float64
+	PowerUpRapidFireChance   float64
+	PowerUpDuration          time.Duration
+	PowerUpDamageMultiplier  float64
+	PowerUpShootDelayFactor  float64
+	PowerUpSpeedMultiplier   float64
 }
 
 var AppConfig *Config
 
+// Modes for equipping weapons picked up from bonuses
+const (
+	AutoEquipOff    = ""
+	AutoEquipBetter = "better" // Equip a picked up weapon that is more expensive than the selected one
+	AutoEquipAlways = "always" // Equip any picked up weapon
+)
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	// Load .env file if it exists
@@ -52,6 +155,676 @@ func LoadConfig() *Config {
 		engineDebugMode = true
 	}
 
+	clientPredictionEnabled := false
+	if predictionStr := os.Getenv("CLIENT_PREDICTION_ENABLED"); predictionStr == "true" {
+		clientPredictionEnabled = true
+	}
+
+	// Ticks slower than this are logged individually, 0 disables the check
+	slowTickThreshold := time.Duration(0)
+	if thresholdStr := os.Getenv("SLOW_TICK_THRESHOLD_MS"); thresholdStr != "" {
+		if val, err := strconv.Atoi(thresholdStr); err == nil && val > 0 {
+			slowTickThreshold = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	assistsEnabled := false
+	if assistsStr := os.Getenv("ASSISTS_ENABLED"); assistsStr == "true" {
+		assistsEnabled = true
+	}
+
+	// Damage dealt within this window before a kill counts as an assist
+	assistWindow := 5 * time.Second
+	if windowStr := os.Getenv("ASSIST_WINDOW_MS"); windowStr != "" {
+		if val, err := strconv.Atoi(windowStr); err == nil && val > 0 {
+			assistWindow = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Share of the kill reward paid to each assisting player
+	assistRewardFraction := 0.5
+	if fractionStr := os.Getenv("ASSIST_REWARD_FRACTION"); fractionStr != "" {
+		if val, err := strconv.ParseFloat(fractionStr, 64); err == nil && val >= 0 && val <= 1 {
+			assistRewardFraction = val
+		}
+	}
+
+	bulletLODEnabled := false
+	if lodStr := os.Getenv("BULLET_LOD_ENABLED"); lodStr == "true" {
+		bulletLODEnabled = true
+	}
+
+	// Bullets farther than this fraction of the sight radius are updated less often
+	bulletLODDistance := 0.5
+	if distanceStr := os.Getenv("BULLET_LOD_DISTANCE"); distanceStr != "" {
+		if val, err := strconv.ParseFloat(distanceStr, 64); err == nil && val >= 0 && val <= 1 {
+			bulletLODDistance = val
+		}
+	}
+
+	// Distant bullets are sent every Nth tick
+	bulletLODInterval := 3
+	if intervalStr := os.Getenv("BULLET_LOD_INTERVAL"); intervalStr != "" {
+		if val, err := strconv.Atoi(intervalStr); err == nil && val > 0 {
+			bulletLODInterval = val
+		}
+	}
+
+	sprintEnabled := false
+	if sprintStr := os.Getenv("SPRINT_ENABLED"); sprintStr == "true" {
+		sprintEnabled = true
+	}
+
+	// Players picking up a chest are invulnerable for this long, 0 disables it
+	chestPickupInvulnerability := time.Duration(0)
+	if invulnerabilityStr := os.Getenv("CHEST_PICKUP_INVULNERABILITY_MS"); invulnerabilityStr != "" {
+		if val, err := strconv.Atoi(invulnerabilityStr); err == nil && val > 0 {
+			chestPickupInvulnerability = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	stealthEnabled := false
+	if stealthStr := os.Getenv("STEALTH_ENABLED"); stealthStr == "true" {
+		stealthEnabled = true
+	}
+
+	// The shops endpoint lists every shop in the session instead of only the discovered ones
+	revealAllShops := false
+	if revealStr := os.Getenv("REVEAL_ALL_SHOPS"); revealStr == "true" {
+		revealAllShops = true
+	}
+
+	// Distance enemies look ahead for walls to steer around, 0 disables steering
+	enemyLookAhead := 0.0
+	if lookAheadStr := os.Getenv("ENEMY_LOOK_AHEAD"); lookAheadStr != "" {
+		if val, err := strconv.ParseFloat(lookAheadStr, 64); err == nil && val >= 0 {
+			enemyLookAhead = val
+		}
+	}
+
+	// Caps for a player's money and score, 0 means the largest value the protocol supports
+	maxMoney := 0
+	if maxMoneyStr := os.Getenv("MAX_MONEY"); maxMoneyStr != "" {
+		if val, err := strconv.Atoi(maxMoneyStr); err == nil && val > 0 {
+			maxMoney = val
+		}
+	}
+
+	maxScore := 0
+	if maxScoreStr := os.Getenv("MAX_SCORE"); maxScoreStr != "" {
+		if val, err := strconv.Atoi(maxScoreStr); err == nil && val > 0 {
+			maxScore = val
+		}
+	}
+
+	// Number of goroutines writing session saves and leaderboard updates
+	dbWorkers := 4
+	if workersStr := os.Getenv("DB_WORKERS"); workersStr != "" {
+		if val, err := strconv.Atoi(workersStr); err == nil && val > 0 {
+			dbWorkers = val
+		}
+	}
+
+	// Dead enemies disappear at once instead of leaving a corpse for a few seconds
+	instantEnemyRemoval := false
+	if removalStr := os.Getenv("INSTANT_ENEMY_REMOVAL"); removalStr == "true" {
+		instantEnemyRemoval = true
+	}
+
+	// A player who joined a session over HTTP keeps their slot this long while connecting
+	joinReservationTTL := 30 * time.Second
+	if ttlStr := os.Getenv("JOIN_RESERVATION_TTL_MS"); ttlStr != "" {
+		if val, err := strconv.Atoi(ttlStr); err == nil && val > 0 {
+			joinReservationTTL = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Deaths are written to the leaderboard in batches this often, 0 writes each death right away
+	var leaderboardFlush time.Duration
+	if flushStr := os.Getenv("LEADERBOARD_FLUSH_INTERVAL_MS"); flushStr != "" {
+		if val, err := strconv.Atoi(flushStr); err == nil && val > 0 {
+			leaderboardFlush = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Thickness of newly generated walls, walls already in a session keep their own
+	wallThickness := WallWidth
+	if thicknessStr := os.Getenv("WALL_THICKNESS"); thicknessStr != "" {
+		if val, err := strconv.ParseFloat(thicknessStr, 64); err == nil && val > 0 {
+			wallThickness = val
+		}
+	}
+
+	// How far a bullet may cut into a wall's edge without stopping, so grazing shots don't clip. 0 stops at the edge
+	bulletWallTolerance := BulletWallTolerance
+	if toleranceStr := os.Getenv("BULLET_WALL_TOLERANCE"); toleranceStr != "" {
+		if val, err := strconv.ParseFloat(toleranceStr, 64); err == nil && val >= 0 {
+			bulletWallTolerance = val
+		}
+	}
+
+	// Degrees a player can turn in a single tick, however long the tick took. 0 allows one
+	// tick interval's worth of turning.
+	maxRotationPerTick := 0.0
+	if rotationStr := os.Getenv("MAX_ROTATION_PER_TICK"); rotationStr != "" {
+		if val, err := strconv.ParseFloat(rotationStr, 64); err == nil && val > 0 && val <= 360 {
+			maxRotationPerTick = val
+		}
+	}
+
+	// Shops scale their prices by a random multiplier within 1 ± this fraction, 0 keeps base prices
+	shopPriceVariation := 0.0
+	if variationStr := os.Getenv("SHOP_PRICE_VARIATION"); variationStr != "" {
+		if val, err := strconv.ParseFloat(variationStr, 64); err == nil && val >= 0 && val < 1 {
+			shopPriceVariation = val
+		}
+	}
+
+	// Send the spawn position of bullets so clients can draw trails and beams
+	bulletTrails := false
+	if trailsStr := os.Getenv("BULLET_TRAILS"); trailsStr == "true" {
+		bulletTrails = true
+	}
+
+	// Serve the engine stats of every live session at /api/v1/debug/sessions, to requests bearing DEBUG_STATS_TOKEN
+	debugStatsEnabled := false
+	if statsStr := os.Getenv("DEBUG_STATS_ENABLED"); statsStr == "true" {
+		debugStatsEnabled = true
+	}
+
+	// Tell clients how long dead enemies linger so they can fade them out
+	enemyDeathFade := false
+	if fadeStr := os.Getenv("ENEMY_DEATH_FADE"); fadeStr == "true" {
+		enemyDeathFade = true
+	}
+
+	// Group chunks into zones like forests and caves with their own generation parameters
+	zonesEnabled := false
+	if zonesStr := os.Getenv("ZONES_ENABLED"); zonesStr == "true" {
+		zonesEnabled = true
+	}
+
+	// Always send coarse positions of the other players in the session, e.g. for a minimap
+	teammatePositions := false
+	if positionsStr := os.Getenv("TEAMMATE_POSITIONS"); positionsStr == "true" {
+		teammatePositions = true
+	}
+
+	// Players have to wait this long after dying before they can respawn
+	var respawnDelay time.Duration
+	if delayStr := os.Getenv("RESPAWN_DELAY_MS"); delayStr != "" {
+		if val, err := strconv.Atoi(delayStr); err == nil && val > 0 {
+			respawnDelay = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Player bullets cancel enemy bullets they run into
+	shootableEnemyBullets := false
+	if shootableStr := os.Getenv("SHOOTABLE_ENEMY_BULLETS"); shootableStr == "true" {
+		shootableEnemyBullets = true
+	}
+
+	// Generated shops keep at least this far from shops in other chunks, 0 allows any spacing
+	minShopDistance := 0.0
+	if distanceStr := os.Getenv("MIN_SHOP_DISTANCE"); distanceStr != "" {
+		if val, err := strconv.ParseFloat(distanceStr, 64); err == nil && val > 0 {
+			minShopDistance = val
+		}
+	}
+
+	// Switch to a weapon right after picking it up, see the AutoEquip modes
+	autoEquipWeapons := AutoEquipOff
+	switch equipStr := os.Getenv("AUTO_EQUIP_WEAPONS"); equipStr {
+	case AutoEquipBetter, AutoEquipAlways:
+		autoEquipWeapons = equipStr
+	}
+
+	// Give sessions created without a name a random one instead of rejecting them
+	autoSessionNames := false
+	if namesStr := os.Getenv("AUTO_SESSION_NAMES"); namesStr == "true" {
+		autoSessionNames = true
+	}
+
+	// An enemy opening fire alerts the enemies within this distance to its target, 0 disables it
+	enemyAggroRadius := 0.0
+	if radiusStr := os.Getenv("ENEMY_AGGRO_RADIUS"); radiusStr != "" {
+		if val, err := strconv.ParseFloat(radiusStr, 64); err == nil && val > 0 {
+			enemyAggroRadius = val
+		}
+	}
+
+	// Share of wall enemies spawned as summoners, 0 disables them
+	summonerChance := 0.0
+	if chanceStr := os.Getenv("SUMMONER_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			summonerChance = val
+		}
+	}
+
+	// Time between a summoner's minions while players are near
+	summonInterval := 8 * time.Second
+	if intervalStr := os.Getenv("SUMMON_INTERVAL_MS"); intervalStr != "" {
+		if val, err := strconv.Atoi(intervalStr); err == nil && val > 0 {
+			summonInterval = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Living minions a summoner may have at once
+	summonerMinionCap := 3
+	if capStr := os.Getenv("SUMMONER_MINION_CAP"); capStr != "" {
+		if val, err := strconv.Atoi(capStr); err == nil && val > 0 {
+			summonerMinionCap = val
+		}
+	}
+
+	// Targets a railgun shot may pierce, 0 for every target up to the first wall
+	railgunPenetration := 0
+	if penetrationStr := os.Getenv("RAILGUN_PENETRATION"); penetrationStr != "" {
+		if val, err := strconv.Atoi(penetrationStr); err == nil && val > 0 {
+			railgunPenetration = val
+		}
+	}
+
+	// How much of the lead-up to a player's death is sent back to them as a kill-cam, 0 disables it
+	killCamDuration := time.Duration(0)
+	if killCamStr := os.Getenv("KILL_CAM_MS"); killCamStr != "" {
+		if val, err := strconv.Atoi(killCamStr); err == nil && val >= 0 {
+			killCamDuration = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Sessions a server keeps in memory at once, 0 for no limit
+	maxLoadedSessions := 0
+	if maxStr := os.Getenv("MAX_LOADED_SESSIONS"); maxStr != "" {
+		if val, err := strconv.Atoi(maxStr); err == nil && val > 0 {
+			maxLoadedSessions = val
+		}
+	}
+
+	// How long a player whose connection dropped stays in the session waiting to reconnect, 0 removes them right away
+	reconnectGracePeriod := 30 * time.Second
+	if graceStr := os.Getenv("RECONNECT_GRACE_PERIOD_MS"); graceStr != "" {
+		if val, err := strconv.Atoi(graceStr); err == nil && val >= 0 {
+			reconnectGracePeriod = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// How long a player stays on a full session's waitlist without polling it, 0 turns waitlists off
+	sessionWaitlistTTL := time.Duration(0)
+	if ttlStr := os.Getenv("SESSION_WAITLIST_TTL_MS"); ttlStr != "" {
+		if val, err := strconv.Atoi(ttlStr); err == nil && val > 0 {
+			sessionWaitlistTTL = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Whether sessions keep track of when they started, their peak player count and how many players they had
+	sessionActivityEnabled := false
+	if activityStr := os.Getenv("SESSION_ACTIVITY_ENABLED"); activityStr == "true" {
+		sessionActivityEnabled = true
+	}
+
+	// How long a session stays loaded after its last player leaves, 0 to unload it right away
+	sessionKeepAlive := time.Duration(0)
+	if keepAliveStr := os.Getenv("SESSION_KEEP_ALIVE_MS"); keepAliveStr != "" {
+		if val, err := strconv.Atoi(keepAliveStr); err == nil && val > 0 {
+			sessionKeepAlive = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Clock skew tolerated when checking token expiry and issue times
+	jwtLeeway := time.Duration(0)
+	if leewayStr := os.Getenv("JWT_LEEWAY_MS"); leewayStr != "" {
+		if val, err := strconv.Atoi(leewayStr); err == nil && val > 0 {
+			jwtLeeway = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Share of their full lives below which moving enemies run from the players they see, 0 disables it
+	enemyFleeThreshold := 0.0
+	if thresholdStr := os.Getenv("ENEMY_FLEE_THRESHOLD"); thresholdStr != "" {
+		if val, err := strconv.ParseFloat(thresholdStr, 64); err == nil && val > 0 && val <= 1 {
+			enemyFleeThreshold = val
+		}
+	}
+
+	// Share of chunks with a locked loot room, 0 disables them
+	lockedRoomChance := 0.0
+	if chanceStr := os.Getenv("LOCKED_ROOM_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			lockedRoomChance = val
+		}
+	}
+
+	// Time between game loop ticks, checked against wall thickness on startup to prevent tunneling
+	gameLoopInterval := DefaultGameLoopInterval
+	if intervalStr := os.Getenv("GAME_LOOP_INTERVAL_MS"); intervalStr != "" {
+		if val, err := strconv.Atoi(intervalStr); err == nil && val > 0 {
+			gameLoopInterval = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Clients whose send buffer is filled past this share get deltas less often, 0 disables throttling
+	deltaThrottleThreshold := 0.0
+	if thresholdStr := os.Getenv("DELTA_THROTTLE_THRESHOLD"); thresholdStr != "" {
+		if val, err := strconv.ParseFloat(thresholdStr, 64); err == nil && val > 0 && val <= 1 {
+			deltaThrottleThreshold = val
+		}
+	}
+
+	// Most ticks a throttled client may go without a delta
+	deltaThrottleMaxInterval := 4
+	if intervalStr := os.Getenv("DELTA_THROTTLE_MAX_INTERVAL"); intervalStr != "" {
+		if val, err := strconv.Atoi(intervalStr); err == nil && val > 1 {
+			deltaThrottleMaxInterval = val
+		}
+	}
+
+	// Connections without a session ID rejoin the user's last session, which is kept after they disconnect
+	autoRejoinSession := false
+	if rejoinStr := os.Getenv("AUTO_REJOIN_SESSION"); rejoinStr == "true" {
+		autoRejoinSession = true
+	}
+
+	// Chests dropped by dead players are visible to the dropper's teammates regardless of fog
+	teamDroppedChests := false
+	if chestsStr := os.Getenv("TEAM_DROPPED_CHESTS"); chestsStr == "true" {
+		teamDroppedChests = true
+	}
+
+	// Share of wall enemies spawned armored, only rockets and the railgun hurt them. 0 disables them
+	armoredEnemyChance := 0.0
+	if chanceStr := os.Getenv("ARMORED_ENEMY_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			armoredEnemyChance = val
+		}
+	}
+
+	// Spawn wall enemies as grunts, brutes and scouts, with their own lives, speed and reward
+	enemyArchetypes := false
+	if archetypesStr := os.Getenv("ENEMY_ARCHETYPES"); archetypesStr == "true" {
+		enemyArchetypes = true
+	}
+
+	// Bullets enemies fire per shot, fanned out like a weak shotgun on harder difficulties
+	enemyBulletSpread := 1
+	if spreadStr := os.Getenv("ENEMY_BULLET_SPREAD"); spreadStr != "" {
+		if val, err := strconv.Atoi(spreadStr); err == nil && val > 0 {
+			enemyBulletSpread = val
+		}
+	}
+
+	// Players can bank money on their account, keeping it across sessions
+	bankingEnabled := false
+	if bankingStr := os.Getenv("BANKING_ENABLED"); bankingStr == "true" {
+		bankingEnabled = true
+	}
+
+	// When every player in a session is dead at once, the session starts over in a fresh dungeon
+	hardcoreMode := false
+	if hardcoreStr := os.Getenv("HARDCORE_MODE"); hardcoreStr == "true" {
+		hardcoreMode = true
+	}
+
+	// Clients connecting with debug=true get entity counts around their player in every delta, for a debug HUD
+	clientDebugEnabled := false
+	if debugStr := os.Getenv("CLIENT_DEBUG_ENABLED"); debugStr == "true" {
+		clientDebugEnabled = true
+	}
+
+	// Largest angle in degrees enemy shots miss by, and how much it grows per 100 units to the target
+	enemyAimInaccuracy := 0.0
+	if inaccuracyStr := os.Getenv("ENEMY_AIM_INACCURACY"); inaccuracyStr != "" {
+		if val, err := strconv.ParseFloat(inaccuracyStr, 64); err == nil && val >= 0 {
+			enemyAimInaccuracy = val
+		}
+	}
+	enemyAimInaccuracyPer100 := 0.0
+	if inaccuracyStr := os.Getenv("ENEMY_AIM_INACCURACY_PER_100"); inaccuracyStr != "" {
+		if val, err := strconv.ParseFloat(inaccuracyStr, 64); err == nil && val >= 0 {
+			enemyAimInaccuracyPer100 = val
+		}
+	}
+
+	// Distance from a player's edge each bonus type is picked up at, half the bonus size by default
+	pickupRadius := func(key string, defaultRadius float64) float64 {
+		if radiusStr := os.Getenv(key); radiusStr != "" {
+			if val, err := strconv.ParseFloat(radiusStr, 64); err == nil && val >= 0 {
+				return val
+			}
+		}
+		return defaultRadius
+	}
+
+	// Distance within which enemies react to players, and the length of a railgun beam.
+	// Both are separate from config.SightRadius, how far players see.
+	enemyAwarenessRadius := EnemyAwarenessRadius
+	if radiusStr := os.Getenv("ENEMY_AWARENESS_RADIUS"); radiusStr != "" {
+		if val, err := strconv.ParseFloat(radiusStr, 64); err == nil && val > 0 {
+			enemyAwarenessRadius = val
+		}
+	}
+	railgunRange := RailgunRange
+	if rangeStr := os.Getenv("RAILGUN_RANGE"); rangeStr != "" {
+		if val, err := strconv.ParseFloat(rangeStr, 64); err == nil && val > 0 {
+			railgunRange = val
+		}
+	}
+
+	// Send joins and leaves in the per-player deltas instead of separate messages, keeping them in order with player updates
+	joinLeaveInDeltas := false
+	if joinLeaveStr := os.Getenv("JOIN_LEAVE_IN_DELTAS"); joinLeaveStr == "true" {
+		joinLeaveInDeltas = true
+	}
+
+	// Most bullets flying in one chunk at once, the oldest are removed past it (0 for no limit)
+	maxBulletsPerChunk := 0
+	if maxStr := os.Getenv("MAX_BULLETS_PER_CHUNK"); maxStr != "" {
+		if val, err := strconv.Atoi(maxStr); err == nil && val >= 0 {
+			maxBulletsPerChunk = val
+		}
+	}
+
+	// Weapons whose hits poison, for players and enemies alike, and how hard and long the poison hurts
+	var poisonWeapons []string
+	if weaponsStr := os.Getenv("POISON_WEAPONS"); weaponsStr != "" {
+		for _, weaponType := range strings.Split(weaponsStr, ",") {
+			if weaponType = strings.TrimSpace(weaponType); weaponType != "" {
+				poisonWeapons = append(poisonWeapons, weaponType)
+			}
+		}
+	}
+	poisonDamage := PoisonDamagePerSecond
+	if damageStr := os.Getenv("POISON_DAMAGE"); damageStr != "" {
+		if val, err := strconv.ParseFloat(damageStr, 64); err == nil && val > 0 {
+			poisonDamage = val
+		}
+	}
+	poisonDuration := PoisonDuration * time.Second
+	if durationStr := os.Getenv("POISON_DURATION_MS"); durationStr != "" {
+		if val, err := strconv.Atoi(durationStr); err == nil && val > 0 {
+			poisonDuration = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Chance for a shot of each weapon to jam instead of firing, as weapon:chance pairs, e.g. "blaster:0.02,shotgun:0.05"
+	gunJamChance := make(map[string]float64)
+	if jamStr := os.Getenv("GUN_JAM_CHANCE"); jamStr != "" {
+		for _, pair := range strings.Split(jamStr, ",") {
+			weaponType, chanceStr, found := strings.Cut(strings.TrimSpace(pair), ":")
+			if !found {
+				continue
+			}
+			if val, err := strconv.ParseFloat(strings.TrimSpace(chanceStr), 64); err == nil && val > 0 && val <= 1 {
+				gunJamChance[strings.TrimSpace(weaponType)] = val
+			}
+		}
+	}
+
+	// Damage enemies deal to players they see within reach, on top of shooting. 0 disables enemy melee
+	enemyMeleeDamage := 0.0
+	if damageStr := os.Getenv("ENEMY_MELEE_DAMAGE"); damageStr != "" {
+		if val, err := strconv.ParseFloat(damageStr, 64); err == nil && val > 0 {
+			enemyMeleeDamage = val
+		}
+	}
+
+	// Gap between an enemy and a player at which the enemy hits them
+	enemyMeleeRange := EnemyMeleeRange
+	if rangeStr := os.Getenv("ENEMY_MELEE_RANGE"); rangeStr != "" {
+		if val, err := strconv.ParseFloat(rangeStr, 64); err == nil && val > 0 {
+			enemyMeleeRange = val
+		}
+	}
+
+	// Time between an enemy's melee hits
+	enemyMeleeInterval := EnemyMeleeInterval * time.Second
+	if intervalStr := os.Getenv("ENEMY_MELEE_INTERVAL_MS"); intervalStr != "" {
+		if val, err := strconv.Atoi(intervalStr); err == nil && val > 0 {
+			enemyMeleeInterval = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Share of wall enemies spawned as flashers, scaled by each zone, 0 disables them
+	flasherChance := 0.0
+	if chanceStr := os.Getenv("FLASHER_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			flasherChance = val
+		}
+	}
+
+	// Distance within which a flasher's death flash blinds players, and how long it blinds them for
+	flasherFlashRadius := EnemyFlasherFlashRadius
+	if radiusStr := os.Getenv("FLASHER_FLASH_RADIUS"); radiusStr != "" {
+		if val, err := strconv.ParseFloat(radiusStr, 64); err == nil && val > 0 {
+			flasherFlashRadius = val
+		}
+	}
+	flasherBlindTime := PlayerBlindTime * time.Second
+	if blindStr := os.Getenv("FLASHER_BLIND_TIME_MS"); blindStr != "" {
+		if val, err := strconv.Atoi(blindStr); err == nil && val > 0 {
+			flasherBlindTime = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Weapons a player gets the first time they join a session, as weapon:ammo pairs, e.g. "shotgun:10,knife"
+	spawnWeapons := make(map[string]int32)
+	if weaponsStr := os.Getenv("SPAWN_WEAPONS"); weaponsStr != "" {
+		for _, pair := range strings.Split(weaponsStr, ",") {
+			weaponType, ammoStr, hasAmmo := strings.Cut(strings.TrimSpace(pair), ":")
+			if weaponType = strings.TrimSpace(weaponType); weaponType == "" {
+				continue
+			}
+			ammo := 0
+			if hasAmmo {
+				if val, err := strconv.Atoi(strings.TrimSpace(ammoStr)); err == nil && val > 0 {
+					ammo = val
+				}
+			}
+			spawnWeapons[weaponType] = int32(ammo)
+		}
+	}
+
+	// Share of wall enemies spawned as gatekeepers, who open a portal to a pocket of loot when they die. 0 disables them
+	gatekeeperChance := 0.0
+	if chanceStr := os.Getenv("GATEKEEPER_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			gatekeeperChance = val
+		}
+	}
+
+	// Share of wall enemies spawned as thieves, who grab players' money on contact and run. 0 disables them
+	thiefChance := 0.0
+	if chanceStr := os.Getenv("THIEF_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val > 0 && val <= 1 {
+			thiefChance = val
+		}
+	}
+
+	// How long a gatekeeper's portal stays open
+	portalLifetime := PortalLifetime * time.Second
+	if lifetimeStr := os.Getenv("PORTAL_LIFETIME_MS"); lifetimeStr != "" {
+		if val, err := strconv.Atoi(lifetimeStr); err == nil && val > 0 {
+			portalLifetime = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Chance for a dying lieutenant to drop a power-up instead of a regular bonus, 0 disables power-ups
+	powerUpDropChance := EnemyLieutenantPowerUpDropChance
+	if chanceStr := os.Getenv("POWER_UP_DROP_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val >= 0 && val <= 1 {
+			powerUpDropChance = val
+		}
+	}
+
+	// Shares of dropped power-ups granting double damage and rapid fire, the rest are speed boosts
+	powerUpDamageChance := PowerUpDoubleDamageChance
+	if chanceStr := os.Getenv("POWER_UP_DOUBLE_DAMAGE_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val >= 0 && val <= 1 {
+			powerUpDamageChance = val
+		}
+	}
+	powerUpRapidFireChance := PowerUpRapidFireChance
+	if chanceStr := os.Getenv("POWER_UP_RAPID_FIRE_CHANCE"); chanceStr != "" {
+		if val, err := strconv.ParseFloat(chanceStr, 64); err == nil && val >= 0 && val <= 1 {
+			powerUpRapidFireChance = val
+		}
+	}
+	if powerUpDamageChance+powerUpRapidFireChance > 1 {
+		log.Println("POWER_UP_DOUBLE_DAMAGE_CHANCE and POWER_UP_RAPID_FIRE_CHANCE add up to more than 1, using the defaults")
+		powerUpDamageChance = PowerUpDoubleDamageChance
+		powerUpRapidFireChance = PowerUpRapidFireChance
+	}
+
+	// How long a picked up power-up lasts
+	powerUpDuration := time.Duration(PowerUpTime * float64(time.Second))
+	if durationStr := os.Getenv("POWER_UP_DURATION_MS"); durationStr != "" {
+		if val, err := strconv.Atoi(durationStr); err == nil && val > 0 {
+			powerUpDuration = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	multiplier := func(key string, defaultMultiplier float64) float64 {
+		if multiplierStr := os.Getenv(key); multiplierStr != "" {
+			if val, err := strconv.ParseFloat(multiplierStr, 64); err == nil && val > 0 {
+				return val
+			}
+		}
+		return defaultMultiplier
+	}
+
+	// What double damage multiplies damage by, rapid fire the shoot delay by and speed boost the speed by
+	powerUpDamageMultiplier := multiplier("POWER_UP_DAMAGE_MULTIPLIER", PowerUpDamageMultiplier)
+	powerUpShootDelayFactor := multiplier("POWER_UP_SHOOT_DELAY_MULTIPLIER", PowerUpShootDelayMultiplier)
+	powerUpSpeedMultiplier := multiplier("POWER_UP_SPEED_MULTIPLIER", PowerUpSpeedMultiplier)
+
+	// Requests a client IP may make to each REST endpoint per minute, 0 for no limit
+	rateLimitPerMinute := 0
+	if limitStr := os.Getenv("RATE_LIMIT_PER_MINUTE"); limitStr != "" {
+		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
+			rateLimitPerMinute = val
+		}
+	}
+
+	// Stricter limit for creating and joining sessions, where private session passwords are checked. 0 for no limit
+	rateLimitJoinPerMinute := 0
+	if limitStr := os.Getenv("RATE_LIMIT_JOIN_PER_MINUTE"); limitStr != "" {
+		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
+			rateLimitJoinPerMinute = val
+		}
+	}
+
+	// Behind a reverse proxy, rate limit clients by the address the proxy appended to X-Forwarded-For instead of the connection's
+	rateLimitTrustProxy := false
+	if trustStr := os.Getenv("RATE_LIMIT_TRUST_PROXY"); trustStr == "true" {
+		rateLimitTrustProxy = true
+	}
+
+	// Record every input players send in every session for anti-cheat review, sessions can also turn it on for themselves
+	recordInputs := false
+	if recordStr := os.Getenv("RECORD_INPUTS"); recordStr == "true" {
+		recordInputs = true
+	}
+
 	config := &Config{
 		MongoDBURL:               getEnvOrDefault("MONGODB_URL", ""),
 		SecretKey:                getEnvOrDefault("SECRET_KEY", ""),
@@ -65,6 +838,101 @@ func LoadConfig() *Config {
 		TLSCert:                  getEnvOrDefault("TLS_CERT", ""),
 		TLSKey:                   getEnvOrDefault("TLS_KEY", ""),
 		EngineDebugMode:          engineDebugMode,
+		ClientPredictionEnabled:  clientPredictionEnabled,
+		SlowTickThreshold:        slowTickThreshold,
+		AssistsEnabled:           assistsEnabled,
+		AssistWindow:             assistWindow,
+		AssistRewardFraction:     assistRewardFraction,
+		BulletLODEnabled:         bulletLODEnabled,
+		BulletLODDistance:        bulletLODDistance,
+		BulletLODInterval:        bulletLODInterval,
+		SprintEnabled:            sprintEnabled,
+		ChestInvulnerability:     chestPickupInvulnerability,
+		StealthEnabled:           stealthEnabled,
+		RevealAllShops:           revealAllShops,
+		EnemyLookAhead:           enemyLookAhead,
+		MaxMoney:                 maxMoney,
+		MaxScore:                 maxScore,
+		DBWorkers:                dbWorkers,
+		InstantEnemyRemoval:      instantEnemyRemoval,
+		JoinReservationTTL:       joinReservationTTL,
+		LeaderboardFlush:         leaderboardFlush,
+		WallThickness:            wallThickness,
+		ShopPriceVariation:       shopPriceVariation,
+		BulletTrails:             bulletTrails,
+		ZonesEnabled:             zonesEnabled,
+		TeammatePositions:        teammatePositions,
+		RespawnDelay:             respawnDelay,
+		ShootableEnemyBullets:    shootableEnemyBullets,
+		MinShopDistance:          minShopDistance,
+		AutoEquipWeapons:         autoEquipWeapons,
+		AutoSessionNames:         autoSessionNames,
+		EnemyAggroRadius:         enemyAggroRadius,
+		SummonerChance:           summonerChance,
+		SummonInterval:           summonInterval,
+		SummonerMinionCap:        summonerMinionCap,
+		RailgunPenetration:       railgunPenetration,
+		MaxLoadedSessions:        maxLoadedSessions,
+		SessionKeepAlive:         sessionKeepAlive,
+		JWTLeeway:                jwtLeeway,
+		EnemyFleeThreshold:       enemyFleeThreshold,
+		LockedRoomChance:         lockedRoomChance,
+		GameLoopInterval:         gameLoopInterval,
+		DeltaThrottleThreshold:   deltaThrottleThreshold,
+		DeltaThrottleMaxInterval: deltaThrottleMaxInterval,
+		AutoRejoinSession:        autoRejoinSession,
+		TeamDroppedChests:        teamDroppedChests,
+		ArmoredEnemyChance:       armoredEnemyChance,
+		EnemyBulletSpread:        enemyBulletSpread,
+		BankingEnabled:           bankingEnabled,
+		HardcoreMode:             hardcoreMode,
+		ClientDebugEnabled:       clientDebugEnabled,
+		EnemyAimInaccuracy:       enemyAimInaccuracy,
+		EnemyAimInaccuracyPer100: enemyAimInaccuracyPer100,
+		AidKitPickupRadius:       pickupRadius("AID_KIT_PICKUP_RADIUS", AidKitSize/2),
+		GogglesPickupRadius:      pickupRadius("GOGGLES_PICKUP_RADIUS", GogglesSize/2),
+		ChestPickupRadius:        pickupRadius("CHEST_PICKUP_RADIUS", ChestSize/2),
+		KeyPickupRadius:          pickupRadius("KEY_PICKUP_RADIUS", KeySize/2),
+		PowerUpPickupRadius:      pickupRadius("POWER_UP_PICKUP_RADIUS", PowerUpSize/2),
+		EnemyAwarenessRadius:     enemyAwarenessRadius,
+		RailgunRange:             railgunRange,
+		JoinLeaveInDeltas:        joinLeaveInDeltas,
+		MaxBulletsPerChunk:       maxBulletsPerChunk,
+		PoisonWeapons:            poisonWeapons,
+		PoisonDamage:             poisonDamage,
+		PoisonDuration:           poisonDuration,
+		GatekeeperChance:         gatekeeperChance,
+		ThiefChance:              thiefChance,
+		PortalLifetime:           portalLifetime,
+		RateLimitPerMinute:       rateLimitPerMinute,
+		RateLimitJoinPerMinute:   rateLimitJoinPerMinute,
+		RateLimitTrustProxy:      rateLimitTrustProxy,
+		RecordInputs:             recordInputs,
+		ReconnectGracePeriod:     reconnectGracePeriod,
+		MaxRotationPerTick:       maxRotationPerTick,
+		KillCamDuration:          killCamDuration,
+		GunJamChance:             gunJamChance,
+		EnemyArchetypes:          enemyArchetypes,
+		SessionWaitlistTTL:       sessionWaitlistTTL,
+		EnemyMeleeDamage:         enemyMeleeDamage,
+		EnemyMeleeRange:          enemyMeleeRange,
+		EnemyMeleeInterval:       enemyMeleeInterval,
+		SpawnWeapons:             spawnWeapons,
+		SessionActivityEnabled:   sessionActivityEnabled,
+		EnemyDeathFade:           enemyDeathFade,
+		DebugStatsEnabled:        debugStatsEnabled,
+		DebugStatsToken:          os.Getenv("DEBUG_STATS_TOKEN"),
+		FlasherFlashRadius:       flasherFlashRadius,
+		FlasherBlindTime:         flasherBlindTime,
+		FlasherChance:            flasherChance,
+		BulletWallTolerance:      bulletWallTolerance,
+		PowerUpDropChance:        powerUpDropChance,
+		PowerUpDamageChance:      powerUpDamageChance,
+		PowerUpRapidFireChance:   powerUpRapidFireChance,
+		PowerUpDuration:          powerUpDuration,
+		PowerUpDamageMultiplier:  powerUpDamageMultiplier,
+		PowerUpShootDelayFactor:  powerUpShootDelayFactor,
+		PowerUpSpeedMultiplier:   powerUpSpeedMultiplier,
 	}
 
 	// Validate required fields
@@ -111,6 +979,12 @@ const (
 	PlayerReward                   = 100.0 // Money for killing enemy
 	PlayerDropInventoryLifetime    = 5 * time.Minute
 
+	// Sprint constants
+	PlayerMaxStamina       = 100.0
+	SprintSpeedMultiplier  = 1.6
+	SprintStaminaDrainRate = 40.0 // Stamina per second while sprinting
+	SprintStaminaRegenRate = 20.0 // Stamina per second while not sprinting
+
 	// Blaster constants
 	BlasterBulletDamage       = 1
 	BlasterBulletSize         = 8.0
@@ -129,6 +1003,7 @@ const (
 	ShotgunNumPellets         = 8
 	ShotgunDamage             = 2.0
 	ShotgunRange              = 200.0
+	ShotgunReloadTime         = 1.5 // Seconds a manual reload keeps the player from firing
 
 	// Rocket Launcher constants
 	RocketLauncherShootDelay     = 1.5   // Seconds
@@ -140,13 +1015,25 @@ const (
 	// Railgun constants
 	RailgunShootDelay = 1.0 // Seconds
 	RailgunDamage     = 3.0
-	RailgunRange      = SightRadius
+	RailgunRange      = 1500.0
+
+	// Melee constants
+	MeleeShootDelay = 0.4 // Seconds between swings
+	MeleeDamage     = 1.5
+	MeleeRange      = 50.0 // Reach from the player's edge
+	MeleeArcDegrees = 90.0 // Width of a swing, centered on where the player faces
+
+	// Enemy melee constants, enemies hit players who get too close
+	EnemyMeleeRange    = 20.0 // Reach from the enemy's edge
+	EnemyMeleeInterval = 1    // Seconds between hits
 
 	// Enemy constants
 	EnemyDeathTraceTime      = 5.0  // Seconds
 	EnemyTowerDeathTraceTime = 30.0 // Seconds
 	EnemyLieutenantChance    = 0.15 // 15% chance to spawn lieutenant instead of soldier
 	EnemySpawnChancePerWall  = 0.8  // 80% chance to spawn enemy for each wall
+	EnemyGuardRouteChance    = 0.2  // 20% chance for a wall enemy to guard the route to a nearby wall
+	EnemyGuardRouteMaxLength = 600.0
 
 	// Enemy soldier constants
 	EnemySoldierSpeed         = 120.0 // Units per second
@@ -157,6 +1044,8 @@ const (
 	EnemySoldierLives       = 1.0
 	EnemySoldierShootDelay  = 1.0   // Seconds
 	EnemySoldierBulletSpeed = 240.0 // Units per second
+	EnemyBulletSpreadAngle  = 20.0  // Degrees covered by an enemy's spread of bullets
+	EnemyMaxAimDeviation    = 45.0  // Degrees, cap on how far an enemy's shot can miss its aim
 
 	EnemySoldierReward            = 20.0 // Money reward
 	EnemySoldierDropChance        = 0.3  // 30% chance to drop bonus
@@ -169,6 +1058,56 @@ const (
 	EnemyLieutenantDropChance       = 0.5  // 50% chance to drop bonus
 	EnemyLieutenantDropChanceWeapon = 0.3  // 30% chance to drop weapon if dropping bonus
 
+	EnemyLieutenantPowerUpDropChance = 0.2 // 20% chance to drop a power-up instead of a regular bonus
+
+	// Enemy flasher constants
+	EnemyFlasherLives       = 1.0
+	EnemyFlasherShootDelay  = 1.5  // Seconds
+	EnemyFlasherReward      = 30.0 // Money reward
+	EnemyFlasherFlashRadius = 300.0
+	PlayerBlindTime         = 3.0 // Seconds a flash blinds players
+	NightVisionBlindFactor  = 0.5 // Goggles soften the flash
+
+	// Enemy summoner constants, summoners stand still and call in minions while players are near
+	EnemySummonerSize       = 32.0
+	EnemySummonerLives      = 4.0
+	EnemySummonerShootDelay = 2.0  // Seconds
+	EnemySummonerReward     = 80.0 // Money reward
+
+	// Enemy minion constants, minions patrol their summoner's wall like soldiers and drop nothing
+	EnemyMinionSize       = 18.0
+	EnemyMinionLives      = 0.5
+	EnemyMinionShootDelay = 1.5 // Seconds
+	EnemyMinionReward     = 5.0 // Money reward
+
+	// Enemy keyholder constants, keyholders guard the door of a locked room and always drop its key
+	EnemyKeyholderLives      = 3.0
+	EnemyKeyholderShootDelay = 1.0  // Seconds
+	EnemyKeyholderReward     = 50.0 // Money reward
+
+	// Enemy gatekeeper constants, gatekeepers open a portal to a pocket of loot when they die
+	EnemyGatekeeperSize       = 36.0
+	EnemyGatekeeperLives      = 5.0
+	EnemyGatekeeperShootDelay = 1.0   // Seconds
+	EnemyGatekeeperReward     = 100.0 // Money reward
+
+	// Enemy thief constants, thieves run at players, grab their money and flee with it
+	EnemyThiefLives         = 2.0
+	EnemyThiefSpeed         = 200.0 // Units per second
+	EnemyThiefReward        = 40.0  // Money reward
+	EnemyThiefStealFraction = 0.5   // Share of the player's money a thief grabs
+	EnemyThiefReach         = 4.0   // Gap between a thief and a player at which it grabs their money
+
+	// Enemy archetype constants, archetypes scale the lives, speed and reward of a wall enemy's type
+	EnemyBruteChance           = 0.2 // 20% chance for a wall enemy to be a brute
+	EnemyBruteLivesMultiplier  = 2.0
+	EnemyBruteSpeedMultiplier  = 0.6
+	EnemyBruteRewardMultiplier = 2.0
+	EnemyScoutChance           = 0.2 // 20% chance for a wall enemy to be a scout
+	EnemyScoutLivesMultiplier  = 0.5
+	EnemyScoutSpeedMultiplier  = 1.6
+	EnemyScoutRewardMultiplier = 1.0
+
 	// Enemy tower constants
 	EnemyTowerLives       = 30.0
 	EnemyTowerShootDelay  = 2.0   // Seconds
@@ -181,29 +1120,80 @@ const (
 
 	EnemyTowerRotationSpeed = 90.0 // Degrees per second
 
+	EnemyAggroDuration = 5.0 // Seconds alerted enemies keep turning towards the player
+
 	// Bonus constants
 	AidKitSize        = 32.0
 	AidKitHealAmount  = 1.0
 	GogglesSize       = 32.0
 	GogglesActiveTime = 20.0 // Seconds
 	ChestSize         = 32.0
+	KeySize           = 24.0
+
+	// Power-up constants
+	PowerUpSize                 = 32.0
+	PowerUpDoubleDamageChance   = 0.4  // Share of dropped power-ups granting double damage
+	PowerUpRapidFireChance      = 0.3  // Share of dropped power-ups granting rapid fire, the rest is speed boost
+	PowerUpTime                 = 10.0 // Seconds a picked up power-up lasts
+	PowerUpDamageMultiplier     = 2.0
+	PowerUpShootDelayMultiplier = 0.5
+	PowerUpSpeedMultiplier      = 1.5
+
+	// Poison constants
+	PoisonDamagePerSecond = 0.5
+	PoisonDuration        = 3 // Seconds
+	PoisonMaxStacks       = 3 // Hits whose poison adds up
+
+	// Gun jam constants
+	GunJamRecoveryTime = 0.5 // Seconds before a jammed gun fires again
 
 	// World constants
 	ChunkSize            = 2000.0
-	SightRadius          = 1500.0
+	SightRadius          = 1500.0 // How far players see entities around them
+	EnemyAwarenessRadius = 1500.0 // Distance within which enemies react to players
 	WallWidth            = 30.0
+	BulletWallTolerance  = 1.0 // Default units a bullet may graze a wall edge without stopping
+	BulletInterceptRange = 8.0 // Distance at which a player bullet cancels an enemy bullet
 	MinWallsPerKiloPixel = 5
 	MaxWallsPerKiloPixel = 10
 	ShopSize             = 64.0
+	BulletHitCellSize    = EnemySoldierSize * 2 // Four enemy radii, players and enemies are bucketed into cells this big for bullet hits
+
+	// Locked room constants, rooms are closed on all sides with a door on one of them
+	LockedRoomSize       = 320.0
+	LockedRoomDoorWidth  = 80.0
+	LockedRoomDoorReach  = 5.0 // Distance from a door at which a player carrying a key opens it
+	LockedRoomChestMoney = 500
+	LockedRoomChestAmmo  = 20 // Of each ammo type
+
+	// Portal constants, portals lead to pocket rooms generated far away from the rest of the world
+	PortalSize        = 48.0
+	PortalLifetime    = 60     // Seconds
+	PocketChunkX      = 100000 // Chunk of the first pocket, the next ones follow along X
+	PocketChunkY      = 100000
+	PocketSize        = 1200.0
+	PocketChests      = 3
+	PocketChestMoney  = 300
+	PocketChestAmmo   = 10 // Of each ammo type
+	PocketGuards      = 3  // Lieutenants patrolling walls inside the pocket
+	PocketGuardLength = 300.0
+
+	// Kill-cam constants
+	KillCamRadius = 800.0 // Killers and bullets further than this from the player are left out of their kill-cam
 
 	// Vision constants
 	TorchRadius                = 200.0
 	NightVisionDetectionRadius = 100.0
+	StationaryDetectionFactor  = 0.6   // Enemies spot players standing still at this share of the usual range
+	TeammatePositionGrid       = 100.0 // Hidden teammates' positions are rounded to this grid
 
 	// Session constants
 	SessionSaveInterval      = 5 * time.Minute
 	DeadEntitiesCacheTimeout = 5 * time.Second
-	GameLoopInterval         = time.Second / 30
+	DefaultGameLoopInterval  = time.Second / 30
+	ThrottleRecoveryDeltas   = 30 // Deltas a throttled client has to take without a near-full buffer before its rate doubles
+	InputRecordFlushInterval = 10 * time.Second
+	MaxRecordedInputs        = 20000 // Inputs a session holds between flushes, the ones over it are dropped
 
 	// Shop constants
 	ShopAmmoProbability = 0.7
@@ -235,3 +1225,34 @@ const (
 	TowerGogglesMinQuantity = 1
 	TowerGogglesMaxQuantity = 2
 )
+
+// Zone holds the generation parameters of a biome. Chunks are grouped into
+// square regions of ZoneSizeInChunks, and every region is assigned one zone.
+type Zone struct {
+	Name             string
+	WallDensity      float64 // Multiplier for the number of walls per chunk
+	EnemySpawnChance float64 // Chance to spawn an enemy for each wall
+	LieutenantChance float64 // Chance for a wall enemy to be a lieutenant
+	FlasherFactor    float64 // Multiplier for the configured chance of a wall enemy to be a flasher
+	ShopChance       float64 // Chance for a chunk to have a shop
+}
+
+const ZoneSizeInChunks = 3
+
+// DefaultZone is used for every chunk when zones are disabled
+var DefaultZone = Zone{
+	WallDensity:      1,
+	EnemySpawnChance: EnemySpawnChancePerWall,
+	LieutenantChance: EnemyLieutenantChance,
+	FlasherFactor:    1,
+	ShopChance:       1,
+}
+
+var Zones = []Zone{
+	{Name: "ruins", WallDensity: 1, EnemySpawnChance: EnemySpawnChancePerWall, LieutenantChance: EnemyLieutenantChance, FlasherFactor: 1, ShopChance: 1},
+	{Name: "forest", WallDensity: 1.4, EnemySpawnChance: 0.6, LieutenantChance: 0.05, FlasherFactor: 2.5, ShopChance: 0.7},
+	{Name: "cave", WallDensity: 0.6, EnemySpawnChance: 0.95, LieutenantChance: 0.3, FlasherFactor: 0.5, ShopChance: 0.4},
+}
+
+// NoZone is sent to players who walk into a chunk without a zone, since an empty zone in a delta means it hasn't changed
+const NoZone = "none"
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUserRepositoryGrantAchievementOnce(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("first and repeated grant", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+		userID := primitive.NewObjectID()
+
+		// The server only modifies the user while the achievement is missing
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+		)
+
+		granted, err := repo.GrantAchievement(context.Background(), userID, "first_kill")
+		if err != nil || !granted {
+			mt.Fatalf("GrantAchievement() = %v, %v; want a new grant", granted, err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if ne, ok := update.Lookup("q", "achievements.id", "$ne").StringValueOK(); !ok || ne != "first_kill" {
+			mt.Errorf("update filter = %v, want users without first_kill", update.Lookup("q"))
+		}
+		if id, ok := update.Lookup("u", "$push", "achievements", "id").StringValueOK(); !ok || id != "first_kill" {
+			mt.Errorf("update = %v, want first_kill pushed to achievements", update.Lookup("u"))
+		}
+
+		granted, err = repo.GrantAchievement(context.Background(), userID, "first_kill")
+		if err != nil || granted {
+			mt.Errorf("GrantAchievement() = %v, %v; want the repeated grant ignored", granted, err)
+		}
+	})
+}
+
+func TestUserRepositoryUpdateKeepsAchievements(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("stale copy", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+		user := &User{
+			ID:           primitive.NewObjectID(),
+			Username:     "player",
+			Achievements: []Achievement{{ID: "first_kill", GrantedAt: time.Now()}},
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		if err := repo.Update(context.Background(), user); err != nil {
+			mt.Fatalf("Update() error = %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, err := update.LookupErr("u", "$set", "achievements"); err == nil {
+			mt.Error("expected Update to leave achievements to GrantAchievement")
+		}
+		if len(user.Achievements) != 1 {
+			mt.Error("expected Update not to modify the caller's user")
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUserRepositoryWithdrawNeedsEnoughBankedMoney(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("withdraw", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+
+		// The server finds no user with enough money
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		applied, err := repo.AddBankedMoney(context.Background(), primitive.NewObjectID(), -50)
+		if err != nil || applied {
+			mt.Errorf("AddBankedMoney() = %v, %v; want the withdrawal refused", applied, err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if gte, ok := update.Lookup("q", "banked_money", "$gte").Int32OK(); !ok || gte != 50 {
+			mt.Errorf("update filter = %v, want users with at least 50 banked", update.Lookup("q"))
+		}
+		if inc, ok := update.Lookup("u", "$inc", "banked_money").Int32OK(); !ok || inc != -50 {
+			mt.Errorf("update = %v, want banked money decremented by 50", update.Lookup("u"))
+		}
+	})
+}
+
+func TestUserRepositoryUpdateKeepsBankedMoney(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("stale copy", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+		user := &User{ID: primitive.NewObjectID(), Username: "player", BankedMoney: 120}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		if err := repo.Update(context.Background(), user); err != nil {
+			mt.Fatalf("Update() error = %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, err := update.LookupErr("u", "$set", "banked_money"); err == nil {
+			mt.Error("expected Update to leave banked money to AddBankedMoney")
+		}
+	})
+}
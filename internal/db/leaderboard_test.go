@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestLeaderboardRepositoryGetByUserIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("known and unknown ids", func(mt *mtest.T) {
+		known := primitive.NewObjectID()
+		unknown := primitive.NewObjectID()
+		repo := &LeaderboardRepository{collection: mt.Coll}
+
+		// Only the known user has entries, so that's all the server sends back
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: known}, {Key: "username", Value: "friend"}, {Key: "score", Value: 300}},
+			bson.D{{Key: "user_id", Value: known}, {Key: "username", Value: "friend"}, {Key: "score", Value: 120}},
+		))
+
+		entries, err := repo.GetByUserIDs(context.Background(), []primitive.ObjectID{known, unknown})
+		if err != nil {
+			mt.Fatalf("GetByUserIDs() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].Score != 300 || entries[0].UserID != known {
+			mt.Fatalf("GetByUserIDs() = %+v, want the known user's two entries", entries)
+		}
+
+		cmd := mt.GetStartedEvent().Command
+		in, ok := cmd.Lookup("filter", "user_id", "$in").ArrayOK()
+		if !ok {
+			mt.Fatalf("find filter = %v, want an $in on user_id", cmd.Lookup("filter"))
+		}
+		if values, _ := in.Values(); len(values) != 2 {
+			mt.Errorf("$in has %d ids, want 2", len(values))
+		}
+		if score, ok := cmd.Lookup("sort", "score").AsInt64OK(); !ok || score != -1 {
+			mt.Errorf("find sort = %v, want score descending", cmd.Lookup("sort"))
+		}
+	})
+
+	mt.Run("no ids", func(mt *mtest.T) {
+		repo := &LeaderboardRepository{collection: mt.Coll}
+
+		entries, err := repo.GetByUserIDs(context.Background(), nil)
+		if err != nil || len(entries) != 0 {
+			mt.Fatalf("GetByUserIDs(nil) = %v, %v; want no entries", entries, err)
+		}
+		if evt := mt.GetStartedEvent(); evt != nil {
+			mt.Errorf("expected no query without ids, got %s", evt.CommandName)
+		}
+	})
+}
+
+func TestLeaderboardRepositoryGetAllEntriesForUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("entries", func(mt *mtest.T) {
+		userID := primitive.NewObjectID()
+		repo := &LeaderboardRepository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: userID}, {Key: "session_id", Value: "latest"}, {Key: "score", Value: 80}},
+			bson.D{{Key: "user_id", Value: userID}, {Key: "session_id", Value: "older"}, {Key: "score", Value: 300}},
+		))
+
+		entries, err := repo.GetAllEntriesForUser(context.Background(), userID)
+		if err != nil {
+			mt.Fatalf("GetAllEntriesForUser() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].SessionID != "latest" {
+			mt.Fatalf("GetAllEntriesForUser() = %+v, want both entries", entries)
+		}
+
+		cmd := mt.GetStartedEvent().Command
+		if filterID, ok := cmd.Lookup("filter", "user_id").ObjectIDOK(); !ok || filterID != userID {
+			mt.Errorf("find filter = %v, want the user's ID", cmd.Lookup("filter"))
+		}
+		if updatedAt, ok := cmd.Lookup("sort", "updated_at").AsInt64OK(); !ok || updatedAt != -1 {
+			mt.Errorf("find sort = %v, want updated_at descending", cmd.Lookup("sort"))
+		}
+		if _, limited := cmd.Lookup("limit").AsInt64OK(); limited {
+			mt.Errorf("expected every entry of the user, got a limit of %v", cmd.Lookup("limit"))
+		}
+	})
+
+	mt.Run("no entries", func(mt *mtest.T) {
+		repo := &LeaderboardRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch))
+
+		entries, err := repo.GetAllEntriesForUser(context.Background(), primitive.NewObjectID())
+		if err != nil || entries == nil || len(entries) != 0 {
+			mt.Errorf("GetAllEntriesForUser() = %v, %v; want an empty list", entries, err)
+		}
+	})
+}
+
+func TestLeaderboardRepositoryUpsertEntriesMatchesUpsertEntry(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("same updates", func(mt *mtest.T) {
+		repo := &LeaderboardRepository{collection: mt.Coll}
+		userID := primitive.NewObjectID()
+		entries := []*LeaderboardEntry{
+			{UserID: userID, Username: "player", SessionID: "s1", SessionName: "Arena", Score: 100, Kills: 2},
+			{UserID: userID, Username: "player", SessionID: "s1", SessionName: "Arena", Score: 40, Kills: 3},
+			{UserID: primitive.NewObjectID(), Username: "other", SessionID: "s1", SessionName: "Arena", Score: 70},
+		}
+
+		var individual []bson.Raw
+		for _, entry := range entries {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+			if err := repo.UpsertEntry(context.Background(), entry); err != nil {
+				mt.Fatalf("UpsertEntry() error = %v", err)
+			}
+			individual = append(individual, updateStatements(mt)...)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}))
+		if err := repo.UpsertEntries(context.Background(), entries); err != nil {
+			mt.Fatalf("UpsertEntries() error = %v", err)
+		}
+		batched := updateStatements(mt)
+
+		if len(batched) != len(individual) {
+			mt.Fatalf("bulk write sent %d updates, want %d", len(batched), len(individual))
+		}
+		// The documents only differ in their timestamps, so the final state is the same
+		for i := range batched {
+			for _, path := range [][]string{
+				{"q"},
+				{"upsert"},
+				{"u", "$max"},
+				{"u", "$inc"},
+				{"u", "$set", "username"},
+				{"u", "$set", "session_name"},
+			} {
+				want, got := lookupValue(mt, individual[i], path), lookupValue(mt, batched[i], path)
+				if !reflect.DeepEqual(got, want) {
+					mt.Errorf("update %d %v = %v, want %v", i, path, got, want)
+				}
+			}
+		}
+	})
+
+	mt.Run("no entries", func(mt *mtest.T) {
+		repo := &LeaderboardRepository{collection: mt.Coll}
+
+		if err := repo.UpsertEntries(context.Background(), nil); err != nil {
+			mt.Fatalf("UpsertEntries(nil) error = %v", err)
+		}
+		if evt := mt.GetStartedEvent(); evt != nil {
+			mt.Errorf("expected no write without entries, got %s", evt.CommandName)
+		}
+	})
+}
+
+// updateStatements returns the statements of the next update command sent to the server
+func updateStatements(mt *mtest.T) []bson.Raw {
+	mt.Helper()
+
+	evt := mt.GetStartedEvent()
+	if evt == nil || evt.CommandName != "update" {
+		mt.Fatalf("expected an update command, got %v", evt)
+	}
+
+	values, err := evt.Command.Lookup("updates").Array().Values()
+	if err != nil {
+		mt.Fatalf("reading updates: %v", err)
+	}
+
+	statements := make([]bson.Raw, len(values))
+	for i, value := range values {
+		statements[i] = value.Document()
+	}
+	return statements
+}
+
+// lookupValue decodes the value at path, documents as maps so that key order doesn't matter
+func lookupValue(mt *mtest.T, doc bson.Raw, path []string) interface{} {
+	mt.Helper()
+
+	raw := doc.Lookup(path...)
+	if raw.Type == bson.TypeEmbeddedDocument {
+		var m bson.M
+		if err := raw.Unmarshal(&m); err != nil {
+			mt.Fatalf("decoding %v: %v", path, err)
+		}
+		return m
+	}
+
+	var value interface{}
+	if err := raw.Unmarshal(&value); err != nil {
+		mt.Fatalf("decoding %v: %v", path, err)
+	}
+	return value
+}
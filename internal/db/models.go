@@ -20,6 +20,24 @@ type User struct {
 	IsActive       bool               `bson:"is_active" json:"is_active"`
 	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
 	CurrentSession string             `bson:"current_session,omitempty" json:"current_session,omitempty"`
+	Settings       UserSettings       `bson:"settings" json:"settings"`
+	Achievements   []Achievement      `bson:"achievements,omitempty" json:"achievements,omitempty"`
+	BankedMoney    int                `bson:"banked_money,omitempty" json:"banked_money,omitempty"`
+}
+
+// UserSettings holds client preferences that follow the user across devices
+type UserSettings struct {
+	PreferredProtocol string            `bson:"preferred_protocol,omitempty" json:"preferred_protocol,omitempty"`
+	ControlScheme     string            `bson:"control_scheme,omitempty" json:"control_scheme,omitempty"`
+	Colors            map[string]string `bson:"colors,omitempty" json:"colors,omitempty"`
+	KeyBindings       map[string]string `bson:"key_bindings,omitempty" json:"key_bindings,omitempty"`
+	Display           map[string]string `bson:"display,omitempty" json:"display,omitempty"`
+}
+
+// Achievement records when a user earned one of the achievements listed in types.Achievements
+type Achievement struct {
+	ID        string    `bson:"id" json:"id"`
+	GrantedAt time.Time `bson:"granted_at" json:"granted_at"`
 }
 
 type InventoryItem struct {
@@ -39,11 +57,16 @@ type PlayerState struct {
 	BulletsLeftByWeaponType map[string]int32 `bson:"bullets_left_by_weapon_type" json:"bullets_left_by_weapon_type"`
 	InvulnerableTimer       float64          `bson:"invulnerable_timer" json:"invulnerable_timer"`
 	NightVisionTimer        float64          `bson:"night_vision_timer" json:"night_vision_timer"`
+	DoubleDamageTimer       float64          `bson:"double_damage_timer" json:"double_damage_timer"`
+	RapidFireTimer          float64          `bson:"rapid_fire_timer" json:"rapid_fire_timer"`
+	SpeedBoostTimer         float64          `bson:"speed_boost_timer" json:"speed_boost_timer"`
 	IsAlive                 bool             `bson:"is_alive" json:"is_alive"`
 	IsConnected             bool             `bson:"is_connected" json:"is_connected"`
 	LastUpdated             time.Time        `bson:"last_updated" json:"last_updated"`
 	Inventory               []InventoryItem  `bson:"inventory" json:"inventory"`
 	SelectedGunType         string           `bson:"selected_gun_type" json:"selected_gun_type"`
+	Team                    string           `bson:"team,omitempty" json:"team,omitempty"`
+	SpawnWeaponsGranted     bool             `bson:"spawn_weapons_granted,omitempty" json:"spawn_weapons_granted,omitempty"`
 }
 
 // Position represents x, y coordinates and rotation
@@ -69,34 +92,56 @@ type Chunk struct {
 	X       int                    `bson:"x" json:"x"`
 	Y       int                    `bson:"y" json:"y"`
 	Objects map[string]WorldObject `bson:"objects" json:"objects"`
+	Zone    string                 `bson:"zone,omitempty" json:"zone,omitempty"`
 }
 
 // GameSession represents a multiplayer game session
 type GameSession struct {
-	ID            primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
-	Name          string                 `bson:"name" json:"name"`
-	HostID        primitive.ObjectID     `bson:"host_id" json:"host_id"`
-	Players       map[string]PlayerState `bson:"players" json:"players"`
-	MaxPlayers    int                    `bson:"max_players" json:"max_players"`
-	IsPrivate     bool                   `bson:"is_private" json:"is_private"`
-	Password      string                 `bson:"password,omitempty" json:"-"`
-	WorldMap      map[string]Chunk       `bson:"world_map" json:"world_map"`
-	SharedObjects map[string]WorldObject `bson:"shared_objects" json:"shared_objects"`
-	CreatedAt     time.Time              `bson:"created_at" json:"created_at"`
-	LastUpdated   time.Time              `bson:"last_updated" json:"last_updated"`
-	IsActive      bool                   `bson:"is_active" json:"is_active"`
-	GameVersion   string                 `bson:"game_version" json:"game_version"`
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name           string                 `bson:"name" json:"name"`
+	HostID         primitive.ObjectID     `bson:"host_id" json:"host_id"`
+	Players        map[string]PlayerState `bson:"players" json:"players"`
+	MaxPlayers     int                    `bson:"max_players" json:"max_players"`
+	IsPrivate      bool                   `bson:"is_private" json:"is_private"`
+	Password       string                 `bson:"password,omitempty" json:"-"`
+	WorldMap       map[string]Chunk       `bson:"world_map" json:"world_map"`
+	SharedObjects  map[string]WorldObject `bson:"shared_objects" json:"shared_objects"`
+	CreatedAt      time.Time              `bson:"created_at" json:"created_at"`
+	LastUpdated    time.Time              `bson:"last_updated" json:"last_updated"`
+	IsActive       bool                   `bson:"is_active" json:"is_active"`
+	GameVersion    string                 `bson:"game_version" json:"game_version"`
+	Seed           string                 `bson:"seed,omitempty" json:"seed,omitempty"`
+	AllowedWeapons []string               `bson:"allowed_weapons,omitempty" json:"allowed_weapons,omitempty"`
+	RecordInputs   bool                   `bson:"record_inputs,omitempty" json:"record_inputs,omitempty"`
+	FriendlyFire   *bool                  `bson:"friendly_fire,omitempty" json:"friendly_fire,omitempty"` // nil means on
+	Unlisted       bool                   `bson:"unlisted,omitempty" json:"unlisted,omitempty"`
+	Activity       SessionActivity        `bson:"activity" json:"activity"`
+}
+
+// FriendlyFireOn reports whether players can hurt each other, which sessions saved before the toggle do
+func (s *GameSession) FriendlyFireOn() bool {
+	return s.FriendlyFire == nil || *s.FriendlyFire
+}
+
+// SessionActivity tracks how busy a session has been over its lifetime
+type SessionActivity struct {
+	StartedAt   time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	PeakPlayers int       `bson:"peak_players" json:"peak_players"`
+	// Every user who ever joined the session
+	PlayerIDs []string `bson:"player_ids,omitempty" json:"-"`
 }
 
 // UserRepository provides database operations for users
 type UserRepository struct {
 	collection *mongo.Collection
+	cache      *userCache
 }
 
 // NewUserRepository creates a new user repository
 func NewUserRepository() *UserRepository {
 	return &UserRepository{
 		collection: Database.Collection("users"),
+		cache:      sharedUserCache,
 	}
 }
 
@@ -120,13 +165,21 @@ func (r *UserRepository) FindByGoogleID(ctx context.Context, googleID string) (*
 	return &user, nil
 }
 
-// FindByID finds a user by ID
+// FindByID finds a user by ID, serving recent lookups from the cache
 func (r *UserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*User, error) {
+	if user, ok := r.cache.get(id); ok {
+		return user, nil
+	}
+
+	// An update landing while the query runs must not be undone by caching what it read
+	generation := r.cache.generation()
+
 	var user User
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
 	if err != nil {
 		return nil, err
 	}
+	r.cache.set(&user, generation)
 	return &user, nil
 }
 
@@ -144,16 +197,81 @@ func (r *UserRepository) Create(ctx context.Context, user *User) error {
 	return nil
 }
 
-// Update updates a user
+// Update updates a user and drops its cached copy.
+// Achievements are left as stored, they only change through GrantAchievement.
 func (r *UserRepository) Update(ctx context.Context, user *User) error {
+	defer r.cache.invalidate(user.ID)
+
+	fields := *user
+	fields.Achievements = nil
+	fields.BankedMoney = 0
+
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": user.ID},
-		bson.M{"$set": user},
+		bson.M{"$set": fields},
+	)
+	return err
+}
+
+// SetCurrentSession records the session the user is in, leaving the rest of the stored user as it is
+func (r *UserRepository) SetCurrentSession(ctx context.Context, userID primitive.ObjectID, sessionID string) error {
+	defer r.cache.invalidate(userID)
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"current_session": sessionID}},
+	)
+	return err
+}
+
+// ClearCurrentSession forgets the user's current session, if it's still the given one.
+// Update can't do it, an empty current session is left out of what it writes.
+func (r *UserRepository) ClearCurrentSession(ctx context.Context, userID primitive.ObjectID, sessionID string) error {
+	defer r.cache.invalidate(userID)
+
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "current_session": sessionID},
+		bson.M{"$unset": bson.M{"current_session": ""}},
 	)
 	return err
 }
 
+// GrantAchievement records an achievement for a user, reporting whether it was newly granted.
+// A user who already has the achievement keeps the original grant time.
+func (r *UserRepository) GrantAchievement(ctx context.Context, userID primitive.ObjectID, achievementID string) (bool, error) {
+	defer r.cache.invalidate(userID)
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "achievements.id": bson.M{"$ne": achievementID}},
+		bson.M{"$push": bson.M{"achievements": Achievement{ID: achievementID, GrantedAt: time.Now()}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// AddBankedMoney moves amount into the user's bank, or out of it when negative, reporting
+// whether it was applied. A withdrawal is only applied when the bank holds enough money.
+func (r *UserRepository) AddBankedMoney(ctx context.Context, userID primitive.ObjectID, amount int) (bool, error) {
+	defer r.cache.invalidate(userID)
+
+	filter := bson.M{"_id": userID}
+	if amount < 0 {
+		filter["banked_money"] = bson.M{"$gte": -amount}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"banked_money": amount}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
 // GameSessionRepository provides database operations for game sessions
 type GameSessionRepository struct {
 	collection *mongo.Collection
@@ -229,6 +347,19 @@ func (r *GameSessionRepository) Update(ctx context.Context, session *GameSession
 	return err
 }
 
+// RemovePlayer takes a player's saved state out of a game session, leaving the rest of it as stored
+func (r *GameSessionRepository) RemovePlayer(ctx context.Context, id primitive.ObjectID, playerID string) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$unset": bson.M{"players." + playerID: ""},
+			"$set":   bson.M{"last_updated": time.Now()},
+		},
+	)
+	return err
+}
+
 // Delete deletes a game session
 func (r *GameSessionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
@@ -253,8 +384,36 @@ type LeaderboardRepository struct {
 	collection *mongo.Collection
 }
 
-// UpsertEntry creates or updates a leaderboard entry for a user in a session
+// UpsertEntry creates or updates a leaderboard entry for a user in a session.
+// Every call counts as one death, so it must be called exactly once per death.
 func (r *LeaderboardRepository) UpsertEntry(ctx context.Context, entry *LeaderboardEntry) error {
+	filter, update := leaderboardUpsert(entry, time.Now())
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+// UpsertEntries applies UpsertEntry for every entry in a single bulk write.
+// The entries are applied in order, so each one still counts as one death.
+func (r *LeaderboardRepository) UpsertEntries(ctx context.Context, entries []*LeaderboardEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, len(entries))
+	for i, entry := range entries {
+		filter, update := leaderboardUpsert(entry, now)
+		models[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models)
+	return err
+}
+
+// leaderboardUpsert builds the filter and update recording one death of the entry's player
+func leaderboardUpsert(entry *LeaderboardEntry, now time.Time) (bson.M, bson.M) {
 	filter := bson.M{
 		"user_id":    entry.UserID,
 		"session_id": entry.SessionID,
@@ -268,19 +427,17 @@ func (r *LeaderboardRepository) UpsertEntry(ctx context.Context, entry *Leaderbo
 		"$set": bson.M{
 			"username":     entry.Username,
 			"session_name": entry.SessionName,
-			"updated_at":   time.Now(),
+			"updated_at":   now,
 		},
 		"$inc": bson.M{
 			"deaths": 1,
 		},
 		"$setOnInsert": bson.M{
-			"created_at": time.Now(),
+			"created_at": now,
 		},
 	}
 
-	opts := options.Update().SetUpsert(true)
-	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
-	return err
+	return filter, update
 }
 
 // GetTopScores returns the top N scores globally
@@ -316,6 +473,27 @@ func (r *LeaderboardRepository) GetTopScoresBySession(ctx context.Context, sessi
 	return entries, nil
 }
 
+// GetByUserIDs returns the entries of the given users across all sessions, highest scores first
+func (r *LeaderboardRepository) GetByUserIDs(ctx context.Context, userIDs []primitive.ObjectID) ([]LeaderboardEntry, error) {
+	if len(userIDs) == 0 {
+		return []LeaderboardEntry{}, nil
+	}
+
+	filter := bson.M{"user_id": bson.M{"$in": userIDs}}
+	opts := options.Find().SetSort(bson.D{{Key: "score", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []LeaderboardEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetUserStats returns statistics for a specific user
 func (r *LeaderboardRepository) GetUserStats(ctx context.Context, userID primitive.ObjectID) (*LeaderboardEntry, error) {
 	// Get the user's best score across all sessions
@@ -328,6 +506,22 @@ func (r *LeaderboardRepository) GetUserStats(ctx context.Context, userID primiti
 	return &entry, nil
 }
 
+// GetAllEntriesForUser returns the user's entries in every session they played, most recently updated first
+func (r *LeaderboardRepository) GetAllEntriesForUser(ctx context.Context, userID primitive.ObjectID) ([]LeaderboardEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []LeaderboardEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetUserSessionEntry returns a user's entry for a specific session
 func (r *LeaderboardRepository) GetUserSessionEntry(ctx context.Context, userID primitive.ObjectID, sessionID string) (*LeaderboardEntry, error) {
 	var entry LeaderboardEntry
@@ -347,3 +541,48 @@ func NewLeaderboardRepository() *LeaderboardRepository {
 		collection: Database.Collection("leaderboard"),
 	}
 }
+
+// InputRecord is one input a player sent, kept to review suspected cheating
+type InputRecord struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SessionID string             `bson:"session_id" json:"session_id"`
+	PlayerID  string             `bson:"player_id" json:"player_id"`
+	At        time.Time          `bson:"at" json:"at"`
+	Sequence  uint32             `bson:"sequence" json:"sequence"`
+	Forward   bool               `bson:"forward" json:"forward"`
+	Backward  bool               `bson:"backward" json:"backward"`
+	Left      bool               `bson:"left" json:"left"`
+	Right     bool               `bson:"right" json:"right"`
+	Shoot     bool               `bson:"shoot" json:"shoot"`
+	Sprint    bool               `bson:"sprint" json:"sprint"`
+	Reload    bool               `bson:"reload" json:"reload"`
+	// Keys held down, by item ID
+	ItemKeys         []int32 `bson:"item_keys,omitempty" json:"item_keys,omitempty"`
+	PurchaseItemKeys []int32 `bson:"purchase_item_keys,omitempty" json:"purchase_item_keys,omitempty"`
+}
+
+// InputRecordRepository provides database operations for recorded player inputs
+type InputRecordRepository struct {
+	collection *mongo.Collection
+}
+
+// NewInputRecordRepository creates a new input record repository
+func NewInputRecordRepository() *InputRecordRepository {
+	return &InputRecordRepository{
+		collection: Database.Collection("input_records"),
+	}
+}
+
+// InsertMany stores the records in a single write
+func (r *InputRecordRepository) InsertMany(ctx context.Context, records []*InputRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(records))
+	for i, record := range records {
+		documents[i] = record
+	}
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUserSettingsRoundTrip(t *testing.T) {
+	user := User{
+		ID:       primitive.NewObjectID(),
+		Email:    "user@example.com",
+		Username: "user",
+		Settings: UserSettings{
+			PreferredProtocol: "binary",
+			ControlScheme:     "wasd",
+			Colors:            map[string]string{"player": "#ff0000"},
+		},
+	}
+
+	t.Run("bson", func(t *testing.T) {
+		data, err := bson.Marshal(user)
+		if err != nil {
+			t.Fatalf("bson.Marshal() error = %v", err)
+		}
+
+		var decoded User
+		if err := bson.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("bson.Unmarshal() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(decoded.Settings, user.Settings) {
+			t.Errorf("Settings = %+v, want %+v", decoded.Settings, user.Settings)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(user.Settings)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+
+		var decoded UserSettings
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(decoded, user.Settings) {
+			t.Errorf("Settings = %+v, want %+v", decoded, user.Settings)
+		}
+	})
+}
+
+func TestInputRecordRepositoryInsertMany(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("single write", func(mt *mtest.T) {
+		repo := &InputRecordRepository{collection: mt.Coll}
+		records := []*InputRecord{
+			{PlayerID: "player", At: time.Now(), Sequence: 1, Forward: true},
+			{PlayerID: "player", At: time.Now(), Sequence: 2, Shoot: true},
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		if err := repo.InsertMany(context.Background(), records); err != nil {
+			mt.Fatalf("InsertMany() error = %v", err)
+		}
+
+		documents := mt.GetStartedEvent().Command.Lookup("documents").Array()
+		if values, _ := documents.Values(); len(values) != 2 {
+			mt.Errorf("inserted %d documents, want 2", len(values))
+		}
+	})
+}
+
+func TestSessionsSavedWithoutFriendlyFireKeepItOn(t *testing.T) {
+	data, err := bson.Marshal(bson.D{{Key: "name", Value: "old session"}, {Key: "is_active", Value: true}})
+	if err != nil {
+		t.Fatalf("bson.Marshal() error = %v", err)
+	}
+
+	var session GameSession
+	if err := bson.Unmarshal(data, &session); err != nil {
+		t.Fatalf("bson.Unmarshal() error = %v", err)
+	}
+	if !session.FriendlyFireOn() {
+		t.Error("expected a session saved before the setting existed to keep friendly fire on")
+	}
+
+	off := false
+	session.FriendlyFire = &off
+	if session.FriendlyFireOn() {
+		t.Error("expected the session to turn friendly fire off")
+	}
+}
+
+func TestClearCurrentSessionUnsetsIt(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("clear", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+		user := &User{ID: primitive.NewObjectID(), Username: "player", CurrentSession: "session"}
+		repo.cache.set(user, repo.cache.generation())
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		if err := repo.ClearCurrentSession(context.Background(), user.ID, "session"); err != nil {
+			mt.Fatalf("ClearCurrentSession() error = %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if session, _ := update.Lookup("q", "current_session").StringValueOK(); session != "session" {
+			mt.Errorf("update filter = %v, want users still in the session", update.Lookup("q"))
+		}
+		if _, err := update.LookupErr("u", "$unset", "current_session"); err != nil {
+			mt.Errorf("update = %v, want the current session unset", update.Lookup("u"))
+		}
+		if _, ok := repo.cache.get(user.ID); ok {
+			mt.Error("expected the cached user to be dropped")
+		}
+	})
+}
+
+func TestSetCurrentSessionOnlySetsIt(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("set", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll, cache: newUserCache(time.Minute)}
+		user := &User{ID: primitive.NewObjectID(), Username: "player"}
+		repo.cache.set(user, repo.cache.generation())
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		if err := repo.SetCurrentSession(context.Background(), user.ID, "session"); err != nil {
+			mt.Fatalf("SetCurrentSession() error = %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		set, err := update.LookupErr("u", "$set")
+		if err != nil {
+			mt.Fatalf("update = %v, want a $set", update.Lookup("u"))
+		}
+		elements, _ := set.Document().Elements()
+		if len(elements) != 1 || elements[0].Key() != "current_session" || elements[0].Value().StringValue() != "session" {
+			mt.Errorf("update $set = %v, want only the current session", set)
+		}
+		if _, ok := repo.cache.get(user.ID); ok {
+			mt.Error("expected the cached user to be dropped")
+		}
+	})
+}
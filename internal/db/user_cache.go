@@ -0,0 +1,111 @@
+package db
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// userCacheTTL bounds how stale a cached user record may get
+const userCacheTTL = 30 * time.Second
+
+// userCacheSweepInterval is how often expired entries of users nobody looked up again are dropped
+const userCacheSweepInterval = time.Minute
+
+type userCacheEntry struct {
+	user      User
+	expiresAt time.Time
+}
+
+// userCache keeps recently loaded users in memory to save round trips on
+// connection events. It is shared by all user repositories so that an update
+// made through one of them invalidates the entry for every other.
+type userCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[primitive.ObjectID]userCacheEntry
+	// Bumped on every invalidation, so a lookup started before one doesn't cache what it read
+	gen       uint64
+	lastSweep time.Time
+}
+
+var sharedUserCache = newUserCache(userCacheTTL)
+
+func newUserCache(ttl time.Duration) *userCache {
+	return &userCache{
+		ttl:       ttl,
+		entries:   make(map[primitive.ObjectID]userCacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// get returns a copy of the cached user, so callers are free to modify it
+func (c *userCache) get(id primitive.ObjectID) (*User, bool) {
+	c.mu.RLock()
+	entry, exists := c.entries[id]
+	c.mu.RUnlock()
+
+	if !exists {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return copyUser(&entry.user), true
+}
+
+// generation returns the invalidation count to hand back to set once the user is loaded
+func (c *userCache) generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.gen
+}
+
+// set caches the user unless an invalidation happened since generation was taken,
+// in which case what was loaded may already be out of date
+func (c *userCache) set(user *User, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= userCacheSweepInterval {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if generation != c.gen {
+		return
+	}
+
+	c.entries[user.ID] = userCacheEntry{
+		user:      *copyUser(user),
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+func (c *userCache) invalidate(id primitive.ObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+	c.gen++
+}
+
+func copyUser(user *User) *User {
+	userCopy := *user
+	userCopy.Settings.Colors = maps.Clone(user.Settings.Colors)
+	userCopy.Settings.KeyBindings = maps.Clone(user.Settings.KeyBindings)
+	userCopy.Settings.Display = maps.Clone(user.Settings.Display)
+	userCopy.Achievements = slices.Clone(user.Achievements)
+	return &userCopy
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newUnreachableUserRepository returns a repository whose collection points at
+// a server that doesn't exist, so any query that reaches it fails fast
+func newUnreachableUserRepository(t *testing.T) *UserRepository {
+	t.Helper()
+
+	clientOptions := options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50 * time.Millisecond)
+	client, err := mongo.Connect(context.Background(), clientOptions)
+	if err != nil {
+		t.Fatalf("mongo.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return &UserRepository{
+		collection: client.Database("dungeon_game_test").Collection("users"),
+		cache:      newUserCache(time.Minute),
+	}
+}
+
+func TestUserRepositoryFindByIDUsesCache(t *testing.T) {
+	repo := newUnreachableUserRepository(t)
+	ctx := context.Background()
+
+	user := &User{
+		ID:       primitive.NewObjectID(),
+		Username: "cached",
+		Settings: UserSettings{Colors: map[string]string{"player": "#ff0000"}},
+	}
+	repo.cache.set(user, repo.cache.generation())
+
+	found, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v, expected cached user without a database query", err)
+	}
+	if found.Username != "cached" {
+		t.Errorf("FindByID() username = %q, want %q", found.Username, "cached")
+	}
+
+	// Changes to the returned user must not leak into the cache
+	found.Username = "changed"
+	found.Settings.Colors["player"] = "#00ff00"
+
+	again, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if again.Username != "cached" || again.Settings.Colors["player"] != "#ff0000" {
+		t.Errorf("cached user was modified through a returned copy: %+v", again)
+	}
+}
+
+func TestUserRepositoryUpdateInvalidatesCache(t *testing.T) {
+	repo := newUnreachableUserRepository(t)
+	ctx := context.Background()
+
+	user := &User{ID: primitive.NewObjectID(), Username: "cached"}
+	repo.cache.set(user, repo.cache.generation())
+
+	// The write itself fails against the unreachable server, but the cached
+	// copy must be dropped either way
+	repo.Update(ctx, user)
+
+	if _, ok := repo.cache.get(user.ID); ok {
+		t.Fatal("expected Update to invalidate the cached user")
+	}
+	if _, err := repo.FindByID(ctx, user.ID); err == nil {
+		t.Error("expected FindByID to query the collection after invalidation")
+	}
+}
+
+func TestUserCacheExpires(t *testing.T) {
+	cache := newUserCache(-time.Second)
+	user := &User{ID: primitive.NewObjectID()}
+	cache.set(user, cache.generation())
+
+	if _, ok := cache.get(user.ID); ok {
+		t.Error("expected expired entry to be ignored")
+	}
+}
+
+func TestUserCacheSetAfterInvalidationIsIgnored(t *testing.T) {
+	cache := newUserCache(time.Minute)
+	user := &User{ID: primitive.NewObjectID(), Username: "stale"}
+
+	// A lookup starts, then an update invalidates the user before the lookup caches what it read
+	generation := cache.generation()
+	cache.invalidate(user.ID)
+	cache.set(user, generation)
+
+	if _, ok := cache.get(user.ID); ok {
+		t.Error("expected the user read before the invalidation not to be cached")
+	}
+
+	cache.set(user, cache.generation())
+	if _, ok := cache.get(user.ID); !ok {
+		t.Error("expected a lookup started after the invalidation to be cached")
+	}
+}
+
+func TestUserCacheSweepsExpiredEntries(t *testing.T) {
+	cache := newUserCache(-time.Second)
+	expired := &User{ID: primitive.NewObjectID()}
+	cache.set(expired, cache.generation())
+
+	cache.lastSweep = time.Now().Add(-userCacheSweepInterval)
+	cache.set(&User{ID: primitive.NewObjectID()}, cache.generation())
+
+	if _, exists := cache.entries[expired.ID]; exists {
+		t.Error("expected the expired entry to be swept")
+	}
+}
@@ -0,0 +1,45 @@
+package game
+
+import "github.com/besuhoff/dungeon-game-go/internal/types"
+
+// AchievementGrant is an achievement a player reached during the session
+type AchievementGrant struct {
+	PlayerID      string
+	AchievementID string
+}
+
+// updateAchievements adds the tick to the time each living player has survived
+// and queues the achievements players reached since the previous tick
+func (e *Engine) updateAchievements(deltaTime float64) {
+	for _, player := range e.state.players {
+		if !player.IsConnected {
+			continue
+		}
+		if player.IsAlive {
+			e.survivalTime[player.ID] += deltaTime
+		}
+
+		for _, achievement := range types.Achievements {
+			if e.achieved[player.ID][achievement.ID] || !achievement.IsEarnedBy(player, e.survivalTime[player.ID]) {
+				continue
+			}
+
+			if e.achieved[player.ID] == nil {
+				e.achieved[player.ID] = make(map[string]bool)
+			}
+			e.achieved[player.ID][achievement.ID] = true
+			e.achievements = append(e.achievements, AchievementGrant{PlayerID: player.ID, AchievementID: achievement.ID})
+		}
+	}
+}
+
+// TakeAchievements returns the achievements reached since the previous call.
+// Each achievement is reported at most once per player for the lifetime of the engine.
+func (e *Engine) TakeAchievements() []AchievementGrant {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	achievements := e.achievements
+	e.achievements = nil
+	return achievements
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestKillAchievementsGrantedOnce(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	tick(e, 100*time.Millisecond)
+	if grants := e.TakeAchievements(); len(grants) != 0 {
+		t.Fatalf("expected no achievements before the first kill, got %v", grants)
+	}
+
+	player.Kills = 1
+	tick(e, 100*time.Millisecond)
+	grants := e.TakeAchievements()
+	if len(grants) != 1 || grants[0] != (AchievementGrant{PlayerID: "player", AchievementID: types.AchievementFirstKill}) {
+		t.Fatalf("expected the first kill achievement, got %v", grants)
+	}
+
+	// Staying past the threshold, or reaching it again in a later life, doesn't grant it again
+	player.Kills = 2
+	tick(e, 100*time.Millisecond)
+	e.killPlayer(player)
+	player.Respawn(&types.Vector2{X: 1000, Y: 1000})
+	player.Kills = 1
+	tick(e, 100*time.Millisecond)
+	if grants := e.TakeAchievements(); len(grants) != 0 {
+		t.Errorf("expected the achievement to be granted once, got %v", grants)
+	}
+
+	player.Kills = 100
+	tick(e, 100*time.Millisecond)
+	grants = e.TakeAchievements()
+	if len(grants) != 1 || grants[0].AchievementID != types.AchievementKills100 {
+		t.Errorf("expected the 100 kills achievement, got %v", grants)
+	}
+}
+
+func TestSurvivalAchievementNeedsOneLife(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	e.survivalTime[player.ID] = 599
+
+	// Dying resets the time survived
+	e.killPlayer(player)
+	player.Respawn(&types.Vector2{X: 1000, Y: 1000})
+	tick(e, 2*time.Second)
+	if grants := e.TakeAchievements(); len(grants) != 0 {
+		t.Fatalf("expected no achievement after dying, got %v", grants)
+	}
+
+	e.survivalTime[player.ID] = 599
+	tick(e, 2*time.Second)
+	grants := e.TakeAchievements()
+	if len(grants) != 1 || grants[0].AchievementID != types.AchievementSurvive10 {
+		t.Errorf("expected the survival achievement, got %v", grants)
+	}
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// enemyAlert is an enemy opening fire on a player during the tick
+type enemyAlert struct {
+	enemy  *types.Enemy
+	player *types.Player
+}
+
+// enemyAggro is the player an alerted enemy turns towards, until the alert wears off
+type enemyAggro struct {
+	playerID string
+	until    time.Time
+}
+
+// alertEnemies makes the enemies within the aggro radius of each enemy that opened fire turn towards its target
+func (e *Engine) alertEnemies(alerts []enemyAlert, now time.Time) {
+	chunkRadius := int(math.Ceil(e.enemyAggroRadius / config.ChunkSize))
+	until := now.Add(time.Duration(config.EnemyAggroDuration * float64(time.Second)))
+
+	for _, alert := range alerts {
+		chunkX, chunkY := utils.ChunkXYFromPosition(alert.enemy.Position.X, alert.enemy.Position.Y)
+		for neighborChunkX := chunkX - chunkRadius; neighborChunkX <= chunkX+chunkRadius; neighborChunkX++ {
+			for neighborChunkY := chunkY - chunkRadius; neighborChunkY <= chunkY+chunkRadius; neighborChunkY++ {
+				neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+				for _, enemy := range e.state.enemiesByChunk[neighborChunkKey] {
+					if enemy == alert.enemy || !enemy.IsAlive || enemy.DistanceToPoint(alert.enemy.Position) > e.enemyAggroRadius {
+						continue
+					}
+					e.enemyAggro[enemy.ID] = enemyAggro{playerID: alert.player.ID, until: until}
+				}
+			}
+		}
+	}
+}
+
+// enemyAggroTarget returns the player an alerted enemy should turn towards, or nil once the alert is over
+func (e *Engine) enemyAggroTarget(enemy *types.Enemy, now time.Time) *types.Player {
+	aggro, exists := e.enemyAggro[enemy.ID]
+	if !exists {
+		return nil
+	}
+
+	player, exists := e.state.players[aggro.playerID]
+	if !exists || !player.IsConnected || !player.IsAlive || now.After(aggro.until) {
+		delete(e.enemyAggro, enemy.ID)
+		return nil
+	}
+	return player
+}
+
+// turnEnemyTowards rotates the enemy to face the target, towers turning at their limited speed,
+// and returns the rotation that faces the target
+func turnEnemyTowards(enemy *types.Enemy, target *types.Vector2, deltaTime float64) float64 {
+	dx := target.X - enemy.Position.X
+	dy := target.Y - enemy.Position.Y
+	desiredRotation := utils.RotationTowards(dx, dy)
+	if enemy.Type != types.EnemyTypeTower {
+		enemy.Rotation = desiredRotation
+		return desiredRotation
+	}
+
+	// Smooth rotation for tower
+	rotationDiff := utils.NormalizeRotation(desiredRotation - enemy.Rotation)
+	if rotationDiff > 180 {
+		rotationDiff -= 360
+	}
+
+	maxRotationChange := config.EnemyTowerRotationSpeed * deltaTime
+	if math.Abs(rotationDiff) < maxRotationChange {
+		enemy.Rotation = desiredRotation
+	} else {
+		if rotationDiff > 0 {
+			enemy.Rotation = utils.NormalizeRotation(enemy.Rotation + maxRotationChange)
+		} else {
+			enemy.Rotation = utils.NormalizeRotation(enemy.Rotation - maxRotationChange)
+		}
+	}
+	return desiredRotation
+}
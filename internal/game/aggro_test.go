@@ -0,0 +1,124 @@
+package game
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addAggroCluster adds a player, an enemy close enough to see and shoot them, and
+// two enemies out of the player's sight, one near the shooter and one far from it
+func addAggroCluster(e *Engine) (*types.Player, *types.Enemy, *types.Enemy) {
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	addEnemy := func(id string, x, y float64) *types.Enemy {
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: id, Position: &types.Vector2{X: x, Y: y}},
+			Type:         types.EnemyTypeSoldier,
+			Lives:        1,
+			Rotation:     45,
+			IsAlive:      true,
+		}
+		e.state.enemiesByChunk["0,0"][id] = enemy
+		return enemy
+	}
+
+	addEnemy("shooter", 1000, 1080)
+	nearby := addEnemy("nearby", 1100, 1400)
+	distant := addEnemy("distant", 1000, 1900)
+	return player, nearby, distant
+}
+
+func TestShootingAggrosNearbyEnemies(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyAggroRadius = 500
+	player, nearby, distant := addAggroCluster(e)
+
+	// The shooter opens fire on the first tick, the alerted enemies turn on the next
+	tick(e, 100*time.Millisecond)
+	tick(e, 100*time.Millisecond)
+
+	wantRotation := math.Atan2(-(player.Position.X-nearby.Position.X), player.Position.Y-nearby.Position.Y) * 180 / math.Pi
+	if nearby.Rotation != wantRotation {
+		t.Errorf("expected the nearby enemy to turn towards the player (%.1f), got %.1f", wantRotation, nearby.Rotation)
+	}
+	if distant.Rotation != 45 {
+		t.Errorf("expected the enemy outside the aggro radius to keep its rotation, got %.1f", distant.Rotation)
+	}
+}
+
+func TestShootingWithoutAggroLeavesNeighborsAlone(t *testing.T) {
+	e := newTestEngine(t)
+	_, nearby, _ := addAggroCluster(e)
+
+	tick(e, 100*time.Millisecond)
+	tick(e, 100*time.Millisecond)
+
+	if nearby.Rotation != 45 {
+		t.Errorf("expected the nearby enemy to stay unaware without aggro, got rotation %.1f", nearby.Rotation)
+	}
+	if len(e.enemyAggro) != 0 {
+		t.Errorf("expected no alerted enemies, got %v", e.enemyAggro)
+	}
+}
+
+func TestEnemyAggroWearsOff(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	enemy := &types.Enemy{ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1000, Y: 1500}}, IsAlive: true}
+	now := time.Now()
+	e.enemyAggro[enemy.ID] = enemyAggro{playerID: player.ID, until: now.Add(time.Second)}
+
+	if target := e.enemyAggroTarget(enemy, now); target != player {
+		t.Fatalf("expected the alerted enemy to target the player, got %v", target)
+	}
+	if target := e.enemyAggroTarget(enemy, now.Add(2*time.Second)); target != nil {
+		t.Errorf("expected the alert to wear off, got target %v", target.ID)
+	}
+	if _, exists := e.enemyAggro[enemy.ID]; exists {
+		t.Error("expected the expired alert to be forgotten")
+	}
+}
+
+func TestEnemiesFireConfiguredSpread(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyBulletSpread = 3
+	addAggroCluster(e)
+
+	tick(e, 100*time.Millisecond)
+
+	directions := make(map[float64]bool)
+	for _, bullet := range e.state.bullets {
+		if bullet.IsEnemy && bullet.OwnerID == "shooter" {
+			directions[math.Atan2(-bullet.Velocity.X, bullet.Velocity.Y)] = true
+		}
+	}
+	if len(directions) != 3 {
+		t.Errorf("expected the shooter to fire 3 bullets in different directions, got %d", len(directions))
+	}
+}
+
+func TestEnemyRotationStaysInRange(t *testing.T) {
+	// Targets to the right of an enemy used to give negative rotations
+	soldier := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "soldier", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Type:         types.EnemyTypeSoldier,
+	}
+	turnEnemyTowards(soldier, &types.Vector2{X: 1100, Y: 1000}, 0.1)
+	if soldier.Rotation != 270 {
+		t.Errorf("expected the soldier to face right at 270, got %.1f", soldier.Rotation)
+	}
+
+	// Towers turning past 0 wrap around instead of going negative
+	tower := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "tower", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Type:         types.EnemyTypeTower,
+		Rotation:     1,
+	}
+	turnEnemyTowards(tower, &types.Vector2{X: 1100, Y: 1000}, 0.1)
+	if tower.Rotation < 180 || tower.Rotation >= 360 {
+		t.Errorf("expected the tower to turn right through 0 into 180-360, got %.1f", tower.Rotation)
+	}
+}
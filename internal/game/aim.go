@@ -0,0 +1,31 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// enemyAimDeviation is the largest angle, in degrees, an enemy's shot can miss its
+// aim by at the given distance to the target: the base inaccuracy plus a share
+// growing with distance, capped at config.EnemyMaxAimDeviation.
+func (e *Engine) enemyAimDeviation(distance float64) float64 {
+	deviation := e.enemyAimInaccuracy + e.enemyAimInaccuracyPer100*distance/100
+	return math.Min(deviation, config.EnemyMaxAimDeviation)
+}
+
+// applyEnemyAimError turns the bullets of one enemy shot by the same random angle,
+// keeping the shape of a spread while missing more often at long range
+func (e *Engine) applyEnemyAimError(bullets []*types.Bullet, distance float64) {
+	deviation := e.enemyAimDeviation(distance)
+	if deviation <= 0 {
+		return
+	}
+
+	angle := (rand.Float64()*2 - 1) * deviation
+	for _, bullet := range bullets {
+		bullet.Velocity.RotateAroundPoint(&types.Vector2{}, angle)
+	}
+}
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// maxAimError fires shots straight down at the given distance and returns the
+// largest angle, in degrees, a bullet strayed from its aim
+func maxAimError(e *Engine, distance float64) float64 {
+	maxError := 0.0
+	for i := 0; i < 200; i++ {
+		bullet := &types.Bullet{Velocity: &types.Vector2{X: 0, Y: 100}}
+		e.applyEnemyAimError([]*types.Bullet{bullet}, distance)
+		maxError = math.Max(maxError, math.Abs(math.Atan2(-bullet.Velocity.X, bullet.Velocity.Y)*180/math.Pi))
+	}
+	return maxError
+}
+
+func TestEnemyAimSpreadGrowsWithDistance(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyAimInaccuracy = 1
+	e.enemyAimInaccuracyPer100 = 2
+
+	near := maxAimError(e, 50)
+	far := maxAimError(e, 500)
+	if near > e.enemyAimDeviation(50) || far > e.enemyAimDeviation(500) {
+		t.Fatalf("expected shots to stay within the allowed deviation, got %.2f near and %.2f far", near, far)
+	}
+	if far <= near*2 {
+		t.Errorf("expected a much wider spread at long range, got %.2f near and %.2f far", near, far)
+	}
+}
+
+func TestEnemyAimIsExactByDefault(t *testing.T) {
+	e := newTestEngine(t)
+
+	if deviation := maxAimError(e, 1000); deviation != 0 {
+		t.Errorf("expected perfectly aimed shots without inaccuracy configured, got %.2f degrees", deviation)
+	}
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// newAssistTestEngine creates an engine paying assists of half the kill reward within a second
+func newAssistTestEngine(t *testing.T, enabled bool) *Engine {
+	t.Helper()
+
+	e := newTestEngine(t)
+	e.assistsEnabled = enabled
+	e.assistWindow = time.Second
+	e.assistRewardFraction = 0.5
+	return e
+}
+
+// hitEnemy shoots the enemy with a bullet of the player's that deals the given damage
+func hitEnemy(e *Engine, enemy *types.Enemy, playerID string, damage float32) {
+	bullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: playerID + "-bullet", Position: enemy.Position},
+		OwnerID:      playerID,
+		IsActive:     true,
+		Damage:       damage,
+	}
+	e.applyBulletHitToEnemy(bullet, enemy, "0,0")
+}
+
+func TestAssistRewardsRecentContributor(t *testing.T) {
+	e := newAssistTestEngine(t, true)
+	killer := addTestPlayer(e, "killer", 1000, 1000)
+	assister := addTestPlayer(e, "assister", 1100, 1000)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1100, 2)
+
+	hitEnemy(e, enemy, assister.ID, 1)
+	hitEnemy(e, enemy, killer.ID, 1)
+
+	if enemy.IsAlive {
+		t.Fatal("expected the second hit to kill the enemy")
+	}
+	reward := int(enemy.Reward())
+	if want := int(float64(reward) * e.assistRewardFraction); assister.Money != want || assister.Kills != 0 {
+		t.Errorf("expected the assister to get %d money and no kill, got %d money and %d kills", want, assister.Money, assister.Kills)
+	}
+	if killer.Money != reward || killer.Kills != 1 {
+		t.Errorf("expected the killer to get the kill reward %d once, got %d money and %d kills", reward, killer.Money, killer.Kills)
+	}
+}
+
+func TestAssistIgnoresContributionsOutsideWindow(t *testing.T) {
+	e := newAssistTestEngine(t, true)
+	killer := addTestPlayer(e, "killer", 1000, 1000)
+	assister := addTestPlayer(e, "assister", 1100, 1000)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1100, 2)
+
+	hitEnemy(e, enemy, assister.ID, 1)
+	enemy.DamageContributors.Record(assister.ID, time.Now().Add(-2*e.assistWindow))
+	hitEnemy(e, enemy, killer.ID, 1)
+
+	if enemy.IsAlive {
+		t.Fatal("expected the second hit to kill the enemy")
+	}
+	if assister.Money != 0 {
+		t.Errorf("expected damage older than the assist window not to be rewarded, got %d money", assister.Money)
+	}
+}
+
+func TestAssistsDisabled(t *testing.T) {
+	e := newAssistTestEngine(t, false)
+	killer := addTestPlayer(e, "killer", 1000, 1000)
+	assister := addTestPlayer(e, "assister", 1100, 1000)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1100, 2)
+
+	hitEnemy(e, enemy, assister.ID, 1)
+	hitEnemy(e, enemy, killer.ID, 1)
+
+	if enemy.IsAlive {
+		t.Fatal("expected the second hit to kill the enemy")
+	}
+	if assister.Money != 0 || len(enemy.DamageContributors) != 0 {
+		t.Errorf("expected no assists without ASSISTS_ENABLED, got %d money and %d contributors", assister.Money, len(enemy.DamageContributors))
+	}
+}
+
+func TestSelfDamageIsNotRecorded(t *testing.T) {
+	e := newAssistTestEngine(t, true)
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	if contributors := e.recordDamage(nil, player.ID, player.ID); len(contributors) != 0 {
+		t.Errorf("expected a player hurting themselves not to count as a contributor, got %v", contributors)
+	}
+
+	if contributors := e.recordDamage(nil, "other", player.ID); len(contributors) != 0 {
+		t.Errorf("expected damage from someone who isn't playing not to be recorded, got %v", contributors)
+	}
+}
@@ -0,0 +1,48 @@
+package game
+
+// DepositMoney takes up to amount of a living player's money for their bank,
+// returning how much was taken
+func (e *Engine) DepositMoney(playerID string, amount int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	player, exists := e.state.players[playerID]
+	if !exists || !player.IsConnected || !player.IsAlive || amount <= 0 {
+		return 0
+	}
+
+	taken := min(amount, player.Money)
+	player.Money -= taken
+	return taken
+}
+
+// WithdrawMoney gives a living player up to amount from their bank without going
+// over the money cap, returning how much was given
+func (e *Engine) WithdrawMoney(playerID string, amount int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	player, exists := e.state.players[playerID]
+	if !exists || !player.IsConnected || !player.IsAlive || amount <= 0 {
+		return 0
+	}
+
+	given := max(0, min(amount, e.maxMoney-player.Money))
+	player.Money += given
+	return given
+}
+
+// ReturnMoney gives a deposit that couldn't be banked back to the player. It was theirs
+// a moment ago, so the money cap doesn't apply. Returns false when the player is gone.
+func (e *Engine) ReturnMoney(playerID string, amount int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	player, exists := e.state.players[playerID]
+	if !exists {
+		return false
+	}
+
+	player.Money += amount
+	return true
+}
@@ -0,0 +1,53 @@
+package game
+
+import "testing"
+
+func TestDepositMoneyTakesWhatThePlayerHas(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 30
+
+	if taken := e.DepositMoney(player.ID, 50); taken != 30 || player.Money != 0 {
+		t.Errorf("expected all 30 to be deposited, took %d leaving %d", taken, player.Money)
+	}
+
+	player.Money = 30
+	player.IsAlive = false
+	if taken := e.DepositMoney(player.ID, 10); taken != 0 || player.Money != 30 {
+		t.Errorf("expected a dead player not to deposit, took %d", taken)
+	}
+}
+
+func TestWithdrawMoneyStopsAtMoneyCap(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxMoney = 100
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 80
+
+	if given := e.WithdrawMoney(player.ID, 50); given != 20 || player.Money != 100 {
+		t.Errorf("expected 20 to be withdrawn up to the cap, gave %d leaving %d", given, player.Money)
+	}
+	if given := e.WithdrawMoney(player.ID, 50); given != 0 {
+		t.Errorf("expected nothing to be withdrawn at the cap, gave %d", given)
+	}
+}
+
+func TestReturnMoneyIgnoresMoneyCap(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxMoney = 100
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 100
+
+	if taken := e.DepositMoney(player.ID, 40); taken != 40 {
+		t.Fatalf("expected 40 to be deposited, took %d", taken)
+	}
+	// Money picked up while the deposit was on its way
+	player.Money += 30
+
+	if !e.ReturnMoney(player.ID, 40) || player.Money != 130 {
+		t.Errorf("expected the whole deposit back over the cap, have %d", player.Money)
+	}
+	if e.ReturnMoney("gone", 40) {
+		t.Error("expected nothing to be returned to a player who left")
+	}
+}
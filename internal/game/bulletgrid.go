@@ -0,0 +1,97 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// wallsOnBulletPath returns the walls a bullet moving by (dx, dy) from position can hit: the
+// walls overlapping the chunks its path crosses. Walls per chunk are worked out once a tick
+// and kept in wallsByChunk.
+func (e *Engine) wallsOnBulletPath(position *types.Vector2, dx, dy float64, wallsByChunk map[[2]int][]*types.Wall) []*types.Wall {
+	minChunkX, minChunkY := utils.ChunkXYFromPosition(math.Min(position.X, position.X+dx), math.Min(position.Y, position.Y+dy))
+	maxChunkX, maxChunkY := utils.ChunkXYFromPosition(math.Max(position.X, position.X+dx), math.Max(position.Y, position.Y+dy))
+
+	// Almost every bullet stays in one chunk in a tick
+	if minChunkX == maxChunkX && minChunkY == maxChunkY {
+		return e.wallsOverlappingChunk(minChunkX, minChunkY, wallsByChunk)
+	}
+
+	var walls []*types.Wall
+	for chunkX := minChunkX; chunkX <= maxChunkX; chunkX++ {
+		for chunkY := minChunkY; chunkY <= maxChunkY; chunkY++ {
+			walls = append(walls, e.wallsOverlappingChunk(chunkX, chunkY, wallsByChunk)...)
+		}
+	}
+	return walls
+}
+
+// wallsOverlappingChunk returns the walls reaching into a chunk. Walls are stored by the chunk
+// they were generated in but may stick out of it, so the neighboring chunks are checked too.
+func (e *Engine) wallsOverlappingChunk(chunkX, chunkY int, wallsByChunk map[[2]int][]*types.Wall) []*types.Wall {
+	if walls, exists := wallsByChunk[[2]int{chunkX, chunkY}]; exists {
+		return walls
+	}
+
+	left, top := float64(chunkX)*config.ChunkSize, float64(chunkY)*config.ChunkSize
+	right, bottom := left+config.ChunkSize, top+config.ChunkSize
+
+	walls := []*types.Wall{}
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if !e.chunkHash[neighborChunkKey] {
+				continue
+			}
+
+			for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
+				topLeft := wall.GetTopLeft()
+				if topLeft.X <= right && topLeft.X+wall.Width >= left && topLeft.Y <= bottom && topLeft.Y+wall.Height >= top {
+					walls = append(walls, wall)
+				}
+			}
+		}
+	}
+
+	wallsByChunk[[2]int{chunkX, chunkY}] = walls
+	return walls
+}
+
+// capBulletsPerChunk removes the oldest bullets of chunks holding more than maxBulletsPerChunk,
+// keeping the collision checks of bullet-heavy fights bounded
+func (e *Engine) capBulletsPerChunk() {
+	if e.maxBulletsPerChunk <= 0 {
+		return
+	}
+
+	bulletsByChunk := make(map[string][]*types.Bullet)
+	for _, bullet := range e.state.bullets {
+		if !bullet.DeletedAt.IsZero() {
+			continue
+		}
+		chunkX, chunkY := utils.ChunkXYFromPosition(bullet.Position.X, bullet.Position.Y)
+		chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+		bulletsByChunk[chunkKey] = append(bulletsByChunk[chunkKey], bullet)
+	}
+
+	now := time.Now()
+	for _, bullets := range bulletsByChunk {
+		if len(bullets) <= e.maxBulletsPerChunk {
+			continue
+		}
+
+		sort.Slice(bullets, func(i, j int) bool {
+			return bullets[i].SpawnTime.Before(bullets[j].SpawnTime)
+		})
+		for _, bullet := range bullets[:len(bullets)-e.maxBulletsPerChunk] {
+			bullet.IsActive = false
+			bullet.DeletedAt = now
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestBulletStopsAtWallStickingIntoItsChunk(t *testing.T) {
+	e := newTestEngine(t)
+
+	// Generated in chunk 0,0 but reaching past its right edge into chunk 1,0
+	e.state.wallsByChunk["0,0"]["wall"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: config.ChunkSize - 100, Y: 1000}},
+		Width:        200,
+		Height:       config.WallWidth,
+		Orientation:  "horizontal",
+	}
+	bullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: config.ChunkSize + 50, Y: 900}},
+		Velocity:     &types.Vector2{X: 0, Y: 1000},
+		WeaponType:   types.WeaponTypeBlaster,
+		SpawnTime:    time.Now(),
+		IsActive:     true,
+	}
+	e.addBullet(bullet)
+
+	tick(e, 200*time.Millisecond)
+
+	if bullet.IsActive || bullet.Position.Y >= 1000 {
+		t.Errorf("expected the bullet to stop at the wall, got active %v at y %.1f", bullet.IsActive, bullet.Position.Y)
+	}
+}
+
+func TestBulletsPerChunkAreCapped(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxBulletsPerChunk = 2
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		e.addBullet(&types.Bullet{
+			ScreenObject: types.ScreenObject{ID: fmt.Sprintf("bullet-%d", i), Position: &types.Vector2{X: 500, Y: 500}},
+			Velocity:     &types.Vector2{},
+			WeaponType:   types.WeaponTypeBlaster,
+			SpawnTime:    now.Add(time.Duration(i) * time.Millisecond),
+			IsActive:     true,
+		})
+	}
+	e.addBullet(&types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "elsewhere", Position: &types.Vector2{X: config.ChunkSize + 500, Y: 500}},
+		Velocity:     &types.Vector2{},
+		WeaponType:   types.WeaponTypeBlaster,
+		SpawnTime:    now.Add(-time.Millisecond),
+		IsActive:     true,
+	})
+
+	tick(e, 10*time.Millisecond)
+
+	for id, expectActive := range map[string]bool{"bullet-0": false, "bullet-1": true, "bullet-2": true, "elsewhere": true} {
+		if active := e.state.bullets[id].IsActive; active != expectActive {
+			t.Errorf("%s: expected active = %v, got %v", id, expectActive, active)
+		}
+	}
+}
+
+// BenchmarkBulletUpdate ticks a world of 7x7 chunks with walls and thousands of bullets flying across it
+func BenchmarkBulletUpdate(b *testing.B) {
+	config.AppConfig = &config.Config{}
+	e := NewEngine("bench-session")
+	rng := rand.New(rand.NewSource(1))
+
+	for chunkX := -3; chunkX <= 3; chunkX++ {
+		for chunkY := -3; chunkY <= 3; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			e.chunkHash[chunkKey] = true
+			e.state.wallsByChunk[chunkKey] = make(map[string]*types.Wall)
+			e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
+			e.state.shopsByChunk[chunkKey] = make(map[string]*types.Shop)
+
+			for i := 0; i < 15; i++ {
+				wallID := fmt.Sprintf("wall-%s-%d", chunkKey, i)
+				e.state.wallsByChunk[chunkKey][wallID] = &types.Wall{
+					ScreenObject: types.ScreenObject{ID: wallID, Position: &types.Vector2{
+						X: (float64(chunkX) + rng.Float64()) * config.ChunkSize,
+						Y: (float64(chunkY) + rng.Float64()) * config.ChunkSize,
+					}},
+					Width:       config.WallWidth,
+					Height:      300,
+					Orientation: "vertical",
+				}
+			}
+		}
+	}
+
+	positions := make([]types.Vector2, 5000)
+	for i := range positions {
+		positions[i] = types.Vector2{X: (rng.Float64()*7 - 3) * config.ChunkSize, Y: (rng.Float64()*7 - 3) * config.ChunkSize}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		e.state.bullets = make(map[string]*types.Bullet, len(positions))
+		for j, position := range positions {
+			id := fmt.Sprintf("bullet-%d", j)
+			e.state.bullets[id] = &types.Bullet{
+				ScreenObject: types.ScreenObject{ID: id, Position: &types.Vector2{X: position.X, Y: position.Y}},
+				Velocity:     &types.Vector2{X: config.BlasterBulletSpeed, Y: 0},
+				WeaponType:   types.WeaponTypeBlaster,
+				SpawnTime:    time.Now(),
+				IsActive:     true,
+			}
+		}
+		b.StartTimer()
+
+		tick(e, 16*time.Millisecond)
+	}
+}
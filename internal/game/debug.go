@@ -0,0 +1,72 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/besuhoff/dungeon-game-go/internal/protocol"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// DebugCounts counts the entities the server tracks in the chunks around a player
+// (-1 to 1), the same area deltas are built for. Returns nil for unknown players.
+func (e *Engine) DebugCounts(playerID string) *protocol.DebugCounts {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	player, exists := e.state.players[playerID]
+	if !exists {
+		return nil
+	}
+
+	playerChunkX, playerChunkY := utils.ChunkXYFromPosition(player.Position.X, player.Position.Y)
+	isNearby := func(x, y float64) bool {
+		chunkX, chunkY := utils.ChunkXYFromPosition(x, y)
+		return chunkX >= playerChunkX-1 && chunkX <= playerChunkX+1 && chunkY >= playerChunkY-1 && chunkY <= playerChunkY+1
+	}
+
+	counts := &protocol.DebugCounts{}
+	for _, other := range e.state.players {
+		if other.IsConnected && isNearby(other.Position.X, other.Position.Y) {
+			counts.Players++
+		}
+	}
+	for _, bullet := range e.state.bullets {
+		if isNearby(bullet.Position.X, bullet.Position.Y) {
+			counts.Bullets++
+		}
+	}
+	for chunkX := playerChunkX - 1; chunkX <= playerChunkX+1; chunkX++ {
+		for chunkY := playerChunkY - 1; chunkY <= playerChunkY+1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			counts.Enemies += uint32(len(e.state.enemiesByChunk[chunkKey]))
+			counts.Walls += uint32(len(e.state.wallsByChunk[chunkKey]))
+		}
+	}
+
+	return counts
+}
+
+// Stats returns a copy of the engine's update and delta calculation stats along with its entity
+// totals. Timings are only gathered in debug mode or while the debug stats endpoint is on.
+func (e *Engine) Stats() EngineStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	e.statsMu.Lock()
+	stats := *e.stats
+	e.statsMu.Unlock()
+
+	stats.Players = len(e.state.players)
+	for _, player := range e.state.players {
+		if player.IsConnected {
+			stats.ConnectedPlayers++
+		}
+	}
+	for _, enemies := range e.state.enemiesByChunk {
+		stats.Enemies += len(enemies)
+	}
+	stats.Bullets = len(e.state.bullets)
+	stats.Bonuses = len(e.state.bonuses)
+
+	return stats
+}
@@ -0,0 +1,34 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestDebugCountsCoverNearbyChunks(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 100, 100)
+	addTestPlayer(e, "nearby", 300, 300)
+	addTestPlayer(e, "far", 3*config.ChunkSize+100, 100)
+
+	e.state.enemiesByChunk["0,0"]["enemy"] = &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 500, Y: 500}},
+		IsAlive:      true,
+	}
+	e.state.wallsByChunk["1,1"]["wall"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: config.ChunkSize + 100, Y: config.ChunkSize + 100}},
+	}
+	e.state.bullets["near"] = &types.Bullet{ScreenObject: types.ScreenObject{ID: "near", Position: &types.Vector2{X: 150, Y: 150}}}
+	e.state.bullets["far"] = &types.Bullet{ScreenObject: types.ScreenObject{ID: "far", Position: &types.Vector2{X: 3*config.ChunkSize + 150, Y: 150}}}
+
+	counts := e.DebugCounts(player.ID)
+	if counts.Players != 2 || counts.Enemies != 1 || counts.Walls != 1 || counts.Bullets != 1 {
+		t.Errorf("expected 2 players, 1 enemy, 1 wall and 1 bullet nearby, got %+v", counts)
+	}
+
+	if e.DebugCounts("unknown") != nil {
+		t.Error("expected no counts for an unknown player")
+	}
+}
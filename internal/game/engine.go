@@ -5,12 +5,14 @@ import (
 	"log"
 	"math"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
 	"github.com/besuhoff/dungeon-game-go/internal/protocol"
 	"github.com/besuhoff/dungeon-game-go/internal/types"
 	"github.com/besuhoff/dungeon-game-go/internal/utils"
@@ -28,23 +30,47 @@ type EngineGameState struct {
 }
 
 type UpdateTimeStats struct {
-	enemies time.Duration
-	bullets time.Duration
-	players time.Duration
-	bonuses time.Duration
+	Enemies time.Duration
+	Bullets time.Duration
+	Players time.Duration
+	Bonuses time.Duration
 }
 
 func (s *UpdateTimeStats) Total() time.Duration {
-	return s.enemies + s.bullets + s.players + s.bonuses
+	return s.Enemies + s.Bullets + s.Players + s.Bonuses
+}
+
+// Average divides the times spent over count updates, zero when there were none
+func (s UpdateTimeStats) Average(count int64) UpdateTimeStats {
+	if count <= 0 {
+		return UpdateTimeStats{}
+	}
+	return UpdateTimeStats{
+		Enemies: s.Enemies / time.Duration(count),
+		Bullets: s.Bullets / time.Duration(count),
+		Players: s.Players / time.Duration(count),
+		Bonuses: s.Bonuses / time.Duration(count),
+	}
 }
 
 type DeltaCalcStats struct {
-	delta          time.Duration
-	updatePrevious time.Duration
+	Delta          time.Duration
+	UpdatePrevious time.Duration
 }
 
 func (s *DeltaCalcStats) Total() time.Duration {
-	return s.delta + s.updatePrevious
+	return s.Delta + s.UpdatePrevious
+}
+
+// Average divides the times spent over count delta calculations, zero when there were none
+func (s DeltaCalcStats) Average(count int64) DeltaCalcStats {
+	if count <= 0 {
+		return DeltaCalcStats{}
+	}
+	return DeltaCalcStats{
+		Delta:          s.Delta / time.Duration(count),
+		UpdatePrevious: s.UpdatePrevious / time.Duration(count),
+	}
 }
 
 type EngineStats struct {
@@ -60,6 +86,16 @@ type EngineStats struct {
 
 	LastReportedAt time.Time
 	Frequency      time.Duration
+
+	// Enemies checked in the last update
+	CheckedEnemies int
+
+	// Entity totals, filled in by Stats
+	Players          int
+	ConnectedPlayers int
+	Enemies          int
+	Bullets          int
+	Bonuses          int
 }
 type Engine struct {
 	mu           sync.RWMutex
@@ -67,6 +103,23 @@ type Engine struct {
 	state        *EngineGameState
 	chunkHash    map[string]bool // Track generated chunks
 	respawnQueue map[string]bool // Players to respawn
+	diedAt       map[string]time.Time
+	respawnDelay time.Duration   // Minimum time between a player's death and their respawn
+	deaths       []*types.Player // Snapshots of players who died since the last TakeDeaths call
+
+	// Walls around each chunk prepared for enemy line-of-sight checks, dropped when walls nearby change
+	sightWallsByChunk map[string][]sightWall
+
+	// Seconds each player has been alive in their current life, the achievements they reached
+	// in this session and the ones not yet taken by TakeAchievements
+	survivalTime map[string]float64
+	achieved     map[string]map[string]bool
+	achievements []AchievementGrant
+
+	// World generation seed, the string it was made from and the source for spawn points derived from it
+	seed     int64
+	seedName string
+	rng      *rand.Rand
 
 	// Previous state for delta computation
 	prevState               map[string]*EngineGameState
@@ -75,13 +128,202 @@ type Engine struct {
 	itemsToUseByPlayer      map[string][]types.InventoryItemID
 	itemsToPurchaseByPlayer map[string][]types.InventoryItemID
 
+	// Shop each player currently stands in, and the enter/leave events not yet sent to them
+	currentShopByPlayer map[string]string
+	shopEventsByPlayer  map[string][]*protocol.ShopEvent
+
+	// Recent frames around each living player, and the kill-cams built from them not yet sent. 0 duration disables recording.
+	killCamDuration  time.Duration
+	killCamFrames    map[string]*killCamBuffer
+	killCamsByPlayer map[string]*protocol.KillCam
+
+	// Players who joined or left since each player's last delta, only tracked when joins and leaves go out with the deltas
+	joinLeaveInDeltas     bool
+	joinedPlayersByPlayer map[string][]*protocol.Player
+	leftPlayersByPlayer   map[string][]string
+
 	stats     *EngineStats
 	debugMode bool
+
+	// Stats are gathered for debug logging and for the debug stats endpoint
+	collectStats bool
+	statsMu      sync.Mutex
+
+	// Echo applied input sequence numbers back to clients for prediction reconciliation
+	clientPrediction bool
+
+	slowTickThreshold time.Duration
+
+	assistsEnabled       bool
+	assistWindow         time.Duration
+	assistRewardFraction float64
+
+	sprintEnabled bool
+
+	// Enemies notice players standing still from a shorter distance
+	stealthEnabled bool
+
+	// Remove dead enemies immediately instead of keeping their corpses
+	instantEnemyRemoval bool
+
+	// Upper bounds for a player's money and score
+	maxMoney int
+	maxScore int
+
+	// How far ahead moving enemies look for walls to steer around, 0 disables steering
+	enemyLookAhead float64
+
+	// Thin dimension of generated walls
+	wallThickness float64
+
+	// Units a bullet may graze a wall edge without stopping
+	bulletWallTolerance float64
+
+	// Degrees a player can turn in a single tick, so a long tick can't snap their aim around
+	maxRotationPerTick float64
+
+	// Time between game loop ticks, used to warn about sessions prone to tunneling
+	tickInterval time.Duration
+
+	// Send the other players' positions even when they can't be detected
+	teammatePositions bool
+
+	// Show chests dropped by dead players to the dropper's teammates regardless of fog
+	teamDroppedChests bool
+
+	// Group chunks into zones with their own generation parameters
+	zonesEnabled bool
+	zoneByChunk  map[string]string // chunkKey -> zone name
+	zoneSent     map[string]string // playerID -> zone last sent to the player
+
+	// Fraction by which generated shops may price items above or below the base price
+	shopPriceVariation float64
+	// Minimum distance between generated shops, 0 allows any spacing
+	minShopDistance float64
+	// Enemies opening fire alert the others within this radius, and the player each alerted enemy turns towards
+	enemyAggroRadius float64
+	enemyAggro       map[string]enemyAggro
+
+	// Share of wall enemies spawned as summoners, seconds between their minions and how many they may have
+	summonerChance    float64
+	summonInterval    float64
+	summonerMinionCap int
+
+	// Share of wall enemies spawned as gatekeepers and how long the portals they leave stay open
+	gatekeeperChance float64
+	portalLifetime   time.Duration
+	portalArrivals   map[string]string // playerID -> portal the player came out on and hasn't stepped off yet
+
+	// Share of wall enemies spawned as thieves
+	thiefChance float64
+
+	// Share of wall enemies spawned armored
+	armoredEnemyChance float64
+
+	// Spawn wall enemies as grunts, brutes and scouts
+	enemyArchetypes bool
+
+	// Bullets enemies fire per shot
+	enemyBulletSpread int
+
+	// Distance within which enemies wake up for players, separate from how far players see
+	enemyAwarenessRadius float64
+
+	// Length of a railgun beam
+	railgunRange float64
+
+	// Most bullets flying in one chunk, the oldest ones go first. 0 for no limit.
+	maxBulletsPerChunk int
+
+	// Players and enemies bucketed by position while bullets move, nil for the rest of the tick
+	hitGrid *spatialGrid
+
+	// Inputs kept for anti-cheat review when recording, and how many didn't fit since the last take
+	recordInputs   bool
+	recordedInputs []*db.InputRecord
+	droppedInputs  int
+
+	// Weapons whose hits poison, and the damage per second and seconds the poison lasts
+	poisonWeapons  map[string]bool
+	poisonDamage   float32
+	poisonDuration float64
+
+	// Chance for a shot of each weapon to jam, and the weapon each player's gun jammed with since their last delta
+	gunJamChance   map[string]float64
+	jammedByPlayer map[string]string
+
+	// Damage enemies deal to players within reach, 0 for no enemy melee, and the seconds between an enemy's hits
+	enemyMeleeDamage   float32
+	enemyMeleeRange    float64
+	enemyMeleeInterval float64
+
+	// Weapons and rounds of their ammo each player gets once, the first time they join
+	spawnWeapons map[string]int32
+
+	// Largest angle, in degrees, enemy shots miss by: a base value plus more per 100 units to the target
+	enemyAimInaccuracy       float64
+	enemyAimInaccuracyPer100 float64
+
+	// Wipe the world when the whole party dies, partyWiped is set until the end of that tick
+	hardcore   bool
+	partyWiped bool
+
+	// Share of their full lives below which moving enemies flee instead of shooting, 0 disables it
+	enemyFleeThreshold float64
+
+	// Share of chunks with a locked room, opened with the key its keyholder drops
+	lockedRoomChance float64
+
+	// How many targets a railgun shot damages before it stops, 0 for no limit
+	railgunPenetration int
+
+	// Whether picked up weapons get selected, one of the config.AutoEquip modes
+	autoEquipWeapons string
+
+	// Seconds of invulnerability granted on chest pickup, 0 when disabled
+	chestPickupInvulnerability float64
+
+	// Weapons players may use and buy in this session, nil allows all of them
+	allowedWeapons types.WeaponSet
+
+	// Players' bullets, rocket explosions and swings hurt other players
+	friendlyFire bool
+
+	// Distance from a player's edge a bonus is picked up at, by bonus type
+	bonusPickupRadius map[string]float64
+
+	// Record where bullets were fired from so clients can draw trails
+	bulletTrails bool
+
+	// Tell clients how long dead enemies linger so they can fade them out
+	enemyDeathFade bool
+
+	// Chance for a dying lieutenant to drop a power-up, the shares of them granting double damage
+	// and rapid fire, the rest being speed boosts, and what picking one up does
+	powerUpDropChance      float64
+	powerUpDamageChance    float64
+	powerUpRapidFireChance float64
+	powerUps               types.PowerUpEffects
+
+	// Share of wall enemies spawned as flashers, before the zone's factor
+	flasherChance float64
+	// Distance within which a flasher's death flash blinds players, and the seconds it blinds them for
+	flasherFlashRadius float64
+	flasherBlindTime   float64
+
+	// Player bullets can shoot down enemy bullets
+	shootableEnemyBullets bool
+
+	// Level of detail for bullets far away from the receiving player
+	bulletLOD         bool
+	bulletLODDistance float64
+	bulletLODInterval uint64
+	tickCount         uint64
 }
 
 // NewEngine creates a new game engine for a session
 func NewEngine(sessionID string) *Engine {
-	return &Engine{
+	e := &Engine{
 		sessionID: sessionID,
 		state: &EngineGameState{
 			players:        make(map[string]*types.Player),
@@ -94,37 +336,238 @@ func NewEngine(sessionID string) *Engine {
 		playerInputState:        make(map[string]*types.InputPayload),
 		itemsToUseByPlayer:      make(map[string][]types.InventoryItemID),
 		itemsToPurchaseByPlayer: make(map[string][]types.InventoryItemID),
+		currentShopByPlayer:     make(map[string]string),
+		shopEventsByPlayer:      make(map[string][]*protocol.ShopEvent),
+		killCamDuration:         config.AppConfig.KillCamDuration,
+		killCamFrames:           make(map[string]*killCamBuffer),
+		killCamsByPlayer:        make(map[string]*protocol.KillCam),
+		joinLeaveInDeltas:       config.AppConfig.JoinLeaveInDeltas,
+		joinedPlayersByPlayer:   make(map[string][]*protocol.Player),
+		leftPlayersByPlayer:     make(map[string][]string),
 		chunkHash:               make(map[string]bool),
+		sightWallsByChunk:       make(map[string][]sightWall),
 		respawnQueue:            make(map[string]bool),
+		diedAt:                  make(map[string]time.Time),
+		respawnDelay:            config.AppConfig.RespawnDelay,
+		survivalTime:            make(map[string]float64),
+		achieved:                make(map[string]map[string]bool),
 		prevState:               make(map[string]*EngineGameState),
 		lastUpdate:              time.Now(),
 		stats: &EngineStats{
 			Frequency: time.Second * 1,
 		},
-		debugMode: config.AppConfig.EngineDebugMode,
+		debugMode:         config.AppConfig.EngineDebugMode,
+		collectStats:      config.AppConfig.EngineDebugMode || config.AppConfig.DebugStatsEnabled,
+		clientPrediction:  config.AppConfig.ClientPredictionEnabled,
+		slowTickThreshold: config.AppConfig.SlowTickThreshold,
+
+		assistsEnabled:       config.AppConfig.AssistsEnabled,
+		assistWindow:         config.AppConfig.AssistWindow,
+		assistRewardFraction: config.AppConfig.AssistRewardFraction,
+
+		sprintEnabled:  config.AppConfig.SprintEnabled,
+		stealthEnabled: config.AppConfig.StealthEnabled,
+
+		chestPickupInvulnerability: config.AppConfig.ChestInvulnerability.Seconds(),
+		bonusPickupRadius: map[string]float64{
+			types.BonusTypeAidKit:       config.AppConfig.AidKitPickupRadius,
+			types.BonusTypeGoggles:      config.AppConfig.GogglesPickupRadius,
+			types.BonusTypeChest:        config.AppConfig.ChestPickupRadius,
+			types.BonusTypeKey:          config.AppConfig.KeyPickupRadius,
+			types.BonusTypeDoubleDamage: config.AppConfig.PowerUpPickupRadius,
+			types.BonusTypeRapidFire:    config.AppConfig.PowerUpPickupRadius,
+			types.BonusTypeSpeedBoost:   config.AppConfig.PowerUpPickupRadius,
+		},
+
+		enemyLookAhead: config.AppConfig.EnemyLookAhead,
+		wallThickness:  wallThickness(config.AppConfig.WallThickness),
+		tickInterval:   tickInterval(config.AppConfig.GameLoopInterval),
+
+		bulletWallTolerance: config.AppConfig.BulletWallTolerance,
+
+		maxRotationPerTick: maxRotationPerTick(config.AppConfig.MaxRotationPerTick, tickInterval(config.AppConfig.GameLoopInterval)),
+
+		teammatePositions: config.AppConfig.TeammatePositions,
+		teamDroppedChests: config.AppConfig.TeamDroppedChests,
+
+		zonesEnabled: config.AppConfig.ZonesEnabled,
+		zoneByChunk:  make(map[string]string),
+		zoneSent:     make(map[string]string),
+
+		shopPriceVariation: config.AppConfig.ShopPriceVariation,
+		minShopDistance:    config.AppConfig.MinShopDistance,
+		autoEquipWeapons:   config.AppConfig.AutoEquipWeapons,
+
+		enemyAggroRadius: config.AppConfig.EnemyAggroRadius,
+		enemyAggro:       make(map[string]enemyAggro),
+
+		summonerChance:    config.AppConfig.SummonerChance,
+		summonInterval:    config.AppConfig.SummonInterval.Seconds(),
+		summonerMinionCap: config.AppConfig.SummonerMinionCap,
+
+		gatekeeperChance: config.AppConfig.GatekeeperChance,
+		thiefChance:      config.AppConfig.ThiefChance,
+		portalLifetime:   portalLifetime(config.AppConfig.PortalLifetime),
+		portalArrivals:   make(map[string]string),
+
+		recordInputs: config.AppConfig.RecordInputs,
+
+		friendlyFire: true,
+
+		armoredEnemyChance: config.AppConfig.ArmoredEnemyChance,
+		enemyArchetypes:    config.AppConfig.EnemyArchetypes,
+		enemyBulletSpread:  config.AppConfig.EnemyBulletSpread,
+
+		enemyAwarenessRadius: distanceOrDefault(config.AppConfig.EnemyAwarenessRadius, config.EnemyAwarenessRadius),
+		railgunRange:         distanceOrDefault(config.AppConfig.RailgunRange, config.RailgunRange),
+		maxBulletsPerChunk:   config.AppConfig.MaxBulletsPerChunk,
+
+		poisonWeapons:  make(map[string]bool),
+		poisonDamage:   float32(config.AppConfig.PoisonDamage),
+		poisonDuration: config.AppConfig.PoisonDuration.Seconds(),
+
+		gunJamChance:   config.AppConfig.GunJamChance,
+		jammedByPlayer: make(map[string]string),
+
+		enemyMeleeDamage:   float32(config.AppConfig.EnemyMeleeDamage),
+		enemyMeleeRange:    distanceOrDefault(config.AppConfig.EnemyMeleeRange, config.EnemyMeleeRange),
+		enemyMeleeInterval: secondsOrDefault(config.AppConfig.EnemyMeleeInterval, config.EnemyMeleeInterval),
+
+		spawnWeapons: config.AppConfig.SpawnWeapons,
+
+		enemyAimInaccuracy:       config.AppConfig.EnemyAimInaccuracy,
+		enemyAimInaccuracyPer100: config.AppConfig.EnemyAimInaccuracyPer100,
+
+		hardcore: config.AppConfig.HardcoreMode,
+
+		enemyFleeThreshold: config.AppConfig.EnemyFleeThreshold,
+		lockedRoomChance:   config.AppConfig.LockedRoomChance,
+
+		railgunPenetration: config.AppConfig.RailgunPenetration,
+
+		instantEnemyRemoval: config.AppConfig.InstantEnemyRemoval,
+
+		maxMoney: fundsCap(config.AppConfig.MaxMoney),
+		maxScore: fundsCap(config.AppConfig.MaxScore),
+
+		bulletTrails:          config.AppConfig.BulletTrails,
+		enemyDeathFade:        config.AppConfig.EnemyDeathFade,
+		shootableEnemyBullets: config.AppConfig.ShootableEnemyBullets,
+
+		flasherChance:      config.AppConfig.FlasherChance,
+		flasherFlashRadius: distanceOrDefault(config.AppConfig.FlasherFlashRadius, config.EnemyFlasherFlashRadius),
+		flasherBlindTime:   secondsOrDefault(config.AppConfig.FlasherBlindTime, config.PlayerBlindTime),
+
+		powerUpDropChance:      config.AppConfig.PowerUpDropChance,
+		powerUpDamageChance:    config.AppConfig.PowerUpDamageChance,
+		powerUpRapidFireChance: config.AppConfig.PowerUpRapidFireChance,
+		powerUps: types.PowerUpEffects{
+			Duration:             secondsOrDefault(config.AppConfig.PowerUpDuration, config.PowerUpTime),
+			DamageMultiplier:     float32(multiplierOrDefault(config.AppConfig.PowerUpDamageMultiplier, config.PowerUpDamageMultiplier)),
+			ShootDelayMultiplier: multiplierOrDefault(config.AppConfig.PowerUpShootDelayFactor, config.PowerUpShootDelayMultiplier),
+			SpeedMultiplier:      multiplierOrDefault(config.AppConfig.PowerUpSpeedMultiplier, config.PowerUpSpeedMultiplier),
+		},
+
+		bulletLOD:         config.AppConfig.BulletLODEnabled,
+		bulletLODDistance: config.AppConfig.BulletLODDistance * config.SightRadius,
+		bulletLODInterval: uint64(max(config.AppConfig.BulletLODInterval, 1)),
+	}
+	e.setSeed(NewSeed())
+	for _, weaponType := range config.AppConfig.PoisonWeapons {
+		e.poisonWeapons[weaponType] = true
+	}
+
+	return e
+}
+
+// fundsCap returns the configured cap for money or score, falling back to the largest value the protocol can carry
+func fundsCap(configured int) int {
+	if configured <= 0 || configured > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return configured
+}
+
+// distanceOrDefault returns the configured distance, falling back to the default when it isn't set
+func distanceOrDefault(configured, defaultDistance float64) float64 {
+	if configured <= 0 {
+		return defaultDistance
+	}
+	return configured
+}
+
+// secondsOrDefault returns the configured duration in seconds, falling back to the default when it isn't set
+func secondsOrDefault(configured time.Duration, defaultSeconds float64) float64 {
+	if configured <= 0 {
+		return defaultSeconds
+	}
+	return configured.Seconds()
+}
+
+// multiplierOrDefault returns the configured multiplier, falling back to the default when it isn't set
+func multiplierOrDefault(configured, defaultMultiplier float64) float64 {
+	if configured <= 0 {
+		return defaultMultiplier
+	}
+	return configured
+}
+
+// wallThickness returns the configured thickness for generated walls, falling back to the default
+func wallThickness(configured float64) float64 {
+	if configured <= 0 {
+		return config.WallWidth
+	}
+	return configured
+}
+
+// maxRotationPerTick returns the configured per-tick rotation limit, falling back to how far
+// a player turns in one tick interval when it isn't a turn between 0 and a full circle
+func maxRotationPerTick(configured float64, tickInterval time.Duration) float64 {
+	if configured <= 0 || configured > 360 {
+		return config.PlayerRotationSpeed * tickInterval.Seconds()
 	}
+	return configured
+}
+
+// tickInterval returns the configured game loop interval, falling back to the default
+func tickInterval(configured time.Duration) time.Duration {
+	if configured <= 0 {
+		return config.DefaultGameLoopInterval
+	}
+	return configured
 }
 
 // ConnectPlayer adds a new player to the game
 func (e *Engine) ConnectPlayer(id, username string) *types.Player {
+	return e.ConnectPlayerOnTeam(id, username, "")
+}
+
+// ConnectPlayerOnTeam adds a new player to the game on the given team, empty for none.
+// The team is only picked on the first join, a player already in the session keeps theirs.
+func (e *Engine) ConnectPlayerOnTeam(id, username, team string) *types.Player {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// Idle sessions don't tick, the time nobody was playing isn't played out on the next tick
+	if !e.hasConnectedPlayers() {
+		e.lastUpdate = time.Now()
+	}
+
 	player, exists := e.state.players[id]
 	if !exists {
 		chunkKey := "0,0"
-		chunksNumber := len(e.chunkHash)
-		if chunksNumber > 0 {
-			randomIndex := rand.Intn(len(e.chunkHash))
-			i := 0
-			for key := range e.chunkHash {
-				if i == randomIndex {
-					chunkKey = key
-					break
-				}
-				i++
+		// New players never start in a pocket room
+		chunkKeys := make([]string, 0, len(e.chunkHash))
+		for key := range e.chunkHash {
+			keyChunkX, _ := strconv.Atoi(strings.Split(key, ",")[0])
+			keyChunkY, _ := strconv.Atoi(strings.Split(key, ",")[1])
+			if !isPocketChunk(keyChunkX, keyChunkY) {
+				chunkKeys = append(chunkKeys, key)
 			}
 		}
+		if len(chunkKeys) > 0 {
+			chunkKey = chunkKeys[rand.Intn(len(chunkKeys))]
+		}
 
 		chunkX, _ := strconv.Atoi(strings.Split(chunkKey, ",")[0])
 		chunkY, _ := strconv.Atoi(strings.Split(chunkKey, ",")[1])
@@ -145,12 +588,14 @@ func (e *Engine) ConnectPlayer(id, username string) *types.Player {
 				types.WeaponTypeBlaster: config.BlasterMaxBullets,
 			},
 			InvulnerableTimer: config.PlayerSpawnInvulnerabilityTime,
+			Stamina:           config.PlayerMaxStamina,
 			IsAlive:           true,
 			IsConnected:       true,
 			Inventory: []types.InventoryItem{
 				{Type: types.InventoryItemBlaster, Quantity: 1},
 			},
 			SelectedGunType: types.WeaponTypeBlaster,
+			Team:            team,
 		}
 
 		e.state.players[id] = player
@@ -162,7 +607,22 @@ func (e *Engine) ConnectPlayer(id, username string) *types.Player {
 		player.IsConnected = true
 	}
 
+	// Dead players get their spawn weapons once they're back, respawning would take them away
+	if player.IsAlive {
+		e.grantSpawnWeapons(player)
+	}
+
+	if e.joinLeaveInDeltas {
+		joined := protocol.ToProtoPlayer(player)
+		for otherID := range e.prevState {
+			if otherID != id {
+				e.joinedPlayersByPlayer[otherID] = append(e.joinedPlayersByPlayer[otherID], joined)
+			}
+		}
+	}
+
 	e.prevState[id] = &EngineGameState{}
+	delete(e.zoneSent, id)
 	e.itemsToUseByPlayer[id] = []types.InventoryItemID{}
 	e.itemsToPurchaseByPlayer[id] = []types.InventoryItemID{}
 	// Generate initial walls and enemies around player
@@ -171,6 +631,35 @@ func (e *Engine) ConnectPlayer(id, username string) *types.Player {
 	return player
 }
 
+// autoEquipWeapon selects the best weapon among the picked up items, depending on the auto-equip mode
+func (e *Engine) autoEquipWeapon(player *types.Player, pickedUp []types.InventoryItem) {
+	if e.autoEquipWeapons == config.AutoEquipOff {
+		return
+	}
+
+	bestWeapon := types.InventoryItemID(0)
+	for _, item := range pickedUp {
+		if _, isWeapon := types.WeaponTypeByInventoryItem[item.Type]; !isWeapon || item.Quantity <= 0 || !e.allowedWeapons.AllowsItem(item.Type) {
+			continue
+		}
+		if bestWeapon == 0 || types.ShopItemPrice[item.Type] > types.ShopItemPrice[bestWeapon] {
+			bestWeapon = item.Type
+		}
+	}
+	if bestWeapon == 0 {
+		return
+	}
+
+	if e.autoEquipWeapons == config.AutoEquipBetter {
+		selectedWeapon := types.InventoryItemByWeaponType[player.SelectedGunType]
+		if types.ShopItemPrice[bestWeapon] <= types.ShopItemPrice[selectedWeapon] {
+			return
+		}
+	}
+
+	player.SelectGunType(bestWeapon, e.allowedWeapons)
+}
+
 // generateInitialWorld creates walls and enemies in chunks around the starting position
 func (e *Engine) generateInitialWorld(center *types.Vector2) {
 	// Generate 3x3 grid of chunks around spawn
@@ -183,6 +672,53 @@ func (e *Engine) generateInitialWorld(center *types.Vector2) {
 	}
 }
 
+// isShopTooClose reports whether the chunk's shop would be nearer than the minimum shop distance to
+// the shop of a neighboring chunk that takes priority. Neighbors are looked at whether they are generated
+// or not, so the outcome doesn't depend on the order chunks are generated in.
+func (e *Engine) isShopTooClose(chunkX, chunkY int) bool {
+	if e.minShopDistance <= 0 {
+		return false
+	}
+
+	chunkRadius := int(math.Ceil(e.minShopDistance / config.ChunkSize))
+	priority := e.shopPriority(chunkX, chunkY)
+	for neighborChunkX := chunkX - chunkRadius; neighborChunkX <= chunkX+chunkRadius; neighborChunkX++ {
+		for neighborChunkY := chunkY - chunkRadius; neighborChunkY <= chunkY+chunkRadius; neighborChunkY++ {
+			if neighborChunkX == chunkX && neighborChunkY == chunkY {
+				continue
+			}
+			// Shops stand in the middle of their chunks
+			distance := math.Hypot(float64(neighborChunkX-chunkX), float64(neighborChunkY-chunkY)) * config.ChunkSize
+			if distance >= e.minShopDistance || e.shopPriority(neighborChunkX, neighborChunkY) < priority {
+				continue
+			}
+			if _, hasShop := e.drawChunkStart(neighborChunkX, neighborChunkY, e.chunkRand(neighborChunkX, neighborChunkY)); hasShop {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// shopPriority decides which of two shops too close to each other is kept, the higher one wins
+func (e *Engine) shopPriority(chunkX, chunkY int) uint64 {
+	return e.seededHash(chunkX, chunkY)
+}
+
+// drawChunkStart draws the numbers every chunk starts with: how many walls it has and whether it has a shop
+func (e *Engine) drawChunkStart(chunkX, chunkY int, rng *rand.Rand) (numWalls int, hasShop bool) {
+	zone := e.zoneForChunk(chunkX, chunkY)
+	kiloPixelsPerChunk := math.Pow(config.ChunkSize/1000.0, 2)
+	minNumWalls := config.MinWallsPerKiloPixel * kiloPixelsPerChunk * zone.WallDensity
+	maxNumWalls := config.MaxWallsPerKiloPixel * kiloPixelsPerChunk * zone.WallDensity
+	numWalls = rng.Intn(int(maxNumWalls-minNumWalls+1)) + int(minNumWalls)
+
+	// Only draw for the shop chance when it can fail, so chunks without zones come out as before
+	hasShop = zone.ShopChance >= 1 || rng.Float64() < zone.ShopChance
+	return numWalls, hasShop
+}
+
 // generateChunk generates walls and enemies for a specific chunk
 func (e *Engine) generateChunk(chunkX, chunkY int, playerPos *types.Vector2) {
 	now := time.Now()
@@ -198,32 +734,42 @@ func (e *Engine) generateChunk(chunkX, chunkY int, playerPos *types.Vector2) {
 		return // Chunk already generated
 	}
 	e.chunkHash[chunkKey] = true
+	e.invalidateSightWalls(chunkKey)
 	e.state.wallsByChunk[chunkKey] = make(map[string]*types.Wall)
 	e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
 	e.state.shopsByChunk[chunkKey] = make(map[string]*types.Shop)
 
+	// Everything in the chunk comes from its own seeded source
+	rng := e.chunkRand(chunkX, chunkY)
+
+	zone := e.zoneForChunk(chunkX, chunkY)
+	if zone.Name != "" {
+		e.zoneByChunk[chunkKey] = zone.Name
+	}
+
 	chunkStartX := float64(chunkX) * config.ChunkSize
 	chunkStartY := float64(chunkY) * config.ChunkSize
 
 	// Randomly generate walls
-	kiloPixelsPerChunk := math.Pow(config.ChunkSize/1000.0, 2)
-	minNumWalls := config.MinWallsPerKiloPixel * kiloPixelsPerChunk
-	maxNumWalls := config.MaxWallsPerKiloPixel * kiloPixelsPerChunk
-	numWalls := rand.Intn(int(maxNumWalls-minNumWalls+1)) + int(minNumWalls)
+	numWalls, hasShop := e.drawChunkStart(chunkX, chunkY, rng)
 
 	chunkCenter := &types.Vector2{
 		X: chunkStartX + config.ChunkSize/2,
 		Y: chunkStartY + config.ChunkSize/2,
 	}
-	shop := types.GenerateShop(chunkCenter)
-
-	e.state.shopsByChunk[chunkKey][shop.ID] = shop
+	if hasShop {
+		// The shop is generated either way so the rest of the chunk draws the same numbers
+		shop := types.GenerateShop(chunkCenter, rng, e.shopPriceVariation, e.allowedWeapons)
+		if !e.isShopTooClose(chunkX, chunkY) {
+			e.state.shopsByChunk[chunkKey][shop.ID] = shop
+		}
+	}
 
 	// Create enemy tower
 	towerRadius := config.EnemyTowerSize / 2
 	towerPosition := &types.Vector2{
-		X: chunkStartX + towerRadius + rand.Float64()*(config.ChunkSize-towerRadius*2),
-		Y: chunkStartY + towerRadius + rand.Float64()*(config.ChunkSize-towerRadius*2),
+		X: chunkStartX + towerRadius + rng.Float64()*(config.ChunkSize-towerRadius*2),
+		Y: chunkStartY + towerRadius + rng.Float64()*(config.ChunkSize-towerRadius*2),
 	}
 	towerID := uuid.New().String()
 	e.state.enemiesByChunk[chunkKey][towerID] = &types.Enemy{
@@ -237,30 +783,31 @@ func (e *Engine) generateChunk(chunkX, chunkY int, playerPos *types.Vector2) {
 		IsAlive:    true,
 	}
 
+	// Only draw for a locked room when they are enabled, so chunks come out as before otherwise
+	var room *lockedRoom
+	if e.lockedRoomChance > 0 && rng.Float64() < e.lockedRoomChance {
+		room = e.generateLockedRoom(chunkKey, chunkStartX, chunkStartY, rng, towerPosition)
+	}
+
+	var placedWalls []*types.Wall
 	for numWalls > 0 {
 		// Random orientation
 		orientation := "vertical"
-		if rand.Float64() < 0.5 {
+		if rng.Float64() < 0.5 {
 			orientation = "horizontal"
 		}
 
 		var x, y, width, height float64
 		if orientation == "vertical" {
-			x = chunkStartX + rand.Float64()*(config.ChunkSize-200) + 100
-			y = chunkStartY + rand.Float64()*(config.ChunkSize-300) + 100
-			width = config.WallWidth
-			height = rand.Float64()*101 + 200 // 200-300
+			x = chunkStartX + rng.Float64()*(config.ChunkSize-200) + 100
+			y = chunkStartY + rng.Float64()*(config.ChunkSize-300) + 100
+			width = e.wallThickness
+			height = rng.Float64()*101 + 200 // 200-300
 		} else {
-			x = chunkStartX + rand.Float64()*(config.ChunkSize-300) + 100
-			y = chunkStartY + rand.Float64()*(config.ChunkSize-200) + 100
-			width = rand.Float64()*101 + 200 // 200-300
-			height = config.WallWidth
-		}
-
-		// Don't spawn walls too close to player
-		safePadding := config.TorchRadius + 40
-		if math.Abs(x-playerPos.X) < safePadding && math.Abs(y-playerPos.Y) < safePadding {
-			continue
+			x = chunkStartX + rng.Float64()*(config.ChunkSize-300) + 100
+			y = chunkStartY + rng.Float64()*(config.ChunkSize-200) + 100
+			width = rng.Float64()*101 + 200 // 200-300
+			height = e.wallThickness
 		}
 
 		wallID := uuid.New().String()
@@ -304,19 +851,84 @@ func (e *Engine) generateChunk(chunkX, chunkY int, playerPos *types.Vector2) {
 			}
 		}
 
-		if overlaps {
+		if overlaps || (room != nil && room.overlaps(wallTopLeft.X, wallTopLeft.Y, width, height, safeWallPadding)) {
 			continue
 		}
 
 		numWalls--
 		e.state.wallsByChunk[chunkKey][wallID] = wall
+		placedWalls = append(placedWalls, wall)
 
 		// Create enemy for this wall
-		if rand.Float64() < config.EnemySpawnChancePerWall {
-			enemy := e.createEnemyForWall(wall)
+		if rng.Float64() < zone.EnemySpawnChance {
+			enemy := e.createEnemyForWall(wall, rng, zone)
+			if rng.Float64() < config.EnemyGuardRouteChance {
+				if secondWall := e.findGuardRouteWall(chunkKey, wall); secondWall != nil {
+					enemy.SecondWallID = secondWall.ID
+				}
+			}
 			e.state.enemiesByChunk[chunkKey][enemy.ID] = enemy
 		}
 	}
+
+	// The player's surroundings are cleared only now, so the chunk draws the same numbers wherever they stand
+	room = e.clearSpawnArea(chunkKey, placedWalls, room, playerPos)
+
+	if room != nil {
+		keyholder := e.createKeyholder(room)
+		e.state.enemiesByChunk[chunkKey][keyholder.ID] = keyholder
+	}
+}
+
+// clearSpawnArea takes the generated walls too close to the player out of the chunk, along with the
+// enemies patrolling them, and the locked room if it would cover the player. Returns the room left standing.
+func (e *Engine) clearSpawnArea(chunkKey string, walls []*types.Wall, room *lockedRoom, playerPos *types.Vector2) *lockedRoom {
+	if room != nil && room.overlaps(playerPos.X, playerPos.Y, 0, 0, config.TorchRadius) {
+		e.removeLockedRoom(chunkKey, room)
+		room = nil
+	}
+
+	safePadding := config.TorchRadius + 40
+	for _, wall := range walls {
+		if math.Abs(wall.Position.X-playerPos.X) >= safePadding || math.Abs(wall.Position.Y-playerPos.Y) >= safePadding {
+			continue
+		}
+
+		delete(e.state.wallsByChunk[chunkKey], wall.ID)
+		for enemyID, enemy := range e.state.enemiesByChunk[chunkKey] {
+			if enemy.WallID == wall.ID {
+				delete(e.state.enemiesByChunk[chunkKey], enemyID)
+				e.releaseMinion(enemy, chunkKey)
+			} else if enemy.SecondWallID == wall.ID {
+				enemy.SecondWallID = ""
+			}
+		}
+		e.invalidateSightWalls(chunkKey)
+	}
+
+	return room
+}
+
+// findGuardRouteWall picks the closest other wall of the chunk within guard route reach
+func (e *Engine) findGuardRouteWall(chunkKey string, wall *types.Wall) *types.Wall {
+	var closest *types.Wall
+	minDist := config.EnemyGuardRouteMaxLength
+	center := wall.GetCenter()
+
+	for _, other := range e.state.wallsByChunk[chunkKey] {
+		if other.ID == wall.ID {
+			continue
+		}
+
+		otherCenter := other.GetCenter()
+		dist := math.Hypot(otherCenter.X-center.X, otherCenter.Y-center.Y)
+		if dist < minDist {
+			minDist = dist
+			closest = other
+		}
+	}
+
+	return closest
 }
 
 func (e *Engine) pickSpawnPoint(playerPos *types.Vector2) *types.Vector2 {
@@ -324,7 +936,7 @@ func (e *Engine) pickSpawnPoint(playerPos *types.Vector2) *types.Vector2 {
 	chunkX, chunkY := utils.ChunkXYFromPosition(playerPos.X, playerPos.Y)
 
 	// Move to the random neighboring chunk
-	chunkIdToMove := rand.Intn(8)
+	chunkIdToMove := e.rng.Intn(8)
 	if chunkIdToMove < 3 {
 		chunkY -= 1
 	}
@@ -415,20 +1027,29 @@ func (e *Engine) pickSpawnPoint(playerPos *types.Vector2) *types.Vector2 {
 }
 
 // createEnemyForWall creates an enemy that patrols along a wall
-func (e *Engine) createEnemyForWall(wall *types.Wall) *types.Enemy {
+func (e *Engine) createEnemyForWall(wall *types.Wall, rng *rand.Rand, zone *config.Zone) *types.Enemy {
 	enemyID := uuid.New().String()
 	enemyType := types.EnemyTypeSoldier
-	enemyLives := config.EnemySoldierLives
 	enemySize := config.EnemySoldierSize
-	if rand.Float64() < config.EnemyLieutenantChance {
+	flasherChance := zone.FlasherFactor * e.flasherChance
+	if roll := rng.Float64(); roll < zone.LieutenantChance {
 		enemyType = types.EnemyTypeLieutenant
-		enemyLives = config.EnemyLieutenantLives
+	} else if roll < zone.LieutenantChance+flasherChance {
+		enemyType = types.EnemyTypeFlasher
+	} else if roll < zone.LieutenantChance+flasherChance+e.summonerChance {
+		enemyType = types.EnemyTypeSummoner
+		enemySize = config.EnemySummonerSize
+	} else if roll < zone.LieutenantChance+flasherChance+e.summonerChance+e.gatekeeperChance {
+		enemyType = types.EnemyTypeGatekeeper
+		enemySize = config.EnemyGatekeeperSize
+	} else if roll < zone.LieutenantChance+flasherChance+e.summonerChance+e.gatekeeperChance+e.thiefChance {
+		enemyType = types.EnemyTypeThief
 	}
 
 	// Spawn enemy on one side of the wall
 	var x, y float64
 	wallSide := 1.0
-	if rand.Float64() < 0.5 {
+	if rng.Float64() < 0.5 {
 		wallSide = -1.0
 	}
 
@@ -445,20 +1066,226 @@ func (e *Engine) createEnemyForWall(wall *types.Wall) *types.Enemy {
 		rotation = 90.0
 	}
 
-	return &types.Enemy{
+	// Rolled last so worlds generated without armored enemies stay the same
+	armored := e.armoredEnemyChance > 0 && rng.Float64() < e.armoredEnemyChance
+	archetype := ""
+	if e.enemyArchetypes {
+		archetype = types.RollEnemyArchetype(rng.Float64())
+	}
+
+	enemy := &types.Enemy{
 		ScreenObject: types.ScreenObject{
 			ID:       enemyID,
 			Position: &types.Vector2{X: x, Y: y},
 		},
 		Rotation:   rotation,
-		Lives:      float32(enemyLives),
 		WallID:     wall.ID,
 		Direction:  1.0,
 		ShootDelay: 0,
 		IsAlive:    true,
 		DeadTimer:  0,
 		Type:       enemyType,
+		Armored:    armored,
+		Archetype:  archetype,
+
+		SummonTimer: e.summonInterval,
+	}
+	enemy.Lives = enemy.MaxLives()
+	return enemy
+}
+
+// playerDetectionParams returns the point enemies look for and the distance they notice the player from
+func (e *Engine) playerDetectionParams(player *types.Player) (*types.Vector2, float64) {
+	detectionPoint, detectionDistance := player.DetectionParams()
+	if e.stealthEnabled && !player.IsMoving {
+		detectionDistance *= config.StationaryDetectionFactor
+	}
+	return detectionPoint, detectionDistance
+}
+
+// findWallNearEnemy looks up a wall by ID in the chunks around the enemy
+func (e *Engine) findWallNearEnemy(enemy *types.Enemy, wallID string) *types.Wall {
+	enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+	for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
+		for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if !e.chunkHash[neighborChunkKey] {
+				continue
+			}
+			if wall, exists := e.state.wallsByChunk[neighborChunkKey][wallID]; exists {
+				return wall
+			}
+		}
+	}
+	return nil
+}
+
+// isEnemyMoveBlocked checks whether moving the enemy by (dx, dy) hits a wall, another enemy or a player
+func (e *Engine) isEnemyMoveBlocked(enemy *types.Enemy, dx, dy float64) bool {
+	enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+	for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
+		for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if !e.chunkHash[neighborChunkKey] {
+				continue
+			}
+
+			// Check collisions with walls
+			for _, w := range e.state.wallsByChunk[neighborChunkKey] {
+				wallTopLeft := w.GetTopLeft()
+				if utils.CheckCircleRectCollision(
+					enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
+					wallTopLeft.X, wallTopLeft.Y, w.Width, w.Height) {
+					return true
+				}
+			}
+
+			// Check collisions with other enemies
+			for _, other := range e.state.enemiesByChunk[neighborChunkKey] {
+				if other.ID != enemy.ID && other.IsAlive {
+					if utils.CheckCircleCollision(
+						enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
+						other.Position.X, other.Position.Y, other.Size()/2) {
+						return true
+					}
+				}
+			}
+		}
 	}
+
+	// Check collisions with players
+	for _, player := range e.state.players {
+		if !player.IsAlive || !player.IsConnected {
+			continue
+		}
+
+		if utils.CheckCircleCollision(
+			enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
+			player.Position.X, player.Position.Y, config.PlayerRadius) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// guardAnchor returns the point next to the wall's center, on the side facing the other wall
+func guardAnchor(wall, otherWall *types.Wall, enemySize float64) *types.Vector2 {
+	center := wall.GetCenter()
+	otherCenter := otherWall.GetCenter()
+
+	if wall.Orientation == "vertical" {
+		side := 1.0
+		if otherCenter.X < center.X {
+			side = -1.0
+		}
+		return &types.Vector2{X: center.X + side*(wall.Width/2+enemySize/2+1), Y: center.Y}
+	}
+
+	side := 1.0
+	if otherCenter.Y < center.Y {
+		side = -1.0
+	}
+	return &types.Vector2{X: center.X, Y: center.Y + side*(wall.Height/2+enemySize/2+1)}
+}
+
+// moveEnemyAlongGuardRoute walks the enemy back and forth between the anchors of its two walls.
+// Direction 1 heads towards the second wall, -1 back to the first one.
+func (e *Engine) moveEnemyAlongGuardRoute(enemy *types.Enemy, wall, secondWall *types.Wall, facingPlayer bool, deltaTime float64) {
+	target := guardAnchor(secondWall, wall, enemy.Size())
+	if enemy.Direction < 0 {
+		target = guardAnchor(wall, secondWall, enemy.Size())
+	}
+
+	toTargetX := target.X - enemy.Position.X
+	toTargetY := target.Y - enemy.Position.Y
+	distance := math.Sqrt(toTargetX*toTargetX + toTargetY*toTargetY)
+	step := enemy.Speed() * deltaTime
+
+	if distance <= step {
+		enemy.Position.X = target.X
+		enemy.Position.Y = target.Y
+		enemy.Direction *= -1
+		return
+	}
+
+	dx := toTargetX / distance * step
+	dy := toTargetY / distance * step
+
+	dx, dy, canMove := e.steerEnemy(enemy, dx, dy, distance)
+	if !canMove {
+		enemy.Direction *= -1
+		return
+	}
+
+	if !facingPlayer {
+		enemy.Rotation = utils.RotationTowards(dx, dy)
+	}
+
+	enemy.Position.X += dx
+	enemy.Position.Y += dy
+}
+
+// enemySteeringAngles are the turns, in degrees, tried in order when a wall blocks the enemy's heading
+var enemySteeringAngles = []float64{30, -30, 60, -60, 90, -90, 120, -120}
+
+// steerEnemy adjusts the enemy's step (dx, dy) towards a target so it turns around walls within the
+// look-ahead distance instead of bumping into them. The look-ahead never goes past the target, which
+// lies maxDistance away. Returns false when the enemy can't move in any direction.
+func (e *Engine) steerEnemy(enemy *types.Enemy, dx, dy, maxDistance float64) (float64, float64, bool) {
+	if e.enemyLookAhead <= 0 {
+		return dx, dy, !e.isEnemyMoveBlocked(enemy, dx, dy)
+	}
+
+	step := math.Sqrt(dx*dx + dy*dy)
+	if step == 0 {
+		return dx, dy, true
+	}
+	lookAhead := math.Min(e.enemyLookAhead, maxDistance)
+	heading := math.Atan2(dy, dx)
+
+	// Prefer headings with no wall ahead, then settle for any step that isn't blocked right away
+	for _, needClearPath := range []bool{true, false} {
+		for _, turn := range append([]float64{0}, enemySteeringAngles...) {
+			angle := heading + turn*math.Pi/180
+			stepX, stepY := math.Cos(angle)*step, math.Sin(angle)*step
+
+			if needClearPath && !e.isEnemyPathClear(enemy, math.Cos(angle), math.Sin(angle), lookAhead) {
+				continue
+			}
+			if !e.isEnemyMoveBlocked(enemy, stepX, stepY) {
+				return stepX, stepY, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// isEnemyPathClear checks that the enemy can travel the given distance along the (dirX, dirY) unit vector without touching a wall
+func (e *Engine) isEnemyPathClear(enemy *types.Enemy, dirX, dirY, distance float64) bool {
+	radius := enemy.Size() / 2
+	endX := enemy.Position.X + dirX*distance
+	endY := enemy.Position.Y + dirY*distance
+
+	enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+	for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
+		for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
+				// Grow the wall by the enemy's radius so the path of its center can be tested as a line
+				wallTopLeft := wall.GetTopLeft()
+				if utils.CheckLineRectCollision(
+					enemy.Position.X, enemy.Position.Y, endX, endY,
+					wallTopLeft.X-radius, wallTopLeft.Y-radius,
+					wall.Width+2*radius, wall.Height+2*radius) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
 }
 
 func (e *Engine) addPlayerToRespawnQueue(id string) {
@@ -467,11 +1294,146 @@ func (e *Engine) addPlayerToRespawnQueue(id string) {
 	}
 }
 
-func (e *Engine) RespawnPlayer(id string) {
+// findShopForPlayer returns the shop whose interaction range the player is in, looking at the surrounding chunks
+func (e *Engine) findShopForPlayer(player *types.Player, playerChunkX, playerChunkY int) *types.Shop {
+	for neighborChunkX := playerChunkX - 1; neighborChunkX <= playerChunkX+1; neighborChunkX++ {
+		for neighborChunkY := playerChunkY - 1; neighborChunkY <= playerChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			for _, shop := range e.state.shopsByChunk[neighborChunkKey] {
+				if shop.IsPlayerInShop(player) {
+					return shop
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// discoverShops marks the shops the player can see as discovered
+func (e *Engine) discoverShops(player *types.Player, playerChunkX, playerChunkY int) {
+	for neighborChunkX := playerChunkX - 1; neighborChunkX <= playerChunkX+1; neighborChunkX++ {
+		for neighborChunkY := playerChunkY - 1; neighborChunkY <= playerChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			for _, shop := range e.state.shopsByChunk[neighborChunkKey] {
+				if !shop.Discovered && shop.IsVisibleToPlayer(player) {
+					shop.Discovered = true
+				}
+			}
+		}
+	}
+}
+
+// updatePlayerShop queues shop enter/leave events when the player crosses a shop's interaction range
+func (e *Engine) updatePlayerShop(player *types.Player, playerChunkX, playerChunkY int) {
+	shopID := ""
+	if shop := e.findShopForPlayer(player, playerChunkX, playerChunkY); shop != nil {
+		shopID = shop.ID
+	}
+
+	currentShopID := e.currentShopByPlayer[player.ID]
+	if shopID == currentShopID {
+		return
+	}
+
+	if currentShopID != "" {
+		e.shopEventsByPlayer[player.ID] = append(e.shopEventsByPlayer[player.ID], &protocol.ShopEvent{
+			Type:   protocol.ShopEventType_SHOP_LEAVE,
+			ShopId: currentShopID,
+		})
+		delete(e.currentShopByPlayer, player.ID)
+	}
+
+	if shopID != "" {
+		e.shopEventsByPlayer[player.ID] = append(e.shopEventsByPlayer[player.ID], &protocol.ShopEvent{
+			Type:   protocol.ShopEventType_SHOP_ENTER,
+			ShopId: shopID,
+		})
+		e.currentShopByPlayer[player.ID] = shopID
+	}
+}
+
+// killPlayer marks the player as dead and remembers the death so it gets reported exactly once
+func (e *Engine) killPlayer(player *types.Player) {
+	player.Die()
+	e.deaths = append(e.deaths, player.Clone())
+	e.diedAt[player.ID] = time.Now()
+	delete(e.respawnQueue, player.ID)
+	delete(e.survivalTime, player.ID)
+
+	if e.hardcore && e.allPlayersDead() {
+		e.partyWiped = true
+	}
+}
+
+// canRespawn reports whether enough time has passed since the player died
+func (e *Engine) canRespawn(player *types.Player) bool {
+	if player.IsAlive {
+		return false
+	}
+	diedAt, exists := e.diedAt[player.ID]
+	return !exists || time.Since(diedAt) >= e.respawnDelay
+}
+
+// TakeDeaths returns the players who died since the previous call, as they were at the moment of death.
+// Players loaded already dead from a saved session are not included.
+func (e *Engine) TakeDeaths() []*types.Player {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	deaths := e.deaths
+	e.deaths = nil
+	return deaths
+}
+
+// RespawnPlayer queues a dead player for respawn, reporting whether the request was accepted.
+// Requests from living players or from players still waiting out the respawn delay are ignored.
+func (e *Engine) RespawnPlayer(id string) bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	player, exists := e.state.players[id]
+	if !exists || !e.canRespawn(player) {
+		return false
+	}
+
 	e.addPlayerToRespawnQueue(id)
+	return true
+}
+
+// IsIdle reports whether nobody is playing. Players waiting out the reconnect grace period don't count.
+func (e *Engine) IsIdle() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return !e.hasConnectedPlayers()
+}
+
+// hasConnectedPlayers reports whether any player in the session is connected
+func (e *Engine) hasConnectedPlayers() bool {
+	for _, player := range e.state.players {
+		if player.IsConnected {
+			return true
+		}
+	}
+	return false
+}
+
+// SuspendPlayer marks a player whose connection dropped as disconnected and lets go of every
+// key, so their character stays in the game untouched while they may still reconnect
+func (e *Engine) SuspendPlayer(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if player, exists := e.state.players[id]; exists {
+		player.IsConnected = false
+	}
+	delete(e.playerInputState, id)
+	if _, exists := e.itemsToUseByPlayer[id]; exists {
+		e.itemsToUseByPlayer[id] = []types.InventoryItemID{}
+	}
+	if _, exists := e.itemsToPurchaseByPlayer[id]; exists {
+		e.itemsToPurchaseByPlayer[id] = []types.InventoryItemID{}
+	}
 }
 
 // DisconnectPlayer removes a player from the game
@@ -488,6 +1450,32 @@ func (e *Engine) DisconnectPlayer(id string) {
 	delete(e.respawnQueue, id)
 	delete(e.itemsToUseByPlayer, id)
 	delete(e.itemsToPurchaseByPlayer, id)
+	delete(e.currentShopByPlayer, id)
+	delete(e.shopEventsByPlayer, id)
+	delete(e.killCamFrames, id)
+	delete(e.killCamsByPlayer, id)
+	delete(e.jammedByPlayer, id)
+	delete(e.zoneSent, id)
+	delete(e.joinedPlayersByPlayer, id)
+	delete(e.leftPlayersByPlayer, id)
+	delete(e.portalArrivals, id)
+
+	if exists && e.joinLeaveInDeltas {
+		for otherID := range e.prevState {
+			e.leftPlayersByPlayer[otherID] = append(e.leftPlayersByPlayer[otherID], id)
+		}
+	}
+}
+
+// RemovePlayer takes the player out of the game for good, they start over if they join again
+func (e *Engine) RemovePlayer(id string) {
+	e.DisconnectPlayer(id)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.state.players, id)
+	delete(e.diedAt, id)
+	delete(e.survivalTime, id)
 }
 
 // UpdatePlayerInput updates player movement and rotation based on input
@@ -495,6 +1483,10 @@ func (e *Engine) UpdatePlayerInput(playerID string, input types.InputPayload) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if e.recordInputs {
+		e.recordInput(playerID, input)
+	}
+
 	prevInput, exists := e.playerInputState[playerID]
 	if exists {
 		for i := range prevInput.ItemKey {
@@ -600,6 +1592,10 @@ func (e *Engine) updatePreviousState(playerID string) {
 
 	prevState.bonuses = make(map[string]*types.Bonus)
 	for id, bonus := range e.state.bonuses {
+		if e.isTeammateChest(bonus, player) {
+			prevState.bonuses[id] = bonus.Clone()
+			continue
+		}
 		for _, p := range playersAbleToSee {
 
 			if bonus.IsVisibleToPlayer(p) {
@@ -620,6 +1616,8 @@ func (e *Engine) Update() {
 	now := time.Now()
 	deltaTime := now.Sub(e.lastUpdate).Seconds()
 	e.lastUpdate = now
+	tickStart := now
+	e.tickCount++
 
 	var updateDuration time.Duration
 
@@ -632,17 +1630,19 @@ func (e *Engine) Update() {
 		}
 
 		if !player.IsAlive {
-			if _, exists := e.respawnQueue[player.ID]; exists {
+			if _, exists := e.respawnQueue[player.ID]; exists && e.canRespawn(player) {
 				// Respawn player
 				spawnPoint := e.pickSpawnPoint(player.Position)
 				player.Respawn(spawnPoint)
 				delete(e.respawnQueue, player.ID)
+				delete(e.diedAt, player.ID)
 			}
 
 			continue
 		}
 
 		playerChunkX, playerChunkY := utils.ChunkXYFromPosition(player.Position.X, player.Position.Y)
+		startX, startY := player.Position.X, player.Position.Y
 
 		// Update timers
 		if player.InvulnerableTimer > 0 {
@@ -653,13 +1653,27 @@ func (e *Engine) Update() {
 			player.NightVisionTimer = math.Max(0, player.NightVisionTimer-deltaTime)
 		}
 
+		if player.BlindTimer > 0 {
+			player.BlindTimer = math.Max(0, player.BlindTimer-deltaTime)
+		}
+
+		if player.ReloadTimer > 0 {
+			player.ReloadTimer = math.Max(0, player.ReloadTimer-deltaTime)
+		}
+
+		player.UpdatePowerUps(deltaTime)
+
+		if len(player.DamageContributors) > 0 {
+			player.DamageContributors.Prune(e.assistWindow, now)
+		}
+
 		player.Recharge(deltaTime)
 
 		itemsToUse := e.itemsToUseByPlayer[player.ID]
 		for _, itemID := range itemsToUse {
 			_, exists := types.WeaponTypeByInventoryItem[itemID]
 			if exists {
-				player.SelectGunType(itemID)
+				player.SelectGunType(itemID, e.allowedWeapons)
 			}
 
 			if itemID == types.InventoryItemAidKit {
@@ -672,6 +1686,8 @@ func (e *Engine) Update() {
 		}
 		e.itemsToUseByPlayer[player.ID] = []types.InventoryItemID{}
 
+		e.openLockedDoors(player, playerChunkX, playerChunkY)
+
 		var playersShop *types.Shop
 		// Check if player is in shop
 		chunkKey := fmt.Sprintf("%d,%d", playerChunkX, playerChunkY)
@@ -684,35 +1700,41 @@ func (e *Engine) Update() {
 
 		itemsToPurchase := e.itemsToPurchaseByPlayer[player.ID]
 		for _, itemID := range itemsToPurchase {
-			if playersShop != nil {
-				playersShop.PurchaseInventoryItem(player, itemID)
+			if playersShop != nil && playersShop.PurchaseInventoryItem(player, itemID, e.allowedWeapons) {
+				e.clampPlayerFunds(player)
 			}
 		}
 		e.itemsToPurchaseByPlayer[player.ID] = []types.InventoryItemID{}
 
 		input, inputExists := e.playerInputState[player.ID]
+		if e.sprintEnabled {
+			wantsSprint := inputExists && input.Sprint && (input.Forward || input.Backward)
+			player.UpdateStamina(wantsSprint, deltaTime)
+		}
+
 		if inputExists {
+			if e.clientPrediction {
+				player.LastProcessedInput = input.Sequence
+			}
 
 			// Process movement input
 			if input.Left || input.Right {
+				turn := 0.0
 				if input.Left {
-					player.Rotation -= config.PlayerRotationSpeed * deltaTime
+					turn -= config.PlayerRotationSpeed * deltaTime
 				}
 				if input.Right {
-					player.Rotation += config.PlayerRotationSpeed * deltaTime
-				}
-
-				// Normalize rotation to 0-360 range
-				for player.Rotation < 0 {
-					player.Rotation += 360
-				}
-				for player.Rotation >= 360 {
-					player.Rotation -= 360
+					turn += config.PlayerRotationSpeed * deltaTime
 				}
+				player.Rotation = utils.NormalizeRotation(player.Rotation + math.Max(-e.maxRotationPerTick, math.Min(e.maxRotationPerTick, turn)))
 			}
 
 			rotationRad := player.Rotation * math.Pi / 180.0
 
+			if input.Reload {
+				player.Reload()
+			}
+
 			if input.Shoot {
 				e.handlePlayerShooting(player)
 			}
@@ -727,8 +1749,8 @@ func (e *Engine) Update() {
 				}
 
 				// Calculate movement
-				intendedDx := -math.Sin(rotationRad) * config.PlayerSpeed * deltaTime * forward
-				intendedDy := math.Cos(rotationRad) * config.PlayerSpeed * deltaTime * forward
+				intendedDx := -math.Sin(rotationRad) * player.Speed(e.powerUps) * deltaTime * forward
+				intendedDy := math.Cos(rotationRad) * player.Speed(e.powerUps) * deltaTime * forward
 
 				dx := intendedDx
 				dy := intendedDy
@@ -842,8 +1864,12 @@ func (e *Engine) Update() {
 			}
 		}
 
+		player.IsMoving = player.Position.X != startX || player.Position.Y != startY
+
 		// Track chunks where players are located
 		playerChunkX, playerChunkY = utils.ChunkXYFromPosition(player.Position.X, player.Position.Y)
+		e.updatePlayerShop(player, playerChunkX, playerChunkY)
+		e.discoverShops(player, playerChunkX, playerChunkY)
 		for neighborChunkX := playerChunkX - 1; neighborChunkX <= playerChunkX+1; neighborChunkX++ {
 			for neighborChunkY := playerChunkY - 1; neighborChunkY <= playerChunkY+1; neighborChunkY++ {
 				neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
@@ -855,14 +1881,15 @@ func (e *Engine) Update() {
 		}
 	}
 
-	if e.debugMode {
+	if e.collectStats {
 		updateDuration = time.Since(now)
-		e.stats.TotalUpdateTime.players += updateDuration
-		e.stats.TotalUpdateTimeSinceLastReport.players += updateDuration
+		e.stats.TotalUpdateTime.Players += updateDuration
+		e.stats.TotalUpdateTimeSinceLastReport.Players += updateDuration
 		now = time.Now()
 	}
 
 	checkedEnemies := 0
+	var alerts []enemyAlert
 
 	// Update enemies
 	for enemyChunkKey := range playersChunks {
@@ -884,9 +1911,17 @@ func (e *Engine) Update() {
 			if enemy.ShootDelay > 0 {
 				enemy.ShootDelay -= deltaTime
 			}
+			if enemy.MeleeDelay > 0 {
+				enemy.MeleeDelay -= deltaTime
+			}
+
+			if len(enemy.DamageContributors) > 0 {
+				enemy.DamageContributors.Prune(e.assistWindow, now)
+			}
 
 			// Find closest player to track
 			var closestVisiblePlayer *types.Player
+			var sightWalls []sightWall // gathered once a player is close enough to need them
 			hasPlayersInSight := false
 			canSee := false
 			minDist := math.MaxFloat64
@@ -896,41 +1931,18 @@ func (e *Engine) Update() {
 					continue
 				}
 
-				detectionPoint, detectionDistance := player.DetectionParams()
+				detectionPoint, detectionDistance := e.playerDetectionParams(player)
 
 				dist := enemy.DistanceToPoint(detectionPoint)
-				if dist < config.SightRadius {
+				if dist < e.enemyAwarenessRadius {
 					hasPlayersInSight = true
 				}
 				if dist < detectionDistance+enemy.Size()/2 {
 					// Add line-of-sight check with walls
-					lineClear := true
-
-					for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
-						for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
-							neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
-							if !e.chunkHash[neighborChunkKey] {
-								continue
-							}
-							for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
-								distanceToWall := enemy.DistanceToPoint(wall.GetCenter())
-								if distanceToWall > 2*wall.GetRadius()+detectionDistance {
-									continue // Wall is beyond player
-								}
-
-								wallTopLeft := wall.GetTopLeft()
-								if utils.CheckLineRectCollision(
-									enemy.Position.X, enemy.Position.Y,
-									detectionPoint.X, detectionPoint.Y,
-									wallTopLeft.X, wallTopLeft.Y,
-									wall.Width, wall.Height) {
-									lineClear = false
-									break
-								}
-							}
-						}
+					if sightWalls == nil {
+						sightWalls = e.sightWallsAround(enemyChunkX, enemyChunkY)
 					}
-					if lineClear {
+					if hasLineOfSight(enemy, detectionPoint, detectionDistance, sightWalls) {
 						canSee = true
 						if dist < minDist {
 							minDist = dist
@@ -944,53 +1956,49 @@ func (e *Engine) Update() {
 				continue // No players nearby
 			}
 
-			if canSee {
-				// Aim at player
-				dx := closestVisiblePlayer.Position.X - enemy.Position.X
-				dy := closestVisiblePlayer.Position.Y - enemy.Position.Y
-				desiredRotation := math.Atan2(-dx, dy) * 180 / math.Pi
-				if enemy.Type == types.EnemyTypeTower {
-					// Smooth rotation for tower
-					rotationDiff := desiredRotation - enemy.Rotation
-					for rotationDiff < -180 {
-						rotationDiff += 360
-					}
-					for rotationDiff > 180 {
-						rotationDiff -= 360
-					}
+			if enemy.Type == types.EnemyTypeSummoner {
+				e.updateSummoner(enemy, deltaTime)
+			}
 
-					maxRotationChange := config.EnemyTowerRotationSpeed * deltaTime
-					if math.Abs(rotationDiff) < maxRotationChange {
-						enemy.Rotation = desiredRotation
-					} else {
-						if rotationDiff > 0 {
-							enemy.Rotation += maxRotationChange
-						} else {
-							enemy.Rotation -= maxRotationChange
-						}
+			// Thieves go after the players they see instead of shooting
+			if enemy.Type == types.EnemyTypeThief && canSee {
+				e.updateThief(enemy, closestVisiblePlayer, enemyChunkKey, deltaTime)
+				continue
+			}
 
-						// Normalize rotation to 0-360 range
-						for enemy.Rotation < 0 {
-							enemy.Rotation += 360
-						}
-						for enemy.Rotation >= 360 {
-							enemy.Rotation -= 360
-						}
-					}
-				} else {
-					enemy.Rotation = desiredRotation
-				}
+			// Wounded enemies run instead of shooting, until they are cornered
+			if canSee && e.isEnemyFleeing(enemy) && e.fleeFromPlayer(enemy, closestVisiblePlayer, enemyChunkKey, deltaTime) {
+				continue
+			}
+
+			// Enemies alerted by others face the player even without seeing them
+			facingPlayer := canSee
+			if canSee {
+				// Players too close get hit as well as shot at
+				e.handleEnemyMelee(enemy, closestVisiblePlayer)
+
+				// Aim at player
+				desiredRotation := turnEnemyTowards(enemy, closestVisiblePlayer.Position, deltaTime)
 
 				// Shoot at player
 				if enemy.ShootDelay <= 0 && enemy.Rotation == desiredRotation {
-					bullet := enemy.Shoot()
-					e.state.bullets[bullet.ID] = bullet
+					bullets := enemy.Shoot(e.enemyBulletSpread)
+					e.applyEnemyAimError(bullets, minDist)
+					for _, bullet := range bullets {
+						e.addBullet(bullet)
+					}
 					enemy.ShootDelay = types.EnemyShootDelayByType[enemy.Type]
+					if e.enemyAggroRadius > 0 {
+						alerts = append(alerts, enemyAlert{enemy: enemy, player: closestVisiblePlayer})
+					}
 				}
+			} else if target := e.enemyAggroTarget(enemy, now); target != nil {
+				turnEnemyTowards(enemy, target.Position, deltaTime)
+				facingPlayer = true
 			}
 
 			shouldPatrol := false
-			if enemy.Type == types.EnemyTypeSoldier && !canSee {
+			if (enemy.Type == types.EnemyTypeSoldier || enemy.Type == types.EnemyTypeFlasher || enemy.Type == types.EnemyTypeMinion || enemy.Type == types.EnemyTypeKeyholder || enemy.Type == types.EnemyTypeThief) && !canSee {
 				shouldPatrol = true
 			}
 			if enemy.Type == types.EnemyTypeLieutenant {
@@ -998,36 +2006,34 @@ func (e *Engine) Update() {
 			}
 
 			if shouldPatrol {
-				// Patrol logic
-				var wall *types.Wall
-				var wallExists bool
-				enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
-				for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
-					for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
-						neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
-						if !e.chunkHash[neighborChunkKey] {
-							continue
-						}
-						wall, wallExists = e.state.wallsByChunk[neighborChunkKey][enemy.WallID]
-						if wallExists {
-							break
-						}
+				// Enemies whose wall is gone roam instead
+				if enemy.Roaming {
+					if !facingPlayer {
+						e.roamEnemy(enemy, enemyChunkKey, deltaTime)
 					}
-					if wallExists {
-						break
+					continue
+				}
+
+				// Patrol logic
+				wall := e.findWallNearEnemy(enemy, enemy.WallID)
+				if wall != nil && enemy.SecondWallID != "" {
+					if secondWall := e.findWallNearEnemy(enemy, enemy.SecondWallID); secondWall != nil {
+						e.moveEnemyAlongGuardRoute(enemy, wall, secondWall, facingPlayer, deltaTime)
+						continue
 					}
 				}
-				if wallExists {
+
+				if wall != nil {
 					var dx, dy float64
 					if wall.Orientation == "vertical" {
-						dy = config.EnemySoldierSpeed * float64(enemy.Direction) * deltaTime
-						if !canSee {
-							enemy.Rotation = 90 - 90*float64(enemy.Direction)
+						dy = enemy.Speed() * float64(enemy.Direction) * deltaTime
+						if !facingPlayer {
+							enemy.Rotation = utils.NormalizeRotation(90 - 90*float64(enemy.Direction))
 						}
 					} else {
-						dx = config.EnemySoldierSpeed * float64(enemy.Direction) * deltaTime
-						if !canSee {
-							enemy.Rotation = -90 * float64(enemy.Direction)
+						dx = enemy.Speed() * float64(enemy.Direction) * deltaTime
+						if !facingPlayer {
+							enemy.Rotation = utils.NormalizeRotation(-90 * float64(enemy.Direction))
 						}
 					}
 
@@ -1036,65 +2042,7 @@ func (e *Engine) Update() {
 						continue
 					}
 
-					// Check collisions with walls
-					collision := false
-					for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
-						for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
-							neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
-							if !e.chunkHash[neighborChunkKey] {
-								continue
-							}
-
-							for _, w := range e.state.wallsByChunk[neighborChunkKey] {
-								wallTopLeft := w.GetTopLeft()
-								if utils.CheckCircleRectCollision(
-									enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
-									wallTopLeft.X, wallTopLeft.Y, w.Width, w.Height) {
-									collision = true
-									break
-								}
-							}
-							if collision {
-								break
-							}
-
-							// Check collisions with other enemies
-							for _, other := range e.state.enemiesByChunk[neighborChunkKey] {
-								if other.ID != enemy.ID && other.IsAlive {
-									if utils.CheckCircleCollision(
-										enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
-										other.Position.X, other.Position.Y, other.Size()/2) {
-										collision = true
-										break
-									}
-								}
-							}
-							if collision {
-								break
-							}
-						}
-						if collision {
-							break
-						}
-					}
-
-					// Check collisions with players (only if no collision detected yet)
-					if !collision {
-						for _, player := range e.state.players {
-							if !player.IsAlive || !player.IsConnected {
-								continue
-							}
-
-							if utils.CheckCircleCollision(
-								enemy.Position.X+dx, enemy.Position.Y+dy, enemy.Size()/2,
-								player.Position.X, player.Position.Y, config.PlayerRadius) {
-								collision = true
-								break
-							}
-						}
-					}
-
-					if collision {
+					if e.isEnemyMoveBlocked(enemy, dx, dy) {
 						enemy.Direction *= -1
 					} else {
 						enemy.Position.X += dx
@@ -1118,14 +2066,33 @@ func (e *Engine) Update() {
 		}
 	}
 
-	if e.debugMode {
+	if len(alerts) > 0 {
+		e.alertEnemies(alerts, now)
+	}
+
+	if e.collectStats {
 		updateDuration = time.Since(now)
-		e.stats.TotalUpdateTime.enemies += updateDuration
-		e.stats.TotalUpdateTimeSinceLastReport.enemies += updateDuration
+		e.stats.TotalUpdateTime.Enemies += updateDuration
+		e.stats.TotalUpdateTimeSinceLastReport.Enemies += updateDuration
 		now = time.Now()
 	}
 
-	// Update bullets
+	e.updatePoison(deltaTime)
+
+	// Remember where bullets started the tick to find the ones that crossed paths
+	var bulletStarts map[string]types.Vector2
+	if e.shootableEnemyBullets {
+		bulletStarts = make(map[string]types.Vector2, len(e.state.bullets))
+		for id, bullet := range e.state.bullets {
+			bulletStarts[id] = *bullet.Position
+		}
+	}
+
+	e.capBulletsPerChunk()
+
+	// Update bullets, each only checking the walls, players and enemies around its own path
+	wallsByChunk := make(map[[2]int][]*types.Wall)
+	e.hitGrid = e.buildSpatialGrid()
 	for _, bullet := range e.state.bullets {
 		// Check if bonus was picked up and needs cleanup
 		if !bullet.DeletedAt.IsZero() {
@@ -1147,31 +2114,21 @@ func (e *Engine) Update() {
 		dx := bullet.Velocity.X * deltaTime
 		dy := bullet.Velocity.Y * deltaTime
 
-		hitFound := false
-
-		bulletNextChunkX, bulletNextChunkY := utils.ChunkXYFromPosition(bullet.Position.X+dx, bullet.Position.Y+dy)
-
-		for neighborChunkX := bulletNextChunkX - 1; neighborChunkX <= bulletNextChunkX+1; neighborChunkX++ {
-			for neighborChunkY := bulletNextChunkY - 1; neighborChunkY <= bulletNextChunkY+1; neighborChunkY++ {
-				neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
-				if !e.chunkHash[neighborChunkKey] {
-					continue
-				}
+		hitFound := false
 
-				// Check collision with walls
-				for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
-					topLeft := wall.GetTopLeft()
-					ix, iy := utils.CutLineSegmentBeforeRect(
-						bullet.Position.X, bullet.Position.Y, bullet.Position.X+dx, bullet.Position.Y+dy,
-						topLeft.X, topLeft.Y,
-						wall.Width, wall.Height)
-
-					if !(ix == bullet.Position.X+dx && iy == bullet.Position.Y+dy) {
-						hitFound = true
-						dx = ix - bullet.Position.X
-						dy = iy - bullet.Position.Y
-					}
-				}
+		// Check collision with walls
+		for _, wall := range e.wallsOnBulletPath(bullet.Position, dx, dy, wallsByChunk) {
+			topLeft := wall.GetTopLeft()
+			ix, iy := utils.CutLineSegmentBeforeRectWithTolerance(
+				bullet.Position.X, bullet.Position.Y, bullet.Position.X+dx, bullet.Position.Y+dy,
+				topLeft.X, topLeft.Y,
+				wall.Width, wall.Height,
+				e.bulletWallTolerance)
+
+			if !(ix == bullet.Position.X+dx && iy == bullet.Position.Y+dy) {
+				hitFound = true
+				dx = ix - bullet.Position.X
+				dy = iy - bullet.Position.Y
 			}
 		}
 
@@ -1194,15 +2151,26 @@ func (e *Engine) Update() {
 		}
 	}
 
-	if e.debugMode {
+	e.hitGrid = nil
+
+	if e.shootableEnemyBullets {
+		e.interceptEnemyBullets(bulletStarts)
+	}
+
+	if e.collectStats {
 		updateDuration = time.Since(now)
-		e.stats.TotalUpdateTime.bullets += updateDuration
-		e.stats.TotalUpdateTimeSinceLastReport.bullets += updateDuration
+		e.stats.TotalUpdateTime.Bullets += updateDuration
+		e.stats.TotalUpdateTimeSinceLastReport.Bullets += updateDuration
 		now = time.Now()
 	}
 
 	// Update bonuses - check pickup
 	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal {
+			e.updatePortal(bonus)
+			continue
+		}
+
 		// Check if bonus was picked up and needs cleanup
 		if !bonus.PickedUpAt.IsZero() {
 			if time.Since(bonus.PickedUpAt) > config.DeadEntitiesCacheTimeout {
@@ -1223,100 +2191,50 @@ func (e *Engine) Update() {
 				continue
 			}
 
-			bonusRadius := config.AidKitSize / 2
-			if bonus.Type == types.BonusTypeGoggles {
-				bonusRadius = config.GogglesSize / 2
-			}
-
 			distance := player.DistanceToPoint(bonus.Position)
 
-			if distance < config.PlayerRadius+bonusRadius {
+			if distance < config.PlayerRadius+e.bonusPickupRadius[bonus.Type] {
 				// Pickup!
-				player.PickupBonus(bonus)
+				pickedUp := bonus.Inventory
+				player.PickupBonus(bonus, e.powerUps)
+				e.autoEquipWeapon(player, pickedUp)
+				e.clampPlayerFunds(player)
+				if bonus.Type == types.BonusTypeChest && e.chestPickupInvulnerability > 0 {
+					player.InvulnerableTimer = math.Max(player.InvulnerableTimer, e.chestPickupInvulnerability)
+				}
 				break
 			}
 		}
 	}
 
-	if e.debugMode {
+	e.updateAchievements(deltaTime)
+	e.recordKillCamFrames(now)
+
+	// Wiped at the end of the tick, the updates above still work on the old world
+	if e.partyWiped {
+		e.wipeWorld()
+	}
+
+	if e.slowTickThreshold > 0 {
+		if tickDuration := time.Since(tickStart); tickDuration > e.slowTickThreshold {
+			e.logSlowTick(tickDuration)
+		}
+	}
+
+	if e.collectStats {
 		// Update stats
 		e.stats.UpdateCount++
 		e.stats.UpdateCountSinceLastReport++
+		e.stats.CheckedEnemies = checkedEnemies
 
 		updateDuration = time.Since(now)
-		e.stats.TotalUpdateTime.bonuses += updateDuration
-		e.stats.TotalUpdateTimeSinceLastReport.bonuses += updateDuration
+		e.stats.TotalUpdateTime.Bonuses += updateDuration
+		e.stats.TotalUpdateTimeSinceLastReport.Bonuses += updateDuration
 
 		if e.stats.LastReportedAt.IsZero() || time.Since(e.stats.LastReportedAt) >= e.stats.Frequency {
-			var avgUpdateTime time.Duration
-			var avgUpdateTimeSinceLastReport time.Duration
-			var avgDeltaCalcTime time.Duration
-			var avgDeltaCalcTimeSinceLastReport time.Duration
-			var avgUpdatePrevStateTime time.Duration
-			var avgUpdatePrevStateTimeSinceLastReport time.Duration
-			var avgUpdateTimeByType UpdateTimeStats
-			var avgUpdateTimeByTypeSinceLastReport UpdateTimeStats
-
-			if e.stats.UpdateCount > 0 {
-				avgUpdateTime = e.stats.TotalUpdateTime.Total() / time.Duration(e.stats.UpdateCount)
-				avgUpdateTimeByType = UpdateTimeStats{
-					players: e.stats.TotalUpdateTime.players / time.Duration(e.stats.UpdateCount),
-					enemies: e.stats.TotalUpdateTime.enemies / time.Duration(e.stats.UpdateCount),
-					bullets: e.stats.TotalUpdateTime.bullets / time.Duration(e.stats.UpdateCount),
-					bonuses: e.stats.TotalUpdateTime.bonuses / time.Duration(e.stats.UpdateCount),
-				}
+			if e.debugMode {
+				e.logStats()
 			}
-			if e.stats.UpdateCountSinceLastReport > 0 {
-				avgUpdateTimeSinceLastReport = e.stats.TotalUpdateTimeSinceLastReport.Total() / time.Duration(e.stats.UpdateCountSinceLastReport)
-				avgUpdateTimeByTypeSinceLastReport = UpdateTimeStats{
-					players: e.stats.TotalUpdateTimeSinceLastReport.players / time.Duration(e.stats.UpdateCountSinceLastReport),
-					enemies: e.stats.TotalUpdateTimeSinceLastReport.enemies / time.Duration(e.stats.UpdateCountSinceLastReport),
-					bullets: e.stats.TotalUpdateTimeSinceLastReport.bullets / time.Duration(e.stats.UpdateCountSinceLastReport),
-					bonuses: e.stats.TotalUpdateTimeSinceLastReport.bonuses / time.Duration(e.stats.UpdateCountSinceLastReport),
-				}
-			}
-			if e.stats.DeltaCalcCount > 0 {
-				avgDeltaCalcTime = e.stats.TotalDeltaCalcTime.Total() / time.Duration(e.stats.DeltaCalcCount)
-				avgUpdatePrevStateTime = e.stats.TotalDeltaCalcTime.updatePrevious / time.Duration(e.stats.DeltaCalcCount)
-			}
-			if e.stats.DeltaCalcCountSinceLastReport > 0 {
-				avgDeltaCalcTimeSinceLastReport = e.stats.TotalDeltaCalcTimeSinceLastReport.Total() / time.Duration(e.stats.DeltaCalcCountSinceLastReport)
-				avgUpdatePrevStateTimeSinceLastReport = e.stats.TotalDeltaCalcTimeSinceLastReport.updatePrevious / time.Duration(e.stats.DeltaCalcCountSinceLastReport)
-			}
-
-			// Print stats
-			log.Printf(
-				"Engine Stats - Session %s:\n"+
-					"Total Updates: %d\n"+
-					"Avg Update Time: %s\n"+
-					"Players: %s, Enemies: %s, Bullets: %s, Bonuses: %s\n"+
-					"Avg Update Time (last period): %s (%d rounds)\n"+
-					"Players: %s (%d elements), Enemies: %s (%d checked), Bullets: %s (%d elements), Bonuses: %s (%d elements)\n"+
-					"Avg Delta Calc Time: %s (of which %s for updating previous state)\n"+
-					"Avg Delta Calc Time (last period): %s (of which %s for updating previous state, %d rounds)\n\n\n",
-				e.sessionID,
-				e.stats.UpdateCount,
-				avgUpdateTime.String(),
-				avgUpdateTimeByType.players.String(),
-				avgUpdateTimeByType.enemies.String(),
-				avgUpdateTimeByType.bullets.String(),
-				avgUpdateTimeByType.bonuses.String(),
-				avgUpdateTimeSinceLastReport.String(),
-				e.stats.UpdateCountSinceLastReport,
-				avgUpdateTimeByTypeSinceLastReport.players.String(),
-				len(e.state.players),
-				avgUpdateTimeByTypeSinceLastReport.enemies.String(),
-				checkedEnemies,
-				avgUpdateTimeByTypeSinceLastReport.bullets.String(),
-				len(e.state.bullets),
-				avgUpdateTimeByTypeSinceLastReport.bonuses.String(),
-				len(e.state.bonuses),
-				avgDeltaCalcTime.String(),
-				avgUpdatePrevStateTime.String(),
-				avgDeltaCalcTimeSinceLastReport.String(),
-				avgUpdatePrevStateTimeSinceLastReport.String(),
-				e.stats.DeltaCalcCountSinceLastReport,
-			)
 
 			e.stats.LastReportedAt = time.Now()
 			e.stats.UpdateCountSinceLastReport = 0
@@ -1327,44 +2245,208 @@ func (e *Engine) Update() {
 	}
 }
 
+// logStats prints the average update and delta calculation times, overall and since the last report
+func (e *Engine) logStats() {
+	avgUpdateTimeByType := e.stats.TotalUpdateTime.Average(e.stats.UpdateCount)
+	avgUpdateTimeByTypeSinceLastReport := e.stats.TotalUpdateTimeSinceLastReport.Average(e.stats.UpdateCountSinceLastReport)
+	avgDeltaCalcTime := e.stats.TotalDeltaCalcTime.Average(e.stats.DeltaCalcCount)
+	avgDeltaCalcTimeSinceLastReport := e.stats.TotalDeltaCalcTimeSinceLastReport.Average(e.stats.DeltaCalcCountSinceLastReport)
+
+	log.Printf(
+		"Engine Stats - Session %s:\n"+
+			"Total Updates: %d\n"+
+			"Avg Update Time: %s\n"+
+			"Players: %s, Enemies: %s, Bullets: %s, Bonuses: %s\n"+
+			"Avg Update Time (last period): %s (%d rounds)\n"+
+			"Players: %s (%d elements), Enemies: %s (%d checked), Bullets: %s (%d elements), Bonuses: %s (%d elements)\n"+
+			"Avg Delta Calc Time: %s (of which %s for updating previous state)\n"+
+			"Avg Delta Calc Time (last period): %s (of which %s for updating previous state, %d rounds)\n\n\n",
+		e.sessionID,
+		e.stats.UpdateCount,
+		avgUpdateTimeByType.Total().String(),
+		avgUpdateTimeByType.Players.String(),
+		avgUpdateTimeByType.Enemies.String(),
+		avgUpdateTimeByType.Bullets.String(),
+		avgUpdateTimeByType.Bonuses.String(),
+		avgUpdateTimeByTypeSinceLastReport.Total().String(),
+		e.stats.UpdateCountSinceLastReport,
+		avgUpdateTimeByTypeSinceLastReport.Players.String(),
+		len(e.state.players),
+		avgUpdateTimeByTypeSinceLastReport.Enemies.String(),
+		e.stats.CheckedEnemies,
+		avgUpdateTimeByTypeSinceLastReport.Bullets.String(),
+		len(e.state.bullets),
+		avgUpdateTimeByTypeSinceLastReport.Bonuses.String(),
+		len(e.state.bonuses),
+		avgDeltaCalcTime.Total().String(),
+		avgDeltaCalcTime.UpdatePrevious.String(),
+		avgDeltaCalcTimeSinceLastReport.Total().String(),
+		avgDeltaCalcTimeSinceLastReport.UpdatePrevious.String(),
+		e.stats.DeltaCalcCountSinceLastReport,
+	)
+}
+
+// logSlowTick reports a single tick that exceeded the slow tick threshold
+func (e *Engine) logSlowTick(tickDuration time.Duration) {
+	connectedPlayers := 0
+	for _, player := range e.state.players {
+		if player.IsConnected {
+			connectedPlayers++
+		}
+	}
+
+	enemies := 0
+	for _, chunkEnemies := range e.state.enemiesByChunk {
+		enemies += len(chunkEnemies)
+	}
+
+	log.Printf("Slow tick in session %s: %s (threshold %s) - players: %d (%d connected), enemies: %d, bullets: %d, bonuses: %d, chunks: %d",
+		e.sessionID, tickDuration, e.slowTickThreshold,
+		len(e.state.players), connectedPlayers, enemies, len(e.state.bullets), len(e.state.bonuses), len(e.chunkHash))
+}
+
+// bulletHit is a player or enemy found on a bullet's path, with its distance from
+// where the bullet started the segment.
+type bulletHit struct {
+	player   *types.Player
+	enemy    *types.Enemy
+	chunkKey string
+	distance float64
+}
+
 func (e *Engine) applyBulletDamage(bullet *types.Bullet, newPosition *types.Vector2) (hitFound bool, hitObjectIDs map[string]bool) {
 	hitObjectIDs = make(map[string]bool)
-	hitFound = false
-	// Check collision with players
-	for _, player := range e.state.players {
+
+	// A railgun beam ends at the first wall on its path, nothing behind it is hit
+	if bullet.WeaponType == types.WeaponTypeRailgun {
+		if end := e.cutBeamAtWalls(bullet.Position, newPosition); *end != *newPosition {
+			newPosition = end
+			if bullet.Velocity != nil {
+				bullet.Velocity = &types.Vector2{X: end.X - bullet.Position.X, Y: end.Y - bullet.Position.Y}
+			}
+		}
+	}
+
+	hits := e.findBulletHits(bullet, newPosition)
+
+	// A railgun with limited penetration only damages the nearest targets and
+	// its beam ends at the last one
+	if bullet.WeaponType == types.WeaponTypeRailgun && e.railgunPenetration > 0 && len(hits) > e.railgunPenetration {
+		sort.Slice(hits, func(i, j int) bool { return hits[i].distance < hits[j].distance })
+		hits = hits[:e.railgunPenetration]
+
+		length := math.Hypot(newPosition.X-bullet.Position.X, newPosition.Y-bullet.Position.Y)
+		if bullet.Velocity != nil && length > 0 {
+			scale := hits[len(hits)-1].distance / length
+			bullet.Velocity = &types.Vector2{X: (newPosition.X - bullet.Position.X) * scale, Y: (newPosition.Y - bullet.Position.Y) * scale}
+		}
+	}
+
+	for _, hit := range hits {
+		if hit.player != nil {
+			e.applyBulletHitToPlayer(bullet, hit.player)
+			hitObjectIDs[hit.player.ID] = true
+		} else {
+			e.applyBulletHitToEnemy(bullet, hit.enemy, hit.chunkKey)
+			hitObjectIDs[hit.enemy.ID] = true
+		}
+	}
+
+	return len(hits) > 0, hitObjectIDs
+}
+
+// cutBeamAtWalls shortens the beam from start to end so it stops at the first wall in the way.
+// Walls are looked up in every chunk along the beam, however long it is.
+func (e *Engine) cutBeamAtWalls(start, end *types.Vector2) *types.Vector2 {
+	startChunkX, startChunkY := utils.ChunkXYFromPosition(start.X, start.Y)
+	endChunkX, endChunkY := utils.ChunkXYFromPosition(end.X, end.Y)
+
+	ix, iy := end.X, end.Y
+	// Walls may stick out of their chunk, so the chunks around the beam are checked too
+	for chunkX := min(startChunkX, endChunkX) - 1; chunkX <= max(startChunkX, endChunkX)+1; chunkX++ {
+		for chunkY := min(startChunkY, endChunkY) - 1; chunkY <= max(startChunkY, endChunkY)+1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			if !e.chunkHash[chunkKey] {
+				continue
+			}
+
+			for _, wall := range e.state.wallsByChunk[chunkKey] {
+				wallTopLeft := wall.GetTopLeft()
+
+				ix, iy = utils.CutLineSegmentBeforeRectWithTolerance(
+					start.X,
+					start.Y,
+					ix,
+					iy,
+					wallTopLeft.X,
+					wallTopLeft.Y,
+					wall.Width,
+					wall.Height,
+					e.bulletWallTolerance,
+				)
+			}
+		}
+	}
+
+	return &types.Vector2{X: ix, Y: iy}
+}
+
+// findBulletHits lists the players and enemies a bullet moving to newPosition
+// passes through
+func (e *Engine) findBulletHits(bullet *types.Bullet, newPosition *types.Vector2) []bulletHit {
+	var hits []bulletHit
+	checkPlayer := func(player *types.Player) {
 		if !player.IsConnected || !player.IsAlive || player.ID == bullet.OwnerID || player.InvulnerableTimer > 0 {
-			continue
+			return
+		}
+		if !bullet.IsEnemy && !e.friendlyFire {
+			return
 		}
 
 		closestPointX, closestPointY := utils.ClosestPointOnLineSegment(bullet.Position.X, bullet.Position.Y, newPosition.X, newPosition.Y, player.Position.X, player.Position.Y)
 		distance := player.DistanceToPoint(&types.Vector2{X: closestPointX, Y: closestPointY})
 
 		if distance < config.PlayerRadius+config.BlasterBulletRadius {
-			// Hit!
-			player.Lives -= bullet.Damage
-			if player.Lives <= 0 {
-				chest := player.DropInventory()
-				if chest != nil {
-					e.state.bonuses[chest.ID] = chest
-				}
-				player.Die()
+			hits = append(hits, bulletHit{
+				player:   player,
+				distance: math.Hypot(closestPointX-bullet.Position.X, closestPointY-bullet.Position.Y),
+			})
+		}
+	}
+	checkEnemy := func(enemy *types.Enemy, chunkKey string) {
+		if !enemy.IsAlive || (bullet.IsEnemy && enemy.ID == bullet.OwnerID) {
+			return
+		}
 
-				// Award money to shooter
-				if shooter, exists := e.state.players[bullet.OwnerID]; exists {
-					shooter.Money += config.PlayerReward
-					shooter.Score += config.PlayerReward
-					shooter.Kills++
-				}
-			} else {
-				player.InvulnerableTimer = config.PlayerInvulnerabilityTime
-			}
+		closestPointX, closestPointY := utils.ClosestPointOnLineSegment(bullet.Position.X, bullet.Position.Y, newPosition.X, newPosition.Y, enemy.Position.X, enemy.Position.Y)
+		distance := enemy.DistanceToPoint(&types.Vector2{X: closestPointX, Y: closestPointY})
 
-			hitObjectIDs[player.ID] = true
-			hitFound = true
+		if distance < enemy.Size()/2+config.BlasterBulletRadius {
+			hits = append(hits, bulletHit{
+				enemy:    enemy,
+				chunkKey: chunkKey,
+				distance: math.Hypot(closestPointX-bullet.Position.X, closestPointY-bullet.Position.Y),
+			})
 		}
 	}
 
 	bulletChunkX, bulletChunkY := utils.ChunkXYFromPosition(newPosition.X, newPosition.Y)
+
+	// While bullets move only the players and enemies in the cells along the path are tested,
+	// shots fired outside of it test everything around
+	if e.hitGrid != nil {
+		e.hitGrid.forCandidates(bullet.Position, newPosition, checkPlayer, func(entry gridEnemy) {
+			if abs(entry.chunk[0]-bulletChunkX) <= 1 && abs(entry.chunk[1]-bulletChunkY) <= 1 {
+				checkEnemy(entry.enemy, entry.chunkKey)
+			}
+		})
+		return hits
+	}
+
+	for _, player := range e.state.players {
+		checkPlayer(player)
+	}
+
 	for neighborChunkX := bulletChunkX - 1; neighborChunkX <= bulletChunkX+1; neighborChunkX++ {
 		for neighborChunkY := bulletChunkY - 1; neighborChunkY <= bulletChunkY+1; neighborChunkY++ {
 			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
@@ -1372,47 +2454,87 @@ func (e *Engine) applyBulletDamage(bullet *types.Bullet, newPosition *types.Vect
 				continue
 			}
 
-			// Check collision with enemies
 			for _, enemy := range e.state.enemiesByChunk[neighborChunkKey] {
-				if !enemy.IsAlive || (bullet.IsEnemy && enemy.ID == bullet.OwnerID) {
-					continue
-				}
-
-				closestPointX, closestPointY := utils.ClosestPointOnLineSegment(bullet.Position.X, bullet.Position.Y, newPosition.X, newPosition.Y, enemy.Position.X, enemy.Position.Y)
-				distance := enemy.DistanceToPoint(&types.Vector2{X: closestPointX, Y: closestPointY})
-
-				if distance < enemy.Size()/2+config.BlasterBulletRadius {
-					// Hit!
-					enemy.Lives -= bullet.Damage
-					if enemy.Lives <= 0 {
-						enemy.IsAlive = false
-						enemy.DeadTimer = config.EnemyDeathTraceTime
-						if enemy.Type == types.EnemyTypeTower {
-							enemy.DeadTimer = config.EnemyTowerDeathTraceTime
-						}
-						// Award money to shooter
-						if !bullet.IsEnemy {
-							if shooter, exists := e.state.players[bullet.OwnerID]; exists {
-								reward := enemy.Reward()
-								shooter.Money += int(reward)
-								shooter.Score += int(reward)
-								shooter.Kills++
-							}
-						}
-
-						e.spawnBonus(enemy)
-					}
-					hitFound = true
-					hitObjectIDs[enemy.ID] = true
-				}
+				checkEnemy(enemy, neighborChunkKey)
 			}
 		}
 	}
 
-	return hitFound, hitObjectIDs
+	return hits
+}
+
+func (e *Engine) applyBulletHitToPlayer(bullet *types.Bullet, player *types.Player) {
+	player.Lives -= bullet.Damage
+	player.DamageContributors = e.recordDamage(player.DamageContributors, bullet.OwnerID, player.ID)
+	if player.Lives <= 0 {
+		e.finishPlayer(player, bullet.OwnerID)
+	} else {
+		player.InvulnerableTimer = config.PlayerInvulnerabilityTime
+		e.poisonOnHit(&player.Poison, bullet)
+	}
+}
+
+// finishPlayer handles a player's death: their inventory is dropped and the killer and assisting players are rewarded.
+// Players who kill themselves get nothing for it.
+func (e *Engine) finishPlayer(player *types.Player, killerID string) {
+	chest := player.DropInventory()
+	if chest != nil {
+		e.state.bonuses[chest.ID] = chest
+	}
+	e.captureKillCam(player, killerID)
+	e.killPlayer(player)
+
+	// Award money to shooter
+	if shooter, exists := e.state.players[killerID]; exists && shooter.ID != player.ID {
+		e.rewardPlayer(shooter, config.PlayerReward)
+		shooter.Kills++
+	}
+	e.awardAssists(player.DamageContributors, killerID, config.PlayerReward)
+	player.DamageContributors = nil
+}
+
+func (e *Engine) applyBulletHitToEnemy(bullet *types.Bullet, enemy *types.Enemy, chunkKey string) {
+	if !enemy.IsVulnerableTo(bullet.WeaponType) {
+		return
+	}
+
+	enemy.Lives -= bullet.Damage
+	if !bullet.IsEnemy {
+		enemy.DamageContributors = e.recordDamage(enemy.DamageContributors, bullet.OwnerID, enemy.ID)
+	}
+	if enemy.Lives <= 0 {
+		e.finishEnemy(enemy, chunkKey, bullet.OwnerID)
+	} else {
+		e.poisonOnHit(&enemy.Poison, bullet)
+	}
+}
+
+// finishEnemy handles an enemy's death: the killing and assisting players are rewarded and the enemy drops its loot.
+// Enemies killed by other enemies reward nobody but their assistants.
+func (e *Engine) finishEnemy(enemy *types.Enemy, chunkKey string, killerID string) {
+	e.killEnemy(enemy, chunkKey)
+	// Award money to shooter
+	if shooter, exists := e.state.players[killerID]; exists {
+		reward := enemy.Reward()
+		e.rewardPlayer(shooter, int(reward))
+		shooter.Kills++
+	}
+	e.awardAssists(enemy.DamageContributors, killerID, int(enemy.Reward()))
+	enemy.DamageContributors = nil
+
+	if enemy.Type == types.EnemyTypeFlasher {
+		e.emitFlash(enemy.Position)
+	}
+
+	e.spawnBonus(enemy)
 }
 
 func (e *Engine) handlePlayerShooting(player *types.Player) {
+	if player.SelectedGunType == types.WeaponTypeKnife {
+		e.handlePlayerMelee(player)
+		return
+	}
+
 	rotationRad := player.Rotation * math.Pi / 180.0
 	bulletsLeft := player.BulletsLeftByWeaponType[player.SelectedGunType]
 	usingBulletsFromInventory := false
@@ -1421,10 +2543,17 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 		bulletsLeft = player.GetInventoryItemQuantity(types.InventoryAmmoIDByWeaponType[player.SelectedGunType])
 		usingBulletsFromInventory = true
 	}
-	shootDelay := types.ShootDelayByWeaponType[player.SelectedGunType]
+	shootDelay := types.ShootDelayByWeaponType[player.SelectedGunType] * player.ShootDelayMultiplier(e.powerUps)
 
-	if bulletsLeft > 0 && time.Since(player.LastShotAt).Seconds() >= shootDelay {
+	if bulletsLeft > 0 && player.ReloadTimer <= 0 && time.Since(player.LastShotAt).Seconds() >= shootDelay {
 		player.LastShotAt = time.Now()
+		// A jammed gun keeps its round and needs a moment before it fires again. Jams are rolled
+		// on the global source, the seeded one is left to the world.
+		if chance := e.gunJamChance[player.SelectedGunType]; chance > 0 && rand.Float64() < chance {
+			player.ReloadTimer = config.GunJamRecoveryTime
+			e.jammedByPlayer[player.ID] = player.SelectedGunType
+			return
+		}
 		if usingBulletsFromInventory {
 			player.UseInventoryItem(types.InventoryAmmoIDByWeaponType[player.SelectedGunType], 1)
 		} else {
@@ -1470,7 +2599,7 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 						for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
 							wallTopLeft := wall.GetTopLeft()
 
-							ix, iy = utils.CutLineSegmentBeforeRect(
+							ix, iy = utils.CutLineSegmentBeforeRectWithTolerance(
 								playerGunPoint.X,
 								playerGunPoint.Y,
 								ix,
@@ -1479,6 +2608,7 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 								wallTopLeft.Y,
 								wall.Width,
 								wall.Height,
+								e.bulletWallTolerance,
 							)
 						}
 					}
@@ -1490,32 +2620,9 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 				})
 			}
 		case types.WeaponTypeRailgun:
-			ix := playerGunPoint.X + -math.Sin(rotationRad)*config.SightRadius
-			iy := playerGunPoint.Y + math.Cos(rotationRad)*config.SightRadius
-
-			for neighborChunkX := playerChunkX - 1; neighborChunkX <= playerChunkX+1; neighborChunkX++ {
-				for neighborChunkY := playerChunkY - 1; neighborChunkY <= playerChunkY+1; neighborChunkY++ {
-					neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
-					if !e.chunkHash[neighborChunkKey] {
-						continue
-					}
-
-					for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
-						wallTopLeft := wall.GetTopLeft()
-
-						ix, iy = utils.CutLineSegmentBeforeRect(
-							playerGunPoint.X,
-							playerGunPoint.Y,
-							ix,
-							iy,
-							wallTopLeft.X,
-							wallTopLeft.Y,
-							wall.Width,
-							wall.Height,
-						)
-					}
-				}
-			}
+			// The beam is cut at the first wall when its damage is applied
+			ix := playerGunPoint.X + -math.Sin(rotationRad)*e.railgunRange
+			iy := playerGunPoint.Y + math.Cos(rotationRad)*e.railgunRange
 
 			velocities = append(velocities, &types.Vector2{
 				X: ix - playerGunPoint.X,
@@ -1529,7 +2636,7 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 			deletedAt = time.Now()
 		}
 
-		damage := types.DamageByWeaponType[player.SelectedGunType] / float32(len(velocities))
+		damage := types.DamageByWeaponType[player.SelectedGunType] * player.DamageMultiplier(e.powerUps) / float32(len(velocities))
 
 		for _, velocity := range velocities {
 			// Create bullet
@@ -1551,18 +2658,66 @@ func (e *Engine) handlePlayerShooting(player *types.Player) {
 				e.applyBulletDamage(bullet, &types.Vector2{X: bullet.Position.X + velocity.X, Y: bullet.Position.Y + velocity.Y})
 			}
 
-			e.state.bullets[bullet.ID] = bullet
+			e.addBullet(bullet)
 		}
 	}
 
 }
 
-func (e *Engine) applyRocketExplosionDamage(explosionCenter *types.Vector2, hitObjectIDs map[string]bool, ownerID string) {
-	shooter, shooterExists := e.state.players[ownerID]
+// interceptEnemyBullets cancels player bullets and enemy bullets that came within
+// intercept range of each other during the tick. Their closest approach is
+// found from the relative movement, so fast bullets can't pass through each other.
+func (e *Engine) interceptEnemyBullets(starts map[string]types.Vector2) {
+	for _, playerBullet := range e.state.bullets {
+		if playerBullet.IsEnemy || !playerBullet.IsActive {
+			continue
+		}
+		playerStart, exists := starts[playerBullet.ID]
+		if !exists {
+			continue
+		}
+
+		for _, enemyBullet := range e.state.bullets {
+			if !enemyBullet.IsEnemy || !enemyBullet.IsActive {
+				continue
+			}
+			enemyStart, exists := starts[enemyBullet.ID]
+			if !exists {
+				continue
+			}
+
+			// Offset between the bullets at the start and at the end of the tick
+			startX, startY := playerStart.X-enemyStart.X, playerStart.Y-enemyStart.Y
+			endX, endY := playerBullet.Position.X-enemyBullet.Position.X, playerBullet.Position.Y-enemyBullet.Position.Y
+			closestX, closestY := utils.ClosestPointOnLineSegment(startX, startY, endX, endY, 0, 0)
+			if math.Hypot(closestX, closestY) > config.BulletInterceptRange {
+				continue
+			}
+
+			now := time.Now()
+			playerBullet.IsActive = false
+			playerBullet.DeletedAt = now
+			enemyBullet.IsActive = false
+			enemyBullet.DeletedAt = now
+			break
+		}
+	}
+}
+
+// addBullet puts a newly fired bullet into the world. With trails enabled its
+// spawn position is kept as the origin, for instant weapons the trail then runs
+// from the origin to the impact point at position + velocity.
+func (e *Engine) addBullet(bullet *types.Bullet) {
+	if e.bulletTrails {
+		bullet.Origin = &types.Vector2{X: bullet.Position.X, Y: bullet.Position.Y}
+	}
+	e.state.bullets[bullet.ID] = bullet
+}
 
-	for _, enemies := range e.state.enemiesByChunk {
+func (e *Engine) applyRocketExplosionDamage(explosionCenter *types.Vector2, hitObjectIDs map[string]bool, ownerID string) {
+	for chunkKey, enemies := range e.state.enemiesByChunk {
 		for _, enemy := range enemies {
-			if !enemy.IsAlive || hitObjectIDs[enemy.ID] {
+			if !enemy.IsAlive || hitObjectIDs[enemy.ID] || !enemy.IsVulnerableTo(types.WeaponTypeRocketLauncher) {
 				continue
 			}
 
@@ -1571,22 +2726,9 @@ func (e *Engine) applyRocketExplosionDamage(explosionCenter *types.Vector2, hitO
 				// Apply damage falloff
 				damage := config.RocketLauncherDamage * (1 - distance/config.RocketLauncherDamageRadius)
 				enemy.Lives -= float32(damage)
+				enemy.DamageContributors = e.recordDamage(enemy.DamageContributors, ownerID, enemy.ID)
 				if enemy.Lives <= 0 {
-					enemy.IsAlive = false
-					enemy.DeadTimer = config.EnemyDeathTraceTime
-					if enemy.Type == types.EnemyTypeTower {
-						enemy.DeadTimer = config.EnemyTowerDeathTraceTime
-					}
-
-					if shooterExists {
-						reward := enemy.Reward()
-						shooter.Money += int(reward)
-						shooter.Score += int(reward)
-						shooter.Kills++
-					}
-
-					// Maybe spawn bonus
-					e.spawnBonus(enemy)
+					e.finishEnemy(enemy, chunkKey, ownerID)
 				}
 			}
 		}
@@ -1596,24 +2738,19 @@ func (e *Engine) applyRocketExplosionDamage(explosionCenter *types.Vector2, hitO
 		if !player.IsConnected || !player.IsAlive || hitObjectIDs[player.ID] {
 			continue
 		}
+		// Only players fire rockets, so without friendly fire they only hurt their owner
+		if !e.friendlyFire && player.ID != ownerID {
+			continue
+		}
 
 		distance := player.DistanceToPoint(explosionCenter)
 		if distance < config.RocketLauncherDamageRadius {
 			// Apply damage falloff
 			damage := config.RocketLauncherDamage * (1 - distance/config.RocketLauncherDamageRadius)
 			player.Lives -= float32(damage)
+			player.DamageContributors = e.recordDamage(player.DamageContributors, ownerID, player.ID)
 			if player.Lives <= 0 {
-				chest := player.DropInventory()
-				if chest != nil {
-					e.state.bonuses[chest.ID] = chest
-				}
-				player.Die()
-
-				if shooterExists && shooter.ID != player.ID {
-					shooter.Money += config.PlayerReward
-					shooter.Score += config.PlayerReward
-					shooter.Kills++
-				}
+				e.finishPlayer(player, ownerID)
 			} else {
 				player.InvulnerableTimer = config.PlayerInvulnerabilityTime
 			}
@@ -1621,10 +2758,114 @@ func (e *Engine) applyRocketExplosionDamage(explosionCenter *types.Vector2, hitO
 	}
 }
 
+// recordDamage remembers the attacking player as a damage contributor of the target,
+// returning the contributors map to store back on the target
+func (e *Engine) recordDamage(contributors types.DamageContributors, attackerID, targetID string) types.DamageContributors {
+	if !e.assistsEnabled || attackerID == targetID {
+		return contributors
+	}
+
+	if _, exists := e.state.players[attackerID]; !exists {
+		return contributors
+	}
+
+	if contributors == nil {
+		contributors = types.DamageContributors{}
+	}
+	contributors.Record(attackerID, time.Now())
+	return contributors
+}
+
+// killEnemy marks the enemy as dead. Its corpse stays around for a while, unless instant
+// removal is enabled, in which case it leaves the chunk right away.
+func (e *Engine) killEnemy(enemy *types.Enemy, chunkKey string) {
+	enemy.IsAlive = false
+	enemy.DeadTimer = config.EnemyDeathTraceTime
+	if enemy.Type == types.EnemyTypeTower {
+		enemy.DeadTimer = config.EnemyTowerDeathTraceTime
+	}
+
+	if e.instantEnemyRemoval {
+		delete(e.state.enemiesByChunk[chunkKey], enemy.ID)
+	}
+	delete(e.enemyAggro, enemy.ID)
+	e.releaseMinion(enemy, chunkKey)
+}
+
+// rewardPlayer adds the reward to the player's money and score, keeping both within the configured caps
+func (e *Engine) rewardPlayer(player *types.Player, reward int) {
+	player.Money += reward
+	player.Score += reward
+	e.clampPlayerFunds(player)
+}
+
+// clampPlayerFunds keeps the player's money and score between 0 and the configured caps
+func (e *Engine) clampPlayerFunds(player *types.Player) {
+	player.Money = max(0, min(player.Money, e.maxMoney))
+	player.Score = max(0, min(player.Score, e.maxScore))
+}
+
+// awardAssists pays a share of the kill reward to recent damage contributors other than the killer
+func (e *Engine) awardAssists(contributors types.DamageContributors, killerID string, reward int) {
+	if !e.assistsEnabled || len(contributors) == 0 {
+		return
+	}
+
+	assistReward := int(float64(reward) * e.assistRewardFraction)
+	for _, playerID := range contributors.Recent(e.assistWindow, time.Now()) {
+		if playerID == killerID {
+			continue
+		}
+
+		if player, exists := e.state.players[playerID]; exists {
+			e.rewardPlayer(player, assistReward)
+		}
+	}
+}
+
+// emitFlash blinds the players caught in a flasher's death flash
+func (e *Engine) emitFlash(position *types.Vector2) {
+	for _, player := range e.state.players {
+		if !player.IsConnected || !player.IsAlive {
+			continue
+		}
+
+		if player.DistanceToPoint(position) < e.flasherFlashRadius {
+			player.Blind(e.flasherBlindTime)
+		}
+	}
+}
+
 // spawnBonus creates a bonus at the given position
 func (e *Engine) spawnBonus(enemy *types.Enemy) {
+	if enemy.Type == types.EnemyTypeKeyholder {
+		e.spawnKey(enemy.Position)
+		return
+	}
+
+	if enemy.Type == types.EnemyTypeGatekeeper {
+		e.openPortal(enemy.Position)
+		return
+	}
+
+	// Thieves only drop what they stole
+	if enemy.Type == types.EnemyTypeThief {
+		e.dropStolenMoney(enemy)
+		return
+	}
+
+	if enemy.Type == types.EnemyTypeLieutenant && rand.Float64() < e.powerUpDropChance {
+		e.spawnPowerUp(enemy.Position)
+		return
+	}
+
+	// Minions come in endlessly, so they drop nothing
+	if enemy.Type == types.EnemyTypeMinion {
+		return
+	}
+
 	// Maybe spawn bonus
-	if (enemy.Type == types.EnemyTypeSoldier || enemy.Type == types.EnemyTypeLieutenant) &&
+	if (enemy.Type == types.EnemyTypeSoldier || enemy.Type == types.EnemyTypeLieutenant || enemy.Type == types.EnemyTypeFlasher || enemy.Type == types.EnemyTypeSummoner) &&
 		rand.Float64() >= config.EnemySoldierDropChance {
 		return
 	}
@@ -1686,6 +2927,28 @@ func (e *Engine) spawnBonus(enemy *types.Enemy) {
 	e.state.bonuses[bonus.ID] = bonus
 }
 
+// spawnPowerUp drops a random timed power-up at the given position
+func (e *Engine) spawnPowerUp(position *types.Vector2) {
+	bonusType := types.BonusTypeSpeedBoost
+	roll := rand.Float64()
+	if roll < e.powerUpDamageChance {
+		bonusType = types.BonusTypeDoubleDamage
+	} else if roll < e.powerUpDamageChance+e.powerUpRapidFireChance {
+		bonusType = types.BonusTypeRapidFire
+	}
+
+	bonus := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: position.X, Y: position.Y},
+		},
+		Type:      bonusType,
+		Inventory: []types.InventoryItem{},
+	}
+
+	e.state.bonuses[bonus.ID] = bonus
+}
+
 func (e *Engine) GetAllPlayers() []*types.Player {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
@@ -1699,6 +2962,71 @@ func (e *Engine) GetAllPlayers() []*types.Player {
 	return playersCopy
 }
 
+// GetShops returns copies of all shops in the session
+func (e *Engine) GetShops() []*types.Shop {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	shops := make([]*types.Shop, 0)
+	for _, chunkShops := range e.state.shopsByChunk {
+		for _, shop := range chunkShops {
+			shops = append(shops, shop.Clone())
+		}
+	}
+
+	return shops
+}
+
+// shouldSkipBulletUpdate tells whether a distant bullet's position update can wait for a later tick.
+// Bullets flying towards the player are never skipped.
+func (e *Engine) shouldSkipBulletUpdate(bullet *types.Bullet, player *types.Player) bool {
+	if !e.bulletLOD || e.tickCount%e.bulletLODInterval == 0 {
+		return false
+	}
+
+	toPlayerX := player.Position.X - bullet.Position.X
+	toPlayerY := player.Position.Y - bullet.Position.Y
+	if toPlayerX*bullet.Velocity.X+toPlayerY*bullet.Velocity.Y > 0 {
+		return false
+	}
+
+	return math.Hypot(toPlayerX, toPlayerY) > e.bulletLODDistance
+}
+
+// otherPlayerPosition returns the position the receiver is told about. With
+// teammate positions enabled, teammates that can't be detected are still shown,
+// rounded to a coarse grid so their exact whereabouts stay hidden. Without
+// teams everyone counts as a teammate.
+func (e *Engine) otherPlayerPosition(player, receiver *types.Player) (*types.Vector2, bool) {
+	if player == nil {
+		return nil, false
+	}
+	if player.IsPositionDetectable() {
+		return player.Position, true
+	}
+	if !e.teammatePositions || !player.IsConnected || !player.IsAlive {
+		return nil, false
+	}
+	if !player.IsTeammateOf(receiver) && (player.Team != "" || receiver.Team != "") {
+		return nil, false
+	}
+
+	return &types.Vector2{
+		X: math.Round(player.Position.X/config.TeammatePositionGrid) * config.TeammatePositionGrid,
+		Y: math.Round(player.Position.Y/config.TeammatePositionGrid) * config.TeammatePositionGrid,
+	}, true
+}
+
+// isTeammateChest reports whether bonus is a chest dropped by one of player's
+// teammates, which is shown regardless of fog when team dropped chests are enabled.
+func (e *Engine) isTeammateChest(bonus *types.Bonus, player *types.Player) bool {
+	if !e.teamDroppedChests || bonus.DroppedBy == "" {
+		return false
+	}
+	dropper, exists := e.state.players[bonus.DroppedBy]
+	return exists && dropper.IsTeammateOf(player)
+}
+
 // GetGameStateDeltaForPlayer computes the delta filtered to player's surrounding chunks (-1 to 1)
 func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameStateDeltaMessage {
 	e.mu.RLock()
@@ -1771,10 +3099,9 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 			}
 		}
 
-		if playerFromState.ID != playerID && playerFromState.IsPositionDetectable() && (!prevExists || prev.Position.X != playerFromState.Position.X || prev.Position.Y != playerFromState.Position.Y) {
-			delta.UpdatedOtherPlayerPositions[id] = &protocol.Vector2{
-				X: playerFromState.Position.X,
-				Y: playerFromState.Position.Y,
+		if position, ok := e.otherPlayerPosition(playerFromState, player); playerFromState.ID != playerID && ok {
+			if prevPosition, prevOk := e.otherPlayerPosition(prev, player); !prevExists || !prevOk || *prevPosition != *position {
+				delta.UpdatedOtherPlayerPositions[id] = protocol.ToProtoVector2(position)
 			}
 		}
 	}
@@ -1806,7 +3133,7 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 			}
 		}
 
-		if id != playerID && (!currentExists || !current.IsPositionDetectable()) {
+		if _, ok := e.otherPlayerPosition(current, player); id != playerID && (!currentExists || !ok) {
 			delta.RemovedOtherPlayerPositions = append(delta.RemovedOtherPlayerPositions, id)
 		}
 	}
@@ -1831,7 +3158,9 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 
 			bulletUpdate := protocol.ToProtoBulletUpdate(prev, bullet)
 			if bulletUpdate != nil {
-				delta.UpdatedBullets[id] = bulletUpdate
+				if !e.shouldSkipBulletUpdate(bullet, player) {
+					delta.UpdatedBullets[id] = bulletUpdate
+				}
 				continue
 			}
 		}
@@ -1866,9 +3195,13 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 					enemyIDsInUpdatedState = append(enemyIDsInUpdatedState, id)
 					if !prevExists {
 						delta.AddedEnemies[id] = protocol.ToProtoEnemy(enemy)
+						delta.AddedEnemies[id].DeathTraceTime = e.enemyDeathTraceTime(enemy)
 					} else {
 						enemyUpdate := protocol.ToProtoEnemyUpdate(prev, enemy)
 						if enemyUpdate != nil {
+							if enemyUpdate.Lives != nil {
+								enemyUpdate.DeathTraceTime = e.enemyDeathTraceTime(enemy)
+							}
 							delta.UpdatedEnemies[id] = enemyUpdate
 						}
 					}
@@ -1974,7 +3307,7 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 
 	// Check for added bonuses in visible chunks
 	for id, bonus := range e.state.bonuses {
-		currentVisible := false
+		currentVisible := e.isTeammateChest(bonus, player)
 		for _, playerAbleToSee := range playersAbleToSee {
 			if bonus.IsVisibleToPlayer(playerAbleToSee) {
 				currentVisible = true
@@ -2001,28 +3334,63 @@ func (e *Engine) GetGameStateDeltaForPlayer(playerID string) *protocol.GameState
 		delta.RemovedBonuses = append(delta.RemovedBonuses, id)
 	}
 
-	if e.debugMode {
-		e.stats.TotalDeltaCalcTimeSinceLastReport.delta += time.Since(now)
-		e.stats.TotalDeltaCalcTime.delta += time.Since(now)
-		now = time.Now()
+	delta.ShopEvents = e.shopEventsByPlayer[playerID]
+	delete(e.shopEventsByPlayer, playerID)
+
+	delta.KillCam = e.killCamsByPlayer[playerID]
+	delete(e.killCamsByPlayer, playerID)
+
+	delta.JammedWeapon = e.jammedByPlayer[playerID]
+	delete(e.jammedByPlayer, playerID)
+
+	delta.JoinedPlayers = e.joinedPlayersByPlayer[playerID]
+	delta.LeftPlayers = e.leftPlayersByPlayer[playerID]
+	delete(e.joinedPlayersByPlayer, playerID)
+	delete(e.leftPlayersByPlayer, playerID)
+
+	// Tell the player about the zone they're in whenever it changes
+	if zone := e.zoneByChunk[fmt.Sprintf("%d,%d", playerChunkX, playerChunkY)]; zone != e.zoneSent[playerID] {
+		delta.Zone = zone
+		if zone == "" {
+			delta.Zone = config.NoZone
+		}
+		e.zoneSent[playerID] = zone
 	}
 
+	deltaDuration := time.Since(now)
+	now = time.Now()
+
 	e.updatePreviousState(playerID)
 
-	if e.debugMode {
+	// Deltas are calculated under the read lock, so the stats they add to have a lock of their own
+	if e.collectStats {
+		updatePreviousDuration := time.Since(now)
+		e.statsMu.Lock()
+		e.stats.TotalDeltaCalcTimeSinceLastReport.Delta += deltaDuration
+		e.stats.TotalDeltaCalcTime.Delta += deltaDuration
 		e.stats.DeltaCalcCountSinceLastReport++
-		e.stats.TotalDeltaCalcTimeSinceLastReport.updatePrevious += time.Since(now)
+		e.stats.TotalDeltaCalcTimeSinceLastReport.UpdatePrevious += updatePreviousDuration
 		e.stats.DeltaCalcCount++
-		e.stats.TotalDeltaCalcTime.updatePrevious += time.Since(now)
+		e.stats.TotalDeltaCalcTime.UpdatePrevious += updatePreviousDuration
+		e.statsMu.Unlock()
 	}
 	return delta
 }
 
+// enemyDeathTraceTime returns how long a dead enemy lingers before it's removed, 0 for living
+// enemies or when clients aren't told
+func (e *Engine) enemyDeathTraceTime(enemy *types.Enemy) float32 {
+	if !e.enemyDeathFade || enemy.IsAlive {
+		return 0
+	}
+	return float32(enemy.DeadTimer)
+}
+
 func (e *Engine) enemiesHaveWall(enemyIDs []string, wallID string) bool {
 	for _, enemyID := range enemyIDs {
 		for _, enemies := range e.state.enemiesByChunk {
 			enemy, exists := enemies[enemyID]
-			if exists && enemy.WallID == wallID {
+			if exists && (enemy.WallID == wallID || enemy.SecondWallID == wallID) {
 				return true
 			}
 		}
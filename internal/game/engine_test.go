@@ -0,0 +1,1870 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/protocol"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// newTestEngine creates an engine whose chunks around the origin are already
+// marked as generated, so ticks don't add random walls and enemies
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+
+	config.AppConfig = &config.Config{}
+	e := NewEngine("test-session")
+
+	for chunkX := -1; chunkX <= 1; chunkX++ {
+		for chunkY := -1; chunkY <= 1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			e.chunkHash[chunkKey] = true
+			e.state.wallsByChunk[chunkKey] = make(map[string]*types.Wall)
+			e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
+			e.state.shopsByChunk[chunkKey] = make(map[string]*types.Shop)
+		}
+	}
+
+	return e
+}
+
+// tick runs a single engine update pretending the given time has passed since the last one
+func tick(e *Engine, dt time.Duration) {
+	e.lastUpdate = time.Now().Add(-dt)
+	e.Update()
+}
+
+func TestGuardRoutePatrolsBetweenWalls(t *testing.T) {
+	e := newTestEngine(t)
+
+	firstWall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall-1", Position: &types.Vector2{X: 500, Y: 400}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	secondWall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall-2", Position: &types.Vector2{X: 900, Y: 400}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	e.state.wallsByChunk["0,0"][firstWall.ID] = firstWall
+	e.state.wallsByChunk["0,0"][secondWall.ID] = secondWall
+
+	enemy := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "guard", Position: &types.Vector2{X: 700, Y: 500}},
+		WallID:       firstWall.ID,
+		SecondWallID: secondWall.ID,
+		Direction:    1,
+		IsAlive:      true,
+		Type:         types.EnemyTypeSoldier,
+	}
+	e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+
+	// Close enough to wake the guard up, too far away to be detected
+	e.state.players["player"] = &types.Player{
+		ScreenObject: types.ScreenObject{ID: "player", Position: &types.Vector2{X: 700, Y: 1500}},
+		Lives:        config.PlayerLives,
+		IsAlive:      true,
+		IsConnected:  true,
+	}
+
+	firstAnchor := guardAnchor(firstWall, secondWall, enemy.Size())
+	secondAnchor := guardAnchor(secondWall, firstWall, enemy.Size())
+
+	reached := func(anchor *types.Vector2) bool {
+		for i := 0; i < 100; i++ {
+			tick(e, 100*time.Millisecond)
+			if math.Abs(enemy.Position.X-anchor.X) < 1e-9 && math.Abs(enemy.Position.Y-anchor.Y) < 1e-9 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !reached(secondAnchor) {
+		t.Fatalf("guard did not reach the second wall, ended at (%.1f, %.1f)", enemy.Position.X, enemy.Position.Y)
+	}
+	if enemy.Direction != -1 {
+		t.Errorf("expected guard to turn back at the second wall, direction is %v", enemy.Direction)
+	}
+
+	if !reached(firstAnchor) {
+		t.Fatalf("guard did not return to the first wall, ended at (%.1f, %.1f)", enemy.Position.X, enemy.Position.Y)
+	}
+	if enemy.Direction != 1 {
+		t.Errorf("expected guard to turn back at the first wall, direction is %v", enemy.Direction)
+	}
+}
+
+func TestGuardAnchorFacesOtherWall(t *testing.T) {
+	left := &types.Wall{
+		ScreenObject: types.ScreenObject{Position: &types.Vector2{X: 100, Y: 0}},
+		Width:        20,
+		Height:       100,
+		Orientation:  "vertical",
+	}
+	right := &types.Wall{
+		ScreenObject: types.ScreenObject{Position: &types.Vector2{X: 300, Y: 0}},
+		Width:        20,
+		Height:       100,
+		Orientation:  "vertical",
+	}
+
+	anchor := guardAnchor(left, right, 30)
+	if anchor.X <= 110 || anchor.Y != 50 {
+		t.Errorf("expected anchor right of the left wall, got (%.1f, %.1f)", anchor.X, anchor.Y)
+	}
+
+	anchor = guardAnchor(right, left, 30)
+	if anchor.X >= 290 || anchor.Y != 50 {
+		t.Errorf("expected anchor left of the right wall, got (%.1f, %.1f)", anchor.X, anchor.Y)
+	}
+}
+
+// addTestPlayer puts a connected, alive player into the engine at the given position
+func addTestPlayer(e *Engine, id string, x, y float64) *types.Player {
+	player := &types.Player{
+		ScreenObject: types.ScreenObject{ID: id, Position: &types.Vector2{X: x, Y: y}},
+		Lives:        config.PlayerLives,
+		Stamina:      config.PlayerMaxStamina,
+		IsAlive:      true,
+		IsConnected:  true,
+	}
+	e.state.players[id] = player
+	e.prevState[id] = &EngineGameState{}
+	return player
+}
+
+func TestLastProcessedInputIsEchoedToItsOwner(t *testing.T) {
+	e := newTestEngine(t)
+	e.clientPrediction = true
+	player := addTestPlayer(e, "player", 1000, 1000)
+	teammate := addTestPlayer(e, "teammate", 1100, 1000)
+	e.GetGameStateDeltaForPlayer(player.ID)
+	e.GetGameStateDeltaForPlayer(teammate.ID)
+
+	// An input that doesn't move the player still has to be acknowledged
+	e.UpdatePlayerInput(player.ID, types.InputPayload{Sequence: 7})
+	tick(e, 100*time.Millisecond)
+
+	update := e.GetGameStateDeltaForPlayer(player.ID).UpdatedPlayers[player.ID]
+	if update == nil || update.Position == nil || update.Position.LastProcessedInput != 7 {
+		t.Fatalf("expected the next delta to echo input 7 to its owner, got %+v", update)
+	}
+	if other := e.GetGameStateDeltaForPlayer(teammate.ID).UpdatedPlayers[player.ID]; other != nil && other.Position != nil {
+		t.Errorf("expected other players not to get the input sequence, got %+v", other.Position)
+	}
+}
+
+func TestSprintDrainsStaminaAndMovesFaster(t *testing.T) {
+	walker := newTestEngine(t)
+	walker.sprintEnabled = true
+	walkingPlayer := addTestPlayer(walker, "player", 1000, 1000)
+	walker.playerInputState["player"] = &types.InputPayload{Forward: true}
+
+	sprinter := newTestEngine(t)
+	sprinter.sprintEnabled = true
+	sprintingPlayer := addTestPlayer(sprinter, "player", 1000, 1000)
+	sprinter.playerInputState["player"] = &types.InputPayload{Forward: true, Sprint: true}
+
+	tick(walker, 100*time.Millisecond)
+	tick(sprinter, 100*time.Millisecond)
+
+	walked := walkingPlayer.Position.Y - 1000
+	sprinted := sprintingPlayer.Position.Y - 1000
+	if sprinted <= walked {
+		t.Errorf("expected sprinting to cover more distance, walked %.1f, sprinted %.1f", walked, sprinted)
+	}
+
+	if sprintingPlayer.Stamina >= config.PlayerMaxStamina {
+		t.Errorf("expected sprinting to drain stamina, got %.1f", sprintingPlayer.Stamina)
+	}
+	if walkingPlayer.Stamina != config.PlayerMaxStamina {
+		t.Errorf("expected walking to keep stamina full, got %.1f", walkingPlayer.Stamina)
+	}
+}
+
+func TestSprintStopsWithoutStamina(t *testing.T) {
+	e := newTestEngine(t)
+	e.sprintEnabled = true
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Stamina = 0
+	e.playerInputState["player"] = &types.InputPayload{Forward: true, Sprint: true}
+
+	tick(e, 100*time.Millisecond)
+
+	if player.IsSprinting {
+		t.Error("expected player without stamina not to sprint")
+	}
+	if moved := player.Position.Y - 1000; moved > config.PlayerSpeed*0.1*1.01 {
+		t.Errorf("expected walking speed without stamina, moved %.1f", moved)
+	}
+}
+
+func TestStaminaRegenerates(t *testing.T) {
+	e := newTestEngine(t)
+	e.sprintEnabled = true
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Stamina = 10
+
+	tick(e, 100*time.Millisecond)
+
+	if player.Stamina <= 10 {
+		t.Errorf("expected stamina to regenerate, got %.1f", player.Stamina)
+	}
+
+	for i := 0; i < 100; i++ {
+		tick(e, 100*time.Millisecond)
+	}
+
+	if player.Stamina != config.PlayerMaxStamina {
+		t.Errorf("expected stamina to cap at %.1f, got %.1f", config.PlayerMaxStamina, player.Stamina)
+	}
+}
+
+func TestChestPickupInvulnerability(t *testing.T) {
+	pickUpChest := func(invulnerability float64) *types.Player {
+		e := newTestEngine(t)
+		e.chestPickupInvulnerability = invulnerability
+		player := addTestPlayer(e, "player", 1000, 1000)
+		e.state.bonuses["chest"] = &types.Bonus{
+			ScreenObject: types.ScreenObject{ID: "chest", Position: &types.Vector2{X: 1000, Y: 1000}},
+			Type:         types.BonusTypeChest,
+			Inventory:    []types.InventoryItem{{Type: types.InventoryItemMoney, Quantity: 10}},
+		}
+
+		tick(e, 100*time.Millisecond)
+
+		if e.state.bonuses["chest"].PickedUpBy != player.ID {
+			t.Fatal("expected the chest to be picked up")
+		}
+		return player
+	}
+
+	if player := pickUpChest(1.5); player.InvulnerableTimer != 1.5 {
+		t.Errorf("expected invulnerability timer 1.5 after chest pickup, got %v", player.InvulnerableTimer)
+	}
+
+	if player := pickUpChest(0); player.InvulnerableTimer != 0 {
+		t.Errorf("expected no invulnerability when the option is disabled, got %v", player.InvulnerableTimer)
+	}
+}
+
+func TestBonusesPickedUpAtConfiguredRadius(t *testing.T) {
+	bonusTypes := []string{
+		types.BonusTypeAidKit, types.BonusTypeGoggles, types.BonusTypeChest, types.BonusTypeKey,
+		types.BonusTypeDoubleDamage, types.BonusTypeRapidFire, types.BonusTypeSpeedBoost,
+	}
+
+	pickedUpAt := func(bonusType string, distance float64) bool {
+		e := newTestEngine(t)
+		e.bonusPickupRadius[bonusType] = 40
+		addTestPlayer(e, "player", 1000, 1000)
+		e.state.bonuses["bonus"] = &types.Bonus{
+			ScreenObject: types.ScreenObject{ID: "bonus", Position: &types.Vector2{X: 1000 + distance, Y: 1000}},
+			Type:         bonusType,
+		}
+
+		tick(e, 100*time.Millisecond)
+
+		return e.state.bonuses["bonus"].PickedUpBy != ""
+	}
+
+	for _, bonusType := range bonusTypes {
+		if !pickedUpAt(bonusType, config.PlayerRadius+39) {
+			t.Errorf("expected %s to be picked up within its radius", bonusType)
+		}
+		if pickedUpAt(bonusType, config.PlayerRadius+41) {
+			t.Errorf("expected %s to stay out of reach beyond its radius", bonusType)
+		}
+	}
+}
+
+func TestStationaryPlayerIsHarderToSpot(t *testing.T) {
+	// The enemy stands ahead of the player's torch, out of reach of a
+	// stationary player's reduced detection range but within the full one
+	spotted := func(moving bool) bool {
+		e := newTestEngine(t)
+		e.stealthEnabled = true
+		player := addTestPlayer(e, "player", 1000, 1000)
+		if moving {
+			e.playerInputState["player"] = &types.InputPayload{Forward: true}
+		}
+
+		torchPoint, _ := player.DetectionParams()
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: torchPoint.X, Y: torchPoint.Y + 170}},
+			Rotation:     45,
+			Lives:        config.EnemySoldierLives,
+			IsAlive:      true,
+			Type:         types.EnemyTypeSoldier,
+		}
+		e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+
+		tick(e, 100*time.Millisecond)
+
+		if player.IsMoving != moving {
+			t.Fatalf("expected IsMoving to be %v", moving)
+		}
+		return enemy.Rotation != 45
+	}
+
+	if !spotted(true) {
+		t.Error("expected the enemy to spot a moving player")
+	}
+	if spotted(false) {
+		t.Error("expected the enemy not to spot a stationary player at the same distance")
+	}
+}
+
+func TestPlayerDetectionParamsShrinkWhenStationary(t *testing.T) {
+	e := newTestEngine(t)
+	e.stealthEnabled = true
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	player.IsMoving = true
+	_, movingDistance := e.playerDetectionParams(player)
+
+	player.IsMoving = false
+	_, stationaryDistance := e.playerDetectionParams(player)
+
+	if movingDistance <= stationaryDistance {
+		t.Errorf("expected moving player to be detected from further away, moving %.1f, stationary %.1f", movingDistance, stationaryDistance)
+	}
+
+	e.stealthEnabled = false
+	if _, distance := e.playerDetectionParams(player); distance != movingDistance {
+		t.Errorf("expected full detection range with stealth disabled, got %.1f", distance)
+	}
+}
+
+func TestDeathsAreReportedOncePerDeath(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	shoot := func() {
+		bullet := &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: player.Position.X - 10, Y: player.Position.Y}},
+			OwnerID:      "enemy",
+			IsEnemy:      true,
+			IsActive:     true,
+			Damage:       config.PlayerLives,
+		}
+		e.applyBulletDamage(bullet, &types.Vector2{X: player.Position.X + 10, Y: player.Position.Y})
+	}
+
+	deaths := 0
+
+	shoot()
+	deaths += len(e.TakeDeaths())
+
+	// A dead player lingering across ticks and reconnects must not count again
+	tick(e, 100*time.Millisecond)
+	e.DisconnectPlayer(player.ID)
+	e.ConnectPlayer(player.ID, player.Username)
+	deaths += len(e.TakeDeaths())
+
+	e.RespawnPlayer(player.ID)
+	tick(e, 100*time.Millisecond)
+	if !player.IsAlive {
+		t.Fatal("expected the player to respawn")
+	}
+	player.InvulnerableTimer = 0
+
+	shoot()
+	tick(e, 100*time.Millisecond)
+	deaths += len(e.TakeDeaths())
+
+	if deaths != 2 {
+		t.Errorf("expected 2 deaths to be reported, got %d", deaths)
+	}
+}
+
+func TestShopEnterAndLeaveEvents(t *testing.T) {
+	e := newTestEngine(t)
+	shop := &types.Shop{ScreenObject: types.ScreenObject{ID: "shop", Position: &types.Vector2{X: 1000, Y: 1000}}}
+	e.state.shopsByChunk["0,0"][shop.ID] = shop
+
+	interactionRange := config.ShopSize*math.Sqrt2/2 + config.PlayerRadius
+	player := addTestPlayer(e, "player", 1000, 1000+interactionRange+1)
+
+	events := func() []*protocol.ShopEvent {
+		tick(e, 100*time.Millisecond)
+		return e.GetGameStateDeltaForPlayer(player.ID).ShopEvents
+	}
+
+	if got := events(); len(got) != 0 {
+		t.Fatalf("expected no events outside the shop, got %v", got)
+	}
+
+	player.Position.Y = 1000 + interactionRange - 1
+	got := events()
+	if len(got) != 1 || got[0].Type != protocol.ShopEventType_SHOP_ENTER || got[0].ShopId != shop.ID {
+		t.Fatalf("expected a single SHOP_ENTER event, got %v", got)
+	}
+
+	if got := events(); len(got) != 0 {
+		t.Fatalf("expected no events while staying in the shop, got %v", got)
+	}
+
+	player.Position.Y = 1000 + interactionRange + 1
+	got = events()
+	if len(got) != 1 || got[0].Type != protocol.ShopEventType_SHOP_LEAVE || got[0].ShopId != shop.ID {
+		t.Fatalf("expected a single SHOP_LEAVE event, got %v", got)
+	}
+}
+
+func TestShopsAreDiscoveredWhenSeen(t *testing.T) {
+	e := newTestEngine(t)
+	nearShop := &types.Shop{ScreenObject: types.ScreenObject{ID: "near", Position: &types.Vector2{X: 1000, Y: 1100}}}
+	farShop := &types.Shop{ScreenObject: types.ScreenObject{ID: "far", Position: &types.Vector2{X: -1500, Y: -1500}}}
+	e.state.shopsByChunk["0,0"][nearShop.ID] = nearShop
+	e.state.shopsByChunk["-1,-1"][farShop.ID] = farShop
+
+	addTestPlayer(e, "player", 1000, 1000)
+	tick(e, 100*time.Millisecond)
+
+	discovered := make(map[string]bool)
+	for _, shop := range e.GetShops() {
+		discovered[shop.ID] = shop.Discovered
+	}
+
+	if len(discovered) != 2 {
+		t.Fatalf("expected GetShops to return both shops, got %d", len(discovered))
+	}
+	if !discovered["near"] {
+		t.Error("expected the shop in sight to be discovered")
+	}
+	if discovered["far"] {
+		t.Error("expected the shop out of sight to stay undiscovered")
+	}
+}
+
+func TestGuardSteersAroundWallOnRoute(t *testing.T) {
+	patrol := func(lookAhead float64) bool {
+		e := newTestEngine(t)
+		e.enemyLookAhead = lookAhead
+
+		firstWall := &types.Wall{
+			ScreenObject: types.ScreenObject{ID: "wall-1", Position: &types.Vector2{X: 500, Y: 400}},
+			Width:        20,
+			Height:       200,
+			Orientation:  "vertical",
+		}
+		secondWall := &types.Wall{
+			ScreenObject: types.ScreenObject{ID: "wall-2", Position: &types.Vector2{X: 900, Y: 400}},
+			Width:        20,
+			Height:       200,
+			Orientation:  "vertical",
+		}
+		obstacle := &types.Wall{
+			ScreenObject: types.ScreenObject{ID: "obstacle", Position: &types.Vector2{X: 700, Y: 460}},
+			Width:        20,
+			Height:       80,
+			Orientation:  "vertical",
+		}
+		for _, wall := range []*types.Wall{firstWall, secondWall, obstacle} {
+			e.state.wallsByChunk["0,0"][wall.ID] = wall
+		}
+
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: "guard", Position: guardAnchor(firstWall, secondWall, config.EnemySoldierSize)},
+			WallID:       firstWall.ID,
+			SecondWallID: secondWall.ID,
+			Direction:    1,
+			IsAlive:      true,
+			Type:         types.EnemyTypeSoldier,
+		}
+		e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+		addTestPlayer(e, "player", 700, 1500)
+
+		target := guardAnchor(secondWall, firstWall, enemy.Size())
+		for i := 0; i < 200; i++ {
+			tick(e, 100*time.Millisecond)
+			if math.Abs(enemy.Position.X-target.X) < 1e-9 && math.Abs(enemy.Position.Y-target.Y) < 1e-9 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if patrol(0) {
+		t.Fatal("expected the guard to be stopped by the obstacle without steering")
+	}
+	if !patrol(100) {
+		t.Error("expected the guard to steer around the obstacle and reach the second wall")
+	}
+}
+
+func TestRewardsClampAtConfiguredMaximum(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxMoney = 1000
+	e.maxScore = 1500
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 990
+	player.Score = 990
+
+	e.rewardPlayer(player, config.PlayerReward)
+
+	if player.Money != 1000 {
+		t.Errorf("expected money to clamp at 1000, got %d", player.Money)
+	}
+	if player.Score != 990+config.PlayerReward {
+		t.Errorf("expected score below its cap to grow, got %d", player.Score)
+	}
+
+	e.rewardPlayer(player, 1000)
+	if player.Score != 1500 {
+		t.Errorf("expected score to clamp at 1500, got %d", player.Score)
+	}
+}
+
+func TestRocketKillsRewardLikeBulletKills(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxMoney = 100000
+	e.maxScore = 100000
+	shooter := addTestPlayer(e, "shooter", 1000, 1000)
+	shooter.Lives = 0.1
+	teammate := addTestPlayer(e, "teammate", 1010, 1000)
+	teammate.Lives = 0.1
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1010, 0.1)
+
+	e.applyRocketExplosionDamage(&types.Vector2{X: 1000, Y: 1000}, map[string]bool{}, shooter.ID)
+
+	if enemy.IsAlive || teammate.IsAlive || shooter.IsAlive {
+		t.Fatal("expected the explosion to kill everyone in it")
+	}
+	if shooter.Kills != 2 {
+		t.Errorf("expected the enemy and teammate to count as kills but not the shooter's own death, got %d", shooter.Kills)
+	}
+	if want := int(enemy.Reward()) + config.PlayerReward; shooter.Score != want {
+		t.Errorf("expected the shooter to be rewarded %d, got %d", want, shooter.Score)
+	}
+}
+
+func TestChestMoneyClampsAtConfiguredMaximum(t *testing.T) {
+	e := newTestEngine(t)
+	e.maxMoney = 100
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 95
+	e.state.bonuses["chest"] = &types.Bonus{
+		ScreenObject: types.ScreenObject{ID: "chest", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Type:         types.BonusTypeChest,
+		Inventory:    []types.InventoryItem{{Type: types.InventoryItemMoney, Quantity: 50}},
+	}
+
+	tick(e, 100*time.Millisecond)
+
+	if player.Money != 100 {
+		t.Errorf("expected money to clamp at 100, got %d", player.Money)
+	}
+}
+
+func TestFundsCapDefaultsToProtocolMaximum(t *testing.T) {
+	e := newTestEngine(t)
+	if e.maxMoney != math.MaxInt32 || e.maxScore != math.MaxInt32 {
+		t.Errorf("expected default caps of %d, got money %d, score %d", math.MaxInt32, e.maxMoney, e.maxScore)
+	}
+}
+
+func TestInstantEnemyRemoval(t *testing.T) {
+	shootEnemy := func(instantRemoval bool) (*Engine, *types.Enemy) {
+		e := newTestEngine(t)
+		e.instantEnemyRemoval = instantRemoval
+		addTestPlayer(e, "player", 1000, 1000)
+
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1000, Y: 1300}},
+			Lives:        1,
+			IsAlive:      true,
+			Type:         types.EnemyTypeSoldier,
+		}
+		e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+		e.state.bullets["bullet"] = &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: 1000, Y: 1290}},
+			Velocity:     &types.Vector2{X: 0, Y: 200},
+			OwnerID:      "player",
+			IsActive:     true,
+			SpawnTime:    time.Now(),
+			Damage:       1,
+			WeaponType:   types.WeaponTypeBlaster,
+		}
+
+		tick(e, 100*time.Millisecond)
+
+		if enemy.IsAlive {
+			t.Fatal("expected the bullet to kill the enemy")
+		}
+		return e, enemy
+	}
+
+	e, enemy := shootEnemy(true)
+	if _, exists := e.state.enemiesByChunk["0,0"][enemy.ID]; exists {
+		t.Error("expected the enemy to be removed in the tick it died")
+	}
+
+	e, enemy = shootEnemy(false)
+	if _, exists := e.state.enemiesByChunk["0,0"][enemy.ID]; !exists {
+		t.Error("expected the enemy corpse to stay without instant removal")
+	}
+}
+
+func TestGeneratedWallsUseConfiguredThickness(t *testing.T) {
+	config.AppConfig = &config.Config{WallThickness: 80}
+	e := NewEngine("test-session")
+	e.generateChunk(0, 0, &types.Vector2{X: 1e9, Y: 1e9})
+
+	if len(e.state.wallsByChunk["0,0"]) == 0 {
+		t.Fatal("expected the chunk to have walls")
+	}
+	for _, wall := range e.state.wallsByChunk["0,0"] {
+		thickness := wall.Height
+		if wall.Orientation == "vertical" {
+			thickness = wall.Width
+		}
+		if thickness != 80 {
+			t.Errorf("expected %s wall to be 80 thick, got %.1f", wall.Orientation, thickness)
+		}
+	}
+}
+
+// addThickWall adds a horizontal wall spanning y 1040-1160, four times the default thickness
+func addThickWall(e *Engine) *types.Wall {
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "thick-wall", Position: &types.Vector2{X: 850, Y: 1100}},
+		Width:        300,
+		Height:       120,
+		Orientation:  "horizontal",
+	}
+	e.state.wallsByChunk["0,0"][wall.ID] = wall
+	return wall
+}
+
+func TestPlayerCollidesWithThickWall(t *testing.T) {
+	e := newTestEngine(t)
+	wall := addThickWall(e)
+	player := addTestPlayer(e, "player", 1000, 950)
+	e.playerInputState["player"] = &types.InputPayload{Forward: true}
+
+	for i := 0; i < 10; i++ {
+		tick(e, 50*time.Millisecond)
+	}
+
+	wallTop := wall.GetTopLeft().Y
+	if player.Position.Y+config.PlayerRadius > wallTop+0.001 {
+		t.Errorf("expected the player to stop at the wall's top edge %.1f, got to %.1f", wallTop, player.Position.Y+config.PlayerRadius)
+	}
+	if player.Position.Y <= 950 {
+		t.Error("expected the player to move towards the wall")
+	}
+}
+
+func TestBulletStopsAtThickWallEdge(t *testing.T) {
+	e := newTestEngine(t)
+	wall := addThickWall(e)
+	bullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: 1000, Y: 1020}},
+		Velocity:     &types.Vector2{X: 0, Y: config.BlasterBulletSpeed},
+		OwnerID:      "player",
+		IsActive:     true,
+		SpawnTime:    time.Now(),
+		WeaponType:   types.WeaponTypeBlaster,
+		Damage:       1,
+	}
+	e.state.bullets[bullet.ID] = bullet
+
+	tick(e, 100*time.Millisecond)
+
+	if bullet.IsActive {
+		t.Fatal("expected the bullet to hit the wall")
+	}
+	wallTop := wall.GetTopLeft().Y
+	if bullet.Position.Y > wallTop+e.bulletWallTolerance {
+		t.Errorf("expected the bullet to stop at the wall's top edge %.1f, got to %.1f", wallTop, bullet.Position.Y)
+	}
+}
+
+func TestBulletGrazingWallEdgeRespectsTolerance(t *testing.T) {
+	config.AppConfig = &config.Config{BulletWallTolerance: 2}
+	if tolerance := NewEngine("tolerance").bulletWallTolerance; tolerance != 2 {
+		t.Fatalf("expected the engine to take the configured tolerance 2, got %.2f", tolerance)
+	}
+
+	// The bullet flies along the wall's top edge, half a unit inside it
+	graze := func(tolerance float64) *types.Bullet {
+		e := newTestEngine(t)
+		e.bulletWallTolerance = tolerance
+		wall := addThickWall(e)
+		topLeft := wall.GetTopLeft()
+		bullet := &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: topLeft.X - 10, Y: topLeft.Y + 0.5}},
+			Velocity:     &types.Vector2{X: 400, Y: 0},
+			OwnerID:      "player",
+			IsActive:     true,
+			SpawnTime:    time.Now(),
+			WeaponType:   types.WeaponTypeBlaster,
+			Damage:       1,
+		}
+		e.state.bullets[bullet.ID] = bullet
+
+		tick(e, 100*time.Millisecond)
+		return bullet
+	}
+
+	if bullet := graze(1); !bullet.IsActive {
+		t.Errorf("expected a bullet within the tolerance of the edge to fly past, stopped at %.1f", bullet.Position.X)
+	}
+	if bullet := graze(0.25); bullet.IsActive {
+		t.Errorf("expected a bullet deeper than the tolerance to hit the wall, got to %.1f", bullet.Position.X)
+	}
+}
+
+func TestBulletTrailsSendOrigin(t *testing.T) {
+	for _, trails := range []bool{false, true} {
+		e := newTestEngine(t)
+		e.bulletTrails = trails
+
+		bullet := &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: 100, Y: 200}},
+			Velocity:     &types.Vector2{X: 0, Y: 300},
+			IsActive:     true,
+		}
+		e.addBullet(bullet)
+		bullet.Position.Y += 50
+
+		origin := protocol.ToProtoBullet(bullet).Origin
+		if !trails {
+			if origin != nil {
+				t.Errorf("expected no origin without trails, got %v", origin)
+			}
+			continue
+		}
+		if origin == nil || origin.X != 100 || origin.Y != 200 {
+			t.Errorf("expected origin (100, 200) to survive the bullet moving, got %v", origin)
+		}
+	}
+}
+
+func TestTeammatePositionsIgnoreFog(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		e := newTestEngine(t)
+		e.teammatePositions = enabled
+		player := addTestPlayer(e, "player", 100, 100)
+
+		// Far outside the torch and sight radius, and hidden by night vision
+		teammate := addTestPlayer(e, "teammate", 1234, 1870)
+		teammate.NightVisionTimer = 10
+
+		position, sent := e.GetGameStateDeltaForPlayer(player.ID).UpdatedOtherPlayerPositions[teammate.ID]
+		if !enabled {
+			if sent {
+				t.Errorf("expected a hidden teammate's position not to be sent by default, got %v", position)
+			}
+			continue
+		}
+
+		if !sent {
+			t.Fatal("expected the teammate's position to be sent regardless of fog")
+		}
+		if position.X != 1200 || position.Y != 1900 {
+			t.Errorf("expected the coarse position (1200, 1900), got (%.0f, %.0f)", position.X, position.Y)
+		}
+	}
+}
+
+func TestTeammatePositionsOnlyGoToTeammates(t *testing.T) {
+	e := newTestEngine(t)
+	e.teammatePositions = true
+	player := addTestPlayer(e, "player", 100, 100)
+	player.Team = "red"
+
+	// All of them far outside the torch and sight radius, and hidden by night vision
+	teammate := addTestPlayer(e, "teammate", 1234, 1870)
+	teammate.Team = "red"
+	opponent := addTestPlayer(e, "opponent", 1870, 1234)
+	opponent.Team = "blue"
+	loner := addTestPlayer(e, "loner", 1500, 1500)
+	for _, other := range []*types.Player{teammate, opponent, loner} {
+		other.NightVisionTimer = 10
+	}
+
+	positions := e.GetGameStateDeltaForPlayer(player.ID).UpdatedOtherPlayerPositions
+	if _, sent := positions[teammate.ID]; !sent {
+		t.Error("expected the teammate's position to be sent")
+	}
+	if position, sent := positions[opponent.ID]; sent {
+		t.Errorf("expected the opponent's position to stay hidden, got %v", position)
+	}
+	if position, sent := positions[loner.ID]; sent {
+		t.Errorf("expected a player without a team to stay hidden from a team, got %v", position)
+	}
+}
+
+func TestDetectableTeammatePositionStaysExact(t *testing.T) {
+	e := newTestEngine(t)
+	e.teammatePositions = true
+	player := addTestPlayer(e, "player", 100, 100)
+	teammate := addTestPlayer(e, "teammate", 1234, 1870)
+
+	position, sent := e.GetGameStateDeltaForPlayer(player.ID).UpdatedOtherPlayerPositions[teammate.ID]
+	if !sent || position.X != 1234 || position.Y != 1870 {
+		t.Errorf("expected the exact position (1234, 1870), got %v", position)
+	}
+}
+
+func TestTeammateSeesDroppedChestThroughFog(t *testing.T) {
+	e := newTestEngine(t)
+	e.teamDroppedChests = true
+	dropper := addTestPlayer(e, "dropper", 1234, 1870)
+	dropper.Team = "red"
+	dropper.IsAlive = false
+	teammate := addTestPlayer(e, "teammate", 100, 100)
+	teammate.Team = "red"
+	rival := addTestPlayer(e, "rival", 3000, 100)
+	rival.Team = "blue"
+
+	e.state.bonuses["chest"] = &types.Bonus{
+		ScreenObject: types.ScreenObject{ID: "chest", Position: &types.Vector2{X: 1234, Y: 1870}},
+		Type:         types.BonusTypeChest,
+		Inventory:    []types.InventoryItem{{Type: types.InventoryItemMoney, Quantity: 50}},
+		DroppedBy:    dropper.ID,
+	}
+
+	if _, sent := e.GetGameStateDeltaForPlayer(teammate.ID).AddedBonuses["chest"]; !sent {
+		t.Error("expected a teammate to see the dropped chest through fog")
+	}
+	if _, sent := e.GetGameStateDeltaForPlayer(rival.ID).AddedBonuses["chest"]; sent {
+		t.Error("expected a player from another team not to see the dropped chest")
+	}
+
+	// Still known on the next delta, so it isn't removed and added again
+	if delta := e.GetGameStateDeltaForPlayer(teammate.ID); len(delta.RemovedBonuses) > 0 || len(delta.AddedBonuses) > 0 {
+		t.Errorf("expected the chest to stay known to the teammate, got added %v, removed %v", delta.AddedBonuses, delta.RemovedBonuses)
+	}
+}
+
+func TestDroppedChestHiddenFromTeammatesByDefault(t *testing.T) {
+	e := newTestEngine(t)
+	dropper := addTestPlayer(e, "dropper", 1234, 1870)
+	dropper.Team = "red"
+	teammate := addTestPlayer(e, "teammate", 100, 100)
+	teammate.Team = "red"
+
+	e.state.bonuses["chest"] = &types.Bonus{
+		ScreenObject: types.ScreenObject{ID: "chest", Position: &types.Vector2{X: 1234, Y: 1870}},
+		Type:         types.BonusTypeChest,
+		DroppedBy:    dropper.ID,
+	}
+
+	if _, sent := e.GetGameStateDeltaForPlayer(teammate.ID).AddedBonuses["chest"]; sent {
+		t.Error("expected the dropped chest to follow fog rules with team chests disabled")
+	}
+}
+
+func TestArmoredEnemyOnlyHurtByArmorPiercingWeapons(t *testing.T) {
+	e := newTestEngine(t)
+	addTestPlayer(e, "player", 1000, 1000)
+
+	enemy := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1000, Y: 1300}},
+		Lives:        config.EnemySoldierLives,
+		IsAlive:      true,
+		Type:         types.EnemyTypeSoldier,
+		Armored:      true,
+	}
+	e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+
+	fire := func(weaponType string) {
+		e.state.bullets[weaponType] = &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: weaponType, Position: &types.Vector2{X: 1000, Y: 1290}},
+			Velocity:     &types.Vector2{X: 0, Y: 200},
+			OwnerID:      "player",
+			IsActive:     true,
+			SpawnTime:    time.Now(),
+			Damage:       types.DamageByWeaponType[weaponType],
+			WeaponType:   weaponType,
+		}
+		tick(e, 100*time.Millisecond)
+	}
+
+	fire(types.WeaponTypeBlaster)
+	if enemy.Lives != config.EnemySoldierLives {
+		t.Fatalf("expected blaster fire not to hurt an armored enemy, lives %v", enemy.Lives)
+	}
+
+	fire(types.WeaponTypeRocketLauncher)
+	if enemy.Lives >= config.EnemySoldierLives {
+		t.Errorf("expected a rocket to hurt an armored enemy, lives %v", enemy.Lives)
+	}
+}
+
+func TestArmoredEnemySpawnRateAndPersistence(t *testing.T) {
+	e := newTestEngine(t)
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	zone := &config.Zone{}
+
+	if enemy := e.createEnemyForWall(wall, rand.New(rand.NewSource(1)), zone); enemy.Armored {
+		t.Error("expected no armored enemies by default")
+	}
+
+	e.armoredEnemyChance = 1
+	enemy := e.createEnemyForWall(wall, rand.New(rand.NewSource(1)), zone)
+	if !enemy.Armored {
+		t.Fatal("expected an armored enemy")
+	}
+	e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+
+	for _, enemies := range loaded.state.enemiesByChunk {
+		if loadedEnemy, exists := enemies[enemy.ID]; exists {
+			if !loadedEnemy.Armored {
+				t.Error("expected the enemy to stay armored after loading")
+			}
+			return
+		}
+	}
+	t.Error("expected the armored enemy to be saved")
+}
+
+func TestEnemyArchetypeSpawnAndPersistence(t *testing.T) {
+	e := newTestEngine(t)
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	zone := &config.Zone{}
+
+	if enemy := e.createEnemyForWall(wall, rand.New(rand.NewSource(1)), zone); enemy.Archetype != "" {
+		t.Errorf("expected no archetypes by default, got %q", enemy.Archetype)
+	}
+
+	e.enemyArchetypes = true
+	rng := rand.New(rand.NewSource(1))
+	seen := make(map[string]*types.Enemy)
+	for i := 0; i < 100; i++ {
+		enemy := e.createEnemyForWall(wall, rng, zone)
+		if enemy.Lives != enemy.MaxLives() {
+			t.Fatalf("expected a %s to spawn at full health %.1f, got %.1f", enemy.Archetype, enemy.MaxLives(), enemy.Lives)
+		}
+		seen[enemy.Archetype] = enemy
+	}
+	for _, archetype := range []string{types.EnemyArchetypeGrunt, types.EnemyArchetypeBrute, types.EnemyArchetypeScout} {
+		if seen[archetype] == nil {
+			t.Fatalf("expected some %s among 100 spawns", archetype)
+		}
+	}
+
+	brute := seen[types.EnemyArchetypeBrute]
+	e.state.enemiesByChunk["0,0"][brute.ID] = brute
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+
+	for _, enemies := range loaded.state.enemiesByChunk {
+		if loadedEnemy, exists := enemies[brute.ID]; exists {
+			if loadedEnemy.Archetype != types.EnemyArchetypeBrute {
+				t.Errorf("expected the brute to stay a brute after loading, got %q", loadedEnemy.Archetype)
+			}
+			return
+		}
+	}
+	t.Error("expected the brute to be saved")
+}
+
+func TestEnemyArchetypePatrolSpeed(t *testing.T) {
+	patrolled := func(archetype string) float64 {
+		e := newTestEngine(t)
+		wall := &types.Wall{
+			ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+			Width:        20,
+			Height:       400,
+			Orientation:  "vertical",
+		}
+		e.state.wallsByChunk["0,0"][wall.ID] = wall
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 500 - 10 - config.EnemySoldierSize/2, Y: 500}},
+			Type:         types.EnemyTypeSoldier,
+			Archetype:    archetype,
+			WallID:       wall.ID,
+			Direction:    1,
+			Lives:        1,
+			IsAlive:      true,
+		}
+		e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+		// Far enough for the enemy not to notice, close enough to keep its chunk active
+		addTestPlayer(e, "player", 1500, 1500)
+
+		tick(e, 100*time.Millisecond)
+		return enemy.Position.Y - 500
+	}
+
+	grunt, brute, scout := patrolled(""), patrolled(types.EnemyArchetypeBrute), patrolled(types.EnemyArchetypeScout)
+	if grunt <= 0 {
+		t.Fatalf("expected the grunt to patrol along the wall, moved %.1f", grunt)
+	}
+	if brute >= grunt || scout <= grunt {
+		t.Errorf("expected brutes to patrol slower and scouts faster than grunts, moved %.1f, %.1f and %.1f", brute, grunt, scout)
+	}
+}
+
+func TestRespawnIgnoredForLivingPlayer(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 50
+	player.Score = 120
+
+	if e.RespawnPlayer(player.ID) {
+		t.Error("expected respawn of a living player to be rejected")
+	}
+	tick(e, 100*time.Millisecond)
+
+	if player.Money != 50 || player.Score != 120 {
+		t.Errorf("expected a living player's state to be kept, got money %d, score %d", player.Money, player.Score)
+	}
+
+	// A request sent while alive must not linger and respawn the player the moment they die
+	e.killPlayer(player)
+	tick(e, 100*time.Millisecond)
+	if player.IsAlive {
+		t.Error("expected the player to stay dead until they ask to respawn")
+	}
+}
+
+func TestRespawnWaitsForDelay(t *testing.T) {
+	e := newTestEngine(t)
+	e.respawnDelay = time.Hour
+	player := addTestPlayer(e, "player", 1000, 1000)
+
+	e.killPlayer(player)
+	if e.RespawnPlayer(player.ID) {
+		t.Error("expected respawn before the delay to be rejected")
+	}
+
+	// Reconnecting queues a respawn too, but it still has to wait
+	e.DisconnectPlayer(player.ID)
+	e.ConnectPlayer(player.ID, player.Username)
+	tick(e, 100*time.Millisecond)
+	if player.IsAlive {
+		t.Fatal("expected the player to stay dead during the respawn delay")
+	}
+
+	e.diedAt[player.ID] = time.Now().Add(-2 * time.Hour)
+	if !e.RespawnPlayer(player.ID) {
+		t.Fatal("expected respawn after the delay to be accepted")
+	}
+	tick(e, 100*time.Millisecond)
+	if !player.IsAlive {
+		t.Error("expected the player to respawn")
+	}
+}
+
+// addCrossingBullets adds a player bullet and an enemy bullet flying head-on
+// into each other, passing within the tick rather than meeting exactly
+func addCrossingBullets(e *Engine, enemyOffsetY float64) (*types.Bullet, *types.Bullet) {
+	playerBullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "player-bullet", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Velocity:     &types.Vector2{X: config.BlasterBulletSpeed, Y: 0},
+		OwnerID:      "player",
+		IsActive:     true,
+		SpawnTime:    time.Now(),
+		WeaponType:   types.WeaponTypeBlaster,
+		Damage:       1,
+	}
+	enemyBullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "enemy-bullet", Position: &types.Vector2{X: 1030, Y: 1000 + enemyOffsetY}},
+		Velocity:     &types.Vector2{X: -config.EnemySoldierBulletSpeed, Y: 0},
+		OwnerID:      "enemy",
+		IsEnemy:      true,
+		IsActive:     true,
+		SpawnTime:    time.Now(),
+		WeaponType:   types.WeaponTypeBlaster,
+		Damage:       1,
+	}
+	e.state.bullets[playerBullet.ID] = playerBullet
+	e.state.bullets[enemyBullet.ID] = enemyBullet
+	return playerBullet, enemyBullet
+}
+
+func TestPlayerBulletShootsDownEnemyBullet(t *testing.T) {
+	e := newTestEngine(t)
+	e.shootableEnemyBullets = true
+	playerBullet, enemyBullet := addCrossingBullets(e, 0)
+
+	tick(e, 100*time.Millisecond)
+
+	if playerBullet.IsActive || enemyBullet.IsActive {
+		t.Errorf("expected both bullets to cancel out, player active %v, enemy active %v", playerBullet.IsActive, enemyBullet.IsActive)
+	}
+}
+
+func TestEnemyBulletsPassWhenNotShootable(t *testing.T) {
+	e := newTestEngine(t)
+	playerBullet, enemyBullet := addCrossingBullets(e, 0)
+
+	tick(e, 100*time.Millisecond)
+
+	if !playerBullet.IsActive || !enemyBullet.IsActive {
+		t.Error("expected bullets to fly through each other by default")
+	}
+}
+
+func TestBulletsMissingEachOtherKeepFlying(t *testing.T) {
+	e := newTestEngine(t)
+	e.shootableEnemyBullets = true
+	playerBullet, enemyBullet := addCrossingBullets(e, config.BulletInterceptRange*2)
+
+	tick(e, 100*time.Millisecond)
+
+	if !playerBullet.IsActive || !enemyBullet.IsActive {
+		t.Error("expected bullets passing out of range to keep flying")
+	}
+}
+
+// shopChunks generates a row of chunks in the given order and lists the ones that kept their shop
+func shopChunks(t *testing.T, minShopDistance float64, chunkXs []int) []string {
+	t.Helper()
+
+	config.AppConfig = &config.Config{}
+	e := NewEngine("shop-distance-test")
+	e.SetSeed("shop-distance")
+	e.minShopDistance = minShopDistance
+	for _, chunkX := range chunkXs {
+		e.generateChunk(chunkX, 0, &types.Vector2{X: 1e9, Y: 1e9})
+	}
+
+	var chunks []string
+	for chunkKey, shops := range e.state.shopsByChunk {
+		if len(shops) > 0 {
+			chunks = append(chunks, chunkKey)
+		}
+	}
+	sort.Strings(chunks)
+	return chunks
+}
+
+func TestAdjacentShopsRespectMinimumDistance(t *testing.T) {
+	minShopDistance := config.ChunkSize * 1.5
+	forward := shopChunks(t, minShopDistance, []int{0, 1, 2, 3, 4, 5})
+
+	if len(forward) == 0 {
+		t.Fatal("expected some chunks to keep their shops")
+	}
+	for i := 1; i < len(forward); i++ {
+		var previous, current int
+		fmt.Sscanf(forward[i-1], "%d,0", &previous)
+		fmt.Sscanf(forward[i], "%d,0", &current)
+		if float64(current-previous)*config.ChunkSize < minShopDistance {
+			t.Errorf("expected no shops within %.0f of another shop, got chunks %v", minShopDistance, forward)
+		}
+	}
+
+	if reverse := shopChunks(t, minShopDistance, []int{5, 4, 3, 2, 1, 0}); !reflect.DeepEqual(forward, reverse) {
+		t.Errorf("expected the same shops whatever order chunks are generated in, got %v and %v", forward, reverse)
+	}
+}
+
+func TestAdjacentShopsWithoutMinimumDistance(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	e := NewEngine("shop-distance-test")
+	far := &types.Vector2{X: 1e9, Y: 1e9}
+	e.generateChunk(0, 0, far)
+	e.generateChunk(1, 0, far)
+
+	if len(e.state.shopsByChunk["0,0"]) != 1 || len(e.state.shopsByChunk["1,0"]) != 1 {
+		t.Error("expected every chunk to have a shop by default")
+	}
+}
+
+// pickUpWeapons drops a chest with the given items under a player holding the selected weapon and ticks
+func pickUpWeapons(t *testing.T, mode string, selected string, items ...types.InventoryItem) *types.Player {
+	e := newTestEngine(t)
+	e.autoEquipWeapons = mode
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Inventory = []types.InventoryItem{
+		{Type: types.InventoryItemBlaster, Quantity: 1},
+		{Type: types.InventoryItemRocketLauncher, Quantity: 1},
+	}
+	player.SelectedGunType = selected
+	e.state.bonuses["chest"] = &types.Bonus{
+		ScreenObject: types.ScreenObject{ID: "chest", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Type:         types.BonusTypeChest,
+		Inventory:    items,
+	}
+
+	tick(e, 100*time.Millisecond)
+	return player
+}
+
+func TestAutoEquipWeapons(t *testing.T) {
+	shotgun := types.InventoryItem{Type: types.InventoryItemShotgun, Quantity: 1}
+	railgun := types.InventoryItem{Type: types.InventoryItemRailgun, Quantity: 1}
+	ammo := types.InventoryItem{Type: types.InventoryItemShotgunAmmo, Quantity: 10}
+
+	tests := []struct {
+		name     string
+		mode     string
+		selected string
+		items    []types.InventoryItem
+		want     string
+	}{
+		{"off keeps the chosen weapon", config.AutoEquipOff, types.WeaponTypeBlaster, []types.InventoryItem{railgun}, types.WeaponTypeBlaster},
+		{"better equips a better weapon", config.AutoEquipBetter, types.WeaponTypeBlaster, []types.InventoryItem{shotgun}, types.WeaponTypeShotgun},
+		{"better keeps a better chosen weapon", config.AutoEquipBetter, types.WeaponTypeRocketLauncher, []types.InventoryItem{shotgun}, types.WeaponTypeRocketLauncher},
+		{"always equips any weapon", config.AutoEquipAlways, types.WeaponTypeRocketLauncher, []types.InventoryItem{shotgun}, types.WeaponTypeShotgun},
+		{"best of several weapons", config.AutoEquipAlways, types.WeaponTypeBlaster, []types.InventoryItem{shotgun, railgun}, types.WeaponTypeRailgun},
+		{"ammo alone changes nothing", config.AutoEquipAlways, types.WeaponTypeBlaster, []types.InventoryItem{ammo}, types.WeaponTypeBlaster},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := pickUpWeapons(t, tt.mode, tt.selected, tt.items...)
+			if player.SelectedGunType != tt.want {
+				t.Errorf("expected %s to be selected, got %s", tt.want, player.SelectedGunType)
+			}
+		})
+	}
+}
+
+func TestLoadFromSessionDropsUnknownInventoryItems(t *testing.T) {
+	e := newTestEngine(t)
+	session := &db.GameSession{
+		GameVersion: config.GameVersion,
+		Players: map[string]db.PlayerState{
+			"player": {
+				PlayerID: "player",
+				IsAlive:  true,
+				Inventory: []db.InventoryItem{
+					{Type: int32(types.InventoryItemBlaster), Quantity: 1},
+					{Type: 999, Quantity: 5},
+					{Type: int32(types.InventoryItemShotgunAmmo), Quantity: 10},
+				},
+			},
+			"corrupted": {
+				PlayerID:  "corrupted",
+				IsAlive:   true,
+				Inventory: []db.InventoryItem{{Type: -1, Quantity: 1}},
+			},
+		},
+		SharedObjects: map[string]db.WorldObject{
+			"shop": {
+				Type: "shop",
+				X:    1000,
+				Y:    1000,
+				Properties: map[string]interface{}{
+					"name": "Shop",
+					"inventory": map[string]interface{}{
+						"8":   map[string]interface{}{"price": int32(50), "quantity": int32(3), "pack_size": int32(1)},
+						"999": map[string]interface{}{"price": int32(1), "quantity": int32(3), "pack_size": int32(1)},
+					},
+				},
+			},
+		},
+	}
+
+	e.LoadFromSession(session)
+
+	want := []types.InventoryItem{
+		{Type: types.InventoryItemBlaster, Quantity: 1},
+		{Type: types.InventoryItemShotgunAmmo, Quantity: 10},
+	}
+	if got := e.state.players["player"].Inventory; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected unknown items to be dropped, got %+v", got)
+	}
+
+	// A player left with nothing still gets the blaster
+	wantBlaster := []types.InventoryItem{{Type: types.InventoryItemBlaster, Quantity: 1}}
+	if got := e.state.players["corrupted"].Inventory; !reflect.DeepEqual(got, wantBlaster) {
+		t.Errorf("expected only the blaster to be left, got %+v", got)
+	}
+
+	shop := e.state.shopsByChunk["0,0"]["shop"]
+	if shop == nil {
+		t.Fatal("expected the shop to be loaded")
+	}
+	if _, exists := shop.Inventory[999]; exists || len(shop.Inventory) != 1 {
+		t.Errorf("expected the shop to keep only known items, got %v", shop.Inventory)
+	}
+}
+
+// addShotgunPlayer adds a player holding an empty shotgun with the given reserve ammo
+func addShotgunPlayer(e *Engine, ammo int32) *types.Player {
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeShotgun
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeShotgun: 0}
+	player.Inventory = []types.InventoryItem{
+		{Type: types.InventoryItemShotgun, Quantity: 1},
+		{Type: types.InventoryItemShotgunAmmo, Quantity: ammo},
+	}
+	return player
+}
+
+func TestManualReloadFillsMagazineFromReserve(t *testing.T) {
+	e := newTestEngine(t)
+	player := addShotgunPlayer(e, 10)
+	e.playerInputState["player"] = &types.InputPayload{Reload: true}
+
+	tick(e, 10*time.Millisecond)
+
+	if got := player.BulletsLeftByWeaponType[types.WeaponTypeShotgun]; got != config.ShotgunMaxBullets {
+		t.Errorf("expected a full magazine of %d after reloading, got %d", config.ShotgunMaxBullets, got)
+	}
+	if got := player.GetInventoryItemQuantity(types.InventoryItemShotgunAmmo); got != 10-config.ShotgunMaxBullets {
+		t.Errorf("expected the reload to use %d shells from the reserve, %d left", config.ShotgunMaxBullets, got)
+	}
+	if player.ReloadTimer <= 0 {
+		t.Error("expected the reload to start the reload timer")
+	}
+}
+
+func TestManualReloadIsLimitedByReserve(t *testing.T) {
+	e := newTestEngine(t)
+	player := addShotgunPlayer(e, 1)
+	e.playerInputState["player"] = &types.InputPayload{Reload: true}
+
+	tick(e, 10*time.Millisecond)
+
+	if got := player.BulletsLeftByWeaponType[types.WeaponTypeShotgun]; got != 1 {
+		t.Errorf("expected the only reserve shell to be loaded, got %d in the magazine", got)
+	}
+	if player.HasInventoryItem(types.InventoryItemShotgunAmmo) {
+		t.Error("expected the reserve to be used up")
+	}
+}
+
+func TestShootingWaitsForReload(t *testing.T) {
+	e := newTestEngine(t)
+	player := addShotgunPlayer(e, 10)
+	e.playerInputState["player"] = &types.InputPayload{Reload: true, Shoot: true}
+
+	tick(e, 10*time.Millisecond)
+
+	if got := player.BulletsLeftByWeaponType[types.WeaponTypeShotgun]; got != config.ShotgunMaxBullets {
+		t.Fatalf("expected no shot while reloading, %d shells left", got)
+	}
+
+	player.ReloadTimer = 0
+	e.playerInputState["player"] = &types.InputPayload{Shoot: true}
+	tick(e, 10*time.Millisecond)
+
+	if got := player.BulletsLeftByWeaponType[types.WeaponTypeShotgun]; got != config.ShotgunMaxBullets-1 {
+		t.Errorf("expected a shot once the reload finished, %d shells left", got)
+	}
+}
+
+func TestBlasterIgnoresManualReload(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeBlaster
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 0}
+
+	if player.Reload() {
+		t.Error("expected the blaster to keep recharging on its own")
+	}
+}
+
+func TestEnemiesAreSentWithMaxLives(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	e.state.enemiesByChunk["0,0"]["lieutenant"] = &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "lieutenant", Position: &types.Vector2{X: 1000, Y: 1050}},
+		Type:         types.EnemyTypeLieutenant,
+		Lives:        1,
+		IsAlive:      true,
+	}
+
+	enemy, sent := e.GetGameStateDeltaForPlayer(player.ID).AddedEnemies["lieutenant"]
+	if !sent {
+		t.Fatal("expected the enemy next to the player to be sent")
+	}
+	if enemy.Lives != 1 || enemy.MaxLives != config.EnemyLieutenantLives {
+		t.Errorf("expected 1 of %.0f lives for a wounded lieutenant, got %.1f of %.1f", config.EnemyLieutenantLives, enemy.Lives, enemy.MaxLives)
+	}
+}
+
+func TestDeadEnemiesAreSentWithDeathTraceTime(t *testing.T) {
+	for _, fade := range []bool{true, false} {
+		e := newTestEngine(t)
+		e.enemyDeathFade = fade
+		player := addTestPlayer(e, "player", 1000, 1000)
+		enemy := addMeleeTestEnemy(e, "enemy", 1000, 1050, 1)
+
+		if added := e.GetGameStateDeltaForPlayer(player.ID).AddedEnemies["enemy"]; added == nil || added.DeathTraceTime != 0 {
+			t.Fatalf("fade %v: expected the living enemy to be sent without a death trace time, got %+v", fade, added)
+		}
+
+		enemy.Lives = 0
+		e.killEnemy(enemy, "0,0")
+		want := float32(0)
+		if fade {
+			want = float32(config.EnemyDeathTraceTime)
+		}
+		update := e.GetGameStateDeltaForPlayer(player.ID).UpdatedEnemies["enemy"]
+		if update == nil || update.Lives == nil || update.DeathTraceTime != want {
+			t.Errorf("fade %v: expected the death to be sent with a trace time of %.1f, got %+v", fade, want, update)
+		}
+
+		// Players who come across the corpse later learn how long it has left
+		enemy.DeadTimer = 2
+		if fade {
+			want = 2
+		}
+		late := addTestPlayer(e, "late", 1000, 1100)
+		if added := e.GetGameStateDeltaForPlayer(late.ID).AddedEnemies["enemy"]; added == nil || added.DeathTraceTime != want {
+			t.Errorf("fade %v: expected the corpse to be sent with %.1f seconds left, got %+v", fade, want, added)
+		}
+	}
+}
+
+func TestReloadedDeadPlayerRespawnsOnceOnConnect(t *testing.T) {
+	session := &db.GameSession{
+		GameVersion: config.GameVersion,
+		Players: map[string]db.PlayerState{
+			"player": {
+				PlayerID:    "player",
+				Name:        "player",
+				Position:    db.Position{X: 1000, Y: 1000},
+				IsAlive:     false,
+				IsConnected: true, // saved while they were playing
+			},
+		},
+	}
+	e := newTestEngine(t)
+	e.LoadFromSession(session)
+	player := e.state.players["player"]
+
+	tick(e, 100*time.Millisecond)
+	if player.IsAlive || player.IsConnected {
+		t.Fatal("expected the player to stay dead and disconnected until they connect")
+	}
+
+	e.ConnectPlayer("player", "player")
+	tick(e, 100*time.Millisecond)
+	if !player.IsAlive {
+		t.Fatal("expected the player to respawn once connected")
+	}
+	if player.InvulnerableTimer != config.PlayerSpawnInvulnerabilityTime {
+		t.Errorf("expected spawn invulnerability %.1f, got %.1f", config.PlayerSpawnInvulnerabilityTime, player.InvulnerableTimer)
+	}
+
+	// Money earned after the respawn would be reset by another one
+	player.Money = 50
+	tick(e, 100*time.Millisecond)
+	if player.Money != 50 || player.InvulnerableTimer >= config.PlayerSpawnInvulnerabilityTime {
+		t.Errorf("expected a single respawn, got money %d and invulnerability %.1f", player.Money, player.InvulnerableTimer)
+	}
+}
+
+// newCrowdedEngine fills the chunks around the origin with enemies and a few players, and
+// returns bullets flying in random directions across them, none of them added to the engine
+func newCrowdedEngine(t testing.TB, enemies, bullets int) (*Engine, []*types.Bullet) {
+	config.AppConfig = &config.Config{}
+	e := NewEngine("crowded-session")
+	rng := rand.New(rand.NewSource(1))
+
+	for chunkX := -1; chunkX <= 1; chunkX++ {
+		for chunkY := -1; chunkY <= 1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			e.chunkHash[chunkKey] = true
+			e.state.wallsByChunk[chunkKey] = make(map[string]*types.Wall)
+			e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
+			e.state.shopsByChunk[chunkKey] = make(map[string]*types.Shop)
+		}
+	}
+
+	randomPosition := func() *types.Vector2 {
+		return &types.Vector2{X: (rng.Float64()*3 - 1) * config.ChunkSize, Y: (rng.Float64()*3 - 1) * config.ChunkSize}
+	}
+	for i := 0; i < enemies; i++ {
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: fmt.Sprintf("enemy-%d", i), Position: randomPosition()},
+			Type:         types.EnemyTypeSoldier,
+			Lives:        config.EnemySoldierLives,
+			IsAlive:      true,
+		}
+		chunkX, chunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+		e.state.enemiesByChunk[fmt.Sprintf("%d,%d", chunkX, chunkY)][enemy.ID] = enemy
+	}
+	for i := 0; i < 10; i++ {
+		position := randomPosition()
+		addTestPlayer(e, fmt.Sprintf("player-%d", i), position.X, position.Y)
+	}
+
+	shots := make([]*types.Bullet, bullets)
+	for i := range shots {
+		angle := rng.Float64() * 2 * math.Pi
+		speed := config.BlasterBulletSpeed
+		if i%10 == 0 {
+			speed = config.RailgunRange // a beam's whole length in one go
+		}
+		shots[i] = &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: fmt.Sprintf("bullet-%d", i), Position: randomPosition()},
+			Velocity:     &types.Vector2{X: math.Cos(angle) * speed, Y: math.Sin(angle) * speed},
+			OwnerID:      "player-0",
+			WeaponType:   types.WeaponTypeBlaster,
+			IsActive:     true,
+		}
+		e.state.bullets[shots[i].ID] = shots[i]
+	}
+	return e, shots
+}
+
+// BenchmarkFindBulletHits looks for the targets of 200 bullets among 500 enemies by scanning
+// the chunks around each bullet and with the spatial grid, reporting how many targets each tests
+func BenchmarkFindBulletHits(b *testing.B) {
+	e, bullets := newCrowdedEngine(b, 500, 200)
+	newPosition := func(bullet *types.Bullet) *types.Vector2 {
+		return &types.Vector2{X: bullet.Position.X + bullet.Velocity.X/60, Y: bullet.Position.Y + bullet.Velocity.Y/60}
+	}
+
+	b.Run("scan", func(b *testing.B) {
+		checks := 0
+		for i := 0; i < b.N; i++ {
+			for _, bullet := range bullets {
+				e.findBulletHits(bullet, newPosition(bullet))
+				end := newPosition(bullet)
+				chunkX, chunkY := utils.ChunkXYFromPosition(end.X, end.Y)
+				checks += len(e.state.players)
+				for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+					for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+						checks += len(e.state.enemiesByChunk[fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)])
+					}
+				}
+			}
+		}
+		b.ReportMetric(float64(checks)/float64(b.N), "checks/op")
+	})
+
+	b.Run("grid", func(b *testing.B) {
+		checks := 0
+		for i := 0; i < b.N; i++ {
+			e.hitGrid = e.buildSpatialGrid()
+			for _, bullet := range bullets {
+				e.findBulletHits(bullet, newPosition(bullet))
+				e.hitGrid.forCandidates(bullet.Position, newPosition(bullet), func(*types.Player) { checks++ }, func(gridEnemy) { checks++ })
+			}
+		}
+		e.hitGrid = nil
+		b.ReportMetric(float64(checks)/float64(b.N), "checks/op")
+	})
+}
+
+// newWalledEngine fills the chunks around the origin with walls and crowds enemies and players
+// into the middle of them, so most enemies have players close enough to check line of sight
+func newWalledEngine(t testing.TB, enemies int) *Engine {
+	config.AppConfig = &config.Config{}
+	e := NewEngine("walled-session")
+	rng := rand.New(rand.NewSource(1))
+
+	for chunkX := -1; chunkX <= 1; chunkX++ {
+		for chunkY := -1; chunkY <= 1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			e.chunkHash[chunkKey] = true
+			e.state.wallsByChunk[chunkKey] = make(map[string]*types.Wall)
+			e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
+			e.state.shopsByChunk[chunkKey] = make(map[string]*types.Shop)
+
+			for i := 0; i < 40; i++ {
+				wallID := fmt.Sprintf("wall-%s-%d", chunkKey, i)
+				wall := &types.Wall{
+					ScreenObject: types.ScreenObject{ID: wallID, Position: &types.Vector2{
+						X: (float64(chunkX) + rng.Float64()) * config.ChunkSize,
+						Y: (float64(chunkY) + rng.Float64()) * config.ChunkSize,
+					}},
+					Width:       config.WallWidth,
+					Height:      50 + rng.Float64()*100,
+					Orientation: "vertical",
+				}
+				if i%2 == 0 {
+					wall.Width, wall.Height, wall.Orientation = wall.Height, config.WallWidth, "horizontal"
+				}
+				e.state.wallsByChunk[chunkKey][wallID] = wall
+			}
+		}
+	}
+
+	randomPosition := func() *types.Vector2 {
+		return &types.Vector2{X: 700 + rng.Float64()*600, Y: 700 + rng.Float64()*600}
+	}
+	for i := 0; i < enemies; i++ {
+		enemy := &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: fmt.Sprintf("enemy-%d", i), Position: randomPosition()},
+			Type:         types.EnemyTypeTower,
+			Lives:        config.EnemyTowerLives,
+			IsAlive:      true,
+		}
+		e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+	}
+	for i := 0; i < 10; i++ {
+		position := randomPosition()
+		addTestPlayer(e, fmt.Sprintf("player-%d", i), position.X, position.Y)
+	}
+	return e
+}
+
+// scanLineOfSight checks the enemy's line of sight against every wall around it, the way
+// enemies did before their walls were cached
+func scanLineOfSight(e *Engine, enemy *types.Enemy, point *types.Vector2, detectionDistance float64) bool {
+	enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+	for neighborChunkX := enemyChunkX - 1; neighborChunkX <= enemyChunkX+1; neighborChunkX++ {
+		for neighborChunkY := enemyChunkY - 1; neighborChunkY <= enemyChunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if !e.chunkHash[neighborChunkKey] {
+				continue
+			}
+			for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
+				if enemy.DistanceToPoint(wall.GetCenter()) > 2*wall.GetRadius()+detectionDistance {
+					continue
+				}
+				topLeft := wall.GetTopLeft()
+				if utils.CheckLineRectCollision(enemy.Position.X, enemy.Position.Y, point.X, point.Y, topLeft.X, topLeft.Y, wall.Width, wall.Height) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func TestCachedLineOfSightMatchesWallScan(t *testing.T) {
+	e := newWalledEngine(t, 300)
+
+	checked, blocked := 0, 0
+	for _, enemy := range e.state.enemiesByChunk["0,0"] {
+		enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+		for _, player := range e.state.players {
+			detectionPoint, detectionDistance := e.playerDetectionParams(player)
+			want := scanLineOfSight(e, enemy, detectionPoint, detectionDistance)
+			if got := hasLineOfSight(enemy, detectionPoint, detectionDistance, e.sightWallsAround(enemyChunkX, enemyChunkY)); got != want {
+				t.Fatalf("%s seeing %s: cached walls say %v, scanning every wall says %v", enemy.ID, player.ID, got, want)
+			}
+			checked++
+			if !want {
+				blocked++
+			}
+		}
+	}
+	if blocked == 0 || blocked == checked {
+		t.Fatalf("expected a mix of clear and blocked lines, %d of %d blocked", blocked, checked)
+	}
+}
+
+func TestCachedLineOfSightFollowsWallChanges(t *testing.T) {
+	e := newTestEngine(t)
+	enemy := &types.Enemy{ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1000, Y: 1900}}}
+	point := &types.Vector2{X: 1000, Y: 2100}
+
+	if !hasLineOfSight(enemy, point, config.TorchRadius, e.sightWallsAround(0, 0)) {
+		t.Fatal("expected a clear line without walls")
+	}
+
+	door := e.addRoomWall("0,1", 900, 2050, 200, "horizontal", true)
+	if hasLineOfSight(enemy, point, config.TorchRadius, e.sightWallsAround(0, 0)) {
+		t.Fatal("expected a wall added next door to block the line")
+	}
+
+	player := addTestPlayer(e, "player", 1000, 2030)
+	player.Inventory = []types.InventoryItem{{Type: types.InventoryItemKey, Quantity: 1}}
+	e.openLockedDoors(player, 0, 1)
+	if _, exists := e.state.wallsByChunk["0,1"][door.ID]; exists {
+		t.Fatal("expected the door to open")
+	}
+	if !hasLineOfSight(enemy, point, config.TorchRadius, e.sightWallsAround(0, 0)) {
+		t.Error("expected the opened door to clear the line")
+	}
+}
+
+func BenchmarkEnemyDetection(b *testing.B) {
+	b.Run("tick", func(b *testing.B) {
+		e := newWalledEngine(b, 300)
+		enemies := e.state.enemiesByChunk["0,0"]
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Enemies hold their fire so every tick does the same work
+			for _, enemy := range enemies {
+				enemy.ShootDelay = 1
+			}
+			tick(e, time.Second/60)
+		}
+	})
+
+	// Every enemy looking for every player, with the cached walls and the way it was done before
+	lookAround := func(b *testing.B, canSee func(e *Engine, enemy *types.Enemy, point *types.Vector2, detectionDistance float64) bool) {
+		e := newWalledEngine(b, 300)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, enemy := range e.state.enemiesByChunk["0,0"] {
+				for _, player := range e.state.players {
+					detectionPoint, detectionDistance := e.playerDetectionParams(player)
+					canSee(e, enemy, detectionPoint, detectionDistance)
+				}
+			}
+		}
+	}
+	b.Run("cached", func(b *testing.B) {
+		lookAround(b, func(e *Engine, enemy *types.Enemy, point *types.Vector2, detectionDistance float64) bool {
+			enemyChunkX, enemyChunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
+			return hasLineOfSight(enemy, point, detectionDistance, e.sightWallsAround(enemyChunkX, enemyChunkY))
+		})
+	})
+	b.Run("scan", func(b *testing.B) {
+		lookAround(b, scanLineOfSight)
+	})
+}
+
+func TestSuspendedPlayerStandsStill(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	e.UpdatePlayerInput(player.ID, types.InputPayload{Forward: true, Shoot: true})
+
+	e.SuspendPlayer(player.ID)
+	tick(e, 100*time.Millisecond)
+
+	if player.IsConnected {
+		t.Error("expected a suspended player to be marked disconnected")
+	}
+	if player.Position.X != 1000 || player.Position.Y != 1000 {
+		t.Errorf("expected a player without input to stand still, got %v", player.Position)
+	}
+	if len(e.state.bullets) != 0 {
+		t.Errorf("expected a player without input not to shoot, got %d bullets", len(e.state.bullets))
+	}
+}
+
+func TestIdleSessionPicksUpWhereItLeftOff(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	if e.IsIdle() {
+		t.Fatal("expected a session with a connected player not to be idle")
+	}
+
+	e.SuspendPlayer(player.ID)
+	if !e.IsIdle() {
+		t.Fatal("expected a session whose only player is waiting to reconnect to be idle")
+	}
+
+	// The session was left alone for an hour
+	e.lastUpdate = time.Now().Add(-time.Hour)
+	e.ConnectPlayer(player.ID, player.Username)
+	if e.IsIdle() {
+		t.Error("expected the session not to be idle once the player is back")
+	}
+	if idle := time.Since(e.lastUpdate); idle > time.Second {
+		t.Errorf("expected the idle hour not to be played out on the next tick, %s since the last update", idle)
+	}
+}
+
+func TestRotationPerTickIsClamped(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Rotation = 90
+
+	// A second-long tick would turn the player half a circle
+	e.UpdatePlayerInput(player.ID, types.InputPayload{Right: true})
+	for i := 0; i < 3; i++ {
+		before := player.Rotation
+		tick(e, time.Second)
+
+		if turned := player.Rotation - before; turned > e.maxRotationPerTick+1e-9 {
+			t.Fatalf("expected at most %.1f degrees per tick, turned %.1f", e.maxRotationPerTick, turned)
+		}
+	}
+	if math.Abs(player.Rotation-(90+3*e.maxRotationPerTick)) > 1e-9 {
+		t.Errorf("expected the player to keep turning at the clamp, got %.1f", player.Rotation)
+	}
+
+	// Turning left wraps around through 0
+	player.Rotation = 5
+	e.UpdatePlayerInput(player.ID, types.InputPayload{Left: true})
+	tick(e, time.Second)
+	if math.Abs(player.Rotation-(360+5-e.maxRotationPerTick)) > 1e-9 {
+		t.Errorf("expected a clamped left turn, got %.1f", player.Rotation)
+	}
+}
+
+func TestRotationClampFallsBackToOneTickOfTurning(t *testing.T) {
+	tick := 100 * time.Millisecond
+	for _, configured := range []float64{0, -10, 720} {
+		if got, want := maxRotationPerTick(configured, tick), config.PlayerRotationSpeed*0.1; math.Abs(got-want) > 1e-9 {
+			t.Errorf("maxRotationPerTick(%v) = %v, want %v", configured, got, want)
+		}
+	}
+	if got := maxRotationPerTick(45, tick); got != 45 {
+		t.Errorf("maxRotationPerTick(45) = %v, want 45", got)
+	}
+}
+
+func TestSlowTickRateTurnsAtFullSpeed(t *testing.T) {
+	config.AppConfig = &config.Config{GameLoopInterval: 500 * time.Millisecond}
+	e := NewEngine("slow-ticks")
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Rotation = 90
+
+	e.UpdatePlayerInput(player.ID, types.InputPayload{Right: true})
+	tick(e, 500*time.Millisecond)
+
+	if want := 90 + config.PlayerRotationSpeed*0.5; math.Abs(player.Rotation-want) > 1e-9 {
+		t.Errorf("expected a half-second tick to turn the player to %.1f, got %.1f", want, player.Rotation)
+	}
+}
+
+func TestDistantBulletsHeadingAtPlayerAreNeverThrottled(t *testing.T) {
+	e := newTestEngine(t)
+	e.bulletLOD = true
+	e.bulletLODDistance = 200
+	e.bulletLODInterval = 1000
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.NightVisionTimer = 10 // See both bullets well beyond the LOD distance
+
+	enemyBullet := func(id string, y float64) {
+		e.addBullet(&types.Bullet{
+			ScreenObject: types.ScreenObject{ID: id, Position: &types.Vector2{X: 1000, Y: y}},
+			Velocity:     &types.Vector2{X: 0, Y: -config.EnemySoldierBulletSpeed},
+			OwnerID:      "enemy",
+			IsEnemy:      true,
+			SpawnTime:    time.Now(),
+			IsActive:     true,
+		})
+	}
+	enemyBullet("incoming", 1600)
+	enemyBullet("outgoing", 400)
+
+	if added := e.GetGameStateDeltaForPlayer(player.ID).AddedBullets; len(added) != 2 {
+		t.Fatalf("expected both bullets to be sent when they appear, got %d", len(added))
+	}
+
+	for i := 0; i < 3; i++ {
+		tick(e, 50*time.Millisecond)
+		updated := e.GetGameStateDeltaForPlayer(player.ID).UpdatedBullets
+		if _, sent := updated["incoming"]; !sent {
+			t.Fatalf("tick %d: expected the distant bullet heading at the player to be sent", i)
+		}
+		if _, sent := updated["outgoing"]; sent {
+			t.Errorf("tick %d: expected the distant bullet flying away to be throttled", i)
+		}
+	}
+}
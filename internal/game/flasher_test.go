@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestFlasherDeathBlindsNearbyPlayers(t *testing.T) {
+	e := newTestEngine(t)
+	e.flasherFlashRadius = 200
+	e.flasherBlindTime = 2
+	near := addTestPlayer(e, "near", 1000, 1150)
+	goggled := addTestPlayer(e, "goggled", 1150, 1000)
+	goggled.NightVisionTimer = 10
+	far := addTestPlayer(e, "far", 1000, 1250)
+	flasher := addMeleeTestEnemy(e, "flasher", 1000, 1000, 0)
+	flasher.Type = types.EnemyTypeFlasher
+
+	e.finishEnemy(flasher, "0,0", near.ID)
+
+	if near.BlindTimer != 2 {
+		t.Errorf("expected the player within the flash radius to be blinded for 2 seconds, got %.1f", near.BlindTimer)
+	}
+	if want := 2 * config.NightVisionBlindFactor; goggled.BlindTimer != want {
+		t.Errorf("expected goggles to soften the flash to %.1f seconds, got %.1f", want, goggled.BlindTimer)
+	}
+	if far.BlindTimer != 0 {
+		t.Errorf("expected the player beyond the flash radius not to be blinded, got %.1f", far.BlindTimer)
+	}
+}
+
+func TestOtherEnemiesDontFlash(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1050)
+	soldier := addMeleeTestEnemy(e, "soldier", 1000, 1000, 0)
+
+	e.finishEnemy(soldier, "0,0", player.ID)
+
+	if player.BlindTimer != 0 {
+		t.Errorf("expected a soldier's death not to blind anyone, got %.1f", player.BlindTimer)
+	}
+}
+
+func TestFlashFallsBackToDefaults(t *testing.T) {
+	e := newTestEngine(t)
+
+	if e.flasherFlashRadius != config.EnemyFlasherFlashRadius || e.flasherBlindTime != config.PlayerBlindTime {
+		t.Errorf("expected unset flash settings to fall back to %.0f and %.1f, got %.0f and %.1f",
+			config.EnemyFlasherFlashRadius, config.PlayerBlindTime, e.flasherFlashRadius, e.flasherBlindTime)
+	}
+}
+
+func TestFlashersAreOptIn(t *testing.T) {
+	e := newTestEngine(t)
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	countFlashers := func(zone config.Zone) int {
+		rng := rand.New(rand.NewSource(1))
+		flashers := 0
+		for i := 0; i < 200; i++ {
+			if e.createEnemyForWall(wall, rng, &zone).Type == types.EnemyTypeFlasher {
+				flashers++
+			}
+		}
+		return flashers
+	}
+
+	for _, zone := range append([]config.Zone{config.DefaultZone}, config.Zones...) {
+		if flashers := countFlashers(zone); flashers != 0 {
+			t.Errorf("expected no flashers by default in zone %q, got %d", zone.Name, flashers)
+		}
+	}
+
+	e.flasherChance = 0.2
+	forest, cave := countFlashers(config.Zones[1]), countFlashers(config.Zones[2])
+	if cave == 0 || forest <= cave {
+		t.Errorf("expected flashers once enabled, more of them in the forest than in caves, got %d and %d", forest, cave)
+	}
+}
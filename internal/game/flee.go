@@ -0,0 +1,54 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// fleeingEnemyTypes are the enemies able to move, towers and summoners hold their ground
+var fleeingEnemyTypes = map[string]bool{
+	types.EnemyTypeSoldier:    true,
+	types.EnemyTypeLieutenant: true,
+	types.EnemyTypeFlasher:    true,
+	types.EnemyTypeMinion:     true,
+	types.EnemyTypeKeyholder:  true,
+}
+
+// isEnemyFleeing reports whether the enemy is wounded badly enough to run from players
+func (e *Engine) isEnemyFleeing(enemy *types.Enemy) bool {
+	if e.enemyFleeThreshold <= 0 || !fleeingEnemyTypes[enemy.Type] {
+		return false
+	}
+	return float64(enemy.Lives) < e.enemyFleeThreshold*float64(types.EnemyLivesByType[enemy.Type])
+}
+
+// fleeFromPlayer moves the enemy directly away from the player, steering around walls. Enemies
+// aren't moved between chunks, so one at the edge of its chunk is cornered. Returns false
+// when the enemy can't get away.
+func (e *Engine) fleeFromPlayer(enemy *types.Enemy, player *types.Player, chunkKey string, deltaTime float64) bool {
+	awayX := enemy.Position.X - player.Position.X
+	awayY := enemy.Position.Y - player.Position.Y
+	distance := math.Sqrt(awayX*awayX + awayY*awayY)
+	if distance == 0 {
+		return false
+	}
+
+	step := enemy.Speed() * deltaTime
+	dx, dy, canMove := e.steerEnemy(enemy, awayX/distance*step, awayY/distance*step, e.enemyAwarenessRadius)
+	if !canMove {
+		return false
+	}
+
+	chunkX, chunkY := utils.ChunkXYFromPosition(enemy.Position.X+dx, enemy.Position.Y+dy)
+	if fmt.Sprintf("%d,%d", chunkX, chunkY) != chunkKey {
+		return false
+	}
+
+	enemy.Rotation = utils.RotationTowards(dx, dy)
+	enemy.Position.X += dx
+	enemy.Position.Y += dy
+	return true
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addWoundedEnemy adds a player and a soldier in their sight with the given share of its lives left
+func addWoundedEnemy(e *Engine, livesShare float32) (*types.Player, *types.Enemy) {
+	player := addTestPlayer(e, "player", 1000, 1000)
+	enemy := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1000, Y: 1100}},
+		Type:         types.EnemyTypeSoldier,
+		Lives:        config.EnemySoldierLives * livesShare,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+	return player, enemy
+}
+
+func TestWoundedEnemyFleesFromPlayer(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyFleeThreshold = 0.5
+	player, enemy := addWoundedEnemy(e, 0.25)
+	startDistance := enemy.DistanceToPoint(player.Position)
+
+	for i := 0; i < 5; i++ {
+		tick(e, 100*time.Millisecond)
+	}
+
+	if distance := enemy.DistanceToPoint(player.Position); distance <= startDistance {
+		t.Errorf("expected the wounded enemy to move away, distance went from %.1f to %.1f", startDistance, distance)
+	}
+	for _, bullet := range e.state.bullets {
+		if bullet.OwnerID == enemy.ID {
+			t.Fatal("expected the fleeing enemy not to shoot")
+		}
+	}
+}
+
+func TestEnemyAboveThresholdHoldsGround(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyFleeThreshold = 0.5
+	player, enemy := addWoundedEnemy(e, 0.75)
+	startDistance := enemy.DistanceToPoint(player.Position)
+
+	for i := 0; i < 5; i++ {
+		tick(e, 100*time.Millisecond)
+	}
+
+	if distance := enemy.DistanceToPoint(player.Position); distance != startDistance {
+		t.Errorf("expected the enemy to hold its ground, distance went from %.1f to %.1f", startDistance, distance)
+	}
+}
+
+func TestWoundedEnemyStaysWithoutFleeThreshold(t *testing.T) {
+	e := newTestEngine(t)
+	player, enemy := addWoundedEnemy(e, 0.25)
+	startDistance := enemy.DistanceToPoint(player.Position)
+
+	tick(e, 100*time.Millisecond)
+
+	if distance := enemy.DistanceToPoint(player.Position); distance != startDistance {
+		t.Errorf("expected fleeing to be off by default, distance went from %.1f to %.1f", startDistance, distance)
+	}
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// setUpCrossfire puts a shooter facing +Y with the weapon in hand, a teammate in the line of fire
+// and an enemy that doesn't shoot back, then fires and lets the bullets fly
+func setUpCrossfire(t *testing.T, friendlyFire bool, weapon string, teammateX, teammateY, enemyX, enemyY float64) (*types.Player, *types.Enemy) {
+	t.Helper()
+	e := newTestEngine(t)
+	e.friendlyFire = friendlyFire
+
+	shooter := addTestPlayer(e, "shooter", 1000, 1000)
+	shooter.SelectedGunType = weapon
+	shooter.BulletsLeftByWeaponType = map[string]int32{weapon: 10}
+	shooter.Inventory = []types.InventoryItem{{Type: types.InventoryAmmoIDByWeaponType[weapon], Quantity: 10}}
+
+	teammate := addTestPlayer(e, "teammate", teammateX, teammateY)
+	enemy := addMeleeTestEnemy(e, "enemy", enemyX, enemyY, 10)
+	enemy.ShootDelay = 100
+
+	e.handlePlayerShooting(shooter)
+	for i := 0; i < 10; i++ {
+		tick(e, 50*time.Millisecond)
+	}
+	return teammate, enemy
+}
+
+func TestFriendlyFireBlaster(t *testing.T) {
+	for _, friendlyFire := range []bool{false, true} {
+		teammate, enemy := setUpCrossfire(t, friendlyFire, types.WeaponTypeBlaster, 990, 1080, 990, 1160)
+
+		if hurt := teammate.Lives < config.PlayerLives; hurt != friendlyFire {
+			t.Errorf("friendly fire %v: expected the teammate to be hurt %v, lives %.1f", friendlyFire, friendlyFire, teammate.Lives)
+		}
+		// Without friendly fire the bullet flies through the teammate into the enemy
+		if hurt := enemy.Lives < 10; hurt == friendlyFire {
+			t.Errorf("friendly fire %v: expected the enemy to be hurt %v, lives %.1f", friendlyFire, !friendlyFire, enemy.Lives)
+		}
+	}
+}
+
+func TestFriendlyFireShotgun(t *testing.T) {
+	for _, friendlyFire := range []bool{false, true} {
+		teammate, enemy := setUpCrossfire(t, friendlyFire, types.WeaponTypeShotgun, 990, 1060, 990, 1150)
+
+		if hurt := teammate.Lives < config.PlayerLives; hurt != friendlyFire {
+			t.Errorf("friendly fire %v: expected the teammate to be hurt %v, lives %.1f", friendlyFire, friendlyFire, teammate.Lives)
+		}
+		if enemy.Lives >= 10 {
+			t.Errorf("friendly fire %v: expected the spread to hurt the enemy, lives %.1f", friendlyFire, enemy.Lives)
+		}
+	}
+}
+
+func TestFriendlyFireRocketSplash(t *testing.T) {
+	for _, friendlyFire := range []bool{false, true} {
+		// The teammate stands beside the enemy the rocket hits
+		teammate, enemy := setUpCrossfire(t, friendlyFire, types.WeaponTypeRocketLauncher, 1060, 1150, 990, 1150)
+
+		if hurt := teammate.Lives < config.PlayerLives; hurt != friendlyFire {
+			t.Errorf("friendly fire %v: expected the splash to hurt the teammate %v, lives %.1f", friendlyFire, friendlyFire, teammate.Lives)
+		}
+		if enemy.Lives >= 10 {
+			t.Errorf("friendly fire %v: expected the rocket to hurt the enemy, lives %.1f", friendlyFire, enemy.Lives)
+		}
+	}
+}
+
+func TestFriendlyFireFollowsSession(t *testing.T) {
+	e := newTestEngine(t)
+	if !e.friendlyFire {
+		t.Error("expected friendly fire before a session is loaded")
+	}
+
+	off, on := false, true
+	e.LoadFromSession(&db.GameSession{GameVersion: config.GameVersion, FriendlyFire: &off})
+	if e.friendlyFire {
+		t.Error("expected the session to turn friendly fire off")
+	}
+
+	e.LoadFromSession(&db.GameSession{GameVersion: config.GameVersion, FriendlyFire: &on})
+	if !e.friendlyFire {
+		t.Error("expected the session to turn friendly fire on")
+	}
+
+	e.LoadFromSession(&db.GameSession{GameVersion: config.GameVersion, FriendlyFire: &off})
+	e.LoadFromSession(&db.GameSession{GameVersion: config.GameVersion})
+	if !e.friendlyFire {
+		t.Error("expected a session saved without the setting to keep friendly fire on")
+	}
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestJammedGunFiresNoBullet(t *testing.T) {
+	e := newTestEngine(t)
+	e.gunJamChance = map[string]float64{types.WeaponTypeBlaster: 1}
+
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeBlaster
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 5}
+
+	e.handlePlayerShooting(player)
+
+	if len(e.state.bullets) != 0 {
+		t.Fatalf("expected a jammed gun to fire nothing, got %d bullets", len(e.state.bullets))
+	}
+	if left := player.BulletsLeftByWeaponType[types.WeaponTypeBlaster]; left != 5 {
+		t.Errorf("expected the jammed round to stay in the gun, %d bullets left", left)
+	}
+	if player.ReloadTimer != config.GunJamRecoveryTime {
+		t.Errorf("expected the jam to take %.1fs to clear, got %.1f", config.GunJamRecoveryTime, player.ReloadTimer)
+	}
+
+	if jammed := e.GetGameStateDeltaForPlayer(player.ID).JammedWeapon; jammed != types.WeaponTypeBlaster {
+		t.Fatalf("expected a jam event for the blaster, got %q", jammed)
+	}
+	if jammed := e.GetGameStateDeltaForPlayer(player.ID).JammedWeapon; jammed != "" {
+		t.Errorf("expected the jam event to be sent once, got %q again", jammed)
+	}
+}
+
+func TestGunJamChanceIsPerWeapon(t *testing.T) {
+	e := newTestEngine(t)
+	e.gunJamChance = map[string]float64{types.WeaponTypeShotgun: 1}
+
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeBlaster
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 5}
+
+	e.handlePlayerShooting(player)
+
+	if len(e.state.bullets) != 1 {
+		t.Fatalf("expected the blaster to fire while only the shotgun jams, got %d bullets", len(e.state.bullets))
+	}
+	if jammed := e.GetGameStateDeltaForPlayer(player.ID).JammedWeapon; jammed != "" {
+		t.Errorf("expected no jam event, got %q", jammed)
+	}
+}
+
+func TestGunJamsLeaveTheSeededSourceAlone(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetSeed("jam-seed")
+	e.gunJamChance = map[string]float64{types.WeaponTypeBlaster: 0.5}
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeBlaster
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 5}
+
+	e.handlePlayerShooting(player)
+
+	want := rand.New(rand.NewSource(e.seed)).Int63()
+	if got := e.rng.Int63(); got != want {
+		t.Error("expected rolling for a jam not to draw from the seeded source")
+	}
+}
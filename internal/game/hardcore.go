@@ -0,0 +1,43 @@
+package game
+
+import (
+	"log"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// allPlayersDead reports whether every connected player is dead
+func (e *Engine) allPlayersDead() bool {
+	connected := 0
+	for _, player := range e.state.players {
+		if !player.IsConnected {
+			continue
+		}
+		if player.IsAlive {
+			return false
+		}
+		connected++
+	}
+	return connected > 0
+}
+
+// wipeWorld throws away the dungeon after the whole party died in hardcore mode.
+// Chunks are generated again from a new seed as players respawn, and the next
+// save replaces the stored world with the fresh one.
+func (e *Engine) wipeWorld() {
+	log.Printf("Party wiped out in session %s, starting a fresh dungeon", e.sessionID)
+
+	e.state.bullets = make(map[string]*types.Bullet)
+	e.state.wallsByChunk = make(map[string]map[string]*types.Wall)
+	e.state.enemiesByChunk = make(map[string]map[string]*types.Enemy)
+	e.state.bonuses = make(map[string]*types.Bonus)
+	e.state.shopsByChunk = make(map[string]map[string]*types.Shop)
+	e.chunkHash = make(map[string]bool)
+	e.sightWallsByChunk = make(map[string][]sightWall)
+	e.zoneByChunk = make(map[string]string)
+	e.enemyAggro = make(map[string]enemyAggro)
+	e.currentShopByPlayer = make(map[string]string)
+	e.setSeed(NewSeed())
+
+	e.partyWiped = false
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// newHardcoreTestEngine returns a hardcore engine with two players and a wall in the world
+func newHardcoreTestEngine(t *testing.T) (*Engine, *types.Player, *types.Player) {
+	e := newTestEngine(t)
+	e.hardcore = true
+	e.state.wallsByChunk["0,0"]["wall"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 400}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	return e, addTestPlayer(e, "first", 1000, 1000), addTestPlayer(e, "second", 1200, 1000)
+}
+
+// savedAndLoaded saves the engine to a session and loads it into a new engine
+func savedAndLoaded(t *testing.T, e *Engine) (*Engine, *db.GameSession) {
+	session := &db.GameSession{GameVersion: config.GameVersion, Seed: "original"}
+	e.SaveToSession(session)
+
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+	return loaded, session
+}
+
+func TestPartyWipeRegeneratesWorld(t *testing.T) {
+	e, first, second := newHardcoreTestEngine(t)
+	e.setSeed("original")
+
+	e.killPlayer(first)
+	e.killPlayer(second)
+	tick(e, 100*time.Millisecond)
+
+	loaded, session := savedAndLoaded(t, e)
+	if _, exists := loaded.state.wallsByChunk["0,0"]["wall"]; exists {
+		t.Error("expected the wiped world not to be loaded again")
+	}
+	if session.Seed == "original" || session.Seed == "" {
+		t.Errorf("expected the wiped world to get a new seed, got %q", session.Seed)
+	}
+	if len(loaded.state.players) != 2 {
+		t.Errorf("expected the players to be kept, got %d", len(loaded.state.players))
+	}
+}
+
+func TestPartialDeathKeepsWorld(t *testing.T) {
+	e, first, _ := newHardcoreTestEngine(t)
+	e.setSeed("original")
+
+	e.killPlayer(first)
+	tick(e, 100*time.Millisecond)
+
+	loaded, session := savedAndLoaded(t, e)
+	if _, exists := loaded.state.wallsByChunk["0,0"]["wall"]; !exists {
+		t.Error("expected the world to be kept while a player is alive")
+	}
+	if session.Seed != "original" {
+		t.Errorf("expected the seed to be kept, got %q", session.Seed)
+	}
+}
+
+func TestPartyWipeWithoutHardcoreKeepsWorld(t *testing.T) {
+	e, first, second := newHardcoreTestEngine(t)
+	e.hardcore = false
+
+	e.killPlayer(first)
+	e.killPlayer(second)
+	tick(e, 100*time.Millisecond)
+
+	loaded, _ := savedAndLoaded(t, e)
+	if _, exists := loaded.state.wallsByChunk["0,0"]["wall"]; !exists {
+		t.Error("expected the world to be kept outside hardcore mode")
+	}
+}
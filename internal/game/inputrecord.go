@@ -0,0 +1,59 @@
+package game
+
+import (
+	"sort"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// recordInput keeps the input with the time it came in, until TakeRecordedInputs.
+// Once config.MaxRecordedInputs are waiting, further inputs are only counted.
+func (e *Engine) recordInput(playerID string, input types.InputPayload) {
+	if len(e.recordedInputs) >= config.MaxRecordedInputs {
+		e.droppedInputs++
+		return
+	}
+
+	e.recordedInputs = append(e.recordedInputs, &db.InputRecord{
+		SessionID:        e.sessionID,
+		PlayerID:         playerID,
+		At:               time.Now(),
+		Sequence:         input.Sequence,
+		Forward:          input.Forward,
+		Backward:         input.Backward,
+		Left:             input.Left,
+		Right:            input.Right,
+		Shoot:            input.Shoot,
+		Sprint:           input.Sprint,
+		Reload:           input.Reload,
+		ItemKeys:         pressedKeys(input.ItemKey),
+		PurchaseItemKeys: pressedKeys(input.PurchaseItemKey),
+	})
+}
+
+// pressedKeys lists the keys held down, in order
+func pressedKeys(keys map[int32]bool) []int32 {
+	var pressed []int32
+	for key, down := range keys {
+		if down {
+			pressed = append(pressed, key)
+		}
+	}
+	sort.Slice(pressed, func(i, j int) bool { return pressed[i] < pressed[j] })
+	return pressed
+}
+
+// TakeRecordedInputs returns the inputs recorded since the previous call, and how many were
+// dropped because too many were waiting
+func (e *Engine) TakeRecordedInputs() ([]*db.InputRecord, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	records, dropped := e.recordedInputs, e.droppedInputs
+	e.recordedInputs = nil
+	e.droppedInputs = 0
+	return records, dropped
+}
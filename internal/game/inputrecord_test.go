@@ -0,0 +1,73 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestInputsRecordedWhenEnabled(t *testing.T) {
+	e := newTestEngine(t)
+	e.recordInputs = true
+	before := time.Now()
+
+	e.UpdatePlayerInput("player", types.InputPayload{Forward: true, Shoot: true, Sequence: 7, ItemKey: map[int32]bool{3: true, 1: true, 2: false}})
+	e.UpdatePlayerInput("player", types.InputPayload{Left: true, Sequence: 8})
+
+	records, dropped := e.TakeRecordedInputs()
+	if len(records) != 2 || dropped != 0 {
+		t.Fatalf("expected 2 recorded inputs and none dropped, got %d and %d", len(records), dropped)
+	}
+	first := records[0]
+	if first.PlayerID != "player" || first.Sequence != 7 || !first.Forward || !first.Shoot || first.Left {
+		t.Errorf("expected the first input as sent, got %+v", first)
+	}
+	if len(first.ItemKeys) != 2 || first.ItemKeys[0] != 1 || first.ItemKeys[1] != 3 {
+		t.Errorf("expected the held item keys 1 and 3, got %v", first.ItemKeys)
+	}
+	if first.At.Before(before) || first.At.After(time.Now()) {
+		t.Errorf("expected the input to be stamped with the time it came in, got %v", first.At)
+	}
+
+	if records, _ := e.TakeRecordedInputs(); len(records) != 0 {
+		t.Errorf("expected taken inputs to be cleared, got %d", len(records))
+	}
+}
+
+func TestInputsNotRecordedWhenDisabled(t *testing.T) {
+	e := newTestEngine(t)
+
+	e.UpdatePlayerInput("player", types.InputPayload{Forward: true})
+
+	if records, dropped := e.TakeRecordedInputs(); len(records) != 0 || dropped != 0 {
+		t.Errorf("expected nothing recorded, got %d inputs and %d dropped", len(records), dropped)
+	}
+}
+
+func TestSessionEnablesInputRecording(t *testing.T) {
+	e := newTestEngine(t)
+	e.LoadFromSession(&db.GameSession{GameVersion: config.GameVersion, RecordInputs: true})
+
+	e.UpdatePlayerInput("player", types.InputPayload{Forward: true})
+
+	if records, _ := e.TakeRecordedInputs(); len(records) != 1 {
+		t.Errorf("expected the session to turn recording on, got %d inputs", len(records))
+	}
+}
+
+func TestRecordedInputsAreBounded(t *testing.T) {
+	e := newTestEngine(t)
+	e.recordInputs = true
+
+	for i := 0; i < config.MaxRecordedInputs+5; i++ {
+		e.UpdatePlayerInput("player", types.InputPayload{Sequence: uint32(i)})
+	}
+
+	records, dropped := e.TakeRecordedInputs()
+	if len(records) != config.MaxRecordedInputs || dropped != 5 {
+		t.Errorf("expected %d inputs kept and 5 dropped, got %d and %d", config.MaxRecordedInputs, len(records), dropped)
+	}
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/protocol"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// killCamPose is where an actor stood at one tick
+type killCamPose struct {
+	x, y, rotation float64
+}
+
+func (p killCamPose) toProto() *protocol.PositionUpdate {
+	return &protocol.PositionUpdate{X: p.x, Y: p.y, Rotation: p.rotation}
+}
+
+// killCamBullet is where a bullet flew at one tick, with what a replay needs to draw it
+type killCamBullet struct {
+	id, ownerID, weaponType string
+	x, y                    float64
+	velocity                types.Vector2
+}
+
+func (b killCamBullet) toProto() *protocol.Bullet {
+	return &protocol.Bullet{
+		Id:         b.id,
+		Position:   &protocol.Vector2{X: b.x, Y: b.y},
+		Velocity:   &protocol.Vector2{X: b.velocity.X, Y: b.velocity.Y},
+		OwnerId:    b.ownerID,
+		WeaponType: b.weaponType,
+		IsActive:   true,
+	}
+}
+
+// killCamSample is one tick around a living player. Other actors and bullets are kept
+// by owner because the killer is only known once the player dies.
+type killCamSample struct {
+	at      time.Time
+	player  killCamPose
+	lives   float32
+	actors  map[string]killCamPose
+	bullets []killCamBullet
+}
+
+// killCamBuffer keeps the most recent samples of a player, overwriting the oldest once full
+type killCamBuffer struct {
+	samples []killCamSample
+	start   int
+}
+
+func (b *killCamBuffer) push(sample killCamSample, capacity int) {
+	if len(b.samples) < capacity {
+		b.samples = append(b.samples, sample)
+		return
+	}
+	b.samples[b.start] = sample
+	b.start = (b.start + 1) % len(b.samples)
+}
+
+// ordered returns the samples oldest first
+func (b *killCamBuffer) ordered() []killCamSample {
+	ordered := make([]killCamSample, 0, len(b.samples))
+	ordered = append(ordered, b.samples[b.start:]...)
+	return append(ordered, b.samples[:b.start]...)
+}
+
+// killCamCapacity is how many ticks fit into the kill-cam window, with one to spare for uneven ticks
+func (e *Engine) killCamCapacity() int {
+	return int((e.killCamDuration+e.tickInterval-1)/e.tickInterval) + 1
+}
+
+// killCamBulletChunks buckets the flying bullets by chunk, so each player only looks at the bullets around them
+func (e *Engine) killCamBulletChunks() map[[2]int][]*types.Bullet {
+	bulletChunks := make(map[[2]int][]*types.Bullet)
+	for _, bullet := range e.state.bullets {
+		if bullet.IsActive {
+			chunkX, chunkY := utils.ChunkXYFromPosition(bullet.Position.X, bullet.Position.Y)
+			bulletChunks[[2]int{chunkX, chunkY}] = append(bulletChunks[[2]int{chunkX, chunkY}], bullet)
+		}
+	}
+	return bulletChunks
+}
+
+// sampleKillCam captures the player and whatever could hurt them at this moment
+func (e *Engine) sampleKillCam(player *types.Player, bulletChunks map[[2]int][]*types.Bullet, now time.Time) killCamSample {
+	sample := killCamSample{
+		at:     now,
+		player: killCamPose{player.Position.X, player.Position.Y, player.Rotation},
+		lives:  player.Lives,
+		actors: make(map[string]killCamPose),
+	}
+
+	for _, other := range e.state.players {
+		if other.ID == player.ID || !other.IsAlive || !other.IsConnected {
+			continue
+		}
+		if player.DistanceToPoint(other.Position) <= config.KillCamRadius {
+			sample.actors[other.ID] = killCamPose{other.Position.X, other.Position.Y, other.Rotation}
+		}
+	}
+
+	chunkX, chunkY := utils.ChunkXYFromPosition(player.Position.X, player.Position.Y)
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			for _, enemy := range e.state.enemiesByChunk[fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)] {
+				if enemy.IsAlive && player.DistanceToPoint(enemy.Position) <= config.KillCamRadius {
+					sample.actors[enemy.ID] = killCamPose{enemy.Position.X, enemy.Position.Y, enemy.Rotation}
+				}
+			}
+			// Only a killer's bullets make it into the kill-cam, the player's own never do
+			for _, bullet := range bulletChunks[[2]int{neighborChunkX, neighborChunkY}] {
+				if bullet.OwnerID != player.ID && player.DistanceToPoint(bullet.Position) <= config.KillCamRadius {
+					sample.bullets = append(sample.bullets, killCamBullet{
+						id:         bullet.ID,
+						ownerID:    bullet.OwnerID,
+						weaponType: bullet.WeaponType,
+						x:          bullet.Position.X,
+						y:          bullet.Position.Y,
+						velocity:   *bullet.Velocity,
+					})
+				}
+			}
+		}
+	}
+
+	return sample
+}
+
+// recordKillCamFrames adds this tick to the kill-cam buffer of every living player
+func (e *Engine) recordKillCamFrames(now time.Time) {
+	if e.killCamDuration <= 0 {
+		return
+	}
+
+	capacity := e.killCamCapacity()
+	bulletChunks := e.killCamBulletChunks()
+	for _, player := range e.state.players {
+		if !player.IsAlive || !player.IsConnected {
+			continue
+		}
+
+		buffer, exists := e.killCamFrames[player.ID]
+		if !exists {
+			buffer = &killCamBuffer{}
+			e.killCamFrames[player.ID] = buffer
+		}
+		buffer.push(e.sampleKillCam(player, bulletChunks, now), capacity)
+	}
+}
+
+// captureKillCam turns the recent frames of a dying player into the kill-cam they get with their next delta.
+// Only the killer and the killer's bullets are kept, the last frame is the moment of death.
+func (e *Engine) captureKillCam(player *types.Player, killerID string) {
+	if e.killCamDuration <= 0 {
+		return
+	}
+
+	now := time.Now()
+	var samples []killCamSample
+	if buffer, exists := e.killCamFrames[player.ID]; exists {
+		samples = buffer.ordered()
+	}
+	samples = append(samples, e.sampleKillCam(player, e.killCamBulletChunks(), now))
+	delete(e.killCamFrames, player.ID)
+
+	killCam := &protocol.KillCam{KillerId: killerID}
+	windowStart := now.Add(-e.killCamDuration)
+	for _, sample := range samples {
+		if sample.at.Before(windowStart) {
+			continue
+		}
+
+		frame := &protocol.KillCamFrame{
+			Timestamp: sample.at.UnixMilli(),
+			Player:    sample.player.toProto(),
+			Lives:     sample.lives,
+		}
+		if killer, exists := sample.actors[killerID]; exists && killerID != "" {
+			frame.Killer = killer.toProto()
+		}
+		for _, bullet := range sample.bullets {
+			if killerID != "" && bullet.ownerID == killerID {
+				frame.Bullets = append(frame.Bullets, bullet.toProto())
+			}
+		}
+		killCam.Frames = append(killCam.Frames, frame)
+	}
+
+	e.killCamsByPlayer[player.ID] = killCam
+}
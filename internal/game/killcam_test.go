@@ -0,0 +1,137 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestKillCamIsSentOnceAfterDeath(t *testing.T) {
+	e := newTestEngine(t)
+	e.killCamDuration = time.Second
+
+	victim := addTestPlayer(e, "victim", 1000, 1000)
+	addTestPlayer(e, "killer", 1200, 1000)
+	addTestPlayer(e, "bystander", 1000, 1200)
+
+	for i := 0; i < 5; i++ {
+		tick(e, 50*time.Millisecond)
+	}
+
+	victim.Lives = 0
+	e.finishPlayer(victim, "killer")
+
+	killCam := e.GetGameStateDeltaForPlayer(victim.ID).KillCam
+	if killCam == nil {
+		t.Fatal("expected a kill-cam in the delta after the death")
+	}
+	if killCam.KillerId != "killer" {
+		t.Errorf("expected the kill-cam to name the killer, got %q", killCam.KillerId)
+	}
+	if len(killCam.Frames) != 6 {
+		t.Fatalf("expected 5 recorded frames and the moment of death, got %d", len(killCam.Frames))
+	}
+	for i, frame := range killCam.Frames {
+		if frame.Killer == nil || frame.Killer.X != 1200 {
+			t.Errorf("frame %d: expected the killer at x 1200, got %v", i, frame.Killer)
+		}
+	}
+	if last := killCam.Frames[len(killCam.Frames)-1]; last.Lives != 0 {
+		t.Errorf("expected the last frame to be the moment of death, lives %.1f", last.Lives)
+	}
+
+	if again := e.GetGameStateDeltaForPlayer(victim.ID).KillCam; again != nil {
+		t.Errorf("expected the kill-cam to be sent only once, got it again with %d frames", len(again.Frames))
+	}
+	if _, exists := e.killCamFrames[victim.ID]; exists {
+		t.Error("expected the frames of a dead player to be dropped")
+	}
+}
+
+func TestKillCamKeepsOnlyTheWindow(t *testing.T) {
+	e := newTestEngine(t)
+	e.killCamDuration = 200 * time.Millisecond
+	victim := addTestPlayer(e, "victim", 1000, 1000)
+
+	for i := 0; i < 50; i++ {
+		tick(e, 50*time.Millisecond)
+	}
+	if got, capacity := len(e.killCamFrames[victim.ID].samples), e.killCamCapacity(); got != capacity {
+		t.Fatalf("expected the buffer to stop growing at %d frames, got %d", capacity, got)
+	}
+
+	// Frames older than the window are left out even while they still fit into the buffer
+	for i := range e.killCamFrames[victim.ID].samples {
+		e.killCamFrames[victim.ID].samples[i].at = time.Now().Add(-time.Second)
+	}
+	tick(e, 50*time.Millisecond)
+
+	victim.Lives = 0
+	e.finishPlayer(victim, "")
+
+	killCam := e.GetGameStateDeltaForPlayer(victim.ID).KillCam
+	if killCam == nil || len(killCam.Frames) != 2 {
+		t.Fatalf("expected the last tick and the moment of death, got %v", killCam)
+	}
+	if killCam.Frames[0].Killer != nil {
+		t.Error("expected no killer pose without a killer")
+	}
+}
+
+func TestKillCamKeepsOnlyTheKillersBullets(t *testing.T) {
+	e := newTestEngine(t)
+	e.killCamDuration = time.Second
+	victim := addTestPlayer(e, "victim", 1000, 1000)
+	addTestPlayer(e, "killer", 1400, 1000)
+	for _, ownerID := range []string{"victim", "killer", "bystander"} {
+		e.state.bullets[ownerID+"-bullet"] = &types.Bullet{
+			ScreenObject: types.ScreenObject{ID: ownerID + "-bullet", Position: &types.Vector2{X: 1300, Y: 1000}},
+			Velocity:     &types.Vector2{X: -10, Y: 0},
+			OwnerID:      ownerID,
+			IsActive:     true,
+			WeaponType:   types.WeaponTypeBlaster,
+		}
+	}
+	e.state.bullets["far-bullet"] = &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "far-bullet", Position: &types.Vector2{X: 5000, Y: 1000}},
+		Velocity:     &types.Vector2{X: -10, Y: 0},
+		OwnerID:      "killer",
+		IsActive:     true,
+	}
+
+	e.recordKillCamFrames(time.Now())
+	if bullets := e.killCamFrames[victim.ID].samples[0].bullets; len(bullets) != 2 {
+		t.Errorf("expected the bullets of others around the victim to be recorded, got %d", len(bullets))
+	}
+
+	victim.Lives = 0
+	e.finishPlayer(victim, "killer")
+
+	killCam := e.GetGameStateDeltaForPlayer(victim.ID).KillCam
+	for i, frame := range killCam.Frames {
+		if len(frame.Bullets) != 1 || frame.Bullets[0].Id != "killer-bullet" {
+			t.Fatalf("frame %d: expected only the killer's bullet, got %v", i, frame.Bullets)
+		}
+		if bullet := frame.Bullets[0]; bullet.Position.X != 1300 || bullet.Velocity.X != -10 || bullet.WeaponType != types.WeaponTypeBlaster {
+			t.Errorf("frame %d: expected the bullet where it flew, got %v", i, bullet)
+		}
+	}
+}
+
+func TestKillCamDisabled(t *testing.T) {
+	e := newTestEngine(t)
+	victim := addTestPlayer(e, "victim", 1000, 1000)
+	addTestPlayer(e, "killer", 1200, 1000)
+
+	tick(e, 50*time.Millisecond)
+	victim.Lives = 0
+	e.finishPlayer(victim, "killer")
+
+	if len(e.killCamFrames) != 0 {
+		t.Errorf("expected nothing recorded with the kill-cam disabled, got %d buffers", len(e.killCamFrames))
+	}
+	if killCam := e.GetGameStateDeltaForPlayer(victim.ID).KillCam; killCam != nil {
+		t.Errorf("expected no kill-cam, got %v", killCam)
+	}
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// lockedRoom is the square taken by a generated locked room, with the door its keyholder guards
+type lockedRoom struct {
+	x, y  float64 // top left corner
+	door  *types.Wall
+	walls []*types.Wall
+	chest *types.Bonus
+	// Unit vector pointing out of the room through the door
+	outX, outY float64
+}
+
+// overlaps reports whether the rectangle comes within padding of the room
+func (r *lockedRoom) overlaps(x, y, width, height, padding float64) bool {
+	return utils.CheckRectCollision(
+		r.x-padding, r.y-padding,
+		config.LockedRoomSize+2*padding, config.LockedRoomSize+2*padding,
+		x, y, width, height,
+	)
+}
+
+// generateLockedRoom walls off a square room somewhere in the chunk with a chest inside and a
+// locked door on one side. Returns nil when the room would cover the tower.
+func (e *Engine) generateLockedRoom(chunkKey string, chunkStartX, chunkStartY float64, rng *rand.Rand, towerPosition *types.Vector2) *lockedRoom {
+	margin := config.EnemySoldierSize * 2
+	room := &lockedRoom{
+		x: chunkStartX + margin + rng.Float64()*(config.ChunkSize-config.LockedRoomSize-margin*2),
+		y: chunkStartY + margin + rng.Float64()*(config.ChunkSize-config.LockedRoomSize-margin*2),
+	}
+	doorSide := rng.Intn(4)
+
+	towerRadius := config.EnemyTowerSize / 2
+	if room.overlaps(towerPosition.X-towerRadius, towerPosition.Y-towerRadius, towerRadius*2, towerRadius*2, margin) {
+		return nil
+	}
+
+	size := config.LockedRoomSize
+	sides := []struct {
+		x, y        float64
+		orientation string
+		outX, outY  float64
+	}{
+		{room.x, room.y, "horizontal", 0, -1},
+		{room.x, room.y + size, "horizontal", 0, 1},
+		{room.x, room.y, "vertical", -1, 0},
+		{room.x + size, room.y, "vertical", 1, 0},
+	}
+
+	for i, side := range sides {
+		if i != doorSide {
+			room.walls = append(room.walls, e.addRoomWall(chunkKey, side.x, side.y, size, side.orientation, false))
+			continue
+		}
+
+		// Split the side around the door in its middle
+		segment := (size - config.LockedRoomDoorWidth) / 2
+		alongX, alongY := 1.0, 0.0
+		if side.orientation == "vertical" {
+			alongX, alongY = 0, 1
+		}
+		room.door = e.addRoomWall(chunkKey, side.x+alongX*segment, side.y+alongY*segment, config.LockedRoomDoorWidth, side.orientation, true)
+		room.walls = append(room.walls,
+			e.addRoomWall(chunkKey, side.x, side.y, segment, side.orientation, false),
+			room.door,
+			e.addRoomWall(chunkKey, side.x+alongX*(segment+config.LockedRoomDoorWidth), side.y+alongY*(segment+config.LockedRoomDoorWidth), segment, side.orientation, false),
+		)
+		room.outX, room.outY = side.outX, side.outY
+	}
+
+	chest := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: room.x + size/2, Y: room.y + size/2},
+		},
+		Type: types.BonusTypeChest,
+		Inventory: []types.InventoryItem{
+			{Type: types.InventoryItemMoney, Quantity: config.LockedRoomChestMoney},
+			{Type: types.InventoryItemShotgunAmmo, Quantity: config.LockedRoomChestAmmo},
+			{Type: types.InventoryItemRocket, Quantity: config.LockedRoomChestAmmo},
+			{Type: types.InventoryItemRailgunAmmo, Quantity: config.LockedRoomChestAmmo},
+		},
+	}
+	e.state.bonuses[chest.ID] = chest
+	room.chest = chest
+
+	return room
+}
+
+// removeLockedRoom takes a generated room's walls and chest out of the chunk again
+func (e *Engine) removeLockedRoom(chunkKey string, room *lockedRoom) {
+	for _, wall := range room.walls {
+		delete(e.state.wallsByChunk[chunkKey], wall.ID)
+	}
+	delete(e.state.bonuses, room.chest.ID)
+	e.invalidateSightWalls(chunkKey)
+}
+
+// addRoomWall adds a wall of the given length starting at (x, y), running right for horizontal walls and down for vertical ones
+func (e *Engine) addRoomWall(chunkKey string, x, y, length float64, orientation string, isDoor bool) *types.Wall {
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: x, Y: y},
+		},
+		Width:       length,
+		Height:      e.wallThickness,
+		Orientation: orientation,
+		IsDoor:      isDoor,
+	}
+	if orientation == "vertical" {
+		wall.Width, wall.Height = e.wallThickness, length
+	}
+
+	e.state.wallsByChunk[chunkKey][wall.ID] = wall
+	e.invalidateSightWalls(chunkKey)
+	return wall
+}
+
+// createKeyholder puts the enemy carrying the room's key outside its door, pacing along it
+func (e *Engine) createKeyholder(room *lockedRoom) *types.Enemy {
+	doorCenter := room.door.GetCenter()
+	distance := e.wallThickness/2 + config.EnemySoldierSize/2 + 1
+
+	rotation := 0.0
+	if room.door.Orientation == "vertical" {
+		rotation = 90.0
+	}
+
+	return &types.Enemy{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: doorCenter.X + room.outX*distance, Y: doorCenter.Y + room.outY*distance},
+		},
+		Rotation:  rotation,
+		Lives:     config.EnemyKeyholderLives,
+		WallID:    room.door.ID,
+		Direction: 1,
+		IsAlive:   true,
+		Type:      types.EnemyTypeKeyholder,
+	}
+}
+
+// openLockedDoors opens the doors the player touches while carrying a key, using up one key per door
+func (e *Engine) openLockedDoors(player *types.Player, playerChunkX, playerChunkY int) {
+	if !player.HasInventoryItem(types.InventoryItemKey) {
+		return
+	}
+
+	for neighborChunkX := playerChunkX - 1; neighborChunkX <= playerChunkX+1; neighborChunkX++ {
+		for neighborChunkY := playerChunkY - 1; neighborChunkY <= playerChunkY+1; neighborChunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			walls := e.state.wallsByChunk[chunkKey]
+			for id, wall := range walls {
+				if !wall.IsDoor {
+					continue
+				}
+
+				topLeft := wall.GetTopLeft()
+				if !utils.CheckCircleRectCollision(
+					player.Position.X, player.Position.Y, config.PlayerRadius+config.LockedRoomDoorReach,
+					topLeft.X, topLeft.Y, wall.Width, wall.Height) {
+					continue
+				}
+
+				if !player.UseInventoryItem(types.InventoryItemKey, 1) {
+					return
+				}
+				delete(walls, id)
+				e.invalidateSightWalls(chunkKey)
+				e.releaseEnemiesFromWall(id, neighborChunkX, neighborChunkY)
+			}
+		}
+	}
+}
+
+// spawnKey drops the key to a locked room where the keyholder died
+func (e *Engine) spawnKey(position *types.Vector2) {
+	key := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: position.X, Y: position.Y},
+		},
+		Type:      types.BonusTypeKey,
+		Inventory: []types.InventoryItem{{Type: types.InventoryItemKey, Quantity: 1}},
+	}
+	e.state.bonuses[key.ID] = key
+}
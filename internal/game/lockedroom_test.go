@@ -0,0 +1,154 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addTestLockedRoom builds a locked room in chunk 0,0 away from the tower and the player
+func addTestLockedRoom(t *testing.T, e *Engine) *lockedRoom {
+	t.Helper()
+
+	far := &types.Vector2{X: -5000, Y: -5000}
+	room := e.generateLockedRoom("0,0", 0, 0, rand.New(rand.NewSource(1)), far)
+	if room == nil || room.door == nil {
+		t.Fatal("expected a locked room with a door")
+	}
+	return room
+}
+
+// findChest returns the chest bonus lying inside the room
+func findChest(e *Engine, room *lockedRoom) *types.Bonus {
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypeChest && room.overlaps(bonus.Position.X, bonus.Position.Y, 0, 0, 0) {
+			return bonus
+		}
+	}
+	return nil
+}
+
+// addPlayerAtDoor puts a player right outside the room's door
+func addPlayerAtDoor(e *Engine, room *lockedRoom) *types.Player {
+	doorCenter := room.door.GetCenter()
+	distance := e.wallThickness/2 + config.PlayerRadius + 1
+	return addTestPlayer(e, "player", doorCenter.X+room.outX*distance, doorCenter.Y+room.outY*distance)
+}
+
+func TestLockedRoomGeneration(t *testing.T) {
+	e := newTestEngine(t)
+	e.lockedRoomChance = 1
+
+	// The room is skipped when it would cover the tower, so try a few chunks
+	var chunkKey string
+	var door *types.Wall
+	for chunkX := 5; chunkX < 15 && door == nil; chunkX++ {
+		e.generateChunk(chunkX, 5, &types.Vector2{X: 0, Y: 0})
+		chunkKey = fmt.Sprintf("%d,5", chunkX)
+		for _, wall := range e.state.wallsByChunk[chunkKey] {
+			if wall.IsDoor {
+				door = wall
+			}
+		}
+	}
+	if door == nil {
+		t.Fatal("expected a chunk with a locked room")
+	}
+
+	var keyholder *types.Enemy
+	for _, enemy := range e.state.enemiesByChunk[chunkKey] {
+		if enemy.Type == types.EnemyTypeKeyholder {
+			keyholder = enemy
+		}
+	}
+	if keyholder == nil || keyholder.WallID != door.ID {
+		t.Fatalf("expected a keyholder guarding the door, got %+v", keyholder)
+	}
+
+	chests := 0
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypeChest {
+			chests++
+			if dist := bonus.DistanceToPoint(door.GetCenter()); dist > config.LockedRoomSize {
+				t.Errorf("expected the chest inside the room, %.1f away from the door", dist)
+			}
+		}
+	}
+	if chests != 1 {
+		t.Errorf("expected one chest in the room, got %d", chests)
+	}
+}
+
+func TestLockedDoorOpensWithKey(t *testing.T) {
+	e := newTestEngine(t)
+	room := addTestLockedRoom(t, e)
+	player := addPlayerAtDoor(e, room)
+
+	tick(e, 100*time.Millisecond)
+	if _, exists := e.state.wallsByChunk["0,0"][room.door.ID]; !exists {
+		t.Fatal("expected the door to stay locked without a key")
+	}
+
+	player.AddInventoryItem(types.InventoryItemKey, 1)
+	tick(e, 100*time.Millisecond)
+
+	if _, exists := e.state.wallsByChunk["0,0"][room.door.ID]; exists {
+		t.Error("expected the door to open for a player carrying a key")
+	}
+	if player.HasInventoryItem(types.InventoryItemKey) {
+		t.Error("expected opening the door to use up the key")
+	}
+}
+
+func TestKeyholderDropsKey(t *testing.T) {
+	e := newTestEngine(t)
+	room := addTestLockedRoom(t, e)
+	keyholder := e.createKeyholder(room)
+	e.state.enemiesByChunk["0,0"][keyholder.ID] = keyholder
+
+	e.killEnemy(keyholder, "0,0")
+	e.spawnBonus(keyholder)
+
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypeKey {
+			if len(bonus.Inventory) != 1 || bonus.Inventory[0].Type != types.InventoryItemKey {
+				t.Errorf("expected the key bonus to hold a key, got %+v", bonus.Inventory)
+			}
+			return
+		}
+	}
+	t.Error("expected the keyholder to drop a key")
+}
+
+func TestLockedRoomIsSaved(t *testing.T) {
+	e := newTestEngine(t)
+	room := addTestLockedRoom(t, e)
+	chest := findChest(e, room)
+	if chest == nil {
+		t.Fatal("expected a chest in the room")
+	}
+	player := addTestPlayer(e, "player", 0, 0)
+	player.AddInventoryItem(types.InventoryItemKey, 1)
+
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+
+	if door := loaded.state.wallsByChunk["0,0"][room.door.ID]; door == nil || !door.IsDoor {
+		t.Errorf("expected the door to be loaded, got %+v", door)
+	}
+	loadedChest := loaded.state.bonuses[chest.ID]
+	if loadedChest == nil || len(loadedChest.Inventory) != len(chest.Inventory) {
+		t.Fatalf("expected the chest to keep its loot, got %+v", loadedChest)
+	}
+	if !loaded.state.players["player"].HasInventoryItem(types.InventoryItemKey) {
+		t.Error("expected the player to keep their key")
+	}
+}
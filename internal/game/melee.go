@@ -0,0 +1,105 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// handlePlayerMelee swings the player's melee weapon, hurting every enemy and player within
+// config.MeleeRange in a config.MeleeArcDegrees arc in front of them, unless a wall is in the way
+func (e *Engine) handlePlayerMelee(player *types.Player) {
+	shootDelay := types.ShootDelayByWeaponType[player.SelectedGunType] * player.ShootDelayMultiplier(e.powerUps)
+	if player.ReloadTimer > 0 || time.Since(player.LastShotAt).Seconds() < shootDelay {
+		return
+	}
+	player.LastShotAt = time.Now()
+
+	// A swing hurts like a bullet that has already arrived, so kills are rewarded the same way
+	swing := &types.Bullet{
+		OwnerID:    player.ID,
+		Damage:     types.DamageByWeaponType[player.SelectedGunType] * player.DamageMultiplier(e.powerUps),
+		WeaponType: player.SelectedGunType,
+	}
+
+	for _, other := range e.state.players {
+		if !e.friendlyFire || other.ID == player.ID || !other.IsConnected || !other.IsAlive || other.InvulnerableTimer > 0 {
+			continue
+		}
+		if e.inMeleeReach(player, other.Position, config.PlayerRadius) {
+			e.applyBulletHitToPlayer(swing, other)
+		}
+	}
+
+	playerChunkX, playerChunkY := utils.ChunkXYFromPosition(player.Position.X, player.Position.Y)
+	for chunkX := playerChunkX - 1; chunkX <= playerChunkX+1; chunkX++ {
+		for chunkY := playerChunkY - 1; chunkY <= playerChunkY+1; chunkY++ {
+			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+			for _, enemy := range e.state.enemiesByChunk[chunkKey] {
+				if enemy.IsAlive && e.inMeleeReach(player, enemy.Position, enemy.Size()/2) {
+					e.applyBulletHitToEnemy(swing, enemy, chunkKey)
+				}
+			}
+		}
+	}
+}
+
+// inMeleeReach reports whether a target of the given radius is within the player's swing:
+// close enough, inside the arc in front of the player and not behind a wall
+func (e *Engine) inMeleeReach(player *types.Player, target *types.Vector2, targetRadius float64) bool {
+	dx, dy := target.X-player.Position.X, target.Y-player.Position.Y
+	distance := math.Hypot(dx, dy)
+	if distance > config.PlayerRadius+config.MeleeRange+targetRadius {
+		return false
+	}
+
+	// Players face (-sin, cos) of their rotation
+	if distance > 0 {
+		rotationRad := player.Rotation * math.Pi / 180.0
+		cosAngle := (-math.Sin(rotationRad)*dx + math.Cos(rotationRad)*dy) / distance
+		if cosAngle < math.Cos(config.MeleeArcDegrees/2*math.Pi/180.0) {
+			return false
+		}
+	}
+
+	return !e.isWallBetween(player.Position, target)
+}
+
+// isWallBetween reports whether a wall around from crosses the line to to
+func (e *Engine) isWallBetween(from, to *types.Vector2) bool {
+	chunkX, chunkY := utils.ChunkXYFromPosition(from.X, from.Y)
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			for _, wall := range e.state.wallsByChunk[fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)] {
+				topLeft := wall.GetTopLeft()
+				if utils.CheckLineRectCollision(from.X, from.Y, to.X, to.Y, topLeft.X, topLeft.Y, wall.Width, wall.Height) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// handleEnemyMelee hits the player the enemy sees once they are within e.enemyMeleeRange of its edge,
+// every e.enemyMeleeInterval seconds. Players who were just hurt are spared like they are from bullets.
+func (e *Engine) handleEnemyMelee(enemy *types.Enemy, player *types.Player) {
+	if e.enemyMeleeDamage <= 0 || enemy.MeleeDelay > 0 || player.InvulnerableTimer > 0 {
+		return
+	}
+	if enemy.DistanceToPoint(player.Position) > enemy.Size()/2+e.enemyMeleeRange+config.PlayerRadius {
+		return
+	}
+
+	enemy.MeleeDelay = e.enemyMeleeInterval
+	player.Lives -= e.enemyMeleeDamage
+	if player.Lives <= 0 {
+		e.finishPlayer(player, enemy.ID)
+	} else {
+		player.InvulnerableTimer = config.PlayerInvulnerabilityTime
+	}
+}
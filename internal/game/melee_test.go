@@ -0,0 +1,177 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addKnifePlayer puts a player facing +Y with a knife in hand into the engine
+func addKnifePlayer(e *Engine) *types.Player {
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeKnife
+	return player
+}
+
+func addMeleeTestEnemy(e *Engine, id string, x, y float64, lives float32) *types.Enemy {
+	enemy := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: id, Position: &types.Vector2{X: x, Y: y}},
+		Type:         types.EnemyTypeSoldier,
+		Lives:        lives,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][id] = enemy
+	return enemy
+}
+
+func TestKnifeKillsEnemyInFrontAndRewardsPlayer(t *testing.T) {
+	e := newTestEngine(t)
+	player := addKnifePlayer(e)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1060, config.MeleeDamage)
+
+	e.handlePlayerShooting(player)
+
+	if enemy.IsAlive {
+		t.Fatalf("expected the swing to kill the enemy, lives left %.1f", enemy.Lives)
+	}
+	if player.Kills != 1 || player.Money == 0 {
+		t.Errorf("expected the kill to be rewarded, got %d kills and %d money", player.Kills, player.Money)
+	}
+	if len(e.state.bullets) != 0 {
+		t.Errorf("expected a swing not to fire bullets, got %d", len(e.state.bullets))
+	}
+}
+
+func TestKnifeMissesTargetsOutsideItsArcOrReach(t *testing.T) {
+	e := newTestEngine(t)
+	player := addKnifePlayer(e)
+	behind := addMeleeTestEnemy(e, "behind", 1000, 940, 10)
+	beside := addMeleeTestEnemy(e, "beside", 1060, 1000, 10)
+	far := addMeleeTestEnemy(e, "far", 1000, 1000+config.PlayerRadius+config.MeleeRange+100, 10)
+
+	e.handlePlayerShooting(player)
+
+	for _, enemy := range []*types.Enemy{behind, beside, far} {
+		if enemy.Lives != 10 {
+			t.Errorf("expected %s not to be hit, lives %.1f", enemy.ID, enemy.Lives)
+		}
+	}
+}
+
+func TestKnifeDoesNotHitThroughWalls(t *testing.T) {
+	e := newTestEngine(t)
+	player := addKnifePlayer(e)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1060, 10)
+	e.state.wallsByChunk["0,0"]["wall"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 900, Y: 1030}},
+		Width:        200,
+		Height:       config.WallWidth,
+		Orientation:  "horizontal",
+	}
+
+	e.handlePlayerShooting(player)
+
+	if enemy.Lives != 10 {
+		t.Errorf("expected the wall to block the swing, lives %.1f", enemy.Lives)
+	}
+}
+
+func TestKnifeSparesInvulnerablePlayers(t *testing.T) {
+	e := newTestEngine(t)
+	player := addKnifePlayer(e)
+	victim := addTestPlayer(e, "victim", 1000, 1040)
+	victim.InvulnerableTimer = 1
+
+	e.handlePlayerShooting(player)
+
+	if victim.Lives != config.PlayerLives {
+		t.Errorf("expected an invulnerable player not to be hurt, lives %.1f", victim.Lives)
+	}
+
+	victim.InvulnerableTimer = 0
+	player.LastShotAt = time.Time{}
+	e.handlePlayerShooting(player)
+
+	if victim.Lives != config.PlayerLives-config.MeleeDamage {
+		t.Errorf("expected the swing to hurt the player, lives %.1f", victim.Lives)
+	}
+}
+
+func TestKnifeRespectsShootDelay(t *testing.T) {
+	e := newTestEngine(t)
+	player := addKnifePlayer(e)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1060, 10)
+
+	e.handlePlayerShooting(player)
+	e.handlePlayerShooting(player)
+
+	if enemy.Lives != 10-config.MeleeDamage {
+		t.Errorf("expected only one swing to land, lives %.1f", enemy.Lives)
+	}
+}
+
+func TestEnemyHitsPlayerWithinReach(t *testing.T) {
+	e := newTestEngine(t)
+	e.enemyMeleeDamage = 1
+	e.enemyMeleeInterval = 1
+
+	player := addTestPlayer(e, "player", 1000, 1000)
+	enemy := addMeleeTestEnemy(e, "enemy", 1000, 1030, 3)
+	enemy.ShootDelay = 100
+
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives-1 {
+		t.Fatalf("expected the enemy to hit the player once, lives %.1f", player.Lives)
+	}
+
+	player.InvulnerableTimer = 0
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives-1 {
+		t.Errorf("expected the enemy to wait before hitting again, lives %.1f", player.Lives)
+	}
+
+	enemy.MeleeDelay = 0
+	player.InvulnerableTimer = 1
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives-1 {
+		t.Errorf("expected an invulnerable player not to be hit, lives %.1f", player.Lives)
+	}
+
+	player.InvulnerableTimer = 0
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives-2 {
+		t.Errorf("expected the enemy to hit again once ready, lives %.1f", player.Lives)
+	}
+}
+
+func TestEnemyMeleeNeedsReachAndConfig(t *testing.T) {
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	addMeleeTestEnemy(e, "close", 1000, 1030, 3).ShootDelay = 100
+
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives {
+		t.Fatalf("expected no enemy melee while disabled, lives %.1f", player.Lives)
+	}
+
+	e = newTestEngine(t)
+	e.enemyMeleeDamage = 1
+	player = addTestPlayer(e, "player", 1000, 1000)
+	addMeleeTestEnemy(e, "far", 1000, 1200, 3).ShootDelay = 100
+
+	tick(e, 50*time.Millisecond)
+	if player.Lives != config.PlayerLives {
+		t.Errorf("expected an enemy out of reach not to hit, lives %.1f", player.Lives)
+	}
+}
+
+func TestEnemyMeleeFallsBackToDefaults(t *testing.T) {
+	e := newTestEngine(t)
+
+	if e.enemyMeleeInterval != config.EnemyMeleeInterval || e.enemyMeleeRange != config.EnemyMeleeRange {
+		t.Errorf("expected unset melee settings to fall back to %.1f seconds and %.0f, got %.1f and %.0f",
+			float64(config.EnemyMeleeInterval), config.EnemyMeleeRange, e.enemyMeleeInterval, e.enemyMeleeRange)
+	}
+}
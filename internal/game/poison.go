@@ -0,0 +1,42 @@
+package game
+
+import (
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// poisonOnHit poisons the target of a bullet fired from a poisoned weapon
+func (e *Engine) poisonOnHit(poison *types.Poison, bullet *types.Bullet) {
+	if !e.poisonWeapons[bullet.WeaponType] {
+		return
+	}
+	poison.ApplyPoison(bullet.OwnerID, e.poisonDamage, e.poisonDuration)
+}
+
+// updatePoison deals poison damage to players and enemies, the poisoner gets the kill
+func (e *Engine) updatePoison(deltaTime float64) {
+	for _, player := range e.state.players {
+		if !player.IsConnected || !player.IsAlive || player.PoisonTimer <= 0 {
+			continue
+		}
+
+		poisonedBy := player.PoisonedBy
+		player.Lives -= player.TickPoison(deltaTime)
+		if player.Lives <= 0 {
+			e.finishPlayer(player, poisonedBy)
+		}
+	}
+
+	for chunkKey, enemies := range e.state.enemiesByChunk {
+		for _, enemy := range enemies {
+			if !enemy.IsAlive || enemy.PoisonTimer <= 0 {
+				continue
+			}
+
+			poisonedBy := enemy.PoisonedBy
+			enemy.Lives -= enemy.TickPoison(deltaTime)
+			if enemy.Lives <= 0 {
+				e.finishEnemy(enemy, chunkKey, poisonedBy)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func newPoisonTestEngine(t *testing.T) *Engine {
+	e := newTestEngine(t)
+	e.poisonWeapons[types.WeaponTypeBlaster] = true
+	e.poisonDamage = 0.5
+	e.poisonDuration = 3
+	return e
+}
+
+func enemyBlasterBullet(damage float32) *types.Bullet {
+	return &types.Bullet{OwnerID: "enemy", IsEnemy: true, WeaponType: types.WeaponTypeBlaster, Damage: damage}
+}
+
+func TestPoisonHurtsOverTimeAndExpires(t *testing.T) {
+	e := newPoisonTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Lives = 5
+
+	e.applyBulletHitToPlayer(enemyBlasterBullet(1), player)
+	if player.Lives != 4 || player.PoisonTimer != 3 {
+		t.Fatalf("expected the hit to poison the player, got lives %.1f and poison timer %.1f", player.Lives, player.PoisonTimer)
+	}
+
+	e.updatePoison(1)
+	if player.Lives != 3.5 {
+		t.Errorf("expected the poison to take 0.5 lives a second, got lives %.2f", player.Lives)
+	}
+
+	// Only the 2 seconds left of the poison hurt
+	e.updatePoison(5)
+	if player.Lives != 2.5 || player.PoisonTimer != 0 || player.PoisonDamage != 0 {
+		t.Errorf("expected the poison to run out, got lives %.2f, timer %.1f, damage %.1f", player.Lives, player.PoisonTimer, player.PoisonDamage)
+	}
+
+	e.updatePoison(1)
+	if player.Lives != 2.5 {
+		t.Errorf("expected no damage after the poison expired, got lives %.2f", player.Lives)
+	}
+}
+
+func TestPoisonStacksUpToLimit(t *testing.T) {
+	e := newPoisonTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Lives = 100
+
+	e.applyBulletHitToPlayer(enemyBlasterBullet(0), player)
+	player.PoisonTimer = 1
+	e.applyBulletHitToPlayer(enemyBlasterBullet(0), player)
+	if player.PoisonDamage != 1 || player.PoisonTimer != 3 {
+		t.Errorf("expected a second hit to add up and restart the poison, got damage %.1f and timer %.1f", player.PoisonDamage, player.PoisonTimer)
+	}
+
+	for i := 0; i < 5; i++ {
+		e.applyBulletHitToPlayer(enemyBlasterBullet(0), player)
+	}
+	if max := float32(0.5 * config.PoisonMaxStacks); player.PoisonDamage != max {
+		t.Errorf("expected poison damage to stop at %.1f, got %.1f", max, player.PoisonDamage)
+	}
+}
+
+func TestPoisonKillCountsForPoisoner(t *testing.T) {
+	e := newPoisonTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	enemy := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1200, Y: 1000}},
+		Type:         types.EnemyTypeSoldier,
+		Lives:        1,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][enemy.ID] = enemy
+
+	e.applyBulletHitToEnemy(&types.Bullet{OwnerID: player.ID, WeaponType: types.WeaponTypeBlaster, Damage: 0.5}, enemy, "0,0")
+	e.updatePoison(2)
+
+	if enemy.IsAlive {
+		t.Fatalf("expected the poison to kill the enemy, lives left %.2f", enemy.Lives)
+	}
+	if player.Kills != 1 || player.Money == 0 {
+		t.Errorf("expected the poisoner to get the kill, got %d kills and %d money", player.Kills, player.Money)
+	}
+}
+
+func TestOnlyConfiguredWeaponsPoison(t *testing.T) {
+	e := newPoisonTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Lives = 5
+
+	e.applyBulletHitToPlayer(&types.Bullet{OwnerID: "tower", IsEnemy: true, WeaponType: types.WeaponTypeRocketLauncher, Damage: 1}, player)
+	e.updatePoison(1)
+
+	if player.PoisonTimer != 0 || math.Abs(float64(player.Lives)-4) > 1e-6 {
+		t.Errorf("expected rockets not to poison, got poison timer %.1f and lives %.2f", player.PoisonTimer, player.Lives)
+	}
+}
@@ -0,0 +1,216 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// portalLifetime returns the configured time a gatekeeper's portal stays open, falling back to the default
+func portalLifetime(configured time.Duration) time.Duration {
+	if configured <= 0 {
+		return config.PortalLifetime * time.Second
+	}
+	return configured
+}
+
+// isPocketChunk reports whether the chunk belongs to a pocket room rather than the dungeon
+func isPocketChunk(chunkX, chunkY int) bool {
+	return chunkX >= config.PocketChunkX-1 && chunkY >= config.PocketChunkY-1 && chunkY <= config.PocketChunkY+1
+}
+
+// openPortal leaves a portal where the gatekeeper died, leading to a pocket room generated for it
+func (e *Engine) openPortal(position *types.Vector2) {
+	portal := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: position.X, Y: position.Y},
+		},
+		Type:        types.BonusTypePortal,
+		Destination: e.generatePocket(position),
+		ExpiresAt:   time.Now().Add(e.portalLifetime),
+	}
+	e.state.bonuses[portal.ID] = portal
+}
+
+// updatePortal closes the portal once it expires, and otherwise takes the players touching it to its destination.
+// A pocket's way out never closes, the pocket goes away with it once it's left empty and nobody can get in anymore.
+func (e *Engine) updatePortal(portal *types.Bonus) {
+	if !portal.ExpiresAt.IsZero() && time.Now().After(portal.ExpiresAt) {
+		delete(e.state.bonuses, portal.ID)
+		return
+	}
+
+	if chunkX, chunkY := utils.ChunkXYFromPosition(portal.Position.X, portal.Position.Y); isPocketChunk(chunkX, chunkY) {
+		if e.isPocketAbandoned(chunkX, chunkY) {
+			e.removePocket(chunkX, chunkY)
+			return
+		}
+	}
+
+	for _, player := range e.state.players {
+		if !player.IsAlive || !player.IsConnected {
+			continue
+		}
+
+		touching := player.DistanceToPoint(portal.Position) < config.PlayerRadius+config.PortalSize/2
+		// Players coming out of a portal have to step off it before it takes them back
+		if e.portalArrivals[player.ID] == portal.ID {
+			if !touching {
+				delete(e.portalArrivals, player.ID)
+			}
+			continue
+		}
+
+		if touching {
+			e.teleportPlayer(player, portal.Destination)
+		}
+	}
+}
+
+// isPocketAbandoned reports whether no player is in the pocket and no open portal leads into it
+func (e *Engine) isPocketAbandoned(chunkX, chunkY int) bool {
+	inPocket := func(position *types.Vector2) bool {
+		positionChunkX, positionChunkY := utils.ChunkXYFromPosition(position.X, position.Y)
+		return abs(positionChunkX-chunkX) <= 1 && abs(positionChunkY-chunkY) <= 1
+	}
+
+	// Players waiting to reconnect or to respawn still count
+	for _, player := range e.state.players {
+		if inPocket(player.Position) {
+			return false
+		}
+	}
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal && !bonus.ExpiresAt.IsZero() && inPocket(bonus.Destination) {
+			return false
+		}
+	}
+	return true
+}
+
+// removePocket takes the pocket out of the world along with everything left in it, freeing its chunks for the next one
+func (e *Engine) removePocket(chunkX, chunkY int) {
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			for enemyID := range e.state.enemiesByChunk[neighborChunkKey] {
+				delete(e.enemyAggro, enemyID)
+			}
+			delete(e.chunkHash, neighborChunkKey)
+			delete(e.state.wallsByChunk, neighborChunkKey)
+			delete(e.state.enemiesByChunk, neighborChunkKey)
+			delete(e.state.shopsByChunk, neighborChunkKey)
+			delete(e.zoneByChunk, neighborChunkKey)
+			e.invalidateSightWalls(neighborChunkKey)
+		}
+	}
+
+	for _, bonus := range e.state.bonuses {
+		bonusChunkX, bonusChunkY := utils.ChunkXYFromPosition(bonus.Position.X, bonus.Position.Y)
+		if abs(bonusChunkX-chunkX) <= 1 && abs(bonusChunkY-chunkY) <= 1 {
+			delete(e.state.bonuses, bonus.ID)
+		}
+	}
+}
+
+// teleportPlayer moves the player to the destination, remembering the portal they come out on
+func (e *Engine) teleportPlayer(player *types.Player, destination *types.Vector2) {
+	player.Position = &types.Vector2{X: destination.X, Y: destination.Y}
+	delete(e.portalArrivals, player.ID)
+
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal && player.DistanceToPoint(bonus.Position) < config.PlayerRadius+config.PortalSize/2 {
+			e.portalArrivals[player.ID] = bonus.ID
+			return
+		}
+	}
+}
+
+// generatePocket walls off a room in the first free pocket chunk, with chests guarded by lieutenants
+// and a portal back to exit that never closes. Returns the point players arrive at.
+func (e *Engine) generatePocket(exit *types.Vector2) *types.Vector2 {
+	chunkX, chunkY := config.PocketChunkX, config.PocketChunkY
+	for e.chunkHash[fmt.Sprintf("%d,%d", chunkX, chunkY)] {
+		chunkX += 3
+	}
+	chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+
+	// The chunks around the pocket are left empty, so players in it never get a dungeon generated next to them
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			e.chunkHash[neighborChunkKey] = true
+			e.invalidateSightWalls(neighborChunkKey)
+			e.state.wallsByChunk[neighborChunkKey] = orEmpty(e.state.wallsByChunk[neighborChunkKey])
+			e.state.enemiesByChunk[neighborChunkKey] = orEmpty(e.state.enemiesByChunk[neighborChunkKey])
+			e.state.shopsByChunk[neighborChunkKey] = orEmpty(e.state.shopsByChunk[neighborChunkKey])
+		}
+	}
+
+	rng := e.chunkRand(chunkX, chunkY)
+	size := config.PocketSize
+	x := float64(chunkX)*config.ChunkSize + (config.ChunkSize-size)/2
+	y := float64(chunkY)*config.ChunkSize + (config.ChunkSize-size)/2
+
+	e.addRoomWall(chunkKey, x, y, size, "horizontal", false)
+	e.addRoomWall(chunkKey, x, y+size, size, "horizontal", false)
+	e.addRoomWall(chunkKey, x, y, size, "vertical", false)
+	e.addRoomWall(chunkKey, x+size, y, size, "vertical", false)
+
+	// Guards pace along walls across the middle of the room, between the arrival point and the chests
+	for i := 0; i < config.PocketGuards; i++ {
+		wallY := y + size*float64(i+1)/float64(config.PocketGuards+1)
+		wall := e.addRoomWall(chunkKey, x+(size-config.PocketGuardLength)/2, wallY, config.PocketGuardLength, "horizontal", false)
+
+		side := 1.0
+		if rng.Float64() < 0.5 {
+			side = -1.0
+		}
+		guard := &types.Enemy{
+			ScreenObject: types.ScreenObject{
+				ID:       uuid.New().String(),
+				Position: &types.Vector2{X: wall.Position.X + rng.Float64()*wall.Width, Y: wallY - side*(wall.Height/2+config.EnemySoldierSize/2)},
+			},
+			Lives:     config.EnemyLieutenantLives,
+			WallID:    wall.ID,
+			Direction: 1,
+			IsAlive:   true,
+			Type:      types.EnemyTypeLieutenant,
+		}
+		e.state.enemiesByChunk[chunkKey][guard.ID] = guard
+	}
+
+	for i := 0; i < config.PocketChests; i++ {
+		chest := &types.Bonus{
+			ScreenObject: types.ScreenObject{
+				ID:       uuid.New().String(),
+				Position: &types.Vector2{X: x + size - size/6, Y: y + size*float64(i+1)/float64(config.PocketChests+1)},
+			},
+			Type: types.BonusTypeChest,
+			Inventory: []types.InventoryItem{
+				{Type: types.InventoryItemMoney, Quantity: config.PocketChestMoney},
+				{Type: types.InventoryItemShotgunAmmo, Quantity: config.PocketChestAmmo},
+				{Type: types.InventoryItemRocket, Quantity: config.PocketChestAmmo},
+				{Type: types.InventoryItemRailgunAmmo, Quantity: config.PocketChestAmmo},
+			},
+		}
+		e.state.bonuses[chest.ID] = chest
+	}
+
+	exitPortal := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: x + size/12, Y: y + size/12},
+		},
+		Type:        types.BonusTypePortal,
+		Destination: &types.Vector2{X: exit.X, Y: exit.Y},
+	}
+	e.state.bonuses[exitPortal.ID] = exitPortal
+
+	return &types.Vector2{X: x + size/6, Y: y + size/2}
+}
@@ -0,0 +1,217 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// killGatekeeper puts a gatekeeper into the engine and kills it, returning the portal it leaves
+func killGatekeeper(t *testing.T, e *Engine, x, y float64) *types.Bonus {
+	t.Helper()
+	gatekeeper := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "gatekeeper", Position: &types.Vector2{X: x, Y: y}},
+		Type:         types.EnemyTypeGatekeeper,
+		Lives:        config.EnemyGatekeeperLives,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][gatekeeper.ID] = gatekeeper
+	e.finishEnemy(gatekeeper, "0,0", "")
+
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal && bonus.Position.X == x && bonus.Position.Y == y {
+			return bonus
+		}
+	}
+	t.Fatal("expected the gatekeeper to leave a portal")
+	return nil
+}
+
+// findExitPortal returns the portal leading back to the given point
+func findExitPortal(t *testing.T, e *Engine, to *types.Vector2) *types.Bonus {
+	t.Helper()
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal && bonus.Destination.X == to.X && bonus.Destination.Y == to.Y {
+			return bonus
+		}
+	}
+	t.Fatal("expected a portal out of the pocket")
+	return nil
+}
+
+func updatePortals(e *Engine) {
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypePortal {
+			e.updatePortal(bonus)
+		}
+	}
+}
+
+func TestGatekeeperOpensPortalToPocket(t *testing.T) {
+	e := newTestEngine(t)
+	portal := killGatekeeper(t, e, 1000, 1000)
+
+	if portal.ExpiresAt.Sub(time.Now()) <= 0 || portal.ExpiresAt.Sub(time.Now()) > config.PortalLifetime*time.Second {
+		t.Errorf("expected the portal to close in %d seconds, closes at %v", config.PortalLifetime, portal.ExpiresAt)
+	}
+
+	chunkX, chunkY := utils.ChunkXYFromPosition(portal.Destination.X, portal.Destination.Y)
+	if chunkX != config.PocketChunkX || chunkY != config.PocketChunkY {
+		t.Fatalf("expected the portal to lead to the first pocket, got chunk %d,%d", chunkX, chunkY)
+	}
+
+	chunkKey := "100000,100000"
+	if walls := len(e.state.wallsByChunk[chunkKey]); walls != 4+config.PocketGuards {
+		t.Errorf("expected the pocket to be walled in with a wall per guard, got %d walls", walls)
+	}
+	if guards := len(e.state.enemiesByChunk[chunkKey]); guards != config.PocketGuards {
+		t.Errorf("expected %d guards, got %d", config.PocketGuards, guards)
+	}
+	chests := 0
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type == types.BonusTypeChest {
+			chests++
+		}
+	}
+	if chests != config.PocketChests {
+		t.Errorf("expected %d chests, got %d", config.PocketChests, chests)
+	}
+
+	exit := findExitPortal(t, e, portal.Position)
+	if !exit.ExpiresAt.IsZero() {
+		t.Error("expected the way out of the pocket never to close")
+	}
+
+	// The next pocket goes next to the first one
+	second := killGatekeeper(t, e, 1500, 1000)
+	if secondChunkX, _ := utils.ChunkXYFromPosition(second.Destination.X, second.Destination.Y); secondChunkX == chunkX {
+		t.Error("expected a second pocket in its own chunk")
+	}
+}
+
+func TestPortalTakesPlayersToPocketAndBack(t *testing.T) {
+	e := newTestEngine(t)
+	portal := killGatekeeper(t, e, 1000, 1000)
+	player := addTestPlayer(e, "player", 1000, 1010)
+
+	updatePortals(e)
+	if player.Position.X != portal.Destination.X || player.Position.Y != portal.Destination.Y {
+		t.Fatalf("expected the player to be taken to the pocket, got %v", player.Position)
+	}
+
+	exit := findExitPortal(t, e, portal.Position)
+	player.Position = &types.Vector2{X: exit.Position.X, Y: exit.Position.Y}
+	updatePortals(e)
+	updatePortals(e)
+	if player.Position.X != portal.Position.X || player.Position.Y != portal.Position.Y {
+		t.Fatalf("expected the player to come out on the portal they entered, got %v", player.Position)
+	}
+
+	// Stepping off and back on takes the player in again
+	player.Position = &types.Vector2{X: 1000, Y: 1200}
+	updatePortals(e)
+	player.Position = &types.Vector2{X: 1000, Y: 1000}
+	updatePortals(e)
+	if player.Position.X != portal.Destination.X || player.Position.Y != portal.Destination.Y {
+		t.Errorf("expected the portal to work again once stepped off, got %v", player.Position)
+	}
+}
+
+func TestPortalClosesWhenExpired(t *testing.T) {
+	e := newTestEngine(t)
+	portal := killGatekeeper(t, e, 1000, 1000)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	insider := addTestPlayer(e, "insider", portal.Destination.X, portal.Destination.Y)
+	portal.ExpiresAt = time.Now().Add(-time.Second)
+
+	updatePortals(e)
+
+	if _, exists := e.state.bonuses[portal.ID]; exists {
+		t.Error("expected the expired portal to be removed")
+	}
+	if player.Position.X != 1000 || player.Position.Y != 1000 {
+		t.Errorf("expected a closed portal not to move the player, got %v", player.Position)
+	}
+	// Players still in the pocket keep their way out
+	findExitPortal(t, e, portal.Position)
+	if insider.Position.X != portal.Destination.X || insider.Position.Y != portal.Destination.Y {
+		t.Errorf("expected the player in the pocket to stay there, got %v", insider.Position)
+	}
+}
+
+func TestAbandonedPocketIsRemoved(t *testing.T) {
+	e := newTestEngine(t)
+	portal := killGatekeeper(t, e, 1000, 1000)
+	player := addTestPlayer(e, "player", 1000, 1010)
+	updatePortals(e)
+	portal.ExpiresAt = time.Now().Add(-time.Second)
+
+	// The player is still inside when the portal closes
+	updatePortals(e)
+	exit := findExitPortal(t, e, portal.Position)
+
+	player.Position = &types.Vector2{X: exit.Position.X, Y: exit.Position.Y}
+	updatePortals(e)
+	updatePortals(e)
+	if player.Position.X != portal.Position.X || player.Position.Y != portal.Position.Y {
+		t.Fatalf("expected the player to leave the pocket, got %v", player.Position)
+	}
+
+	pocketChunkKey := "100000,100000"
+	if e.chunkHash[pocketChunkKey] || len(e.state.wallsByChunk[pocketChunkKey]) > 0 || len(e.state.enemiesByChunk[pocketChunkKey]) > 0 {
+		t.Error("expected the empty pocket to be removed")
+	}
+	for _, bonus := range e.state.bonuses {
+		if chunkX, chunkY := utils.ChunkXYFromPosition(bonus.Position.X, bonus.Position.Y); isPocketChunk(chunkX, chunkY) {
+			t.Errorf("expected the pocket's %s to be removed along with it", bonus.Type)
+		}
+	}
+
+	// Removed pockets aren't saved, and their chunks go to the next one
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+	if _, exists := session.WorldMap[pocketChunkKey]; exists {
+		t.Error("expected the removed pocket not to be saved")
+	}
+	next := killGatekeeper(t, e, 1500, 1000)
+	if chunkX, _ := utils.ChunkXYFromPosition(next.Destination.X, next.Destination.Y); chunkX != config.PocketChunkX {
+		t.Errorf("expected the next pocket to take the free chunk, got chunk %d", chunkX)
+	}
+}
+
+func TestNewPlayersDoNotStartInPockets(t *testing.T) {
+	e := newTestEngine(t)
+	e.chunkHash = make(map[string]bool)
+	e.generatePocket(&types.Vector2{X: 1000, Y: 1000})
+
+	for i := 0; i < 20; i++ {
+		player := e.ConnectPlayer("player", "player")
+		if chunkX, chunkY := utils.ChunkXYFromPosition(player.Position.X, player.Position.Y); isPocketChunk(chunkX, chunkY) {
+			t.Fatalf("expected the player to start in the dungeon, got chunk %d,%d", chunkX, chunkY)
+		}
+		delete(e.state.players, "player")
+	}
+}
+
+func TestPortalSurvivesSessionReload(t *testing.T) {
+	e := newTestEngine(t)
+	portal := killGatekeeper(t, e, 1000, 1000)
+
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+
+	loadedPortal, exists := loaded.state.bonuses[portal.ID]
+	if !exists {
+		t.Fatal("expected the portal to be saved")
+	}
+	if *loadedPortal.Destination != *portal.Destination || loadedPortal.ExpiresAt.Unix() != portal.ExpiresAt.Unix() {
+		t.Errorf("expected the portal to keep its destination and closing time, got %v and %v", loadedPortal.Destination, loadedPortal.ExpiresAt)
+	}
+	findExitPortal(t, loaded, portal.Position)
+}
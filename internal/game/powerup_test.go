@@ -0,0 +1,149 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestPowerUpsArePickedUpAndExpire(t *testing.T) {
+	powerUps := []struct {
+		bonusType string
+		duration  float64
+		timer     func(p *types.Player) float64
+	}{
+		{types.BonusTypeDoubleDamage, config.PowerUpTime, func(p *types.Player) float64 { return p.DoubleDamageTimer }},
+		{types.BonusTypeRapidFire, config.PowerUpTime, func(p *types.Player) float64 { return p.RapidFireTimer }},
+		{types.BonusTypeSpeedBoost, config.PowerUpTime, func(p *types.Player) float64 { return p.SpeedBoostTimer }},
+	}
+
+	for _, powerUp := range powerUps {
+		e := newTestEngine(t)
+		player := addTestPlayer(e, "player", 1000, 1000)
+		e.state.bonuses["power-up"] = &types.Bonus{
+			ScreenObject: types.ScreenObject{ID: "power-up", Position: &types.Vector2{X: 1000, Y: 1000}},
+			Type:         powerUp.bonusType,
+		}
+
+		tick(e, 100*time.Millisecond)
+
+		if e.state.bonuses["power-up"].PickedUpBy != player.ID {
+			t.Fatalf("expected %s to be picked up", powerUp.bonusType)
+		}
+		if timer := powerUp.timer(player); timer < powerUp.duration-0.1 || timer > powerUp.duration {
+			t.Errorf("expected %s to last %.1f seconds after pickup, got %.2f", powerUp.bonusType, powerUp.duration, timer)
+		}
+
+		for elapsed := 0.0; elapsed <= powerUp.duration; elapsed += 0.1 {
+			tick(e, 100*time.Millisecond)
+		}
+
+		if timer := powerUp.timer(player); timer != 0 {
+			t.Errorf("expected %s to expire after %.1f seconds, %.2f left", powerUp.bonusType, powerUp.duration, timer)
+		}
+	}
+}
+
+func TestPowerUpMultipliers(t *testing.T) {
+	shoot := func(e *Engine, player *types.Player, sinceLastShot time.Duration) *types.Bullet {
+		clear(e.state.bullets)
+		player.LastShotAt = time.Now().Add(-sinceLastShot)
+		e.handlePlayerShooting(player)
+		for _, bullet := range e.state.bullets {
+			return bullet
+		}
+		return nil
+	}
+
+	e := newTestEngine(t)
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeBlaster
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: config.BlasterMaxBullets}
+
+	plain := shoot(e, player, time.Second)
+	if plain == nil {
+		t.Fatal("expected the blaster to fire")
+	}
+	halfDelay := time.Duration(config.BlasterShootDelay * 0.6 * float64(time.Second))
+	if shoot(e, player, halfDelay) != nil {
+		t.Fatal("expected the blaster not to fire again before its shoot delay")
+	}
+
+	player.DoubleDamageTimer = config.PowerUpTime
+	player.RapidFireTimer = config.PowerUpTime
+	boosted := shoot(e, player, halfDelay)
+	if boosted == nil {
+		t.Fatal("expected rapid fire to shorten the shoot delay")
+	}
+	if boosted.Damage != plain.Damage*config.PowerUpDamageMultiplier {
+		t.Errorf("expected double damage bullets to deal %.1f, got %.1f", plain.Damage*config.PowerUpDamageMultiplier, boosted.Damage)
+	}
+
+	walker := newTestEngine(t)
+	walkingPlayer := addTestPlayer(walker, "player", 1000, 1000)
+	walker.playerInputState["player"] = &types.InputPayload{Forward: true}
+
+	booster := newTestEngine(t)
+	boostedPlayer := addTestPlayer(booster, "player", 1000, 1000)
+	boostedPlayer.SpeedBoostTimer = config.PowerUpTime
+	booster.playerInputState["player"] = &types.InputPayload{Forward: true}
+
+	tick(walker, 100*time.Millisecond)
+	tick(booster, 100*time.Millisecond)
+
+	walked := walkingPlayer.Position.Y - 1000
+	boostedWalk := boostedPlayer.Position.Y - 1000
+	if walked <= 0 || boostedWalk < walked*config.PowerUpSpeedMultiplier*0.99 {
+		t.Errorf("expected the speed boost to cover %.1fx the distance, walked %.1f, boosted %.1f", config.PowerUpSpeedMultiplier, walked, boostedWalk)
+	}
+}
+
+func TestSpawnedPowerUpIsATimedPowerUp(t *testing.T) {
+	e := newTestEngine(t)
+
+	for i := 0; i < 20; i++ {
+		e.spawnPowerUp(&types.Vector2{X: 1000, Y: 1000})
+	}
+
+	for _, bonus := range e.state.bonuses {
+		if !bonus.IsPowerUp() {
+			t.Errorf("expected only timed power-ups to be dropped, got %s", bonus.Type)
+		}
+	}
+}
+
+func TestPowerUpsFollowConfig(t *testing.T) {
+	config.AppConfig = &config.Config{
+		PowerUpDropChance:       1,
+		PowerUpDamageChance:     1,
+		PowerUpDuration:         2 * time.Second,
+		PowerUpDamageMultiplier: 3,
+	}
+	e := NewEngine("power-ups")
+	player := addTestPlayer(e, "player", 1000, 1000)
+	lieutenant := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "lieutenant", Position: &types.Vector2{X: 1000, Y: 1000}},
+		Type:         types.EnemyTypeLieutenant,
+	}
+
+	e.spawnBonus(lieutenant)
+
+	if len(e.state.bonuses) != 1 {
+		t.Fatalf("expected the lieutenant to drop a single bonus, got %d", len(e.state.bonuses))
+	}
+	for _, bonus := range e.state.bonuses {
+		if bonus.Type != types.BonusTypeDoubleDamage {
+			t.Fatalf("expected every dropped power-up to grant double damage, got %s", bonus.Type)
+		}
+		player.PickupBonus(bonus, e.powerUps)
+	}
+
+	if player.DoubleDamageTimer != 2 {
+		t.Errorf("expected the power-up to last the configured 2 seconds, got %.2f", player.DoubleDamageTimer)
+	}
+	if multiplier := player.DamageMultiplier(e.powerUps); multiplier != 3 {
+		t.Errorf("expected double damage to multiply damage by the configured 3, got %.1f", multiplier)
+	}
+}
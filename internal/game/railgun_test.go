@@ -0,0 +1,124 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// fireRailgunThroughLine lines up three soldiers in front of a player and fires a
+// railgun beam through all of them, with the walls standing in the way
+func fireRailgunThroughLine(t *testing.T, penetration int, walls ...*types.Wall) (*types.Bullet, []*types.Enemy) {
+	e := newTestEngine(t)
+	e.railgunPenetration = penetration
+	player := addTestPlayer(e, "player", 1000, 1000)
+	for _, wall := range walls {
+		e.state.wallsByChunk["0,0"][wall.ID] = wall
+	}
+
+	// Added farthest first so the hits aren't found in path order
+	enemies := make([]*types.Enemy, 3)
+	for i := len(enemies) - 1; i >= 0; i-- {
+		enemies[i] = &types.Enemy{
+			ScreenObject: types.ScreenObject{ID: fmt.Sprintf("enemy-%d", i), Position: &types.Vector2{X: 1000, Y: 1100 + float64(i)*100}},
+			Type:         types.EnemyTypeSoldier,
+			Lives:        config.RailgunDamage * 2,
+			IsAlive:      true,
+		}
+		e.state.enemiesByChunk["0,0"][enemies[i].ID] = enemies[i]
+	}
+
+	bullet := &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "beam", Position: &types.Vector2{X: player.Position.X, Y: player.Position.Y}},
+		Velocity:     &types.Vector2{X: 0, Y: 500},
+		OwnerID:      player.ID,
+		Damage:       config.RailgunDamage,
+		WeaponType:   types.WeaponTypeRailgun,
+	}
+	e.applyBulletDamage(bullet, &types.Vector2{X: bullet.Position.X + bullet.Velocity.X, Y: bullet.Position.Y + bullet.Velocity.Y})
+
+	return bullet, enemies
+}
+
+func TestRailgunPenetrationDamagesNearestTargets(t *testing.T) {
+	bullet, enemies := fireRailgunThroughLine(t, 2)
+
+	for i, enemy := range enemies {
+		damaged := enemy.Lives < config.RailgunDamage*2
+		if damaged != (i < 2) {
+			t.Errorf("enemy %d: damaged = %v, lives %.1f", i, damaged, enemy.Lives)
+		}
+	}
+
+	// The beam is drawn up to the last enemy it pierced
+	if bullet.Velocity.Y >= enemies[2].Position.Y-bullet.Position.Y-enemies[2].Size()/2 {
+		t.Errorf("expected the beam to stop before the third enemy, got length %.1f", bullet.Velocity.Y)
+	}
+}
+
+func TestRailgunWithoutPenetrationLimitHitsEverything(t *testing.T) {
+	bullet, enemies := fireRailgunThroughLine(t, 0)
+
+	for i, enemy := range enemies {
+		if enemy.Lives >= config.RailgunDamage*2 {
+			t.Errorf("expected enemy %d to be damaged", i)
+		}
+	}
+	if bullet.Velocity.Y != 500 {
+		t.Errorf("expected the beam length to be unchanged, got %.1f", bullet.Velocity.Y)
+	}
+}
+
+func TestRailgunStopsAtWallBetweenTargets(t *testing.T) {
+	// Between the first and the second enemy
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 950, Y: 1150}},
+		Width:        100,
+		Height:       config.WallWidth,
+		Orientation:  "horizontal",
+	}
+
+	for _, penetration := range []int{0, 2} {
+		bullet, enemies := fireRailgunThroughLine(t, penetration, wall)
+
+		for i, enemy := range enemies {
+			damaged := enemy.Lives < config.RailgunDamage*2
+			if damaged != (i == 0) {
+				t.Errorf("penetration %d, enemy %d: damaged = %v, lives %.1f", penetration, i, damaged, enemy.Lives)
+			}
+		}
+		if bullet.Velocity.Y > wall.Position.Y-bullet.Position.Y {
+			t.Errorf("penetration %d: expected the beam to end at the wall, got length %.1f", penetration, bullet.Velocity.Y)
+		}
+	}
+}
+
+func TestRailgunRangeIsSeparateFromSight(t *testing.T) {
+	e := newTestEngine(t)
+	e.railgunRange = 500
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.SelectedGunType = types.WeaponTypeRailgun
+	player.Inventory = append(player.Inventory, types.InventoryItem{Type: types.InventoryItemRailgunAmmo, Quantity: 1})
+
+	e.handlePlayerShooting(player)
+
+	var beam *types.Bullet
+	for _, bullet := range e.state.bullets {
+		beam = bullet
+	}
+	if beam == nil {
+		t.Fatal("expected the railgun to fire")
+	}
+	if length := math.Hypot(beam.Velocity.X, beam.Velocity.Y); math.Abs(length-500) > 1e-6 {
+		t.Errorf("expected a beam as long as the railgun range, got %.1f", length)
+	}
+
+	// Players still see as far as before
+	observer := addTestPlayer(e, "observer", 1000+config.SightRadius-10, 1000)
+	if !player.IsVisibleToPlayer(observer) {
+		t.Error("expected players within sight radius to stay visible with a short railgun")
+	}
+}
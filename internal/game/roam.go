@@ -0,0 +1,50 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// releaseEnemiesFromWall lets go of a wall that is gone for the enemies patrolling it. Guards
+// keep to their other wall, enemies left with no wall at all become roamers.
+func (e *Engine) releaseEnemiesFromWall(wallID string, chunkX, chunkY int) {
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			for _, enemy := range e.state.enemiesByChunk[fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)] {
+				if enemy.SecondWallID == wallID {
+					enemy.SecondWallID = ""
+				}
+				if enemy.WallID == wallID {
+					enemy.WallID, enemy.SecondWallID = enemy.SecondWallID, ""
+					enemy.Roaming = enemy.WallID == ""
+				}
+			}
+		}
+	}
+}
+
+// roamEnemy walks an enemy with no wall to patrol the way it is facing, steering around walls
+// and turning somewhere else when it gets stuck. Like fleeing enemies, roamers aren't moved
+// between chunks, so they turn back at the edge of theirs.
+func (e *Engine) roamEnemy(enemy *types.Enemy, chunkKey string, deltaTime float64) {
+	heading := enemy.Rotation * math.Pi / 180
+	step := enemy.Speed() * deltaTime
+	dx, dy, canMove := e.steerEnemy(enemy, -math.Sin(heading)*step, math.Cos(heading)*step, e.enemyAwarenessRadius)
+
+	if canMove {
+		chunkX, chunkY := utils.ChunkXYFromPosition(enemy.Position.X+dx, enemy.Position.Y+dy)
+		canMove = fmt.Sprintf("%d,%d", chunkX, chunkY) == chunkKey
+	}
+	if !canMove {
+		enemy.Rotation = utils.NormalizeRotation(enemy.Rotation + 90 + rand.Float64()*180)
+		return
+	}
+
+	enemy.Rotation = utils.RotationTowards(dx, dy)
+	enemy.Position.X += dx
+	enemy.Position.Y += dy
+}
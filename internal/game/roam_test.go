@@ -0,0 +1,64 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestKeyholderRoamsOnceItsDoorIsOpened(t *testing.T) {
+	e := newTestEngine(t)
+	room := addTestLockedRoom(t, e)
+	keyholder := e.createKeyholder(room)
+	e.state.enemiesByChunk["0,0"][keyholder.ID] = keyholder
+	player := addPlayerAtDoor(e, room)
+	player.AddInventoryItem(types.InventoryItemKey, 1)
+
+	tick(e, 100*time.Millisecond)
+
+	if _, exists := e.state.wallsByChunk["0,0"][room.door.ID]; exists {
+		t.Fatal("expected the door to open for a player carrying a key")
+	}
+	if !keyholder.Roaming || keyholder.WallID != "" {
+		t.Fatalf("expected the keyholder to roam once its door is gone, roaming %v, wall %q", keyholder.Roaming, keyholder.WallID)
+	}
+
+	// Step out of the keyholder's sight but stay close enough for it to keep moving
+	player.Position = &types.Vector2{X: keyholder.Position.X - room.outX*600, Y: keyholder.Position.Y - room.outY*600}
+	start := *keyholder.Position
+	for i := 0; i < 10; i++ {
+		tick(e, 100*time.Millisecond)
+	}
+
+	if !keyholder.IsAlive {
+		t.Fatal("expected the keyholder to stay alive")
+	}
+	if *keyholder.Position == start {
+		t.Error("expected the roaming keyholder to walk around instead of freezing in place")
+	}
+}
+
+func TestReleasingWallKeepsGuardsOtherWall(t *testing.T) {
+	e := newTestEngine(t)
+	guard := addMeleeTestEnemy(e, "guard", 1000, 1000, 1)
+	guard.WallID, guard.SecondWallID = "first", "second"
+	other := addMeleeTestEnemy(e, "other", 1200, 1000, 1)
+	other.WallID = "unrelated"
+
+	e.releaseEnemiesFromWall("first", 0, 0)
+
+	if guard.WallID != "second" || guard.SecondWallID != "" || guard.Roaming {
+		t.Errorf("expected the guard to patrol its remaining wall, got wall %q, second wall %q, roaming %v",
+			guard.WallID, guard.SecondWallID, guard.Roaming)
+	}
+	if other.WallID != "unrelated" || other.Roaming {
+		t.Error("expected enemies on other walls to be left alone")
+	}
+
+	e.releaseEnemiesFromWall("second", 0, 0)
+
+	if guard.WallID != "" || !guard.Roaming {
+		t.Errorf("expected the guard to roam with no walls left, got wall %q, roaming %v", guard.WallID, guard.Roaming)
+	}
+}
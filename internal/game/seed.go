@@ -0,0 +1,90 @@
+package game
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// seedAlphabet is used for generated seeds, kept free of look-alike characters so seeds are easy to share
+const seedAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// generatedSeedLength is the length of seeds picked for sessions created without one
+const generatedSeedLength = 8
+
+var seedPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ErrInvalidSeed is returned for seeds that aren't 1-32 letters, digits, dashes or underscores
+var ErrInvalidSeed = errors.New("seed must be 1-32 letters, digits, dashes or underscores")
+
+// ErrInvalidTeam is returned for team names that aren't 1-32 letters, digits, dashes or underscores
+var ErrInvalidTeam = errors.New("team must be 1-32 letters, digits, dashes or underscores")
+
+// ValidateTeam checks that a team name picked by a joining player has the expected format
+func ValidateTeam(team string) error {
+	if !seedPattern.MatchString(team) {
+		return ErrInvalidTeam
+	}
+	return nil
+}
+
+// ValidateSeed checks that a shared seed string has the expected format
+func ValidateSeed(seed string) error {
+	if !seedPattern.MatchString(seed) {
+		return ErrInvalidSeed
+	}
+	return nil
+}
+
+// NewSeed returns a random seed string for a new world
+func NewSeed() string {
+	seed := make([]byte, generatedSeedLength)
+	for i := range seed {
+		seed[i] = seedAlphabet[rand.Intn(len(seedAlphabet))]
+	}
+	return string(seed)
+}
+
+// NewSessionName returns a random name for a session created without one
+func NewSessionName() string {
+	return types.SessionNames[rand.Intn(len(types.SessionNames))]
+}
+
+// SetSeed makes the world generated from now on depend only on the seed: the
+// same seed yields the same walls, enemies and shops in every chunk
+func (e *Engine) SetSeed(seed string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.setSeed(seed)
+}
+
+func (e *Engine) setSeed(seed string) {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	e.seed = int64(h.Sum64())
+	e.seedName = seed
+	e.rng = rand.New(rand.NewSource(e.seed))
+}
+
+// chunkRand returns the random source a chunk is generated from, so chunks
+// come out the same no matter in which order players explore them
+func (e *Engine) chunkRand(chunkX, chunkY int) *rand.Rand {
+	return rand.New(rand.NewSource(int64(e.seededHash(chunkX, chunkY))))
+}
+
+// seededHash mixes the world seed with a pair of grid coordinates
+func (e *Engine) seededHash(x, y int) uint64 {
+	h := fnv.New64a()
+	var buf [24]byte
+	for i, v := range []int64{e.seed, int64(x), int64(y)} {
+		for b := 0; b < 8; b++ {
+			buf[i*8+b] = byte(uint64(v) >> (8 * b))
+		}
+	}
+	h.Write(buf[:])
+	return h.Sum64()
+}
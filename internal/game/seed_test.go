@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// generatedLayout generates one chunk from the seed and describes its walls,
+// enemies and shops, leaving out the random object IDs
+func generatedLayout(t *testing.T, seed string, chunkX, chunkY int) []string {
+	t.Helper()
+
+	// Keep the player far away so no walls are cleared around them
+	return generatedLayoutAround(t, seed, chunkX, chunkY, &types.Vector2{X: 1e9, Y: 1e9})
+}
+
+// generatedLayoutAround is generatedLayout with the player standing at playerPos
+func generatedLayoutAround(t *testing.T, seed string, chunkX, chunkY int, playerPos *types.Vector2) []string {
+	t.Helper()
+
+	config.AppConfig = &config.Config{}
+	e := NewEngine("seed-test")
+	e.SetSeed(seed)
+
+	e.generateChunk(chunkX, chunkY, playerPos)
+
+	var layout []string
+	chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+	for _, wall := range e.state.wallsByChunk[chunkKey] {
+		layout = append(layout, fmt.Sprintf("wall %.3f,%.3f %.3fx%.3f", wall.Position.X, wall.Position.Y, wall.Width, wall.Height))
+	}
+	for _, enemy := range e.state.enemiesByChunk[chunkKey] {
+		layout = append(layout, fmt.Sprintf("%s %.3f,%.3f", enemy.Type, enemy.Position.X, enemy.Position.Y))
+	}
+	for _, shop := range e.state.shopsByChunk[chunkKey] {
+		for itemID, item := range shop.Inventory {
+			layout = append(layout, fmt.Sprintf("shop %s item %d x%d", shop.Name, itemID, item.Quantity))
+		}
+	}
+
+	sort.Strings(layout)
+	return layout
+}
+
+func TestSameSeedGeneratesSameChunk(t *testing.T) {
+	first := generatedLayout(t, "seed-of-the-day", 3, -2)
+	if len(first) == 0 {
+		t.Fatal("expected the chunk to have content")
+	}
+
+	if second := generatedLayout(t, "seed-of-the-day", 3, -2); !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed generated different chunks:\n%v\n%v", first, second)
+	}
+	if other := generatedLayout(t, "another-seed", 3, -2); reflect.DeepEqual(first, other) {
+		t.Error("expected a different seed to generate a different chunk")
+	}
+}
+
+func TestPlayerInsideChunkOnlyClearsTheirSurroundings(t *testing.T) {
+	far := generatedLayout(t, "seed-of-the-day", 3, -2)
+	inside := generatedLayoutAround(t, "seed-of-the-day", 3, -2, &types.Vector2{
+		X: 3*config.ChunkSize + config.ChunkSize/2,
+		Y: -2*config.ChunkSize + config.ChunkSize/2,
+	})
+
+	if len(inside) == 0 || len(inside) >= len(far) {
+		t.Fatalf("expected the player to clear part of the chunk, got %d of %d entries", len(inside), len(far))
+	}
+	for _, entry := range inside {
+		if _, found := slices.BinarySearch(far, entry); !found {
+			t.Errorf("expected %q to be generated wherever the player stands", entry)
+		}
+	}
+}
+
+func TestValidateSeed(t *testing.T) {
+	for _, seed := range []string{"a", "seed-of-the-day", "Seed_2024", NewSeed()} {
+		if err := ValidateSeed(seed); err != nil {
+			t.Errorf("ValidateSeed(%q) error = %v", seed, err)
+		}
+	}
+
+	for _, seed := range []string{"", "has space", "emoji🙂", "x1234567890123456789012345678901234"} {
+		if err := ValidateSeed(seed); err == nil {
+			t.Errorf("ValidateSeed(%q) expected an error", seed)
+		}
+	}
+}
+
+func TestValidateTeam(t *testing.T) {
+	for _, team := range []string{"red", "blue-team", "Team_2"} {
+		if err := ValidateTeam(team); err != nil {
+			t.Errorf("ValidateTeam(%q) error = %v", team, err)
+		}
+	}
+
+	for _, team := range []string{"", "red team", "<script>", "x1234567890123456789012345678901234"} {
+		if err := ValidateTeam(team); err == nil {
+			t.Errorf("ValidateTeam(%q) expected an error", team)
+		}
+	}
+}
+
+func TestNewSessionName(t *testing.T) {
+	for _, name := range types.SessionNames {
+		if name == "" || len(name) > 50 {
+			t.Errorf("session name %q must be 1-50 characters to pass session validation", name)
+		}
+	}
+
+	name := NewSessionName()
+	if !slices.Contains(types.SessionNames, name) {
+		t.Errorf("NewSessionName() = %q, want one of the curated names", name)
+	}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"fmt"
+	"log"
 	"math/rand"
 	"time"
 
@@ -25,6 +26,15 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if session.Seed != "" {
+		e.setSeed(session.Seed)
+	}
+	e.allowedWeapons = types.NewWeaponSet(session.AllowedWeapons)
+	e.friendlyFire = session.FriendlyFireOn()
+	if session.RecordInputs {
+		e.recordInputs = true
+	}
+
 	// Load walls from shared objects
 	for id, obj := range session.SharedObjects {
 		if obj.Type == "wall" {
@@ -47,6 +57,9 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			if orientation, ok := obj.Properties["orientation"].(string); ok {
 				wall.Orientation = orientation
 			}
+			if isDoor, ok := obj.Properties["door"].(bool); ok {
+				wall.IsDoor = isDoor
+			}
 			chiunkX, chunkY := utils.ChunkXYFromPosition(wall.Position.X, wall.Position.Y)
 			chunkKey := fmt.Sprintf("%d,%d", chiunkX, chunkY)
 			if _, exists := e.state.wallsByChunk[chunkKey]; !exists {
@@ -70,6 +83,9 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			if wallID, ok := obj.Properties["wall_id"].(string); ok {
 				enemy.WallID = wallID
 			}
+			if secondWallID, ok := obj.Properties["second_wall_id"].(string); ok {
+				enemy.SecondWallID = secondWallID
+			}
 			if enemyType, ok := obj.Properties["type"].(string); ok {
 				enemy.Type = enemyType
 			}
@@ -96,6 +112,31 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			if enemy.Type != types.EnemyTypeTower && enemy.Direction == 0 {
 				enemy.Direction = 1
 			}
+			if summonTimer, ok := obj.Properties["summon_timer"].(float64); ok {
+				enemy.SummonTimer = summonTimer
+			}
+			if minions, ok := obj.Properties["minions"].(int32); ok {
+				enemy.Minions = int(minions)
+			} else if minions, ok := obj.Properties["minions"].(float64); ok {
+				enemy.Minions = int(minions)
+			}
+			if summonerID, ok := obj.Properties["summoner_id"].(string); ok {
+				enemy.SummonerID = summonerID
+			}
+			if armored, ok := obj.Properties["armored"].(bool); ok {
+				enemy.Armored = armored
+			}
+			if archetype, ok := obj.Properties["archetype"].(string); ok {
+				enemy.Archetype = archetype
+			}
+			if stolenMoney, ok := obj.Properties["stolen_money"].(int32); ok {
+				enemy.StolenMoney = int(stolenMoney)
+			} else if stolenMoney, ok := obj.Properties["stolen_money"].(float64); ok {
+				enemy.StolenMoney = int(stolenMoney)
+			}
+			if roaming, ok := obj.Properties["roaming"].(bool); ok {
+				enemy.Roaming = roaming
+			}
 			chunkX, chunkY := utils.ChunkXYFromPosition(enemy.Position.X, enemy.Position.Y)
 			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
 			if _, exists := e.state.enemiesByChunk[chunkKey]; !exists {
@@ -124,6 +165,31 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 					bonus.DroppedAt = time.Unix(droppedAt, 0)
 				}
 			}
+			if expiresAt, ok := obj.Properties["expires_at"].(int64); ok && expiresAt > 0 {
+				bonus.ExpiresAt = time.Unix(expiresAt, 0)
+			}
+			destinationX, okX := obj.Properties["destination_x"].(float64)
+			destinationY, okY := obj.Properties["destination_y"].(float64)
+			if okX && okY {
+				bonus.Destination = &types.Vector2{X: destinationX, Y: destinationY}
+			}
+			if inventory, ok := obj.Properties["inventory"].(map[string]interface{}); ok {
+				for itemIDStr, quantity := range inventory {
+					var itemID types.InventoryItemID
+					fmt.Sscanf(itemIDStr, "%d", &itemID)
+					if !types.KnownInventoryItems[itemID] {
+						log.Printf("Dropping unknown item %q from bonus %s in session %s", itemIDStr, id, e.sessionID)
+						continue
+					}
+					item := types.InventoryItem{Type: itemID}
+					if q, ok := quantity.(int32); ok {
+						item.Quantity = q
+					} else if q, ok := quantity.(float64); ok {
+						item.Quantity = int32(q)
+					}
+					bonus.Inventory = append(bonus.Inventory, item)
+				}
+			}
 
 			e.state.bonuses[id] = bonus
 		} else if obj.Type == "shop" {
@@ -137,9 +203,11 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			if shopName, ok := obj.Properties["name"].(string); ok {
 				shop.Name = shopName
 			}
+			discovered, _ := obj.Properties["discovered"].(bool)
 
 			if session.GameVersion < "1.0.0" {
-				shop = types.GenerateShop(shop.Position)
+				chunkX, chunkY := utils.ChunkXYFromPosition(shop.Position.X, shop.Position.Y)
+				shop = types.GenerateShop(shop.Position, e.chunkRand(chunkX, chunkY), e.shopPriceVariation, e.allowedWeapons)
 			} else {
 				// Parse inventory from properties
 				if inventory, ok := obj.Properties["inventory"].(map[string]interface{}); ok {
@@ -147,6 +215,10 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 					for itemIDStr, itemData := range inventory {
 						var itemID types.InventoryItemID
 						fmt.Sscanf(itemIDStr, "%d", &itemID)
+						if !types.KnownInventoryItems[itemID] {
+							log.Printf("Dropping unknown item %q from shop %s in session %s", itemIDStr, id, e.sessionID)
+							continue
+						}
 						if itemMap, ok := itemData.(map[string]interface{}); ok {
 							item := &types.ShopInventoryItem{}
 							if price, ok := itemMap["price"].(int32); ok {
@@ -167,6 +239,7 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			if shop.Name == "" {
 				shop.Name = types.ShopNames[rand.Intn(len(types.ShopNames))]
 			}
+			shop.Discovered = discovered
 
 			chunkX, chunkY := utils.ChunkXYFromPosition(shop.Position.X, shop.Position.Y)
 			chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
@@ -188,18 +261,21 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			}
 		}
 
-		if len(playerState.Inventory) == 0 {
+		for _, item := range playerState.Inventory {
+			if !types.KnownInventoryItems[types.InventoryItemID(item.Type)] {
+				log.Printf("Dropping unknown inventory item %d of player %s in session %s", item.Type, playerID, e.sessionID)
+				continue
+			}
+			inventory = append(inventory, types.InventoryItem{
+				Type:     types.InventoryItemID(item.Type),
+				Quantity: item.Quantity,
+			})
+		}
+
+		if len(inventory) == 0 {
 			inventory = []types.InventoryItem{
 				{Type: types.InventoryItemBlaster, Quantity: 1},
 			}
-		} else {
-			inventory = make([]types.InventoryItem, len(playerState.Inventory))
-			for i, item := range playerState.Inventory {
-				inventory[i] = types.InventoryItem{
-					Type:     types.InventoryItemID(item.Type),
-					Quantity: item.Quantity,
-				}
-			}
 		}
 
 		gunType := types.WeaponTypeBlaster
@@ -207,37 +283,47 @@ func (e *Engine) LoadFromSession(session *db.GameSession) {
 			gunType = playerState.SelectedGunType
 		}
 
+		// Players come back disconnected whatever the saved state says, a session saved mid-game has
+		// everyone connected. Dead players are queued for respawn by ConnectPlayer once they're back.
 		player := &types.Player{
 			ScreenObject: types.ScreenObject{
 				ID:       playerState.PlayerID,
 				Position: &types.Vector2{X: playerState.Position.X, Y: playerState.Position.Y},
 			},
 			Username:                playerState.Name,
-			Rotation:                playerState.Position.Rotation,
+			Rotation:                utils.NormalizeRotation(playerState.Position.Rotation),
 			Lives:                   playerState.Lives,
 			Score:                   playerState.Score,
 			Money:                   playerState.Money,
 			BulletsLeftByWeaponType: playerState.BulletsLeftByWeaponType,
 			InvulnerableTimer:       playerState.InvulnerableTimer,
 			NightVisionTimer:        playerState.NightVisionTimer,
+			DoubleDamageTimer:       playerState.DoubleDamageTimer,
+			RapidFireTimer:          playerState.RapidFireTimer,
+			SpeedBoostTimer:         playerState.SpeedBoostTimer,
+			Stamina:                 config.PlayerMaxStamina,
 			Kills:                   playerState.Kills,
 			IsAlive:                 playerState.IsAlive,
-			IsConnected:             playerState.IsConnected,
 			Inventory:               inventory,
 			SelectedGunType:         gunType,
+			Team:                    playerState.Team,
+			SpawnWeaponsGranted:     playerState.SpawnWeaponsGranted,
 		}
 
+		e.clampPlayerFunds(player)
 		e.state.players[playerID] = player
-
-		if !player.IsAlive {
-			e.addPlayerToRespawnQueue(playerID)
-		}
 	}
 
 	// Load chunk hash from world map
-	for chunkID := range session.WorldMap {
+	e.sightWallsByChunk = make(map[string][]sightWall)
+	for chunkID, chunk := range session.WorldMap {
 		e.chunkHash[chunkID] = true
+		if chunk.Zone != "" {
+			e.zoneByChunk[chunkID] = chunk.Zone
+		}
 	}
+
+	e.checkTickSafety()
 }
 
 // SaveToSession saves the engine state to a database session
@@ -245,6 +331,9 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	// A world wiped in hardcore mode is regenerated from a new seed
+	session.Seed = e.seedName
+
 	// Save players
 	session.Players = make(map[string]db.PlayerState)
 	for id, player := range e.state.players {
@@ -267,10 +356,15 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 			BulletsLeftByWeaponType: player.BulletsLeftByWeaponType,
 			InvulnerableTimer:       player.InvulnerableTimer,
 			NightVisionTimer:        player.NightVisionTimer,
+			DoubleDamageTimer:       player.DoubleDamageTimer,
+			RapidFireTimer:          player.RapidFireTimer,
+			SpeedBoostTimer:         player.SpeedBoostTimer,
 			IsAlive:                 player.IsAlive,
 			IsConnected:             player.IsConnected,
 			SelectedGunType:         player.SelectedGunType,
+			Team:                    player.Team,
 			Inventory:               inventory,
+			SpawnWeaponsGranted:     player.SpawnWeaponsGranted,
 		}
 	}
 
@@ -289,6 +383,7 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 					"width":       wall.Width,
 					"height":      wall.Height,
 					"orientation": wall.Orientation,
+					"door":        wall.IsDoor,
 				},
 			}
 		}
@@ -306,10 +401,18 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 				X:        enemy.Position.X,
 				Y:        enemy.Position.Y,
 				Properties: map[string]interface{}{
-					"wall_id":   enemy.WallID,
-					"direction": enemy.Direction,
-					"lives":     enemy.Lives,
-					"type":      enemy.Type,
+					"wall_id":        enemy.WallID,
+					"second_wall_id": enemy.SecondWallID,
+					"direction":      enemy.Direction,
+					"lives":          enemy.Lives,
+					"type":           enemy.Type,
+					"summon_timer":   enemy.SummonTimer,
+					"minions":        int32(enemy.Minions),
+					"summoner_id":    enemy.SummonerID,
+					"armored":        enemy.Armored,
+					"archetype":      enemy.Archetype,
+					"stolen_money":   int32(enemy.StolenMoney),
+					"roaming":        enemy.Roaming,
 				},
 			}
 		}
@@ -333,8 +436,9 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 				X:        shop.Position.X,
 				Y:        shop.Position.Y,
 				Properties: map[string]interface{}{
-					"inventory": inventoryProps,
-					"name":      shop.Name,
+					"inventory":  inventoryProps,
+					"name":       shop.Name,
+					"discovered": shop.Discovered,
 				},
 			}
 		}
@@ -351,16 +455,31 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 			droppedAt = bonus.DroppedAt.Unix()
 		}
 
+		inventoryProps := make(map[string]interface{})
+		for _, item := range bonus.Inventory {
+			inventoryProps[fmt.Sprintf("%d", item.Type)] = item.Quantity
+		}
+
+		properties := map[string]interface{}{
+			"bonus_type": bonus.Type,
+			"dropped_by": bonus.DroppedBy,
+			"dropped_at": droppedAt,
+			"inventory":  inventoryProps,
+		}
+		if bonus.Destination != nil {
+			properties["destination_x"] = bonus.Destination.X
+			properties["destination_y"] = bonus.Destination.Y
+		}
+		if !bonus.ExpiresAt.IsZero() {
+			properties["expires_at"] = bonus.ExpiresAt.Unix()
+		}
+
 		session.SharedObjects[id] = db.WorldObject{
-			ObjectID: id,
-			Type:     "bonus",
-			X:        bonus.Position.X,
-			Y:        bonus.Position.Y,
-			Properties: map[string]interface{}{
-				"bonus_type": bonus.Type,
-				"dropped_by": bonus.DroppedBy,
-				"dropped_at": droppedAt,
-			},
+			ObjectID:   id,
+			Type:       "bonus",
+			X:          bonus.Position.X,
+			Y:          bonus.Position.Y,
+			Properties: properties,
 		}
 	}
 
@@ -375,6 +494,7 @@ func (e *Engine) SaveToSession(session *db.GameSession) {
 			X:       x,
 			Y:       y,
 			Objects: make(map[string]db.WorldObject),
+			Zone:    e.zoneByChunk[chunkID],
 		}
 	}
 
@@ -393,5 +513,10 @@ func (e *Engine) Clear() {
 	e.state.bonuses = make(map[string]*types.Bonus)
 	e.state.shopsByChunk = make(map[string]map[string]*types.Shop)
 	e.chunkHash = make(map[string]bool)
+	e.sightWallsByChunk = make(map[string][]sightWall)
+	e.zoneByChunk = make(map[string]string)
+	e.diedAt = make(map[string]time.Time)
+	e.survivalTime = make(map[string]float64)
+	e.enemyAggro = make(map[string]enemyAggro)
 	e.prevState = make(map[string]*EngineGameState)
 }
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// sightWall is a wall with what line-of-sight checks need worked out in advance
+type sightWall struct {
+	centerX, centerY float64
+	radius           float64
+	left, top        float64
+	width, height    float64
+}
+
+// sightWallsAround returns the walls in the generated chunks around the given one, the ones enemies
+// in it check their line of sight against. They're gathered on first use and kept until walls change.
+func (e *Engine) sightWallsAround(chunkX, chunkY int) []sightWall {
+	chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+	if walls, cached := e.sightWallsByChunk[chunkKey]; cached {
+		return walls
+	}
+
+	walls := []sightWall{}
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if !e.chunkHash[neighborChunkKey] {
+				continue
+			}
+			for _, wall := range e.state.wallsByChunk[neighborChunkKey] {
+				center := wall.GetCenter()
+				topLeft := wall.GetTopLeft()
+				walls = append(walls, sightWall{
+					centerX: center.X,
+					centerY: center.Y,
+					radius:  wall.GetRadius(),
+					left:    topLeft.X,
+					top:     topLeft.Y,
+					width:   wall.Width,
+					height:  wall.Height,
+				})
+			}
+		}
+	}
+
+	e.sightWallsByChunk[chunkKey] = walls
+	return walls
+}
+
+// invalidateSightWalls drops the cached walls of every chunk whose surroundings include the changed chunk
+func (e *Engine) invalidateSightWalls(chunkKey string) {
+	var chunkX, chunkY int
+	fmt.Sscanf(chunkKey, "%d,%d", &chunkX, &chunkY)
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			delete(e.sightWallsByChunk, fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY))
+		}
+	}
+}
+
+// hasLineOfSight reports whether no wall blocks the line from the enemy to the point.
+// Walls further than twice their radius beyond the detection distance are skipped.
+func hasLineOfSight(enemy *types.Enemy, point *types.Vector2, detectionDistance float64, walls []sightWall) bool {
+	for i := range walls {
+		wall := &walls[i]
+		dx := enemy.Position.X - wall.centerX
+		dy := enemy.Position.Y - wall.centerY
+		if distanceToWall := math.Sqrt(dx*dx + dy*dy); distanceToWall > 2*wall.radius+detectionDistance {
+			continue // Wall is beyond player
+		}
+
+		if utils.CheckLineRectCollision(
+			enemy.Position.X, enemy.Position.Y,
+			point.X, point.Y,
+			wall.left, wall.top,
+			wall.width, wall.height) {
+			return false
+		}
+	}
+	return true
+}
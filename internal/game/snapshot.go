@@ -0,0 +1,146 @@
+package game
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/protocol"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// engineSnapshot is the full engine state written by ExportSnapshot, including
+// bullets and timers that aren't persisted with the session
+type engineSnapshot struct {
+	SessionID string
+	Seed      int64
+	SeedName  string
+
+	Players        map[string]*types.Player
+	Bullets        map[string]*types.Bullet
+	WallsByChunk   map[string]map[string]*types.Wall
+	EnemiesByChunk map[string]map[string]*types.Enemy
+	Bonuses        map[string]*types.Bonus
+	ShopsByChunk   map[string]map[string]*types.Shop
+
+	ChunkHash    map[string]bool
+	ZoneByChunk  map[string]string
+	RespawnQueue map[string]bool
+	DiedAt       map[string]time.Time
+	SurvivalTime map[string]float64
+	Achieved     map[string]map[string]bool
+	EnemyAggro   map[string]snapshotAggro
+}
+
+type snapshotAggro struct {
+	PlayerID string
+	Until    time.Time
+}
+
+// ExportSnapshot encodes the whole state of the engine, so a session can be
+// dumped to a file and loaded elsewhere with ImportSnapshot to debug it
+func (e *Engine) ExportSnapshot() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	snapshot := &engineSnapshot{
+		SessionID:      e.sessionID,
+		Seed:           e.seed,
+		SeedName:       e.seedName,
+		Players:        e.state.players,
+		Bullets:        e.state.bullets,
+		WallsByChunk:   e.state.wallsByChunk,
+		EnemiesByChunk: e.state.enemiesByChunk,
+		Bonuses:        e.state.bonuses,
+		ShopsByChunk:   e.state.shopsByChunk,
+		ChunkHash:      e.chunkHash,
+		ZoneByChunk:    e.zoneByChunk,
+		RespawnQueue:   e.respawnQueue,
+		DiedAt:         e.diedAt,
+		SurvivalTime:   e.survivalTime,
+		Achieved:       e.achieved,
+		EnemyAggro:     make(map[string]snapshotAggro),
+	}
+	for enemyID, aggro := range e.enemyAggro {
+		snapshot.EnemyAggro[enemyID] = snapshotAggro{PlayerID: aggro.playerID, Until: aggro.until}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportSnapshot replaces the engine state with one written by ExportSnapshot.
+// Players come back disconnected and get the full state once they connect.
+func (e *Engine) ImportSnapshot(data []byte) error {
+	var snapshot engineSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sessionID = snapshot.SessionID
+	e.seed = snapshot.Seed
+	e.seedName = snapshot.SeedName
+	e.rng = rand.New(rand.NewSource(e.seed))
+
+	// Gob leaves out empty maps, so every map is recreated before use
+	e.state = &EngineGameState{
+		players:        orEmpty(snapshot.Players),
+		bullets:        orEmpty(snapshot.Bullets),
+		wallsByChunk:   orEmpty(snapshot.WallsByChunk),
+		enemiesByChunk: orEmpty(snapshot.EnemiesByChunk),
+		bonuses:        orEmpty(snapshot.Bonuses),
+		shopsByChunk:   orEmpty(snapshot.ShopsByChunk),
+	}
+	e.chunkHash = orEmpty(snapshot.ChunkHash)
+	e.sightWallsByChunk = make(map[string][]sightWall)
+	e.zoneByChunk = orEmpty(snapshot.ZoneByChunk)
+	e.respawnQueue = orEmpty(snapshot.RespawnQueue)
+	e.diedAt = orEmpty(snapshot.DiedAt)
+	e.survivalTime = orEmpty(snapshot.SurvivalTime)
+	e.achieved = orEmpty(snapshot.Achieved)
+	e.enemyAggro = make(map[string]enemyAggro)
+	for enemyID, aggro := range snapshot.EnemyAggro {
+		e.enemyAggro[enemyID] = enemyAggro{playerID: aggro.PlayerID, until: aggro.Until}
+	}
+
+	for chunkKey := range e.chunkHash {
+		e.state.wallsByChunk[chunkKey] = orEmpty(e.state.wallsByChunk[chunkKey])
+		e.state.enemiesByChunk[chunkKey] = orEmpty(e.state.enemiesByChunk[chunkKey])
+		e.state.shopsByChunk[chunkKey] = orEmpty(e.state.shopsByChunk[chunkKey])
+	}
+	for _, player := range e.state.players {
+		player.IsConnected = false
+		player.BulletsLeftByWeaponType = orEmpty(player.BulletsLeftByWeaponType)
+	}
+
+	e.prevState = make(map[string]*EngineGameState)
+	e.playerInputState = make(map[string]*types.InputPayload)
+	e.zoneSent = make(map[string]string)
+	e.currentShopByPlayer = make(map[string]string)
+	e.shopEventsByPlayer = make(map[string][]*protocol.ShopEvent)
+	e.killCamFrames = make(map[string]*killCamBuffer)
+	e.killCamsByPlayer = make(map[string]*protocol.KillCam)
+	e.jammedByPlayer = make(map[string]string)
+	e.joinedPlayersByPlayer = make(map[string][]*protocol.Player)
+	e.leftPlayersByPlayer = make(map[string][]string)
+	e.portalArrivals = make(map[string]string)
+	e.lastUpdate = time.Now()
+
+	return nil
+}
+
+// orEmpty returns m, or an empty map in place of nil
+func orEmpty[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return make(map[K]V)
+	}
+	return m
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	e := newTestEngine(t)
+	e.setSeed("snapshot-seed")
+	// Snapshots drop the monotonic clock reading, so the test uses plain wall times
+	now := time.Now().Round(0)
+
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 4}
+	player.Inventory = []types.InventoryItem{{Type: types.InventoryItemBlaster, Quantity: 1}}
+	player.InvulnerableTimer = 1.5
+	player.LastShotAt = now
+
+	dead := addTestPlayer(e, "dead", 1200, 1000)
+	dead.BulletsLeftByWeaponType = map[string]int32{types.WeaponTypeBlaster: 0}
+	dead.IsAlive = false
+	e.diedAt[dead.ID] = now
+	e.respawnQueue[dead.ID] = true
+	e.survivalTime[player.ID] = 42
+
+	e.state.enemiesByChunk["0,0"]["enemy"] = &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "enemy", Position: &types.Vector2{X: 1100, Y: 1300}},
+		Type:         types.EnemyTypeSummoner,
+		Lives:        3,
+		IsAlive:      true,
+		LastShot:     now,
+		SummonTimer:  2.5,
+		Minions:      1,
+	}
+	e.enemyAggro["enemy"] = enemyAggro{playerID: player.ID, until: now.Add(time.Second)}
+	e.state.wallsByChunk["0,0"]["wall"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	e.state.bullets["bullet"] = &types.Bullet{
+		ScreenObject: types.ScreenObject{ID: "bullet", Position: &types.Vector2{X: 1000, Y: 1050}},
+		Velocity:     &types.Vector2{X: 0, Y: 300},
+		OwnerID:      player.ID,
+		IsActive:     true,
+		SpawnTime:    now,
+		Damage:       1,
+		WeaponType:   types.WeaponTypeBlaster,
+	}
+
+	data, err := e.ExportSnapshot()
+	if err != nil {
+		t.Fatalf("ExportSnapshot() error = %v", err)
+	}
+
+	loaded := newTestEngine(t)
+	if err := loaded.ImportSnapshot(data); err != nil {
+		t.Fatalf("ImportSnapshot() error = %v", err)
+	}
+
+	// Imported players wait for their clients to connect
+	for _, p := range e.state.players {
+		p.IsConnected = false
+	}
+
+	if loaded.sessionID != e.sessionID || loaded.seed != e.seed || loaded.seedName != e.seedName {
+		t.Errorf("session %s with seed %d (%q), want %s with seed %d (%q)", loaded.sessionID, loaded.seed, loaded.seedName, e.sessionID, e.seed, e.seedName)
+	}
+	if !reflect.DeepEqual(loaded.state.players, e.state.players) {
+		t.Errorf("players differ after round trip: %+v", loaded.state.players)
+	}
+	if !reflect.DeepEqual(loaded.state.bullets, e.state.bullets) {
+		t.Errorf("bullets differ after round trip: %+v", loaded.state.bullets)
+	}
+	if !reflect.DeepEqual(loaded.state.enemiesByChunk["0,0"], e.state.enemiesByChunk["0,0"]) {
+		t.Errorf("enemies differ after round trip: %+v", loaded.state.enemiesByChunk["0,0"])
+	}
+	if !reflect.DeepEqual(loaded.state.wallsByChunk["0,0"], e.state.wallsByChunk["0,0"]) {
+		t.Errorf("walls differ after round trip: %+v", loaded.state.wallsByChunk["0,0"])
+	}
+	if !reflect.DeepEqual(loaded.chunkHash, e.chunkHash) {
+		t.Errorf("chunks differ after round trip: %v", loaded.chunkHash)
+	}
+	if !loaded.diedAt[dead.ID].Equal(now) || !loaded.respawnQueue[dead.ID] || loaded.survivalTime[player.ID] != 42 {
+		t.Error("expected death and survival timers to survive the round trip")
+	}
+	if aggro := loaded.enemyAggro["enemy"]; aggro.playerID != player.ID || !aggro.until.Equal(now.Add(time.Second)) {
+		t.Errorf("enemy aggro = %+v after round trip", aggro)
+	}
+
+	// Empty chunks must still accept new objects
+	if loaded.state.shopsByChunk["1,1"] == nil {
+		t.Error("expected empty chunks to come back as empty maps")
+	}
+}
+
+func TestImportSnapshotRejectsInvalidData(t *testing.T) {
+	e := newTestEngine(t)
+	if err := e.ImportSnapshot([]byte("not a snapshot")); err == nil {
+		t.Error("expected an error for invalid snapshot data")
+	}
+}
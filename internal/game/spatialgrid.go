@@ -0,0 +1,158 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// gridEnemy is an enemy in the spatial grid along with the chunk it's stored in
+type gridEnemy struct {
+	enemy    *types.Enemy
+	chunk    [2]int
+	chunkKey string
+}
+
+// spatialGrid buckets players and enemies into square cells, so a bullet only has to be tested
+// against the ones in the cells its path crosses. Every entity goes into each cell its
+// hit circle overlaps.
+type spatialGrid struct {
+	cellSize float64
+	players  map[[2]int][]*types.Player
+	enemies  map[[2]int][]gridEnemy
+}
+
+// buildSpatialGrid buckets every player and the enemies in the chunks around bullets flying
+// this tick. Living and vulnerable state is left to the bullet checks, since it changes as
+// bullets hit.
+func (e *Engine) buildSpatialGrid() *spatialGrid {
+	grid := &spatialGrid{
+		cellSize: config.BulletHitCellSize,
+		players:  make(map[[2]int][]*types.Player),
+		enemies:  make(map[[2]int][]gridEnemy),
+	}
+
+	for _, player := range e.state.players {
+		grid.forCellsAround(player.Position, config.PlayerRadius+config.BlasterBulletRadius, func(cell [2]int) {
+			grid.players[cell] = append(grid.players[cell], player)
+		})
+	}
+
+	chunks := make(map[[2]int]bool)
+	for _, bullet := range e.state.bullets {
+		if !bullet.DeletedAt.IsZero() {
+			continue
+		}
+		bulletChunkX, bulletChunkY := utils.ChunkXYFromPosition(bullet.Position.X, bullet.Position.Y)
+		for neighborChunkX := bulletChunkX - 2; neighborChunkX <= bulletChunkX+2; neighborChunkX++ {
+			for neighborChunkY := bulletChunkY - 2; neighborChunkY <= bulletChunkY+2; neighborChunkY++ {
+				chunks[[2]int{neighborChunkX, neighborChunkY}] = true
+			}
+		}
+	}
+	for chunk := range chunks {
+		chunkKey := fmt.Sprintf("%d,%d", chunk[0], chunk[1])
+		if !e.chunkHash[chunkKey] {
+			continue
+		}
+		for _, enemy := range e.state.enemiesByChunk[chunkKey] {
+			grid.forCellsAround(enemy.Position, enemy.Size()/2+config.BlasterBulletRadius, func(cell [2]int) {
+				grid.enemies[cell] = append(grid.enemies[cell], gridEnemy{enemy: enemy, chunk: chunk, chunkKey: chunkKey})
+			})
+		}
+	}
+
+	return grid
+}
+
+func (g *spatialGrid) cell(x, y float64) [2]int {
+	return [2]int{int(math.Floor(x / g.cellSize)), int(math.Floor(y / g.cellSize))}
+}
+
+// forCellsAround calls visit for every cell the square around a circle overlaps
+func (g *spatialGrid) forCellsAround(center *types.Vector2, radius float64, visit func(cell [2]int)) {
+	minCell := g.cell(center.X-radius, center.Y-radius)
+	maxCell := g.cell(center.X+radius, center.Y+radius)
+	for cellX := minCell[0]; cellX <= maxCell[0]; cellX++ {
+		for cellY := minCell[1]; cellY <= maxCell[1]; cellY++ {
+			visit([2]int{cellX, cellY})
+		}
+	}
+}
+
+// forCellsOnSegment calls visit for every cell the segment passes through, walking them from start to end
+func (g *spatialGrid) forCellsOnSegment(start, end *types.Vector2, visit func(cell [2]int)) {
+	current := g.cell(start.X, start.Y)
+	last := g.cell(end.X, end.Y)
+	dx, dy := end.X-start.X, end.Y-start.Y
+
+	// Distance along the segment, as a share of its length, to the next cell border on each axis
+	stepX, nextX, deltaX := 0, math.Inf(1), math.Inf(1)
+	if dx > 0 {
+		stepX, nextX, deltaX = 1, (float64(current[0]+1)*g.cellSize-start.X)/dx, g.cellSize/dx
+	} else if dx < 0 {
+		stepX, nextX, deltaX = -1, (float64(current[0])*g.cellSize-start.X)/dx, -g.cellSize/dx
+	}
+	stepY, nextY, deltaY := 0, math.Inf(1), math.Inf(1)
+	if dy > 0 {
+		stepY, nextY, deltaY = 1, (float64(current[1]+1)*g.cellSize-start.Y)/dy, g.cellSize/dy
+	} else if dy < 0 {
+		stepY, nextY, deltaY = -1, (float64(current[1])*g.cellSize-start.Y)/dy, -g.cellSize/dy
+	}
+
+	// Bounded by the cell count, so rounding can't walk past the last cell
+	steps := abs(last[0]-current[0]) + abs(last[1]-current[1])
+	visit(current)
+	for i := 0; i < steps; i++ {
+		if nextX < nextY {
+			current[0] += stepX
+			nextX += deltaX
+		} else {
+			current[1] += stepY
+			nextY += deltaY
+		}
+		visit(current)
+	}
+}
+
+// forCandidates calls the callbacks once for every player and enemy bucketed in the cells the segment crosses
+func (g *spatialGrid) forCandidates(start, end *types.Vector2, onPlayer func(*types.Player), onEnemy func(gridEnemy)) {
+	// Entities can only show up twice when the segment crosses more than one cell
+	var seenPlayers map[*types.Player]bool
+	var seenEnemies map[*types.Enemy]bool
+	if g.cell(start.X, start.Y) != g.cell(end.X, end.Y) {
+		seenPlayers = make(map[*types.Player]bool)
+		seenEnemies = make(map[*types.Enemy]bool)
+	}
+
+	g.forCellsOnSegment(start, end, func(cell [2]int) {
+		for _, player := range g.players[cell] {
+			if seenPlayers != nil {
+				if seenPlayers[player] {
+					continue
+				}
+				seenPlayers[player] = true
+			}
+			onPlayer(player)
+		}
+		for _, entry := range g.enemies[cell] {
+			if seenEnemies != nil {
+				if seenEnemies[entry.enemy] {
+					continue
+				}
+				seenEnemies[entry.enemy] = true
+			}
+			onEnemy(entry)
+		}
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// hitIDs lists the IDs of the targets found for the bullet, sorted
+func hitIDs(hits []bulletHit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if hit.player != nil {
+			ids = append(ids, hit.player.ID)
+		} else {
+			ids = append(ids, hit.enemy.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSpatialGridFindsSameHitsAsScan(t *testing.T) {
+	e, bullets := newCrowdedEngine(t, 2000, 500)
+	grid := e.buildSpatialGrid()
+
+	found := 0
+	for _, bullet := range bullets {
+		end := &types.Vector2{X: bullet.Position.X + bullet.Velocity.X/60, Y: bullet.Position.Y + bullet.Velocity.Y/60}
+
+		e.hitGrid = nil
+		scanned := hitIDs(e.findBulletHits(bullet, end))
+		e.hitGrid = grid
+		gridded := hitIDs(e.findBulletHits(bullet, end))
+
+		if len(scanned) != len(gridded) {
+			t.Fatalf("%s: scan found %v, grid found %v", bullet.ID, scanned, gridded)
+		}
+		for i := range scanned {
+			if scanned[i] != gridded[i] {
+				t.Fatalf("%s: scan found %v, grid found %v", bullet.ID, scanned, gridded)
+			}
+		}
+		found += len(scanned)
+	}
+
+	if found == 0 {
+		t.Fatal("expected some bullets to hit something")
+	}
+}
+
+func TestSpatialGridWalksEveryCellOnSegment(t *testing.T) {
+	grid := &spatialGrid{cellSize: 10}
+	var cells [][2]int
+	grid.forCellsOnSegment(&types.Vector2{X: 5, Y: 5}, &types.Vector2{X: 34, Y: 15}, func(cell [2]int) {
+		cells = append(cells, cell)
+	})
+
+	expected := [][2]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {3, 1}}
+	if len(cells) != len(expected) {
+		t.Fatalf("expected cells %v, got %v", expected, cells)
+	}
+	for i := range expected {
+		if cells[i] != expected[i] {
+			t.Fatalf("expected cells %v, got %v", expected, cells)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// grantSpawnWeapons gives the player the configured spawn weapons and their ammo. Each player
+// gets them only once, respawning starts over with the blaster like before.
+func (e *Engine) grantSpawnWeapons(player *types.Player) {
+	if player.SpawnWeaponsGranted || len(e.spawnWeapons) == 0 {
+		return
+	}
+	player.SpawnWeaponsGranted = true
+
+	// Sorted so every player's inventory lists them in the same order
+	weaponTypes := make([]string, 0, len(e.spawnWeapons))
+	for weaponType := range e.spawnWeapons {
+		weaponTypes = append(weaponTypes, weaponType)
+	}
+	sort.Strings(weaponTypes)
+
+	for _, weaponType := range weaponTypes {
+		weaponID, isWeapon := types.InventoryItemByWeaponType[weaponType]
+		if !isWeapon || !e.allowedWeapons.Allows(weaponType) {
+			continue
+		}
+
+		if !player.HasInventoryItem(weaponID) {
+			player.AddInventoryItem(weaponID, 1)
+		}
+		if ammoID, usesAmmo := types.InventoryAmmoIDByWeaponType[weaponType]; usesAmmo && e.spawnWeapons[weaponType] > 0 {
+			player.AddInventoryItem(ammoID, e.spawnWeapons[weaponType])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestSpawnWeaponsGrantedOnlyOnFirstJoin(t *testing.T) {
+	e := newTestEngine(t)
+	e.spawnWeapons = map[string]int32{types.WeaponTypeShotgun: 10, types.WeaponTypeKnife: 0}
+
+	player := e.ConnectPlayer("player", "player")
+	if !player.HasInventoryItem(types.InventoryItemShotgun) || !player.HasInventoryItem(types.InventoryItemKnife) {
+		t.Fatalf("expected the spawn weapons on join, got %v", player.Inventory)
+	}
+	if ammo := player.GetInventoryItemQuantity(types.InventoryItemShotgunAmmo); ammo != 10 {
+		t.Errorf("expected 10 shotgun shells, got %d", ammo)
+	}
+
+	e.DisconnectPlayer(player.ID)
+	e.ConnectPlayer(player.ID, "player")
+	if ammo := player.GetInventoryItemQuantity(types.InventoryItemShotgunAmmo); ammo != 10 {
+		t.Errorf("expected reconnecting not to grant the weapons again, got %d shells", ammo)
+	}
+
+	player.Lives = 0
+	e.finishPlayer(player, "")
+	e.diedAt[player.ID] = time.Now().Add(-time.Hour)
+	e.RespawnPlayer(player.ID)
+	tick(e, 50*time.Millisecond)
+
+	if !player.IsAlive {
+		t.Fatal("expected the player to respawn")
+	}
+	if player.HasInventoryItem(types.InventoryItemShotgun) || player.HasInventoryItem(types.InventoryItemKnife) {
+		t.Errorf("expected a respawned player to start with the blaster only, got %v", player.Inventory)
+	}
+}
+
+func TestSpawnWeaponsSkipDisallowedWeapons(t *testing.T) {
+	e := newTestEngine(t)
+	e.spawnWeapons = map[string]int32{types.WeaponTypeRailgun: 5}
+	e.allowedWeapons = types.NewWeaponSet([]string{types.WeaponTypeBlaster, types.WeaponTypeShotgun})
+
+	player := e.ConnectPlayer("player", "player")
+	if player.HasInventoryItem(types.InventoryItemRailgun) || player.HasInventoryItem(types.InventoryItemRailgunAmmo) {
+		t.Errorf("expected no weapons the session doesn't allow, got %v", player.Inventory)
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// updateSummoner counts down to the summoner's next minion and calls it in, as long as it has fewer than the cap
+func (e *Engine) updateSummoner(summoner *types.Enemy, deltaTime float64) {
+	summoner.SummonTimer -= deltaTime
+	if summoner.SummonTimer > 0 {
+		return
+	}
+
+	summoner.SummonTimer = e.summonInterval
+	if summoner.Minions < e.summonerMinionCap {
+		e.summonMinion(summoner)
+	}
+}
+
+// summonMinion places a minion next to the summoner, patrolling the summoner's wall away from it.
+// Minions go to alternating sides so they don't pile up on each other.
+func (e *Engine) summonMinion(summoner *types.Enemy) {
+	direction := int8(1)
+	if summoner.Minions%2 == 1 {
+		direction = -1
+	}
+	spacing := float64(direction) * (summoner.Size()/2 + config.EnemyMinionSize/2 + 1)
+
+	position := &types.Vector2{X: summoner.Position.X + spacing, Y: summoner.Position.Y}
+	rotation := utils.NormalizeRotation(-90 * float64(direction))
+	if wall := e.findWallNearEnemy(summoner, summoner.WallID); wall != nil && wall.Orientation == "vertical" {
+		position = &types.Vector2{X: summoner.Position.X, Y: summoner.Position.Y + spacing}
+		rotation = utils.NormalizeRotation(90 - 90*float64(direction))
+	}
+
+	minion := &types.Enemy{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: position,
+		},
+		Type:       types.EnemyTypeMinion,
+		Rotation:   rotation,
+		Lives:      float32(config.EnemyMinionLives),
+		WallID:     summoner.WallID,
+		Direction:  direction,
+		IsAlive:    true,
+		SummonerID: summoner.ID,
+	}
+
+	chunkX, chunkY := utils.ChunkXYFromPosition(position.X, position.Y)
+	chunkKey := fmt.Sprintf("%d,%d", chunkX, chunkY)
+	if _, exists := e.state.enemiesByChunk[chunkKey]; !exists {
+		e.state.enemiesByChunk[chunkKey] = make(map[string]*types.Enemy)
+	}
+	e.state.enemiesByChunk[chunkKey][minion.ID] = minion
+	summoner.Minions++
+}
+
+// releaseMinion frees a slot of the minion's summoner, once, whenever the minion dies or leaves the chunk.
+// The summoner is looked up around the chunk the minion was summoned into, since a roaming minion may wander off.
+// Minions outlive their summoner as ordinary enemies.
+func (e *Engine) releaseMinion(minion *types.Enemy, chunkKey string) {
+	summonerID := minion.SummonerID
+	if summonerID == "" {
+		return
+	}
+	minion.SummonerID = ""
+
+	var chunkX, chunkY int
+	fmt.Sscanf(chunkKey, "%d,%d", &chunkX, &chunkY)
+	for neighborChunkX := chunkX - 1; neighborChunkX <= chunkX+1; neighborChunkX++ {
+		for neighborChunkY := chunkY - 1; neighborChunkY <= chunkY+1; neighborChunkY++ {
+			neighborChunkKey := fmt.Sprintf("%d,%d", neighborChunkX, neighborChunkY)
+			if summoner, exists := e.state.enemiesByChunk[neighborChunkKey][summonerID]; exists {
+				if summoner.IsAlive && summoner.Minions > 0 {
+					summoner.Minions--
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addTestSummoner adds a player and a summoner within their sight but out of detection range
+func addTestSummoner(e *Engine) *types.Enemy {
+	addTestPlayer(e, "player", 1000, 1000)
+	e.summonInterval = 1
+	e.summonerMinionCap = 2
+
+	summoner := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "summoner", Position: &types.Vector2{X: 1000, Y: 1500}},
+		Type:         types.EnemyTypeSummoner,
+		Lives:        config.EnemySummonerLives,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][summoner.ID] = summoner
+	return summoner
+}
+
+// minionsOf returns the living minions of the summoner
+func minionsOf(e *Engine, summoner *types.Enemy) []*types.Enemy {
+	var minions []*types.Enemy
+	for _, enemies := range e.state.enemiesByChunk {
+		for _, enemy := range enemies {
+			if enemy.SummonerID == summoner.ID && enemy.IsAlive {
+				minions = append(minions, enemy)
+			}
+		}
+	}
+	return minions
+}
+
+func TestSummonerCallsMinionsUpToCap(t *testing.T) {
+	e := newTestEngine(t)
+	summoner := addTestSummoner(e)
+
+	tick(e, 100*time.Millisecond)
+	minions := minionsOf(e, summoner)
+	if len(minions) != 1 || minions[0].Type != types.EnemyTypeMinion {
+		t.Fatalf("expected the summoner to call in a minion right away, got %d", len(minions))
+	}
+
+	// The next minion waits for the summon interval
+	tick(e, 100*time.Millisecond)
+	if got := len(minionsOf(e, summoner)); got != 1 {
+		t.Fatalf("expected no minion before the interval, got %d", got)
+	}
+
+	for i := 0; i < 4; i++ {
+		tick(e, 1100*time.Millisecond)
+	}
+	if got := len(minionsOf(e, summoner)); got != 2 || summoner.Minions != 2 {
+		t.Errorf("expected the minions to stop at the cap of 2, got %d (counted %d)", got, summoner.Minions)
+	}
+}
+
+func TestKilledMinionFreesSummonerSlot(t *testing.T) {
+	e := newTestEngine(t)
+	summoner := addTestSummoner(e)
+	tick(e, 100*time.Millisecond)
+	tick(e, 1100*time.Millisecond)
+
+	minion := minionsOf(e, summoner)[0]
+	e.killEnemy(minion, "0,0")
+	if summoner.Minions != 1 {
+		t.Fatalf("expected a killed minion to free a slot, summoner counts %d", summoner.Minions)
+	}
+
+	tick(e, 1100*time.Millisecond)
+	if got := len(minionsOf(e, summoner)); got != 2 {
+		t.Errorf("expected the summoner to replace the minion, got %d", got)
+	}
+
+	// Minions stay around as ordinary enemies when their summoner dies
+	e.killEnemy(summoner, "0,0")
+	for _, minion := range minionsOf(e, summoner) {
+		e.killEnemy(minion, "0,0")
+	}
+	if summoner.Minions != 2 {
+		t.Errorf("expected a dead summoner's count to stay as it was, got %d", summoner.Minions)
+	}
+}
+
+func TestWanderingMinionFreesSummonerSlotOnce(t *testing.T) {
+	e := newTestEngine(t)
+	summoner := addTestSummoner(e)
+	tick(e, 100*time.Millisecond)
+	tick(e, 1100*time.Millisecond)
+
+	// A minion that lost its wall roams off, chunks away from its summoner
+	minion := minionsOf(e, summoner)[0]
+	minion.Roaming = true
+	minion.Position = &types.Vector2{X: 5 * config.ChunkSize, Y: 5 * config.ChunkSize}
+
+	e.killEnemy(minion, "0,0")
+	if summoner.Minions != 1 {
+		t.Fatalf("expected the wandering minion to free a slot, summoner counts %d", summoner.Minions)
+	}
+
+	// Its corpse being hit again doesn't free another one
+	e.killEnemy(minion, "0,0")
+	if summoner.Minions != 1 {
+		t.Errorf("expected a minion to free its slot only once, summoner counts %d", summoner.Minions)
+	}
+}
+
+func TestSummonerStateIsSaved(t *testing.T) {
+	e := newTestEngine(t)
+	summoner := addTestSummoner(e)
+	tick(e, 100*time.Millisecond)
+	minion := minionsOf(e, summoner)[0]
+
+	session := &db.GameSession{GameVersion: config.GameVersion}
+	e.SaveToSession(session)
+
+	loaded := newTestEngine(t)
+	loaded.LoadFromSession(session)
+
+	var loadedSummoner, loadedMinion *types.Enemy
+	for _, enemies := range loaded.state.enemiesByChunk {
+		if enemy, exists := enemies[summoner.ID]; exists {
+			loadedSummoner = enemy
+		}
+		if enemy, exists := enemies[minion.ID]; exists {
+			loadedMinion = enemy
+		}
+	}
+
+	if loadedSummoner == nil || loadedSummoner.Minions != 1 || loadedSummoner.SummonTimer != summoner.SummonTimer {
+		t.Errorf("expected the summoner to keep its minion count and timer, got %+v", loadedSummoner)
+	}
+	if loadedMinion == nil || loadedMinion.SummonerID != summoner.ID || loadedMinion.Type != types.EnemyTypeMinion {
+		t.Errorf("expected the minion to remember its summoner, got %+v", loadedMinion)
+	}
+}
+
+func TestSummonerSpawnRate(t *testing.T) {
+	e := newTestEngine(t)
+	wall := &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "wall", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        20,
+		Height:       200,
+		Orientation:  "vertical",
+	}
+	zone := &config.Zone{}
+
+	if enemy := e.createEnemyForWall(wall, rand.New(rand.NewSource(1)), zone); enemy.Type != types.EnemyTypeSoldier {
+		t.Errorf("expected no summoners by default, got %q", enemy.Type)
+	}
+
+	e.summonerChance = 1
+	if enemy := e.createEnemyForWall(wall, rand.New(rand.NewSource(1)), zone); enemy.Type != types.EnemyTypeSummoner {
+		t.Errorf("expected a summoner, got %q", enemy.Type)
+	}
+}
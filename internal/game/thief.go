@@ -0,0 +1,90 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// updateThief runs the thief at the player it sees until it touches them and grabs their money,
+// after which it flees from whoever it sees
+func (e *Engine) updateThief(thief *types.Enemy, player *types.Player, chunkKey string, deltaTime float64) {
+	if thief.StolenMoney > 0 {
+		e.fleeFromPlayer(thief, player, chunkKey, deltaTime)
+		return
+	}
+
+	if !e.thiefTouches(thief, player) {
+		// Players block enemies, so the thief stops just short of them
+		e.chasePlayer(thief, player, thief.Size()/2+config.PlayerRadius+config.EnemyThiefReach/2, chunkKey, deltaTime)
+	}
+	if e.thiefTouches(thief, player) {
+		e.stealMoney(thief, player)
+	}
+}
+
+// thiefTouches reports whether the thief is close enough to the player to reach into their pockets
+func (e *Engine) thiefTouches(thief *types.Enemy, player *types.Player) bool {
+	return utils.CheckCircleCollision(
+		thief.Position.X, thief.Position.Y, thief.Size()/2+config.EnemyThiefReach,
+		player.Position.X, player.Position.Y, config.PlayerRadius)
+}
+
+// chasePlayer moves the enemy straight at the player until it is stopAt away from them, steering
+// around walls. Like fleeing enemies, chasing ones aren't moved between chunks.
+func (e *Engine) chasePlayer(enemy *types.Enemy, player *types.Player, stopAt float64, chunkKey string, deltaTime float64) {
+	towardsX := player.Position.X - enemy.Position.X
+	towardsY := player.Position.Y - enemy.Position.Y
+	distance := math.Sqrt(towardsX*towardsX + towardsY*towardsY)
+	if distance <= stopAt {
+		return
+	}
+
+	step := math.Min(enemy.Speed()*deltaTime, distance-stopAt)
+	dx, dy, canMove := e.steerEnemy(enemy, towardsX/distance*step, towardsY/distance*step, distance)
+	if !canMove {
+		return
+	}
+
+	chunkX, chunkY := utils.ChunkXYFromPosition(enemy.Position.X+dx, enemy.Position.Y+dy)
+	if fmt.Sprintf("%d,%d", chunkX, chunkY) != chunkKey {
+		return
+	}
+
+	enemy.Rotation = utils.RotationTowards(dx, dy)
+	enemy.Position.X += dx
+	enemy.Position.Y += dy
+}
+
+// stealMoney moves a share of the player's money to the thief
+func (e *Engine) stealMoney(thief *types.Enemy, player *types.Player) {
+	stolen := int(math.Ceil(float64(player.Money) * config.EnemyThiefStealFraction))
+	if stolen <= 0 {
+		return
+	}
+
+	player.Money -= stolen
+	thief.StolenMoney += stolen
+}
+
+// dropStolenMoney leaves the money a thief carried in a chest where it died
+func (e *Engine) dropStolenMoney(thief *types.Enemy) {
+	if thief.StolenMoney <= 0 {
+		return
+	}
+
+	chest := &types.Bonus{
+		ScreenObject: types.ScreenObject{
+			ID:       uuid.New().String(),
+			Position: &types.Vector2{X: thief.Position.X, Y: thief.Position.Y},
+		},
+		Type:      types.BonusTypeChest,
+		Inventory: []types.InventoryItem{{Type: types.InventoryItemMoney, Quantity: int32(thief.StolenMoney)}},
+	}
+	e.state.bonuses[chest.ID] = chest
+	thief.StolenMoney = 0
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// addTestThief adds a player carrying money and a thief close enough to see them
+func addTestThief(e *Engine) (*types.Player, *types.Enemy) {
+	player := addTestPlayer(e, "player", 1000, 1000)
+	player.Money = 100
+
+	thief := &types.Enemy{
+		ScreenObject: types.ScreenObject{ID: "thief", Position: &types.Vector2{X: 1000, Y: 1100}},
+		Type:         types.EnemyTypeThief,
+		Lives:        config.EnemyThiefLives,
+		Direction:    1,
+		IsAlive:      true,
+	}
+	e.state.enemiesByChunk["0,0"][thief.ID] = thief
+	return player, thief
+}
+
+func TestThiefStealsMoneyOnContactAndFlees(t *testing.T) {
+	e := newTestEngine(t)
+	player, thief := addTestThief(e)
+
+	for i := 0; i < 20 && thief.StolenMoney == 0; i++ {
+		tick(e, 50*time.Millisecond)
+	}
+
+	if player.Money != 50 || thief.StolenMoney != 50 {
+		t.Fatalf("expected the thief to grab half of the money, player has %d, thief %d", player.Money, thief.StolenMoney)
+	}
+	if len(e.state.bullets) != 0 {
+		t.Errorf("expected the thief not to shoot, got %d bullets", len(e.state.bullets))
+	}
+
+	distance := thief.DistanceToPoint(player.Position)
+	tick(e, 50*time.Millisecond)
+	if thief.DistanceToPoint(player.Position) <= distance {
+		t.Errorf("expected the thief to run off with the money, distance went from %.1f to %.1f", distance, thief.DistanceToPoint(player.Position))
+	}
+	if player.Money != 50 {
+		t.Errorf("expected the thief to steal only once, player has %d", player.Money)
+	}
+}
+
+func TestKillingThiefRecoversMoney(t *testing.T) {
+	e := newTestEngine(t)
+	player, thief := addTestThief(e)
+	thief.StolenMoney = 60
+	player.Money = 40
+
+	e.finishEnemy(thief, "0,0", "")
+
+	var chest *types.Bonus
+	for _, bonus := range e.state.bonuses {
+		chest = bonus
+	}
+	if len(e.state.bonuses) != 1 || chest.Type != types.BonusTypeChest {
+		t.Fatalf("expected the thief to drop a single chest, got %d bonuses", len(e.state.bonuses))
+	}
+
+	player.Position = &types.Vector2{X: chest.Position.X, Y: chest.Position.Y}
+	tick(e, 50*time.Millisecond)
+
+	if player.Money != 100 {
+		t.Errorf("expected the chest to give the stolen money back, player has %d", player.Money)
+	}
+}
+
+func TestThiefWithoutLootDropsNothing(t *testing.T) {
+	e := newTestEngine(t)
+	_, thief := addTestThief(e)
+
+	e.finishEnemy(thief, "0,0", "")
+
+	if len(e.state.bonuses) != 0 {
+		t.Errorf("expected a thief that stole nothing to drop nothing, got %d bonuses", len(e.state.bonuses))
+	}
+}
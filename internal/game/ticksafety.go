@@ -0,0 +1,63 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+)
+
+// maxBulletSpeed is the speed of the fastest bullet any weapon or enemy fires
+var maxBulletSpeed = math.Max(
+	math.Max(config.BlasterBulletSpeed, config.RocketLauncherBulletSpeed),
+	math.Max(config.EnemySoldierBulletSpeed, config.EnemyTowerBulletSpeed),
+)
+
+// MaxTickMovement returns the farthest a player, enemy or bullet can travel in one tick,
+// with players under a speed boost multiplying their speed by speedBoost
+func MaxTickMovement(interval time.Duration, sprintEnabled bool, speedBoost float64) float64 {
+	playerSpeed := config.PlayerSpeed * math.Max(speedBoost, 1)
+	if sprintEnabled {
+		playerSpeed *= config.SprintSpeedMultiplier
+	}
+
+	enemySpeed := math.Max(config.EnemySoldierSpeed, config.EnemyThiefSpeed) * math.Max(config.EnemyBruteSpeedMultiplier, config.EnemyScoutSpeedMultiplier)
+	speed := math.Max(math.Max(playerSpeed, enemySpeed), maxBulletSpeed)
+	return speed * interval.Seconds()
+}
+
+// ValidateTickSafety checks that nothing moves farther than the thinnest wall in
+// one tick. Not every collision is tested along the whole step, so longer steps
+// let entities tunnel through walls.
+func ValidateTickSafety(interval time.Duration, minWallThickness float64, sprintEnabled bool, speedBoost float64) error {
+	if interval <= 0 {
+		return fmt.Errorf("game loop interval must be positive, got %v", interval)
+	}
+
+	movement := MaxTickMovement(interval, sprintEnabled, speedBoost)
+	if movement >= minWallThickness {
+		return fmt.Errorf("entities may move %.1f units per %v tick, which is not less than the wall thickness of %.1f", movement, interval, minWallThickness)
+	}
+	return nil
+}
+
+// minWallThickness returns the thickness of the thinnest wall in the session, or the
+// thickness of generated walls when it has none
+func (e *Engine) minWallThickness() float64 {
+	thickness := e.wallThickness
+	for _, walls := range e.state.wallsByChunk {
+		for _, wall := range walls {
+			thickness = math.Min(thickness, math.Min(wall.Width, wall.Height))
+		}
+	}
+	return thickness
+}
+
+// checkTickSafety warns when walls loaded with the session are too thin for the game loop interval
+func (e *Engine) checkTickSafety() {
+	if err := ValidateTickSafety(e.tickInterval, e.minWallThickness(), e.sprintEnabled, e.powerUps.SpeedMultiplier); err != nil {
+		log.Printf("Session %s is prone to tunneling: %v", e.sessionID, err)
+	}
+}
@@ -0,0 +1,57 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestDefaultTickIntervalIsSafe(t *testing.T) {
+	if err := ValidateTickSafety(config.DefaultGameLoopInterval, config.WallWidth, true, config.PowerUpSpeedMultiplier); err != nil {
+		t.Fatalf("expected the default tick interval to be safe, got %v", err)
+	}
+}
+
+func TestSlowTickIntervalIsUnsafe(t *testing.T) {
+	if err := ValidateTickSafety(100*time.Millisecond, config.WallWidth, false, config.PowerUpSpeedMultiplier); err == nil {
+		t.Fatal("expected a 100ms tick to be unsafe for default walls")
+	}
+	if err := ValidateTickSafety(config.DefaultGameLoopInterval, 10, false, config.PowerUpSpeedMultiplier); err == nil {
+		t.Fatal("expected 10 unit thick walls to be unsafe at the default tick interval")
+	}
+	if err := ValidateTickSafety(0, config.WallWidth, false, config.PowerUpSpeedMultiplier); err == nil {
+		t.Fatal("expected a zero tick interval to be rejected")
+	}
+}
+
+func TestSprintCountsTowardsTickMovement(t *testing.T) {
+	interval := 50 * time.Millisecond
+	if walking, sprinting := MaxTickMovement(interval, false, config.PowerUpSpeedMultiplier), MaxTickMovement(interval, true, config.PowerUpSpeedMultiplier); sprinting <= walking {
+		t.Fatalf("expected sprinting to increase the tick movement, got %.1f and %.1f", walking, sprinting)
+	}
+}
+
+func TestSpeedBoostCountsTowardsTickMovement(t *testing.T) {
+	interval := 50 * time.Millisecond
+	if normal, boosted := MaxTickMovement(interval, false, config.PowerUpSpeedMultiplier), MaxTickMovement(interval, false, 3); boosted <= normal {
+		t.Fatalf("expected a stronger speed boost to increase the tick movement, got %.1f and %.1f", normal, boosted)
+	}
+}
+
+func TestMinWallThicknessIncludesLoadedWalls(t *testing.T) {
+	e := newTestEngine(t)
+	if got := e.minWallThickness(); got != config.WallWidth {
+		t.Fatalf("expected the generated wall thickness %.1f without walls, got %.1f", config.WallWidth, got)
+	}
+
+	e.state.wallsByChunk["0,0"]["thin"] = &types.Wall{
+		ScreenObject: types.ScreenObject{ID: "thin", Position: &types.Vector2{X: 500, Y: 500}},
+		Width:        200,
+		Height:       12,
+	}
+	if got := e.minWallThickness(); got != 12 {
+		t.Fatalf("expected the thinnest wall to be 12 units thick, got %.1f", got)
+	}
+}
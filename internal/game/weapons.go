@@ -0,0 +1,29 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+// ErrBlasterRequired is returned for weapon whitelists without the blaster every player starts with
+var ErrBlasterRequired = errors.New("the blaster can't be disallowed")
+
+// ValidateAllowedWeapons checks a session's weapon whitelist. An empty list allows every weapon.
+func ValidateAllowedWeapons(weaponTypes []string) error {
+	if len(weaponTypes) == 0 {
+		return nil
+	}
+
+	allowed := types.NewWeaponSet(weaponTypes)
+	for weaponType := range allowed {
+		if _, known := types.InventoryItemByWeaponType[weaponType]; !known {
+			return fmt.Errorf("unknown weapon %q", weaponType)
+		}
+	}
+	if !allowed.Allows(types.WeaponTypeBlaster) {
+		return ErrBlasterRequired
+	}
+	return nil
+}
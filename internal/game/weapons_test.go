@@ -0,0 +1,27 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestValidateAllowedWeapons(t *testing.T) {
+	tests := []struct {
+		name    string
+		weapons []string
+		valid   bool
+	}{
+		{"all weapons by default", nil, true},
+		{"blaster only", []string{types.WeaponTypeBlaster}, true},
+		{"no rockets", []string{types.WeaponTypeBlaster, types.WeaponTypeShotgun, types.WeaponTypeRailgun}, true},
+		{"unknown weapon", []string{types.WeaponTypeBlaster, "bfg"}, false},
+		{"without the blaster", []string{types.WeaponTypeShotgun}, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateAllowedWeapons(tt.weapons); (err == nil) != tt.valid {
+			t.Errorf("%s: got error %v, expected valid = %v", tt.name, err, tt.valid)
+		}
+	}
+}
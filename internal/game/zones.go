@@ -0,0 +1,28 @@
+package game
+
+import (
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+)
+
+// zoneForChunk returns the zone a chunk belongs to. Zones cover square regions
+// of chunks and are picked from the world seed, so a seed always yields the
+// same zones.
+func (e *Engine) zoneForChunk(chunkX, chunkY int) *config.Zone {
+	if !e.zonesEnabled || len(config.Zones) == 0 {
+		return &config.DefaultZone
+	}
+
+	regionX := floorDiv(chunkX, config.ZoneSizeInChunks)
+	regionY := floorDiv(chunkY, config.ZoneSizeInChunks)
+
+	return &config.Zones[e.seededHash(regionX, regionY)%uint64(len(config.Zones))]
+}
+
+// floorDiv divides rounding towards negative infinity, so regions don't straddle the origin
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
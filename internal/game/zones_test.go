@@ -0,0 +1,116 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func newZonedEngine(t *testing.T, seed string) *Engine {
+	t.Helper()
+
+	config.AppConfig = &config.Config{ZonesEnabled: true}
+	e := NewEngine("zone-test")
+	e.SetSeed(seed)
+	return e
+}
+
+func TestZonesCoverRegionsDeterministically(t *testing.T) {
+	e := newZonedEngine(t, "zones")
+	other := newZonedEngine(t, "zones")
+
+	names := make(map[string]bool)
+	for regionX := -5; regionX < 5; regionX++ {
+		for regionY := -5; regionY < 5; regionY++ {
+			firstX, firstY := regionX*config.ZoneSizeInChunks, regionY*config.ZoneSizeInChunks
+			zone := e.zoneForChunk(firstX, firstY)
+			names[zone.Name] = true
+
+			// Every chunk of the region shares its zone, in any engine with the same seed
+			for dx := 0; dx < config.ZoneSizeInChunks; dx++ {
+				for dy := 0; dy < config.ZoneSizeInChunks; dy++ {
+					if got := other.zoneForChunk(firstX+dx, firstY+dy); got.Name != zone.Name {
+						t.Fatalf("chunk (%d,%d) is in %q, expected region zone %q", firstX+dx, firstY+dy, got.Name, zone.Name)
+					}
+				}
+			}
+		}
+	}
+
+	if len(names) < 2 {
+		t.Errorf("expected the world to have several zones, got %v", names)
+	}
+}
+
+func TestZoneIsStoredWithChunk(t *testing.T) {
+	e := newZonedEngine(t, "zones")
+	e.generateChunk(-4, 7, &types.Vector2{X: 1e9, Y: 1e9})
+
+	want := e.zoneForChunk(-4, 7).Name
+	if got := e.zoneByChunk["-4,7"]; got != want {
+		t.Fatalf("expected chunk zone %q, got %q", want, got)
+	}
+
+	session := &db.GameSession{}
+	e.SaveToSession(session)
+	if got := session.WorldMap["-4,7"].Zone; got != want {
+		t.Fatalf("expected saved chunk zone %q, got %q", want, got)
+	}
+
+	loaded := newZonedEngine(t, "another-seed")
+	loaded.LoadFromSession(session)
+	if got := loaded.zoneByChunk["-4,7"]; got != want {
+		t.Errorf("expected loaded chunk zone %q, got %q", want, got)
+	}
+}
+
+func TestZonesDisabledUseDefaultZone(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	e := NewEngine("zone-test")
+	e.generateChunk(0, 0, &types.Vector2{X: 1e9, Y: 1e9})
+
+	if len(e.zoneByChunk) != 0 {
+		t.Errorf("expected no zones without ZONES_ENABLED, got %v", e.zoneByChunk)
+	}
+	if len(e.state.shopsByChunk["0,0"]) != 1 {
+		t.Errorf("expected every chunk to keep its shop, got %d", len(e.state.shopsByChunk["0,0"]))
+	}
+}
+
+func TestZoneIsSentWhenItChanges(t *testing.T) {
+	e := newZonedEngine(t, "zones")
+	e.zoneByChunk["0,0"] = "forest"
+	e.zoneByChunk["1,0"] = "cave"
+	player := addTestPlayer(e, "player", 100, 100)
+
+	if zone := e.GetGameStateDeltaForPlayer(player.ID).Zone; zone != "forest" {
+		t.Fatalf("expected the first delta to carry zone %q, got %q", "forest", zone)
+	}
+	if zone := e.GetGameStateDeltaForPlayer(player.ID).Zone; zone != "" {
+		t.Errorf("expected an unchanged zone not to be sent again, got %q", zone)
+	}
+
+	player.Position.X = config.ChunkSize + 100
+	if zone := e.GetGameStateDeltaForPlayer(player.ID).Zone; zone != "cave" {
+		t.Errorf("expected zone %q after moving, got %q", "cave", zone)
+	}
+
+	// Leaving every zone is sent too, and doesn't look like no change
+	player.Position.X = 2*config.ChunkSize + 100
+	if zone := e.GetGameStateDeltaForPlayer(player.ID).Zone; zone != config.NoZone {
+		t.Errorf("expected zone %q after moving out of the zones, got %q", config.NoZone, zone)
+	}
+	if zone := e.GetGameStateDeltaForPlayer(player.ID).Zone; zone != "" {
+		t.Errorf("expected leaving the zones to be sent once, got %q", zone)
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	for _, tt := range []struct{ a, b, want int }{{5, 3, 1}, {3, 3, 1}, {0, 3, 0}, {-1, 3, -1}, {-3, 3, -1}, {-4, 3, -2}} {
+		if got := floorDiv(tt.a, tt.b); got != tt.want {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bank actions
+const (
+	BankDeposit  = "deposit"
+	BankWithdraw = "withdraw"
+)
+
+// bankRefundAttempts is how many times money that couldn't be handed to the player is put back in the bank
+const bankRefundAttempts = 3
+
+// BankRequest moves money between the player in a session and their bank
+type BankRequest struct {
+	Action string `json:"action"` // BankDeposit or BankWithdraw
+	Amount int    `json:"amount"`
+}
+
+// BankResponse reports how much money was moved and what is left in the bank
+type BankResponse struct {
+	Amount      int `json:"amount"`
+	BankedMoney int `json:"banked_money"`
+}
+
+// HandleBank deposits money from the user's player in a running session to their bank,
+// or withdraws it back. Withdrawals stop at the session's money cap.
+func (h *SessionHandler) HandleBank(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !h.bankingEnabled {
+		utils.WriteJSONError(w, http.StatusNotFound, "Banking is disabled")
+		return
+	}
+
+	user, err := h.getCurrentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	var req BankRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 ||
+		(req.Action != BankDeposit && req.Action != BankWithdraw) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Extract session ID from URL path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sessions/")
+	sessionIDStr := strings.TrimSuffix(path, "/bank")
+
+	engine := h.liveSessions.GetSessionEngine(sessionIDStr)
+	if engine == nil {
+		utils.WriteJSONError(w, http.StatusConflict, "Session is not running")
+		return
+	}
+
+	ctx := context.Background()
+	playerID := user.ID.Hex()
+	var moved int
+
+	switch req.Action {
+	case BankDeposit:
+		moved = engine.DepositMoney(playerID, req.Amount)
+		if moved == 0 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "No money to deposit")
+			return
+		}
+		if _, err := h.userRepo.AddBankedMoney(ctx, user.ID, moved); err != nil {
+			log.Printf("Failed to bank %d for user %s: %v", moved, playerID, err)
+			if !engine.ReturnMoney(playerID, moved) {
+				log.Printf("Failed to return %d to player %s, who left the session", moved, playerID)
+			}
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to deposit money")
+			return
+		}
+
+	case BankWithdraw:
+		applied, err := h.userRepo.AddBankedMoney(ctx, user.ID, -req.Amount)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to withdraw money")
+			return
+		}
+		if !applied {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Not enough banked money")
+			return
+		}
+
+		// Whatever doesn't fit under the money cap, or can't be handed to the player, goes back
+		moved = engine.WithdrawMoney(playerID, req.Amount)
+		if refund := req.Amount - moved; refund > 0 {
+			if err := h.refundBank(ctx, user.ID, refund); err != nil {
+				log.Printf("Failed to return %d to the bank of user %s: %v", refund, playerID, err)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to return the rest of the withdrawal to the bank")
+				return
+			}
+		}
+		if moved == 0 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Player can't take any more money")
+			return
+		}
+	}
+
+	response := BankResponse{Amount: moved}
+	if updated, err := h.userRepo.FindByID(ctx, user.ID); err == nil {
+		response.BankedMoney = updated.BankedMoney
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// refundBank puts money back in the user's bank, retrying a few times since it was already taken out
+func (h *SessionHandler) refundBank(ctx context.Context, userID primitive.ObjectID, amount int) error {
+	var err error
+	for attempt := 0; attempt < bankRefundAttempts; attempt++ {
+		if _, err = h.userRepo.AddBankedMoney(ctx, userID, amount); err == nil {
+			return nil
+		}
+	}
+	return err
+}
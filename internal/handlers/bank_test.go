@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+var (
+	bankUpdated = mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+	bankFailed  = mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bank is down"})
+)
+
+// bankRequest signs a user in and puts their player with the given money into a running session
+// capped at 100, answering the user lookup. The caller queues the bank's responses.
+func bankRequest(mt *mtest.T, body string, money int) (*SessionHandler, *http.Request, *types.Player) {
+	mt.Helper()
+
+	userID := primitive.NewObjectID()
+	req := authorizeAs(mt, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/session/bank", strings.NewReader(body)), userID)
+	config.AppConfig.MaxMoney = 100
+	engine := game.NewEngine("session")
+	player := engine.ConnectPlayer(userID.Hex(), "player")
+	player.Money = money
+
+	h := &SessionHandler{
+		userRepo:       db.NewUserRepository(),
+		liveSessions:   &fakeLiveSessions{sessionID: "session", engine: engine},
+		bankingEnabled: true,
+	}
+	return h, req, player
+}
+
+func TestHandleBankPartialWithdraw(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("refunds what doesn't fit", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		h, req, player := bankRequest(mt, `{"action":"withdraw","amount":50}`, 80)
+		mt.AddMockResponses(bankUpdated, bankUpdated, mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "banked_money", Value: 30}},
+		))
+
+		rec := httptest.NewRecorder()
+		h.HandleBank(rec, req)
+
+		if rec.Code != http.StatusOK {
+			mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		var response BankResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			mt.Fatalf("response is not valid JSON: %v", err)
+		}
+		if response.Amount != 20 || player.Money != 100 {
+			mt.Errorf("withdrew %d leaving the player %d, want 20 up to the cap", response.Amount, player.Money)
+		}
+
+		mt.GetStartedEvent() // user lookup
+		mt.GetStartedEvent() // withdrawal
+		refund := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if got := refund.Lookup("u", "$inc", "banked_money").Int32(); got != 30 {
+			mt.Errorf("refunded %d, want the 30 over the cap", got)
+		}
+	})
+}
+
+func TestHandleBankFailedTransfers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("failed deposit goes back over the cap", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		// A player over the cap, as after a pickup the tick hasn't clamped yet
+		h, req, player := bankRequest(mt, `{"action":"deposit","amount":40}`, 120)
+		mt.AddMockResponses(bankFailed)
+
+		rec := httptest.NewRecorder()
+		h.HandleBank(rec, req)
+
+		if decodeError(mt.T, rec, http.StatusInternalServerError).Message != "Failed to deposit money" {
+			mt.Errorf("unexpected error for a failed deposit")
+		}
+		if player.Money != 120 {
+			mt.Errorf("expected the whole deposit back, player has %d", player.Money)
+		}
+	})
+
+	mt.Run("failed refund is retried and reported", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		h, req, player := bankRequest(mt, `{"action":"withdraw","amount":50}`, 80)
+		mt.AddMockResponses(bankUpdated, bankFailed, bankFailed, bankFailed)
+
+		rec := httptest.NewRecorder()
+		h.HandleBank(rec, req)
+
+		decodeError(mt.T, rec, http.StatusInternalServerError)
+		if player.Money != 100 {
+			mt.Errorf("expected the player to keep the part under the cap, has %d", player.Money)
+		}
+
+		mt.GetStartedEvent() // user lookup
+		mt.GetStartedEvent() // withdrawal
+		for attempt := 1; attempt <= bankRefundAttempts; attempt++ {
+			if evt := mt.GetStartedEvent(); evt == nil || evt.CommandName != "update" {
+				mt.Fatalf("expected refund attempt %d, got %v", attempt, evt)
+			}
+		}
+	})
+}
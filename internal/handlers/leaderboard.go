@@ -3,11 +3,15 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // LeaderboardHandler handles leaderboard-related HTTP requests
@@ -24,8 +28,12 @@ func NewLeaderboardHandler() *LeaderboardHandler {
 	}
 }
 
+// maxLeaderboardUserIDs bounds how many users can be looked up in one request
+const maxLeaderboardUserIDs = 100
+
 // LeaderboardEntry represents an entry in the leaderboard
 type LeaderboardEntry struct {
+	UserID      string `json:"userId"`
 	Username    string `json:"username"`
 	Score       int    `json:"score"`
 	SessionID   string `json:"sessionId"`
@@ -35,20 +43,26 @@ type LeaderboardEntry struct {
 
 // UserStats represents user statistics
 type UserStats struct {
-	TotalGames   int     `json:"total_games"`
-	HighestScore int     `json:"highest_score"`
-	AverageScore float64 `json:"average_score"`
-	RecentScores []struct {
-		Score     int    `json:"score"`
-		SessionID string `json:"session_id"`
-		CreatedAt string `json:"created_at"`
-	} `json:"recent_scores"`
+	TotalGames   int           `json:"total_games"`
+	HighestScore int           `json:"highest_score"`
+	AverageScore float64       `json:"average_score"`
+	RecentScores []RecentScore `json:"recent_scores"`
+}
+
+// RecentScore is a user's score in one of their recent sessions
+type RecentScore struct {
+	Score     int    `json:"score"`
+	SessionID string `json:"session_id"`
+	CreatedAt string `json:"created_at"`
 }
 
+// recentScoresInStats is how many of the user's latest sessions their stats list
+const recentScoresInStats = 5
+
 // HandleGetGlobalLeaderboard returns the global leaderboard
 func (h *LeaderboardHandler) HandleGetGlobalLeaderboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -67,14 +81,160 @@ func (h *LeaderboardHandler) HandleGetGlobalLeaderboard(w http.ResponseWriter, r
 	leaderboardRepo := db.NewLeaderboardRepository()
 	dbEntries, err := leaderboardRepo.GetTopScores(ctx, limit)
 	if err != nil {
-		http.Error(w, "Failed to fetch leaderboard", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch leaderboard")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toLeaderboardEntries(dbEntries))
+}
+
+// HandleGetSessionLeaderboard returns the leaderboard of the session in the path, /api/v1/leaderboard/session/{id}
+func (h *LeaderboardHandler) HandleGetSessionLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, "/api/v1/leaderboard/session/"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
-	// Convert to response format
+	// Parse query parameters
+	limitStr := r.URL.Query().Get("limit")
+	limit := 100
+	if limitStr != "" {
+		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	leaderboardRepo := db.NewLeaderboardRepository()
+	dbEntries, err := leaderboardRepo.GetTopScoresBySession(ctx, sessionID.Hex(), limit)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch leaderboard")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toLeaderboardEntries(dbEntries))
+}
+
+// HandleGetUsersLeaderboard returns the entries of the users listed in the comma-separated ids
+// query parameter, e.g. a player's friends. Unknown users simply have no entries.
+func (h *LeaderboardHandler) HandleGetUsersLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if _, err := currentUser(r); err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	idsStr := r.URL.Query().Get("ids")
+	if idsStr == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Missing user IDs")
+		return
+	}
+
+	idStrs := strings.Split(idsStr, ",")
+	if len(idStrs) > maxLeaderboardUserIDs {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("At most %d user IDs are allowed", maxLeaderboardUserIDs))
+		return
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(idStrs))
+	for _, idStr := range idStrs {
+		userID, err := primitive.ObjectIDFromHex(strings.TrimSpace(idStr))
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid user ID format")
+			return
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	leaderboardRepo := db.NewLeaderboardRepository()
+	dbEntries, err := leaderboardRepo.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch leaderboard")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toLeaderboardEntries(dbEntries))
+}
+
+// HandleGetMyStats returns the authenticated user's stats across all the sessions they played
+func (h *LeaderboardHandler) HandleGetMyStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, err := currentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	leaderboardRepo := db.NewLeaderboardRepository()
+	dbEntries, err := leaderboardRepo.GetAllEntriesForUser(ctx, user.ID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toUserStats(dbEntries))
+}
+
+// toUserStats sums up a user's entries, which come most recently updated first
+func toUserStats(dbEntries []db.LeaderboardEntry) UserStats {
+	stats := UserStats{
+		TotalGames:   len(dbEntries),
+		RecentScores: []RecentScore{},
+	}
+
+	totalScore := 0
+	for i, entry := range dbEntries {
+		totalScore += entry.Score
+		if i == 0 || entry.Score > stats.HighestScore {
+			stats.HighestScore = entry.Score
+		}
+		if i < recentScoresInStats {
+			stats.RecentScores = append(stats.RecentScores, RecentScore{
+				Score:     entry.Score,
+				SessionID: entry.SessionID,
+				CreatedAt: entry.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+	}
+	if len(dbEntries) > 0 {
+		stats.AverageScore = float64(totalScore) / float64(len(dbEntries))
+	}
+
+	return stats
+}
+
+// toLeaderboardEntries converts database entries to the response format
+func toLeaderboardEntries(dbEntries []db.LeaderboardEntry) []LeaderboardEntry {
 	entries := make([]LeaderboardEntry, len(dbEntries))
 	for i, entry := range dbEntries {
 		entries[i] = LeaderboardEntry{
+			UserID:      entry.UserID.Hex(),
 			Username:    entry.Username,
 			Score:       entry.Score,
 			SessionID:   entry.SessionID,
@@ -82,7 +242,5 @@ func (h *LeaderboardHandler) HandleGetGlobalLeaderboard(w http.ResponseWriter, r
 			CreatedAt:   entry.UpdatedAt.Format(time.RFC3339),
 		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(entries)
+	return entries
 }
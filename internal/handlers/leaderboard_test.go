@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/auth"
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// authorize signs the request in as a new active user, whose lookup the mock database answers next
+func authorize(mt *mtest.T, req *http.Request) *http.Request {
+	mt.Helper()
+	return authorizeAs(mt, req, primitive.NewObjectID())
+}
+
+// authorizeAs is authorize for a given user
+func authorizeAs(mt *mtest.T, req *http.Request, userID primitive.ObjectID) *http.Request {
+	mt.Helper()
+
+	config.AppConfig = &config.Config{SecretKey: "test-secret", AccessTokenExpireMinutes: 5}
+	mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch,
+		bson.D{{Key: "_id", Value: userID}, {Key: "username", Value: "player"}, {Key: "is_active", Value: true}},
+	))
+
+	token, err := auth.GenerateToken(userID)
+	if err != nil {
+		mt.Fatalf("GenerateToken() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestHandleGetUsersLeaderboard(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	h := &LeaderboardHandler{}
+
+	mt.Run("known and unknown ids", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		known := primitive.NewObjectID()
+		unknown := primitive.NewObjectID()
+		url := "/api/v1/leaderboard/users?ids=" + known.Hex() + "," + unknown.Hex()
+		req := authorize(mt, httptest.NewRequest(http.MethodGet, url, nil))
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: known}, {Key: "username", Value: "friend"}, {Key: "score", Value: 300}, {Key: "session_id", Value: "s1"}},
+		))
+
+		rec := httptest.NewRecorder()
+		h.HandleGetUsersLeaderboard(rec, req)
+
+		if rec.Code != http.StatusOK {
+			mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		var entries []LeaderboardEntry
+		if err := json.NewDecoder(rec.Body).Decode(&entries); err != nil {
+			mt.Fatalf("response is not valid JSON: %v", err)
+		}
+		if len(entries) != 1 || entries[0].UserID != known.Hex() || entries[0].Score != 300 {
+			mt.Errorf("entries = %+v, want only the known user's entry", entries)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		wantStatus int
+	}{
+		{"wrong method", http.MethodPost, "?ids=" + primitive.NewObjectID().Hex(), http.StatusMethodNotAllowed},
+		{"missing ids", http.MethodGet, "", http.StatusBadRequest},
+		{"invalid id", http.MethodGet, "?ids=" + primitive.NewObjectID().Hex() + ",nope", http.StatusBadRequest},
+		{"too many ids", http.MethodGet, "?ids=" + strings.Repeat(primitive.NewObjectID().Hex()+",", maxLeaderboardUserIDs) + primitive.NewObjectID().Hex(), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			rec := httptest.NewRecorder()
+			h.HandleGetUsersLeaderboard(rec, authorize(mt, httptest.NewRequest(tt.method, "/api/v1/leaderboard/users"+tt.query, nil)))
+			decodeError(mt.T, rec, tt.wantStatus)
+		})
+	}
+
+	t.Run("without token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.HandleGetUsersLeaderboard(rec, httptest.NewRequest(http.MethodGet, "/api/v1/leaderboard/users?ids="+primitive.NewObjectID().Hex(), nil))
+		decodeError(t, rec, http.StatusUnauthorized)
+	})
+}
+
+func TestHandleGetSessionLeaderboard(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	h := &LeaderboardHandler{}
+	sessionID := primitive.NewObjectID().Hex()
+
+	get := func(mt *mtest.T) []LeaderboardEntry {
+		rec := httptest.NewRecorder()
+		h.HandleGetSessionLeaderboard(rec, httptest.NewRequest(http.MethodGet, "/api/v1/leaderboard/session/"+sessionID+"?limit=10", nil))
+
+		if rec.Code != http.StatusOK {
+			mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		var entries []LeaderboardEntry
+		if err := json.NewDecoder(rec.Body).Decode(&entries); err != nil {
+			mt.Fatalf("response is not valid JSON: %v", err)
+		}
+		return entries
+	}
+
+	mt.Run("entries", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch,
+			bson.D{{Key: "user_id", Value: primitive.NewObjectID()}, {Key: "username", Value: "top"}, {Key: "score", Value: 500}, {Key: "session_id", Value: sessionID}},
+			bson.D{{Key: "user_id", Value: primitive.NewObjectID()}, {Key: "username", Value: "second"}, {Key: "score", Value: 200}, {Key: "session_id", Value: sessionID}},
+		))
+
+		entries := get(mt)
+		if len(entries) != 2 || entries[0].Score != 500 || entries[0].SessionID != sessionID {
+			mt.Errorf("entries = %+v, want both entries of the session", entries)
+		}
+	})
+
+	mt.Run("no entries yet", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.leaderboard", mtest.FirstBatch))
+
+		if entries := get(mt); entries == nil || len(entries) != 0 {
+			mt.Errorf("entries = %+v, want an empty array", entries)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"wrong method", http.MethodPost, "/api/v1/leaderboard/session/" + sessionID, http.StatusMethodNotAllowed},
+		{"invalid id", http.MethodGet, "/api/v1/leaderboard/session/nope", http.StatusBadRequest},
+		{"missing id", http.MethodGet, "/api/v1/leaderboard/session/", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleGetSessionLeaderboard(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			decodeError(t, rec, tt.wantStatus)
+		})
+	}
+}
+
+func TestToUserStats(t *testing.T) {
+	now := time.Now()
+	var entries []db.LeaderboardEntry
+	for i, score := range []int{120, 500, 80, 300, 0, 200} {
+		entries = append(entries, db.LeaderboardEntry{
+			Score:     score,
+			SessionID: fmt.Sprintf("session-%d", i),
+			UpdatedAt: now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+
+	stats := toUserStats(entries)
+	if stats.TotalGames != 6 || stats.HighestScore != 500 || stats.AverageScore != 200 {
+		t.Errorf("stats = %+v, want 6 games, highest 500 and average 200", stats)
+	}
+	if len(stats.RecentScores) != 5 || stats.RecentScores[0].SessionID != "session-0" || stats.RecentScores[4].Score != 0 {
+		t.Errorf("recent scores = %+v, want the five latest sessions in order", stats.RecentScores)
+	}
+
+	empty := toUserStats(nil)
+	if empty.TotalGames != 0 || empty.AverageScore != 0 || empty.RecentScores == nil {
+		t.Errorf("stats without entries = %+v, want zeros and an empty list", empty)
+	}
+}
+
+func TestHandleGetMyStatsErrors(t *testing.T) {
+	h := &LeaderboardHandler{}
+
+	tests := []struct {
+		name       string
+		method     string
+		wantStatus int
+	}{
+		{"wrong method", http.MethodPost, http.StatusMethodNotAllowed},
+		{"without token", http.MethodGet, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleGetMyStats(rec, httptest.NewRequest(tt.method, "/api/v1/leaderboard/me", nil))
+			decodeError(t, rec, tt.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// rateLimitSweepInterval is how often buckets of clients that have gone quiet are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimiter limits how many requests each client IP makes to an endpoint. Every client
+// has a bucket of perMinute requests that refills evenly over a minute.
+type RateLimiter struct {
+	mu         sync.Mutex
+	perMinute  float64
+	trustProxy bool
+	buckets    map[string]*rateBucket
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+type rateBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter returns a limiter allowing perMinute requests per client IP, or nil when
+// perMinute is 0. With trustProxy, clients are told apart by the last X-Forwarded-For address,
+// the one the proxy appended, as anything before it is up to the client.
+func NewRateLimiter(perMinute int, trustProxy bool) *RateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		perMinute:  float64(perMinute),
+		trustProxy: trustProxy,
+		buckets:    make(map[string]*rateBucket),
+		lastSweep:  time.Now(),
+		now:        time.Now,
+	}
+}
+
+// Wrap rejects requests over the limit with 429 Too Many Requests and a Retry-After header
+// before they reach next. A nil limiter lets every request through.
+func (l *RateLimiter) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, allowed := l.allow(l.clientIP(r)); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			utils.WriteJSONError(w, http.StatusTooManyRequests, "Too many requests, try again later")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// allow takes a request from the client's bucket, or reports how long until there is one
+func (l *RateLimiter) allow(client string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	refillPerSecond := l.perMinute / 60
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		// A bucket untouched for a minute is full again, as good as a new one
+		for ip, bucket := range l.buckets {
+			if now.Sub(bucket.updated) >= time.Minute {
+				delete(l.buckets, ip)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, exists := l.buckets[client]
+	if !exists {
+		bucket = &rateBucket{tokens: l.perMinute, updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(l.perMinute, bucket.tokens+now.Sub(bucket.updated).Seconds()*refillPerSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / refillPerSecond * float64(time.Second)), false
+	}
+	bucket.tokens--
+	return 0, true
+}
+
+// clientIP returns the address requests are counted against
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	if l.trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			addresses := strings.Split(forwarded, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// limitedRequest sends a request from the address through the limited handler and returns the response
+func limitedRequest(handler http.HandlerFunc, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestRateLimiterRejectsClientsOverLimit(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(2, false)
+	limiter.now = func() time.Time { return now }
+	handler := limiter.Wrap(okHandler)
+
+	for i := 0; i < 2; i++ {
+		if rec := limitedRequest(handler, "10.0.0.1:1234", ""); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+
+	rec := limitedRequest(handler, "10.0.0.1:5678", "")
+	apiErr := decodeError(t, rec, http.StatusTooManyRequests)
+	if apiErr.Code != "too_many_requests" {
+		t.Errorf("error code = %q, want too_many_requests", apiErr.Code)
+	}
+	// Two requests a minute refill one every 30 seconds
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "30" {
+		t.Errorf("Retry-After = %q, want 30", retryAfter)
+	}
+
+	if rec := limitedRequest(handler, "10.0.0.2:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want 200", rec.Code)
+	}
+
+	now = now.Add(30 * time.Second)
+	if rec := limitedRequest(handler, "10.0.0.1:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("after refill: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimiterUsesForwardedForOnlyWhenTrusted(t *testing.T) {
+	for _, trustProxy := range []bool{false, true} {
+		limiter := NewRateLimiter(1, trustProxy)
+		handler := limiter.Wrap(okHandler)
+
+		limitedRequest(handler, "10.0.0.1:1234", "203.0.113.1")
+		rec := limitedRequest(handler, "10.0.0.1:1234", "203.0.113.2")
+
+		// Behind a trusted proxy the two requests come from different clients
+		wantStatus := http.StatusTooManyRequests
+		if trustProxy {
+			wantStatus = http.StatusOK
+		}
+		if rec.Code != wantStatus {
+			t.Errorf("trustProxy = %v: status = %d, want %d", trustProxy, rec.Code, wantStatus)
+		}
+	}
+}
+
+func TestRateLimiterIgnoresForwardedForSetByClients(t *testing.T) {
+	handler := NewRateLimiter(1, true).Wrap(okHandler)
+
+	limitedRequest(handler, "10.0.0.1:1234", "203.0.113.1")
+	// The proxy appends the real address after whatever the client sent
+	rec := limitedRequest(handler, "10.0.0.1:1234", "198.51.100.7, 203.0.113.1")
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d for a client forging X-Forwarded-For", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimiterDisabledWithoutLimit(t *testing.T) {
+	handler := NewRateLimiter(0, false).Wrap(okHandler)
+	for i := 0; i < 100; i++ {
+		if rec := limitedRequest(handler, "10.0.0.1:1234", ""); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterDropsQuietClients(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(5, false)
+	limiter.now = func() time.Time { return now }
+	handler := limiter.Wrap(okHandler)
+
+	limitedRequest(handler, "10.0.0.1:1234", "")
+	now = now.Add(2 * time.Minute)
+	limitedRequest(handler, "10.0.0.2:1234", "")
+
+	if _, exists := limiter.buckets["10.0.0.1"]; exists || len(limiter.buckets) != 1 {
+		t.Errorf("expected only the active client's bucket to be kept, got %d buckets", len(limiter.buckets))
+	}
+}
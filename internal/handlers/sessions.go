@@ -3,101 +3,198 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/besuhoff/dungeon-game-go/internal/auth"
+	"github.com/besuhoff/dungeon-game-go/internal/config"
 	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// LiveSessions gives access to the sessions running on this server
+type LiveSessions interface {
+	GetSessionEngine(sessionID string) *game.Engine
+	// GetLivePlayerCount returns how many players are in the session, and whether it's loaded
+	GetLivePlayerCount(sessionID string) (int, bool)
+	// KickPlayer takes the player out of the running session and closes their connection,
+	// returning false when they aren't in it
+	KickPlayer(sessionID, playerID string) bool
+	// ReserveSlot holds a place in the session for the user until they connect,
+	// returning false when the session is already full
+	ReserveSlot(sessionID, userID string, maxPlayers int) bool
+	// JoinWaitlist queues the user for the next free slot in the session and returns their position,
+	// returning false when waitlists are turned off
+	JoinWaitlist(sessionID, userID string) (int, bool)
+	// WaitlistStatus returns the user's position on the waitlist and whether a slot is reserved for them,
+	// returning false when they aren't on it
+	WaitlistStatus(sessionID, userID string) (int, bool, bool)
+	// GetSessionActivity returns the activity of the running session and how long it has been running,
+	// returning false when it isn't loaded or sessions don't track their activity
+	GetSessionActivity(sessionID string) (db.SessionActivity, time.Duration, bool)
+}
+
 // SessionHandler handles session-related HTTP requests
 type SessionHandler struct {
-	sessionRepo *db.GameSessionRepository
-	userRepo    *db.UserRepository
+	sessionRepo  *db.GameSessionRepository
+	userRepo     *db.UserRepository
+	liveSessions LiveSessions
+
+	// Sessions created without a name get a random one instead of being rejected
+	autoSessionNames bool
+
+	// Players can move money between the session and their account's bank
+	bankingEnabled bool
+
+	// Shops are listed whether or not anyone has discovered them
+	revealAllShops bool
 }
 
 // NewSessionHandler creates a new session handler
-func NewSessionHandler() *SessionHandler {
+func NewSessionHandler(liveSessions LiveSessions) *SessionHandler {
 	return &SessionHandler{
-		sessionRepo: db.NewGameSessionRepository(),
-		userRepo:    db.NewUserRepository(),
+		sessionRepo:  db.NewGameSessionRepository(),
+		userRepo:     db.NewUserRepository(),
+		liveSessions: liveSessions,
+
+		autoSessionNames: config.AppConfig.AutoSessionNames,
+		bankingEnabled:   config.AppConfig.BankingEnabled,
+		revealAllShops:   config.AppConfig.RevealAllShops,
 	}
 }
 
 // CreateSessionRequest represents the request body for creating a session
 type CreateSessionRequest struct {
-	Name       string `json:"name"`
-	MaxPlayers int    `json:"max_players"`
-	IsPrivate  bool   `json:"is_private"`
-	Password   string `json:"password,omitempty"`
+	Name           string   `json:"name"`
+	MaxPlayers     int      `json:"max_players"`
+	IsPrivate      bool     `json:"is_private"`
+	Password       string   `json:"password,omitempty"`
+	Seed           string   `json:"seed,omitempty"`
+	AllowedWeapons []string `json:"allowed_weapons,omitempty"`
+	RecordInputs   bool     `json:"record_inputs,omitempty"`
+	FriendlyFire   *bool    `json:"friendly_fire,omitempty"`
+	Unlisted       bool     `json:"unlisted,omitempty"`
 }
 
 // SessionResponse represents a game session response
 type SessionResponse struct {
-	ID            string                    `json:"id"`
-	Name          string                    `json:"name"`
-	Host          UserResponse              `json:"host"`
-	MaxPlayers    int                       `json:"max_players"`
-	IsPrivate     bool                      `json:"is_private"`
-	WorldMap      map[string]db.Chunk       `json:"world_map"`
-	SharedObjects map[string]db.WorldObject `json:"shared_objects"`
-	GameState     map[string]interface{}    `json:"game_state"`
-	PlayerRoles   map[string]string         `json:"player_roles"`
-	Players       map[string]db.PlayerState `json:"players"`
-	CreatedAt     string                    `json:"created_at"`
-	IsActive      bool                      `json:"is_active"`
+	ID             string                    `json:"id"`
+	Name           string                    `json:"name"`
+	Host           *UserResponse             `json:"host,omitempty"`
+	MaxPlayers     int                       `json:"max_players"`
+	IsPrivate      bool                      `json:"is_private"`
+	WorldMap       map[string]db.Chunk       `json:"world_map"`
+	SharedObjects  map[string]db.WorldObject `json:"shared_objects"`
+	GameState      map[string]interface{}    `json:"game_state"`
+	PlayerRoles    map[string]string         `json:"player_roles"`
+	Players        map[string]db.PlayerState `json:"players"`
+	CreatedAt      string                    `json:"created_at"`
+	IsActive       bool                      `json:"is_active"`
+	Seed           string                    `json:"seed,omitempty"`
+	AllowedWeapons []string                  `json:"allowed_weapons,omitempty"`
+	RecordInputs   bool                      `json:"record_inputs,omitempty"`
+	FriendlyFire   bool                      `json:"friendly_fire"`
+	Unlisted       bool                      `json:"unlisted,omitempty"`
 }
 
-// UserResponse represents a user in responses
-type UserResponse struct {
-	ID             string `json:"id"`
-	Email          string `json:"email"`
-	Username       string `json:"username"`
-	GoogleID       string `json:"google_id,omitempty"`
-	IsActive       bool   `json:"is_active"`
-	CurrentSession string `json:"current_session,omitempty"`
-	CreatedAt      string `json:"created_at"`
+// WaitlistResponse represents a player's place on a full session's waitlist
+type WaitlistResponse struct {
+	Position int  `json:"position"`
+	Ready    bool `json:"ready"`
+}
+
+// SessionStateResponse represents a session along with its state on the server, if it's running
+type SessionStateResponse struct {
+	SessionResponse
+	Live     *LiveSessionResponse     `json:"live,omitempty"`
+	Activity *SessionActivityResponse `json:"activity,omitempty"`
+}
+
+// SessionActivityResponse represents how busy a session has been over its lifetime
+type SessionActivityResponse struct {
+	StartedAt     string `json:"started_at"`
+	PeakPlayers   int    `json:"peak_players"`
+	UniquePlayers int    `json:"unique_players"`
+	// Seconds the session has been running on the server, left out when it isn't loaded
+	UptimeSeconds int64 `json:"uptime_seconds,omitempty"`
 }
 
+// LiveSessionResponse represents the in-memory state of a running session
+type LiveSessionResponse struct {
+	PlayerCount  int                  `json:"player_count"`
+	AlivePlayers []LivePlayerResponse `json:"alive_players"`
+}
+
+// LivePlayerResponse represents a living player of a running session
+type LivePlayerResponse struct {
+	ID       string  `json:"id"`
+	Username string  `json:"username"`
+	Lives    float32 `json:"lives"`
+	Score    int     `json:"score"`
+	Kills    int     `json:"kills"`
+}
+
+// UserResponse represents a user in responses
+type UserResponse = auth.UserResponse
+
 // getCurrentUser extracts and validates the JWT token, returning the user
 func (h *SessionHandler) getCurrentUser(r *http.Request) (*db.User, error) {
+	return currentUser(r)
+}
+
+// currentUser extracts and validates the JWT token of the request, returning its user as long as they are active
+func currentUser(r *http.Request) (*db.User, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		return nil, http.ErrNoCookie
 	}
 
 	token := strings.TrimPrefix(authHeader, "Bearer ")
-	userID, err := auth.ValidateToken(token)
-	if err != nil {
-		return nil, err
-	}
+	return auth.AuthenticateActiveUser(context.Background(), token)
+}
 
-	ctx := context.Background()
-	return h.userRepo.FindByID(ctx, userID)
+// writeAuthError responds to a request whose user couldn't be authenticated, deactivated users are forbidden
+func writeAuthError(w http.ResponseWriter, err error) {
+	if errors.Is(err, auth.ErrUserInactive) {
+		utils.WriteJSONError(w, http.StatusForbidden, "Forbidden: user is inactive")
+		return
+	}
+	utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
 }
 
 // HandleCreateSession creates a new game session
 func (h *SessionHandler) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	user, err := h.getCurrentUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeAuthError(w, err)
 		return
 	}
 
 	var req CreateSessionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	// Quick-create flows may leave the name out, explicit names are always kept
+	if req.Name == "" && h.autoSessionNames {
+		req.Name = game.NewSessionName()
+	}
+
 	if req.Name == "" || len(req.Name) > 50 {
-		http.Error(w, "Name must be between 1 and 50 characters", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Name must be between 1 and 50 characters")
 		return
 	}
 
@@ -105,24 +202,47 @@ func (h *SessionHandler) HandleCreateSession(w http.ResponseWriter, r *http.Requ
 		req.MaxPlayers = 10
 	}
 
+	// A shared seed recreates the world of another session, otherwise the session gets a fresh one
+	if req.Seed == "" {
+		req.Seed = game.NewSeed()
+	} else if err := game.ValidateSeed(req.Seed); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid seed: "+err.Error())
+		return
+	}
+
+	if err := game.ValidateAllowedWeapons(req.AllowedWeapons); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid allowed weapons: "+err.Error())
+		return
+	}
+
+	if req.Unlisted && !req.IsPrivate {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Only private sessions can be unlisted")
+		return
+	}
+
 	ctx := context.Background()
 	session := &db.GameSession{
-		Name:       req.Name,
-		HostID:     user.ID,
-		MaxPlayers: req.MaxPlayers,
-		IsPrivate:  req.IsPrivate,
-		Password:   req.Password,
-		Players:    map[string]db.PlayerState{},
+		Name:           req.Name,
+		HostID:         user.ID,
+		MaxPlayers:     req.MaxPlayers,
+		IsPrivate:      req.IsPrivate,
+		Password:       req.Password,
+		Players:        map[string]db.PlayerState{},
+		Seed:           req.Seed,
+		AllowedWeapons: req.AllowedWeapons,
+		RecordInputs:   req.RecordInputs,
+		FriendlyFire:   req.FriendlyFire,
+		Unlisted:       req.Unlisted,
 	}
 
 	if err := h.sessionRepo.Create(ctx, session); err != nil {
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create session")
 		return
 	}
 
 	// Update user's current session
 	user.CurrentSession = session.ID.Hex()
-	h.userRepo.Update(ctx, user)
+	h.userRepo.SetCurrentSession(ctx, user.ID, user.CurrentSession)
 
 	response := h.sessionToResponse(session, user)
 	w.Header().Set("Content-Type", "application/json")
@@ -133,25 +253,29 @@ func (h *SessionHandler) HandleCreateSession(w http.ResponseWriter, r *http.Requ
 // HandleListSessions lists all active sessions
 func (h *SessionHandler) HandleListSessions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	_, err := h.getCurrentUser(r)
+	user, err := h.getCurrentUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeAuthError(w, err)
 		return
 	}
 
 	ctx := context.Background()
 	sessions, err := h.sessionRepo.FindActiveSessions(ctx)
 	if err != nil {
-		http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch sessions")
 		return
 	}
 
 	responses := make([]SessionResponse, 0, len(sessions))
 	for _, session := range sessions {
+		if !listedFor(&session, user) {
+			continue
+		}
+
 		host, err := h.userRepo.FindByID(ctx, session.HostID)
 		if err != nil {
 			continue
@@ -163,16 +287,117 @@ func (h *SessionHandler) HandleListSessions(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(responses)
 }
 
+// listedFor reports whether the session shows up in the user's session list,
+// unlisted sessions are only listed for their host
+func listedFor(session *db.GameSession, user *db.User) bool {
+	return !session.Unlisted || session.HostID == user.ID
+}
+
+// HandleGetSession returns a session, with its live state when it's running on this server
+func (h *SessionHandler) HandleGetSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	_, err := h.getCurrentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	// Extract session ID from URL path
+	sessionIDStr := strings.TrimPrefix(r.URL.Path, "/api/v1/sessions/")
+
+	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	ctx := context.Background()
+	session, err := h.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	// A session whose host account is gone is shown without a host
+	host, err := h.userRepo.FindByID(ctx, session.HostID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch session host")
+		return
+	}
+	response := SessionStateResponse{
+		SessionResponse: h.sessionToResponse(session, host),
+		Live:            h.liveSessionState(sessionIDStr),
+		Activity:        h.sessionActivity(session),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// sessionActivity returns the activity of the session, kept up to date while it's running on this server.
+// Returns nil for sessions nobody played since activity was tracked.
+func (h *SessionHandler) sessionActivity(session *db.GameSession) *SessionActivityResponse {
+	activity, uptime, loaded := h.liveSessions.GetSessionActivity(session.ID.Hex())
+	if !loaded {
+		activity, uptime = session.Activity, 0
+	}
+	if activity.StartedAt.IsZero() {
+		return nil
+	}
+
+	return &SessionActivityResponse{
+		StartedAt:     activity.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+		PeakPlayers:   activity.PeakPlayers,
+		UniquePlayers: len(activity.PlayerIDs),
+		UptimeSeconds: int64(uptime.Seconds()),
+	}
+}
+
+// liveSessionState returns the state of a session running on this server, nil when it isn't loaded
+func (h *SessionHandler) liveSessionState(sessionID string) *LiveSessionResponse {
+	playerCount, loaded := h.liveSessions.GetLivePlayerCount(sessionID)
+	engine := h.liveSessions.GetSessionEngine(sessionID)
+	if !loaded || engine == nil {
+		return nil
+	}
+
+	live := &LiveSessionResponse{PlayerCount: playerCount, AlivePlayers: make([]LivePlayerResponse, 0)}
+	for _, player := range engine.GetAllPlayers() {
+		if !player.IsAlive || !player.IsConnected {
+			continue
+		}
+		live.AlivePlayers = append(live.AlivePlayers, LivePlayerResponse{
+			ID:       player.ID,
+			Username: player.Username,
+			Lives:    player.Lives,
+			Score:    player.Score,
+			Kills:    player.Kills,
+		})
+	}
+	sort.Slice(live.AlivePlayers, func(i, j int) bool {
+		if live.AlivePlayers[i].Score != live.AlivePlayers[j].Score {
+			return live.AlivePlayers[i].Score > live.AlivePlayers[j].Score
+		}
+		return live.AlivePlayers[i].ID < live.AlivePlayers[j].ID
+	})
+
+	return live
+}
+
 // HandleJoinSession joins an existing session
 func (h *SessionHandler) HandleJoinSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	user, err := h.getCurrentUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeAuthError(w, err)
 		return
 	}
 
@@ -182,42 +407,48 @@ func (h *SessionHandler) HandleJoinSession(w http.ResponseWriter, r *http.Reques
 
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
 	var body struct {
 		Password string `json:"password"`
+		Waitlist bool   `json:"waitlist"`
 	}
 	json.NewDecoder(r.Body).Decode(&body)
 
 	ctx := context.Background()
 	session, err := h.sessionRepo.FindByID(ctx, sessionID)
 	if err != nil {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
 		return
 	}
 
-	connectedPlayersCount := 0
-	for _, p := range session.Players {
-		if p.IsConnected {
-			connectedPlayersCount++
-		}
-	} 
-	if connectedPlayersCount >= session.MaxPlayers {
-		http.Error(w, "Session is full", http.StatusBadRequest)
+	if session.IsPrivate && session.Password != body.Password {
+		utils.WriteJSONError(w, http.StatusForbidden, "Invalid password")
 		return
 	}
 
-	if session.IsPrivate && session.Password != body.Password {
-		http.Error(w, "Invalid password", http.StatusForbidden)
+	// Count live connections and pending joins together, so concurrent joins can't overfill the session
+	if !h.liveSessions.ReserveSlot(session.ID.Hex(), user.ID.Hex(), session.MaxPlayers) {
+		// Players who asked to wait get in line for the next free slot instead
+		if body.Waitlist {
+			if position, ok := h.liveSessions.JoinWaitlist(session.ID.Hex(), user.ID.Hex()); ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				json.NewEncoder(w).Encode(WaitlistResponse{Position: position})
+				return
+			}
+		}
+		utils.WriteJSONError(w, http.StatusBadRequest, "Session is full")
 		return
 	}
 
 	// Update user's current session
 	user.CurrentSession = session.ID.Hex()
-	h.userRepo.Update(ctx, user)
+	h.userRepo.SetCurrentSession(ctx, user.ID, user.CurrentSession)
 
+	// The player has joined either way, the host is left out when it can't be loaded
 	host, _ := h.userRepo.FindByID(ctx, session.HostID)
 	response := h.sessionToResponse(session, host)
 
@@ -225,16 +456,123 @@ func (h *SessionHandler) HandleJoinSession(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
+// HandleGetWaitlist tells a player waiting for a full session where they are in line,
+// or that a slot is reserved for them and they can join now
+func (h *SessionHandler) HandleGetWaitlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, err := h.getCurrentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	// Extract session ID from URL path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sessions/")
+	sessionIDStr := strings.TrimSuffix(path, "/waitlist")
+
+	if _, err := primitive.ObjectIDFromHex(sessionIDStr); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	position, ready, found := h.liveSessions.WaitlistStatus(sessionIDStr, user.ID.Hex())
+	if !found {
+		utils.WriteJSONError(w, http.StatusNotFound, "Not on the waitlist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WaitlistResponse{Position: position, Ready: ready})
+}
+
+// HandleKickPlayer lets the host remove a player from the session
+func (h *SessionHandler) HandleKickPlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, err := h.getCurrentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	// Extract session ID from URL path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sessions/")
+	sessionIDStr := strings.TrimSuffix(path, "/kick")
+
+	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var body struct {
+		PlayerID string `json:"player_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.PlayerID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx := context.Background()
+	session, err := h.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	if session.HostID != user.ID {
+		utils.WriteJSONError(w, http.StatusForbidden, "Only the host can kick players")
+		return
+	}
+	if body.PlayerID == user.ID.Hex() {
+		utils.WriteJSONError(w, http.StatusBadRequest, "The host can't kick themselves")
+		return
+	}
+
+	// Players who joined since the last save are only known to the running session
+	_, stored := session.Players[body.PlayerID]
+	live := h.liveSessions.KickPlayer(sessionIDStr, body.PlayerID)
+	if !stored && !live {
+		utils.WriteJSONError(w, http.StatusNotFound, "Player not in session")
+		return
+	}
+
+	// Only the player is taken out, a save of the running session may be writing the rest at the same time
+	if stored {
+		if err := h.sessionRepo.RemovePlayer(ctx, sessionID, body.PlayerID); err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update session")
+			return
+		}
+	}
+
+	// Kicked players don't get rejoined to the session
+	if playerID, err := primitive.ObjectIDFromHex(body.PlayerID); err == nil {
+		if err := h.userRepo.ClearCurrentSession(ctx, playerID, sessionIDStr); err != nil {
+			log.Printf("Failed to clear current session of kicked player %s: %v", body.PlayerID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Player kicked"})
+}
+
 // HandleDeleteSession leaves a session
 func (h *SessionHandler) HandleDeleteSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	user, err := h.getCurrentUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeAuthError(w, err)
 		return
 	}
 
@@ -243,19 +581,19 @@ func (h *SessionHandler) HandleDeleteSession(w http.ResponseWriter, r *http.Requ
 
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
 	ctx := context.Background()
 	session, err := h.sessionRepo.FindByID(ctx, sessionID)
 	if err != nil {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
 		return
 	}
 
 	if session.HostID != user.ID {
-		http.Error(w, "Only the host can delete the session", http.StatusForbidden)
+		utils.WriteJSONError(w, http.StatusForbidden, "Only the host can delete the session")
 		return
 	}
 
@@ -265,26 +603,29 @@ func (h *SessionHandler) HandleDeleteSession(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(map[string]string{"message": "Successfully deleted session"})
 }
 
-// sessionToResponse converts a session to a response object
+// sessionToResponse converts a session to a response object, host may be nil when it couldn't be loaded
 func (h *SessionHandler) sessionToResponse(session *db.GameSession, host *db.User) SessionResponse {
+	var hostResponse *UserResponse
+	if host != nil {
+		userResponse := auth.NewUserResponse(host)
+		hostResponse = &userResponse
+	}
+
 	return SessionResponse{
-		ID:   session.ID.Hex(),
-		Name: session.Name,
-		Host: UserResponse{
-			ID:             host.ID.Hex(),
-			Email:          host.Email,
-			Username:       host.Username,
-			GoogleID:       host.GoogleID,
-			IsActive:       host.IsActive,
-			CurrentSession: host.CurrentSession,
-			CreatedAt:      host.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
-		MaxPlayers:    session.MaxPlayers,
-		IsPrivate:     session.IsPrivate,
-		WorldMap:      session.WorldMap,
-		SharedObjects: session.SharedObjects,
-		Players:       session.Players,
-		CreatedAt:     session.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		IsActive:      session.IsActive,
+		ID:             session.ID.Hex(),
+		Name:           session.Name,
+		Host:           hostResponse,
+		MaxPlayers:     session.MaxPlayers,
+		IsPrivate:      session.IsPrivate,
+		WorldMap:       session.WorldMap,
+		SharedObjects:  session.SharedObjects,
+		Players:        session.Players,
+		CreatedAt:      session.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		IsActive:       session.IsActive,
+		Seed:           session.Seed,
+		AllowedWeapons: session.AllowedWeapons,
+		RecordInputs:   session.RecordInputs,
+		FriendlyFire:   session.FriendlyFireOn(),
+		Unlisted:       session.Unlisted,
 	}
 }
@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/auth"
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// decodeError checks the response status and returns the decoded JSON error body
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int) utils.ErrorDetails {
+	t.Helper()
+
+	if rec.Code != wantStatus {
+		t.Fatalf("status = %d, want %d", rec.Code, wantStatus)
+	}
+
+	var body utils.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("error body is not valid JSON: %v", err)
+	}
+	return body.Error
+}
+
+func TestSessionHandlerErrorsAreJSON(t *testing.T) {
+	h := &SessionHandler{}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"create with wrong method", h.HandleCreateSession, http.MethodGet, "/api/v1/sessions", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"create without token", h.HandleCreateSession, http.MethodPost, "/api/v1/sessions", http.StatusUnauthorized, "unauthorized"},
+		{"list without token", h.HandleListSessions, http.MethodGet, "/api/v1/sessions", http.StatusUnauthorized, "unauthorized"},
+		{"join without token", h.HandleJoinSession, http.MethodPost, "/api/v1/sessions/abc/join", http.StatusUnauthorized, "unauthorized"},
+		{"get with wrong method", h.HandleGetSession, http.MethodPost, "/api/v1/sessions/abc", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"get without token", h.HandleGetSession, http.MethodGet, "/api/v1/sessions/abc", http.StatusUnauthorized, "unauthorized"},
+		{"waitlist with wrong method", h.HandleGetWaitlist, http.MethodPost, "/api/v1/sessions/abc/waitlist", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"waitlist without token", h.HandleGetWaitlist, http.MethodGet, "/api/v1/sessions/abc/waitlist", http.StatusUnauthorized, "unauthorized"},
+		{"kick with wrong method", h.HandleKickPlayer, http.MethodGet, "/api/v1/sessions/abc/kick", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"kick without token", h.HandleKickPlayer, http.MethodPost, "/api/v1/sessions/abc/kick", http.StatusUnauthorized, "unauthorized"},
+		{"delete with wrong method", h.HandleDeleteSession, http.MethodPost, "/api/v1/sessions/abc", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"shops without token", h.HandleGetShops, http.MethodGet, "/api/v1/sessions/abc/shops", http.StatusUnauthorized, "unauthorized"},
+		{"bank with wrong method", h.HandleBank, http.MethodGet, "/api/v1/sessions/abc/bank", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"bank while disabled", h.HandleBank, http.MethodPost, "/api/v1/sessions/abc/bank", http.StatusNotFound, "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			apiErr := decodeError(t, rec, tt.wantStatus)
+			if apiErr.Code != tt.wantCode || apiErr.Message == "" {
+				t.Errorf("error = %+v, want code %q with a message", apiErr, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLeaderboardHandlerErrorsAreJSON(t *testing.T) {
+	h := &LeaderboardHandler{}
+
+	rec := httptest.NewRecorder()
+	h.HandleGetGlobalLeaderboard(rec, httptest.NewRequest(http.MethodPost, "/api/v1/leaderboard/global", nil))
+
+	if apiErr := decodeError(t, rec, http.StatusMethodNotAllowed); apiErr.Code != "method_not_allowed" {
+		t.Errorf("error code = %q, want %q", apiErr.Code, "method_not_allowed")
+	}
+}
+
+func TestWriteAuthErrorForbidsInactiveUsers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeAuthError(rec, auth.ErrUserInactive)
+	if apiErr := decodeError(t, rec, http.StatusForbidden); apiErr.Code != "forbidden" {
+		t.Errorf("error code = %q, want %q", apiErr.Code, "forbidden")
+	}
+
+	rec = httptest.NewRecorder()
+	writeAuthError(rec, http.ErrNoCookie)
+	if apiErr := decodeError(t, rec, http.StatusUnauthorized); apiErr.Code != "unauthorized" {
+		t.Errorf("error code = %q, want %q", apiErr.Code, "unauthorized")
+	}
+}
+
+// fakeLiveSessions serves a single loaded session
+type fakeLiveSessions struct {
+	sessionID   string
+	engine      *game.Engine
+	playerCount int
+	activity    *db.SessionActivity
+	uptime      time.Duration
+	full        bool
+	// Position users get on the waitlist, 0 when waitlists are turned off
+	waitlistPosition int
+}
+
+func (f *fakeLiveSessions) GetSessionEngine(sessionID string) *game.Engine {
+	if sessionID != f.sessionID {
+		return nil
+	}
+	return f.engine
+}
+
+func (f *fakeLiveSessions) GetLivePlayerCount(sessionID string) (int, bool) {
+	return f.playerCount, sessionID == f.sessionID
+}
+
+func (f *fakeLiveSessions) KickPlayer(sessionID, playerID string) bool {
+	return false
+}
+
+func (f *fakeLiveSessions) ReserveSlot(sessionID, userID string, maxPlayers int) bool {
+	return !f.full
+}
+
+func (f *fakeLiveSessions) JoinWaitlist(sessionID, userID string) (int, bool) {
+	return f.waitlistPosition, f.waitlistPosition > 0
+}
+
+func (f *fakeLiveSessions) WaitlistStatus(sessionID, userID string) (int, bool, bool) {
+	return 0, false, false
+}
+
+func (f *fakeLiveSessions) GetSessionActivity(sessionID string) (db.SessionActivity, time.Duration, bool) {
+	if sessionID != f.sessionID || f.activity == nil {
+		return db.SessionActivity{}, 0, false
+	}
+	return *f.activity, f.uptime, true
+}
+
+func TestListedFor(t *testing.T) {
+	host := &db.User{ID: primitive.NewObjectID()}
+	other := &db.User{ID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name    string
+		session db.GameSession
+		user    *db.User
+		want    bool
+	}{
+		{"public session", db.GameSession{HostID: host.ID}, other, true},
+		{"private session", db.GameSession{HostID: host.ID, IsPrivate: true}, other, true},
+		{"unlisted session", db.GameSession{HostID: host.ID, IsPrivate: true, Unlisted: true}, other, false},
+		{"unlisted session of the host", db.GameSession{HostID: host.ID, IsPrivate: true, Unlisted: true}, host, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listedFor(&tt.session, tt.user); got != tt.want {
+				t.Errorf("listedFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveSessionStateListsAlivePlayers(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	engine := game.NewEngine("session")
+	leader := engine.ConnectPlayer("leader", "leader")
+	engine.ConnectPlayer("runner-up", "runner-up")
+	engine.ConnectPlayer("dropped", "dropped")
+	engine.DisconnectPlayer("dropped")
+	leader.Score = 100
+
+	h := &SessionHandler{liveSessions: &fakeLiveSessions{sessionID: "session", engine: engine, playerCount: 2}}
+
+	live := h.liveSessionState("session")
+	if live == nil {
+		t.Fatal("expected the loaded session's live state")
+	}
+	if live.PlayerCount != 2 {
+		t.Errorf("player count = %d, want 2", live.PlayerCount)
+	}
+	if len(live.AlivePlayers) != 2 || live.AlivePlayers[0].ID != "leader" || live.AlivePlayers[1].ID != "runner-up" {
+		t.Errorf("alive players = %+v, want the connected players by score", live.AlivePlayers)
+	}
+
+	if live := h.liveSessionState("other"); live != nil {
+		t.Errorf("expected no live state for a session that isn't loaded, got %+v", live)
+	}
+}
+
+func TestSessionActivity(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	running := &db.GameSession{ID: primitive.NewObjectID(), Activity: db.SessionActivity{StartedAt: startedAt, PeakPlayers: 1}}
+	saved := &db.GameSession{ID: primitive.NewObjectID(), Activity: db.SessionActivity{StartedAt: startedAt, PeakPlayers: 2, PlayerIDs: []string{"a", "b", "c"}}}
+	neverPlayed := &db.GameSession{ID: primitive.NewObjectID()}
+
+	h := &SessionHandler{liveSessions: &fakeLiveSessions{
+		sessionID: running.ID.Hex(),
+		activity:  &db.SessionActivity{StartedAt: startedAt, PeakPlayers: 4, PlayerIDs: []string{"a", "b", "c", "d", "e"}},
+		uptime:    90 * time.Second,
+	}}
+
+	tests := []struct {
+		name    string
+		session *db.GameSession
+		want    *SessionActivityResponse
+	}{
+		{"running session reports live activity", running, &SessionActivityResponse{StartedAt: "2026-01-02T15:04:05Z", PeakPlayers: 4, UniquePlayers: 5, UptimeSeconds: 90}},
+		{"unloaded session reports saved activity", saved, &SessionActivityResponse{StartedAt: "2026-01-02T15:04:05Z", PeakPlayers: 2, UniquePlayers: 3}},
+		{"session never played has no activity", neverPlayed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.sessionActivity(tt.session)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("sessionActivity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSessionFriendlyFire(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStored bool
+		want       bool
+	}{
+		{"left out", `{"name":"co-op"}`, false, true},
+		{"off", `{"name":"co-op","friendly_fire":false}`, true, false},
+		{"on", `{"name":"co-op","friendly_fire":true}`, true, true},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			req := authorize(mt, httptest.NewRequest(http.MethodPost, "/api/v1/sessions", strings.NewReader(tt.body)))
+			mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+			h := NewSessionHandler(nil)
+
+			rec := httptest.NewRecorder()
+			h.HandleCreateSession(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+			}
+			var response SessionResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				mt.Fatalf("response is not valid JSON: %v", err)
+			}
+			if response.FriendlyFire != tt.want {
+				mt.Errorf("friendly_fire = %v, want %v", response.FriendlyFire, tt.want)
+			}
+
+			mt.GetStartedEvent() // user lookup
+			stored := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+			if _, err := stored.LookupErr("friendly_fire"); (err == nil) != tt.wantStored {
+				mt.Errorf("friendly_fire stored = %v, want %v", err == nil, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestJoinFullSessionWaitlist(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name             string
+		body             string
+		waitlistPosition int
+		wantStatus       int
+	}{
+		{"waiting", `{"waitlist":true}`, 2, http.StatusAccepted},
+		{"not asking to wait", `{}`, 2, http.StatusBadRequest},
+		{"waitlists off", `{"waitlist":true}`, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			sessionID := primitive.NewObjectID()
+			req := authorize(mt, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID.Hex()+"/join", strings.NewReader(tt.body)))
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: sessionID}, {Key: "name", Value: "full"}, {Key: "max_players", Value: 1}},
+			))
+			h := NewSessionHandler(&fakeLiveSessions{sessionID: sessionID.Hex(), full: true, waitlistPosition: tt.waitlistPosition})
+
+			rec := httptest.NewRecorder()
+			h.HandleJoinSession(rec, req)
+
+			if tt.wantStatus != http.StatusAccepted {
+				if decodeError(mt.T, rec, tt.wantStatus).Message != "Session is full" {
+					mt.Errorf("expected the session to be reported full")
+				}
+				return
+			}
+			if rec.Code != http.StatusAccepted {
+				mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+			}
+			var response WaitlistResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				mt.Fatalf("response is not valid JSON: %v", err)
+			}
+			if response.Position != tt.waitlistPosition || response.Ready {
+				mt.Errorf("waitlist = %+v, want position %d", response, tt.waitlistPosition)
+			}
+		})
+	}
+}
+
+func TestKickPlayerOnlyRemovesThePlayer(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("kick", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		hostID := primitive.NewObjectID()
+		kickedID := primitive.NewObjectID().Hex()
+		sessionID := primitive.NewObjectID()
+		req := authorizeAs(mt, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID.Hex()+"/kick", strings.NewReader(`{"player_id":"`+kickedID+`"}`)), hostID)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: sessionID},
+				{Key: "name", Value: "co-op"},
+				{Key: "host_id", Value: hostID},
+				{Key: "players", Value: bson.D{{Key: kickedID, Value: bson.D{{Key: "score", Value: 10}}}}},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+		)
+		h := NewSessionHandler(&fakeLiveSessions{sessionID: sessionID.Hex()})
+
+		rec := httptest.NewRecorder()
+		h.HandleKickPlayer(rec, req)
+
+		if rec.Code != http.StatusOK {
+			mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+
+		mt.GetStartedEvent() // user lookup
+		mt.GetStartedEvent() // session lookup
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		if _, err := update.LookupErr("$unset", "players."+kickedID); err != nil {
+			mt.Errorf("expected the kicked player to be unset, got %v", update)
+		}
+		if _, err := update.LookupErr("$set", "name"); err == nil {
+			mt.Errorf("expected the rest of the session not to be written back, got %v", update)
+		}
+	})
+}
+
+// toDocument turns a map into a document for a mock cursor
+func toDocument(fields bson.M) bson.D {
+	var document bson.D
+	for key, value := range fields {
+		document = append(document, bson.E{Key: key, Value: value})
+	}
+	return document
+}
+
+// applyUpdate returns the stored document as the update would leave it, for top-level $set and $unset fields
+func applyUpdate(stored bson.M, update bson.Raw) bson.M {
+	updated := bson.M{}
+	for key, value := range stored {
+		updated[key] = value
+	}
+	if set, ok := update.Lookup("$set").DocumentOK(); ok {
+		elements, _ := set.Elements()
+		for _, element := range elements {
+			updated[element.Key()] = element.Value()
+		}
+	}
+	if unset, ok := update.Lookup("$unset").DocumentOK(); ok {
+		elements, _ := unset.Elements()
+		for _, element := range elements {
+			delete(updated, element.Key())
+		}
+	}
+	return updated
+}
+
+func TestKickedPlayerForgetsTheSession(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("kick", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		hostID := primitive.NewObjectID()
+		kickedID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID()
+		kicked := bson.M{"_id": kickedID, "username": "kicked", "is_active": true, "current_session": sessionID.Hex()}
+
+		req := authorizeAs(mt, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID.Hex()+"/kick", strings.NewReader(`{"player_id":"`+kickedID.Hex()+`"}`)), hostID)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: sessionID},
+				{Key: "host_id", Value: hostID},
+				{Key: "players", Value: bson.D{{Key: kickedID.Hex(), Value: bson.D{{Key: "score", Value: 10}}}}},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			// Enough for the kicked player to be looked up and written back, or updated directly
+			mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch, toDocument(kicked)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		h := NewSessionHandler(&fakeLiveSessions{sessionID: sessionID.Hex()})
+
+		rec := httptest.NewRecorder()
+		h.HandleKickPlayer(rec, req)
+		if rec.Code != http.StatusOK {
+			mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+
+		mt.GetStartedEvent() // user lookup
+		mt.GetStartedEvent() // session lookup
+		mt.GetStartedEvent() // session update
+		var stored bson.M
+		for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+			if event.CommandName == "update" {
+				stored = applyUpdate(kicked, event.Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document())
+			}
+		}
+		if stored == nil {
+			mt.Fatal("expected the kicked player to be updated")
+		}
+
+		// Reloaded from the database as the update left it
+		mt.ClearMockResponses()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch, toDocument(stored)))
+		player, err := db.NewUserRepository().FindByID(context.Background(), kickedID)
+		if err != nil {
+			mt.Fatalf("FindByID() error = %v", err)
+		}
+		if player.CurrentSession != "" {
+			mt.Errorf("current_session = %q, want it cleared for the kicked player", player.CurrentSession)
+		}
+	})
+}
+
+func TestSessionWithMissingHostIsShownWithoutHost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		call   func(h *SessionHandler) http.HandlerFunc
+	}{
+		{"get", http.MethodGet, "", func(h *SessionHandler) http.HandlerFunc { return h.HandleGetSession }},
+		{"join", http.MethodPost, "/join", func(h *SessionHandler) http.HandlerFunc { return h.HandleJoinSession }},
+	}
+
+	for _, request := range requests {
+		mt.Run(request.name, func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			sessionID := primitive.NewObjectID()
+			req := authorizeAs(mt, httptest.NewRequest(request.method, "/api/v1/sessions/"+sessionID.Hex()+request.path, strings.NewReader(`{}`)), primitive.NewObjectID())
+			mt.AddMockResponses(
+				mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch, bson.D{
+					{Key: "_id", Value: sessionID},
+					{Key: "name", Value: "orphaned"},
+					{Key: "host_id", Value: primitive.NewObjectID()},
+					{Key: "max_players", Value: 4},
+				}),
+			)
+			if request.method == http.MethodPost {
+				mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+			}
+			// The host account no longer exists
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch))
+			h := NewSessionHandler(&fakeLiveSessions{})
+
+			rec := httptest.NewRecorder()
+			request.call(h)(rec, req)
+
+			if rec.Code != http.StatusOK {
+				mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			var response map[string]any
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				mt.Fatalf("response is not valid JSON: %v", err)
+			}
+			if response["name"] != "orphaned" {
+				mt.Errorf("response = %v, want the session", response)
+			}
+			if _, hasHost := response["host"]; hasHost {
+				mt.Errorf("expected the missing host to be left out, got %v", response["host"])
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShopItemResponse represents an item offered by a shop
+type ShopItemResponse struct {
+	ItemID   types.InventoryItemID `json:"item_id"`
+	Price    int                   `json:"price"`
+	PackSize int                   `json:"pack_size"`
+	Quantity int                   `json:"quantity"`
+}
+
+// ShopResponse represents a shop in responses
+type ShopResponse struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	X         float64            `json:"x"`
+	Y         float64            `json:"y"`
+	Inventory []ShopItemResponse `json:"inventory"`
+}
+
+// HandleGetShops lists the shops of a session the user is a member of
+func (h *SessionHandler) HandleGetShops(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, err := h.getCurrentUser(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	// Extract session ID from URL path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sessions/")
+	sessionIDStr := strings.TrimSuffix(path, "/shops")
+
+	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	ctx := context.Background()
+	session, err := h.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	_, isPlayer := session.Players[user.ID.Hex()]
+	if !isPlayer && session.HostID != user.ID && user.CurrentSession != sessionIDStr {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a member of this session")
+		return
+	}
+
+	// Prefer the live engine, the stored session may be behind on purchases
+	responses := make([]ShopResponse, 0)
+	if engine := h.liveSessions.GetSessionEngine(sessionIDStr); engine != nil {
+		for _, shop := range engine.GetShops() {
+			if !shop.Discovered && !h.revealAllShops {
+				continue
+			}
+			responses = append(responses, shopToResponse(shop))
+		}
+	} else {
+		for id, obj := range session.SharedObjects {
+			if obj.Type != "shop" {
+				continue
+			}
+			if discovered, _ := obj.Properties["discovered"].(bool); !discovered && !h.revealAllShops {
+				continue
+			}
+			responses = append(responses, storedShopToResponse(id, obj))
+		}
+	}
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ID < responses[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// storedShopToResponse converts a shop saved with a session to a response object
+func storedShopToResponse(id string, obj db.WorldObject) ShopResponse {
+	name, _ := obj.Properties["name"].(string)
+
+	inventory := make([]ShopItemResponse, 0)
+	if items, ok := obj.Properties["inventory"].(map[string]interface{}); ok {
+		for itemIDStr, itemData := range items {
+			var itemID types.InventoryItemID
+			fmt.Sscanf(itemIDStr, "%d", &itemID)
+			itemMap, ok := itemData.(map[string]interface{})
+			if !ok || !types.KnownInventoryItems[itemID] {
+				continue
+			}
+
+			item := ShopItemResponse{ItemID: itemID}
+			if price, ok := itemMap["price"].(int32); ok {
+				item.Price = int(price)
+			}
+			if packSize, ok := itemMap["pack_size"].(int32); ok {
+				item.PackSize = int(packSize)
+			}
+			if quantity, ok := itemMap["quantity"].(int32); ok {
+				item.Quantity = int(quantity)
+			}
+			inventory = append(inventory, item)
+		}
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].ItemID < inventory[j].ItemID
+	})
+
+	return ShopResponse{
+		ID:        id,
+		Name:      name,
+		X:         obj.X,
+		Y:         obj.Y,
+		Inventory: inventory,
+	}
+}
+
+// shopToResponse converts a shop to a response object
+func shopToResponse(shop *types.Shop) ShopResponse {
+	inventory := make([]ShopItemResponse, 0, len(shop.Inventory))
+	for itemID, item := range shop.Inventory {
+		inventory = append(inventory, ShopItemResponse{
+			ItemID:   itemID,
+			Price:    item.Price,
+			PackSize: item.PackSize,
+			Quantity: item.Quantity,
+		})
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].ItemID < inventory[j].ItemID
+	})
+
+	return ShopResponse{
+		ID:        shop.ID,
+		Name:      shop.Name,
+		X:         shop.Position.X,
+		Y:         shop.Position.Y,
+		Inventory: inventory,
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestGetShopsOfStoredSession(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	for _, revealAll := range []bool{false, true} {
+		mt.Run(fmt.Sprintf("revealAllShops %v", revealAll), func(mt *mtest.T) {
+			previous := db.Database
+			db.Database = mt.DB
+			defer func() { db.Database = previous }()
+
+			hostID := primitive.NewObjectID()
+			sessionID := primitive.NewObjectID()
+			shop := func(name string, discovered bool) bson.D {
+				return bson.D{
+					{Key: "object_id", Value: name},
+					{Key: "type", Value: "shop"},
+					{Key: "x", Value: 100.0},
+					{Key: "y", Value: 200.0},
+					{Key: "properties", Value: bson.D{
+						{Key: "name", Value: name},
+						{Key: "discovered", Value: discovered},
+						{Key: "inventory", Value: bson.D{
+							{Key: "1", Value: bson.D{{Key: "price", Value: int32(50)}, {Key: "quantity", Value: int32(3)}, {Key: "pack_size", Value: int32(10)}}},
+						}},
+					}},
+				}
+			}
+
+			req := authorizeAs(mt, httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID.Hex()+"/shops", nil), hostID)
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: sessionID},
+				{Key: "host_id", Value: hostID},
+				{Key: "shared_objects", Value: bson.D{
+					{Key: "found", Value: shop("found", true)},
+					{Key: "hidden", Value: shop("hidden", false)},
+					{Key: "wall", Value: bson.D{{Key: "object_id", Value: "wall"}, {Key: "type", Value: "wall"}}},
+				}},
+			}))
+			h := NewSessionHandler(&fakeLiveSessions{})
+			h.revealAllShops = revealAll
+
+			rec := httptest.NewRecorder()
+			h.HandleGetShops(rec, req)
+			if rec.Code != http.StatusOK {
+				mt.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			var shops []ShopResponse
+			if err := json.NewDecoder(rec.Body).Decode(&shops); err != nil {
+				mt.Fatalf("response is not valid JSON: %v", err)
+			}
+
+			want := []string{"found"}
+			if revealAll {
+				want = []string{"found", "hidden"}
+			}
+			if len(shops) != len(want) {
+				mt.Fatalf("got %d shops, want %v", len(shops), want)
+			}
+			for i, shop := range shops {
+				if shop.ID != want[i] || shop.Name != want[i] || shop.X != 100 || shop.Y != 200 {
+					mt.Errorf("shop %d = %+v, want %q at (100, 200)", i, shop, want[i])
+				}
+				wantItem := ShopItemResponse{ItemID: types.InventoryItemBlaster, Price: 50, PackSize: 10, Quantity: 3}
+				if len(shop.Inventory) != 1 || shop.Inventory[0] != wantItem {
+					mt.Errorf("shop %q inventory = %+v, want [%+v]", shop.ID, shop.Inventory, wantItem)
+				}
+			}
+		})
+	}
+}
@@ -51,6 +51,12 @@ func ToProtoPlayer(p *types.Player) *Player {
 		BulletsLeftByWeaponType: p.BulletsLeftByWeaponType,
 		NightVisionTimer:        p.NightVisionTimer,
 		InvulnerableTimer:       p.InvulnerableTimer,
+		DoubleDamageTimer:       p.DoubleDamageTimer,
+		RapidFireTimer:          p.RapidFireTimer,
+		SpeedBoostTimer:         p.SpeedBoostTimer,
+		Stamina:                 p.Stamina,
+		BlindTimer:              p.BlindTimer,
+		ReloadTimer:             p.ReloadTimer,
 		IsAlive:                 p.IsAlive,
 		Inventory:               inventory,
 		SelectedGunType:         p.SelectedGunType,
@@ -63,12 +69,18 @@ func ToProtoPlayerUpdate(prev, curr *types.Player, isCurrentPlayer bool) *Player
 	}
 
 	update := &PlayerUpdate{}
-	if prev.Position.X != curr.Position.X || prev.Position.Y != curr.Position.Y || prev.Rotation != curr.Rotation {
+	if prev.Position.X != curr.Position.X || prev.Position.Y != curr.Position.Y || prev.Rotation != curr.Rotation ||
+		(isCurrentPlayer && prev.LastProcessedInput != curr.LastProcessedInput) {
 		update.Position = &PositionUpdate{
 			X:        curr.Position.X,
 			Y:        curr.Position.Y,
 			Rotation: curr.Rotation,
 		}
+
+		// Only the owner reconciles its predicted position against the server one
+		if isCurrentPlayer {
+			update.Position.LastProcessedInput = curr.LastProcessedInput
+		}
 	}
 
 	if isCurrentPlayer && (prev.Kills != curr.Kills || prev.Score != curr.Score || prev.Money != curr.Money) {
@@ -84,10 +96,24 @@ func ToProtoPlayerUpdate(prev, curr *types.Player, isCurrentPlayer bool) *Player
 		}
 	}
 
-	if prev.NightVisionTimer != curr.NightVisionTimer || prev.InvulnerableTimer != curr.InvulnerableTimer {
+	if isCurrentPlayer && prev.Stamina != curr.Stamina {
+		update.Stamina = &StaminaUpdate{
+			Stamina: curr.Stamina,
+		}
+	}
+
+	if prev.NightVisionTimer != curr.NightVisionTimer || prev.InvulnerableTimer != curr.InvulnerableTimer ||
+		prev.DoubleDamageTimer != curr.DoubleDamageTimer || prev.RapidFireTimer != curr.RapidFireTimer ||
+		prev.SpeedBoostTimer != curr.SpeedBoostTimer || prev.BlindTimer != curr.BlindTimer ||
+		prev.ReloadTimer != curr.ReloadTimer {
 		update.Timers = &TimersUpdate{
 			NightVisionTimer:  curr.NightVisionTimer,
 			InvulnerableTimer: curr.InvulnerableTimer,
+			DoubleDamageTimer: curr.DoubleDamageTimer,
+			RapidFireTimer:    curr.RapidFireTimer,
+			SpeedBoostTimer:   curr.SpeedBoostTimer,
+			BlindTimer:        curr.BlindTimer,
+			ReloadTimer:       curr.ReloadTimer,
 		}
 	}
 
@@ -130,7 +156,7 @@ func ToProtoPlayerUpdate(prev, curr *types.Player, isCurrentPlayer bool) *Player
 	}
 
 	if update.Position == nil && update.Score == nil && update.PlayerBullets == nil &&
-		update.Timers == nil && update.Lives == nil && update.Inventory == nil {
+		update.Timers == nil && update.Lives == nil && update.Inventory == nil && update.Stamina == nil {
 		return nil
 	}
 
@@ -142,7 +168,7 @@ func ToProtoBullet(b *types.Bullet) *Bullet {
 	if b == nil {
 		return nil
 	}
-	return &Bullet{
+	bullet := &Bullet{
 		Id:         b.ID,
 		Position:   ToProtoVector2(b.Position),
 		Velocity:   ToProtoVector2(b.Velocity),
@@ -154,6 +180,10 @@ func ToProtoBullet(b *types.Bullet) *Bullet {
 		DeletedAt:  b.DeletedAt.UnixMilli(),
 		WeaponType: b.WeaponType,
 	}
+	if b.Origin != nil {
+		bullet.Origin = ToProtoVector2(b.Origin)
+	}
+	return bullet
 }
 
 func ToProtoBulletUpdate(prev, curr *types.Bullet) *PositionUpdate {
@@ -182,6 +212,7 @@ func ToProtoWall(w *types.Wall) *Wall {
 		Width:       w.Width,
 		Height:      w.Height,
 		Orientation: w.Orientation,
+		IsDoor:      w.IsDoor,
 	}
 }
 
@@ -191,13 +222,16 @@ func ToProtoEnemy(e *types.Enemy) *Enemy {
 		return nil
 	}
 	return &Enemy{
-		Id:       e.ID,
-		Position: ToProtoVector2(e.Position),
-		Rotation: e.Rotation,
-		Lives:    e.Lives,
-		WallId:   e.WallID,
-		IsAlive:  e.IsAlive,
-		Type:     e.Type,
+		Id:        e.ID,
+		Position:  ToProtoVector2(e.Position),
+		Rotation:  e.Rotation,
+		Lives:     e.Lives,
+		WallId:    e.WallID,
+		IsAlive:   e.IsAlive,
+		Type:      e.Type,
+		MaxLives:  e.MaxLives(),
+		Armored:   e.Armored,
+		Archetype: e.Archetype,
 	}
 }
 
@@ -318,6 +352,9 @@ func FromProtoInput(input *InputMessage) types.InputPayload {
 		Shoot:           input.Shoot,
 		ItemKey:         input.ItemKey,
 		PurchaseItemKey: input.PurchaseItemKey,
+		Sequence:        input.Sequence,
+		Sprint:          input.Sprint,
+		Reload:          input.Reload,
 	}
 }
 
@@ -328,6 +365,7 @@ func IsGameStateDeltaEmpty(delta *GameStateDeltaMessage) bool {
 		len(delta.AddedEnemies) == 0 && len(delta.UpdatedEnemies) == 0 && len(delta.RemovedEnemies) == 0 &&
 		len(delta.AddedBonuses) == 0 && len(delta.UpdatedBonuses) == 0 && len(delta.RemovedBonuses) == 0 &&
 		len(delta.AddedShops) == 0 && len(delta.UpdatedShops) == 0 && len(delta.RemovedShops) == 0 &&
-		len(delta.AddedPlayersShops) == 0 && len(delta.RemovedPlayersShops) == 0 &&
-		len(delta.UpdatedOtherPlayerPositions) == 0 && len(delta.RemovedOtherPlayerPositions) == 0
+		len(delta.AddedPlayersShops) == 0 && len(delta.RemovedPlayersShops) == 0 && len(delta.ShopEvents) == 0 && delta.Zone == "" &&
+		len(delta.UpdatedOtherPlayerPositions) == 0 && len(delta.RemovedOtherPlayerPositions) == 0 && delta.DebugCounts == nil &&
+		len(delta.JoinedPlayers) == 0 && len(delta.LeftPlayers) == 0
 }
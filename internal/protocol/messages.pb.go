@@ -86,6 +86,55 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_messages_proto_rawDescGZIP(), []int{0}
 }
 
+type ShopEventType int32
+
+const (
+	ShopEventType_SHOP_EVENT_UNKNOWN ShopEventType = 0
+	ShopEventType_SHOP_ENTER         ShopEventType = 1
+	ShopEventType_SHOP_LEAVE         ShopEventType = 2
+)
+
+// Enum value maps for ShopEventType.
+var (
+	ShopEventType_name = map[int32]string{
+		0: "SHOP_EVENT_UNKNOWN",
+		1: "SHOP_ENTER",
+		2: "SHOP_LEAVE",
+	}
+	ShopEventType_value = map[string]int32{
+		"SHOP_EVENT_UNKNOWN": 0,
+		"SHOP_ENTER":         1,
+		"SHOP_LEAVE":         2,
+	}
+)
+
+func (x ShopEventType) Enum() *ShopEventType {
+	p := new(ShopEventType)
+	*p = x
+	return p
+}
+
+func (x ShopEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShopEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_messages_proto_enumTypes[1].Descriptor()
+}
+
+func (ShopEventType) Type() protoreflect.EnumType {
+	return &file_messages_proto_enumTypes[1]
+}
+
+func (x ShopEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShopEventType.Descriptor instead.
+func (ShopEventType) EnumDescriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{1}
+}
+
 // Common structures
 type Vector2 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -208,6 +257,12 @@ type Player struct {
 	IsAlive                 bool                   `protobuf:"varint,12,opt,name=is_alive,json=isAlive,proto3" json:"is_alive,omitempty"`
 	Inventory               []*InventoryItem       `protobuf:"bytes,14,rep,name=inventory,proto3" json:"inventory,omitempty"`
 	SelectedGunType         string                 `protobuf:"bytes,15,opt,name=selected_gun_type,json=selectedGunType,proto3" json:"selected_gun_type,omitempty"`
+	DoubleDamageTimer       float64                `protobuf:"fixed64,16,opt,name=double_damage_timer,json=doubleDamageTimer,proto3" json:"double_damage_timer,omitempty"`
+	RapidFireTimer          float64                `protobuf:"fixed64,17,opt,name=rapid_fire_timer,json=rapidFireTimer,proto3" json:"rapid_fire_timer,omitempty"`
+	SpeedBoostTimer         float64                `protobuf:"fixed64,18,opt,name=speed_boost_timer,json=speedBoostTimer,proto3" json:"speed_boost_timer,omitempty"`
+	Stamina                 float64                `protobuf:"fixed64,19,opt,name=stamina,proto3" json:"stamina,omitempty"`
+	BlindTimer              float64                `protobuf:"fixed64,20,opt,name=blind_timer,json=blindTimer,proto3" json:"blind_timer,omitempty"`
+	ReloadTimer             float64                `protobuf:"fixed64,21,opt,name=reload_timer,json=reloadTimer,proto3" json:"reload_timer,omitempty"`
 	unknownFields           protoimpl.UnknownFields
 	sizeCache               protoimpl.SizeCache
 }
@@ -347,6 +402,48 @@ func (x *Player) GetSelectedGunType() string {
 	return ""
 }
 
+func (x *Player) GetDoubleDamageTimer() float64 {
+	if x != nil {
+		return x.DoubleDamageTimer
+	}
+	return 0
+}
+
+func (x *Player) GetRapidFireTimer() float64 {
+	if x != nil {
+		return x.RapidFireTimer
+	}
+	return 0
+}
+
+func (x *Player) GetSpeedBoostTimer() float64 {
+	if x != nil {
+		return x.SpeedBoostTimer
+	}
+	return 0
+}
+
+func (x *Player) GetStamina() float64 {
+	if x != nil {
+		return x.Stamina
+	}
+	return 0
+}
+
+func (x *Player) GetBlindTimer() float64 {
+	if x != nil {
+		return x.BlindTimer
+	}
+	return 0
+}
+
+func (x *Player) GetReloadTimer() float64 {
+	if x != nil {
+		return x.ReloadTimer
+	}
+	return 0
+}
+
 type Bullet struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -359,6 +456,7 @@ type Bullet struct {
 	IsActive      bool                   `protobuf:"varint,7,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	DeletedAt     int64                  `protobuf:"varint,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
 	WeaponType    string                 `protobuf:"bytes,8,opt,name=weapon_type,json=weaponType,proto3" json:"weapon_type,omitempty"`
+	Origin        *Vector2               `protobuf:"bytes,12,opt,name=origin,proto3" json:"origin,omitempty"` // Spawn position, only sent when bullet trails are enabled
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -463,6 +561,13 @@ func (x *Bullet) GetWeaponType() string {
 	return ""
 }
 
+func (x *Bullet) GetOrigin() *Vector2 {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
 type Wall struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -470,6 +575,7 @@ type Wall struct {
 	Width         float64                `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
 	Height        float64                `protobuf:"fixed64,4,opt,name=height,proto3" json:"height,omitempty"`
 	Orientation   string                 `protobuf:"bytes,5,opt,name=orientation,proto3" json:"orientation,omitempty"`
+	IsDoor        bool                   `protobuf:"varint,6,opt,name=is_door,json=isDoor,proto3" json:"is_door,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -539,17 +645,28 @@ func (x *Wall) GetOrientation() string {
 	return ""
 }
 
+func (x *Wall) GetIsDoor() bool {
+	if x != nil {
+		return x.IsDoor
+	}
+	return false
+}
+
 type Enemy struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Position      *Vector2               `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
-	Rotation      float64                `protobuf:"fixed64,3,opt,name=rotation,proto3" json:"rotation,omitempty"`
-	Lives         float32                `protobuf:"fixed32,4,opt,name=lives,proto3" json:"lives,omitempty"`
-	WallId        string                 `protobuf:"bytes,5,opt,name=wall_id,json=wallId,proto3" json:"wall_id,omitempty"`
-	IsAlive       bool                   `protobuf:"varint,6,opt,name=is_alive,json=isAlive,proto3" json:"is_alive,omitempty"`
-	Type          string                 `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Position       *Vector2               `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+	Rotation       float64                `protobuf:"fixed64,3,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	Lives          float32                `protobuf:"fixed32,4,opt,name=lives,proto3" json:"lives,omitempty"`
+	WallId         string                 `protobuf:"bytes,5,opt,name=wall_id,json=wallId,proto3" json:"wall_id,omitempty"`
+	IsAlive        bool                   `protobuf:"varint,6,opt,name=is_alive,json=isAlive,proto3" json:"is_alive,omitempty"`
+	Type           string                 `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	MaxLives       float32                `protobuf:"fixed32,8,opt,name=max_lives,json=maxLives,proto3" json:"max_lives,omitempty"`                      // Lives of the enemy type at full health, for health bars
+	Armored        bool                   `protobuf:"varint,9,opt,name=armored,proto3" json:"armored,omitempty"`                                         // Only rockets and the railgun damage armored enemies
+	Archetype      string                 `protobuf:"bytes,10,opt,name=archetype,proto3" json:"archetype,omitempty"`                                     // grunt, brute or scout, empty for enemies spawned without archetypes
+	DeathTraceTime float32                `protobuf:"fixed32,11,opt,name=death_trace_time,json=deathTraceTime,proto3" json:"death_trace_time,omitempty"` // Seconds until a dead enemy is removed, for fading it out
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Enemy) Reset() {
@@ -631,6 +748,34 @@ func (x *Enemy) GetType() string {
 	return ""
 }
 
+func (x *Enemy) GetMaxLives() float32 {
+	if x != nil {
+		return x.MaxLives
+	}
+	return 0
+}
+
+func (x *Enemy) GetArmored() bool {
+	if x != nil {
+		return x.Armored
+	}
+	return false
+}
+
+func (x *Enemy) GetArchetype() string {
+	if x != nil {
+		return x.Archetype
+	}
+	return ""
+}
+
+func (x *Enemy) GetDeathTraceTime() float32 {
+	if x != nil {
+		return x.DeathTraceTime
+	}
+	return 0
+}
+
 type Bonus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -844,8 +989,12 @@ type InputMessage struct {
 	Shoot           bool                   `protobuf:"varint,5,opt,name=shoot,proto3" json:"shoot,omitempty"`
 	ItemKey         map[int32]bool         `protobuf:"bytes,6,rep,name=item_key,json=itemKey,proto3" json:"item_key,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	PurchaseItemKey map[int32]bool         `protobuf:"bytes,7,rep,name=purchase_item_key,json=purchaseItemKey,proto3" json:"purchase_item_key,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Client-assigned, monotonically increasing input sequence number
+	Sequence      uint32 `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Sprint        bool   `protobuf:"varint,9,opt,name=sprint,proto3" json:"sprint,omitempty"`
+	Reload        bool   `protobuf:"varint,10,opt,name=reload,proto3" json:"reload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InputMessage) Reset() {
@@ -927,13 +1076,36 @@ func (x *InputMessage) GetPurchaseItemKey() map[int32]bool {
 	return nil
 }
 
+func (x *InputMessage) GetSequence() uint32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *InputMessage) GetSprint() bool {
+	if x != nil {
+		return x.Sprint
+	}
+	return false
+}
+
+func (x *InputMessage) GetReload() bool {
+	if x != nil {
+		return x.Reload
+	}
+	return false
+}
+
 type PositionUpdate struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
-	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
-	Rotation      float64                `protobuf:"fixed64,3,opt,name=rotation,proto3" json:"rotation,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	X        float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y        float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Rotation float64                `protobuf:"fixed64,3,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	// Last input sequence applied by the server (own player only)
+	LastProcessedInput uint32 `protobuf:"varint,4,opt,name=last_processed_input,json=lastProcessedInput,proto3" json:"last_processed_input,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *PositionUpdate) Reset() {
@@ -987,10 +1159,22 @@ func (x *PositionUpdate) GetRotation() float64 {
 	return 0
 }
 
+func (x *PositionUpdate) GetLastProcessedInput() uint32 {
+	if x != nil {
+		return x.LastProcessedInput
+	}
+	return 0
+}
+
 type TimersUpdate struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	InvulnerableTimer float64                `protobuf:"fixed64,1,opt,name=invulnerable_timer,json=invulnerableTimer,proto3" json:"invulnerable_timer,omitempty"`
 	NightVisionTimer  float64                `protobuf:"fixed64,2,opt,name=night_vision_timer,json=nightVisionTimer,proto3" json:"night_vision_timer,omitempty"`
+	DoubleDamageTimer float64                `protobuf:"fixed64,3,opt,name=double_damage_timer,json=doubleDamageTimer,proto3" json:"double_damage_timer,omitempty"`
+	RapidFireTimer    float64                `protobuf:"fixed64,4,opt,name=rapid_fire_timer,json=rapidFireTimer,proto3" json:"rapid_fire_timer,omitempty"`
+	SpeedBoostTimer   float64                `protobuf:"fixed64,5,opt,name=speed_boost_timer,json=speedBoostTimer,proto3" json:"speed_boost_timer,omitempty"`
+	BlindTimer        float64                `protobuf:"fixed64,6,opt,name=blind_timer,json=blindTimer,proto3" json:"blind_timer,omitempty"`
+	ReloadTimer       float64                `protobuf:"fixed64,7,opt,name=reload_timer,json=reloadTimer,proto3" json:"reload_timer,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -1039,6 +1223,41 @@ func (x *TimersUpdate) GetNightVisionTimer() float64 {
 	return 0
 }
 
+func (x *TimersUpdate) GetDoubleDamageTimer() float64 {
+	if x != nil {
+		return x.DoubleDamageTimer
+	}
+	return 0
+}
+
+func (x *TimersUpdate) GetRapidFireTimer() float64 {
+	if x != nil {
+		return x.RapidFireTimer
+	}
+	return 0
+}
+
+func (x *TimersUpdate) GetSpeedBoostTimer() float64 {
+	if x != nil {
+		return x.SpeedBoostTimer
+	}
+	return 0
+}
+
+func (x *TimersUpdate) GetBlindTimer() float64 {
+	if x != nil {
+		return x.BlindTimer
+	}
+	return 0
+}
+
+func (x *TimersUpdate) GetReloadTimer() float64 {
+	if x != nil {
+		return x.ReloadTimer
+	}
+	return 0
+}
+
 type LivesUpdate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Lives         float32                `protobuf:"fixed32,1,opt,name=lives,proto3" json:"lives,omitempty"`
@@ -1203,6 +1422,50 @@ func (x *ScoreUpdate) GetKills() int32 {
 	return 0
 }
 
+type StaminaUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stamina       float64                `protobuf:"fixed64,1,opt,name=stamina,proto3" json:"stamina,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StaminaUpdate) Reset() {
+	*x = StaminaUpdate{}
+	mi := &file_messages_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StaminaUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StaminaUpdate) ProtoMessage() {}
+
+func (x *StaminaUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StaminaUpdate.ProtoReflect.Descriptor instead.
+func (*StaminaUpdate) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StaminaUpdate) GetStamina() float64 {
+	if x != nil {
+		return x.Stamina
+	}
+	return 0
+}
+
 type PlayerBulletsUpdate struct {
 	state                   protoimpl.MessageState `protogen:"open.v1"`
 	BulletsLeftByWeaponType map[string]int32       `protobuf:"bytes,1,rep,name=bullets_left_by_weapon_type,json=bulletsLeftByWeaponType,proto3" json:"bullets_left_by_weapon_type,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
@@ -1212,7 +1475,7 @@ type PlayerBulletsUpdate struct {
 
 func (x *PlayerBulletsUpdate) Reset() {
 	*x = PlayerBulletsUpdate{}
-	mi := &file_messages_proto_msgTypes[15]
+	mi := &file_messages_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1224,7 +1487,7 @@ func (x *PlayerBulletsUpdate) String() string {
 func (*PlayerBulletsUpdate) ProtoMessage() {}
 
 func (x *PlayerBulletsUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[15]
+	mi := &file_messages_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1237,7 +1500,7 @@ func (x *PlayerBulletsUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerBulletsUpdate.ProtoReflect.Descriptor instead.
 func (*PlayerBulletsUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{15}
+	return file_messages_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *PlayerBulletsUpdate) GetBulletsLeftByWeaponType() map[string]int32 {
@@ -1255,13 +1518,14 @@ type PlayerUpdate struct {
 	Inventory     *InventoryUpdate       `protobuf:"bytes,4,opt,name=inventory,proto3" json:"inventory,omitempty"`
 	Score         *ScoreUpdate           `protobuf:"bytes,5,opt,name=score,proto3" json:"score,omitempty"`
 	PlayerBullets *PlayerBulletsUpdate   `protobuf:"bytes,6,opt,name=player_bullets,json=playerBullets,proto3" json:"player_bullets,omitempty"`
+	Stamina       *StaminaUpdate         `protobuf:"bytes,7,opt,name=stamina,proto3" json:"stamina,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PlayerUpdate) Reset() {
 	*x = PlayerUpdate{}
-	mi := &file_messages_proto_msgTypes[16]
+	mi := &file_messages_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1273,7 +1537,7 @@ func (x *PlayerUpdate) String() string {
 func (*PlayerUpdate) ProtoMessage() {}
 
 func (x *PlayerUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[16]
+	mi := &file_messages_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1286,7 +1550,7 @@ func (x *PlayerUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerUpdate.ProtoReflect.Descriptor instead.
 func (*PlayerUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{16}
+	return file_messages_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *PlayerUpdate) GetPosition() *PositionUpdate {
@@ -1331,6 +1595,13 @@ func (x *PlayerUpdate) GetPlayerBullets() *PlayerBulletsUpdate {
 	return nil
 }
 
+func (x *PlayerUpdate) GetStamina() *StaminaUpdate {
+	if x != nil {
+		return x.Stamina
+	}
+	return nil
+}
+
 type DeletionUpdate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IsActive      bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
@@ -1341,7 +1612,7 @@ type DeletionUpdate struct {
 
 func (x *DeletionUpdate) Reset() {
 	*x = DeletionUpdate{}
-	mi := &file_messages_proto_msgTypes[17]
+	mi := &file_messages_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1353,7 +1624,7 @@ func (x *DeletionUpdate) String() string {
 func (*DeletionUpdate) ProtoMessage() {}
 
 func (x *DeletionUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[17]
+	mi := &file_messages_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1366,7 +1637,7 @@ func (x *DeletionUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeletionUpdate.ProtoReflect.Descriptor instead.
 func (*DeletionUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{17}
+	return file_messages_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *DeletionUpdate) GetIsActive() bool {
@@ -1384,16 +1655,17 @@ func (x *DeletionUpdate) GetDeletedAt() int64 {
 }
 
 type EnemyUpdate struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Position      *PositionUpdate        `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
-	Lives         *LivesUpdate           `protobuf:"bytes,2,opt,name=lives,proto3" json:"lives,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Position       *PositionUpdate        `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
+	Lives          *LivesUpdate           `protobuf:"bytes,2,opt,name=lives,proto3" json:"lives,omitempty"`
+	DeathTraceTime float32                `protobuf:"fixed32,3,opt,name=death_trace_time,json=deathTraceTime,proto3" json:"death_trace_time,omitempty"` // Sent with the lives update of an enemy that died
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *EnemyUpdate) Reset() {
 	*x = EnemyUpdate{}
-	mi := &file_messages_proto_msgTypes[18]
+	mi := &file_messages_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1405,7 +1677,7 @@ func (x *EnemyUpdate) String() string {
 func (*EnemyUpdate) ProtoMessage() {}
 
 func (x *EnemyUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[18]
+	mi := &file_messages_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1418,7 +1690,7 @@ func (x *EnemyUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnemyUpdate.ProtoReflect.Descriptor instead.
 func (*EnemyUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{18}
+	return file_messages_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *EnemyUpdate) GetPosition() *PositionUpdate {
@@ -1435,6 +1707,13 @@ func (x *EnemyUpdate) GetLives() *LivesUpdate {
 	return nil
 }
 
+func (x *EnemyUpdate) GetDeathTraceTime() float32 {
+	if x != nil {
+		return x.DeathTraceTime
+	}
+	return 0
+}
+
 type BonusUpdate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PickedUpBy    string                 `protobuf:"bytes,1,opt,name=picked_up_by,json=pickedUpBy,proto3" json:"picked_up_by,omitempty"`
@@ -1444,7 +1723,7 @@ type BonusUpdate struct {
 
 func (x *BonusUpdate) Reset() {
 	*x = BonusUpdate{}
-	mi := &file_messages_proto_msgTypes[19]
+	mi := &file_messages_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1456,7 +1735,7 @@ func (x *BonusUpdate) String() string {
 func (*BonusUpdate) ProtoMessage() {}
 
 func (x *BonusUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[19]
+	mi := &file_messages_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1469,7 +1748,7 @@ func (x *BonusUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BonusUpdate.ProtoReflect.Descriptor instead.
 func (*BonusUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{19}
+	return file_messages_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *BonusUpdate) GetPickedUpBy() string {
@@ -1488,7 +1767,7 @@ type ShopUpdate struct {
 
 func (x *ShopUpdate) Reset() {
 	*x = ShopUpdate{}
-	mi := &file_messages_proto_msgTypes[20]
+	mi := &file_messages_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1500,7 +1779,7 @@ func (x *ShopUpdate) String() string {
 func (*ShopUpdate) ProtoMessage() {}
 
 func (x *ShopUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[20]
+	mi := &file_messages_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1513,7 +1792,7 @@ func (x *ShopUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShopUpdate.ProtoReflect.Descriptor instead.
 func (*ShopUpdate) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{20}
+	return file_messages_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ShopUpdate) GetInventory() map[int32]*ShopItem {
@@ -1523,6 +1802,59 @@ func (x *ShopUpdate) GetInventory() map[int32]*ShopItem {
 	return nil
 }
 
+// Sent when the player walks into or out of a shop's interaction range
+type ShopEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          ShopEventType          `protobuf:"varint,1,opt,name=type,proto3,enum=protocol.ShopEventType" json:"type,omitempty"`
+	ShopId        string                 `protobuf:"bytes,2,opt,name=shop_id,json=shopId,proto3" json:"shop_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShopEvent) Reset() {
+	*x = ShopEvent{}
+	mi := &file_messages_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShopEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShopEvent) ProtoMessage() {}
+
+func (x *ShopEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShopEvent.ProtoReflect.Descriptor instead.
+func (*ShopEvent) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ShopEvent) GetType() ShopEventType {
+	if x != nil {
+		return x.Type
+	}
+	return ShopEventType_SHOP_EVENT_UNKNOWN
+}
+
+func (x *ShopEvent) GetShopId() string {
+	if x != nil {
+		return x.ShopId
+	}
+	return ""
+}
+
 type GameStateDeltaMessage struct {
 	state                       protoimpl.MessageState     `protogen:"open.v1"`
 	AddedPlayers                map[string]*Player         `protobuf:"bytes,1,rep,name=added_players,json=addedPlayers,proto3" json:"added_players,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -1547,13 +1879,20 @@ type GameStateDeltaMessage struct {
 	UpdatedOtherPlayerPositions map[string]*Vector2        `protobuf:"bytes,20,rep,name=updated_other_player_positions,json=updatedOtherPlayerPositions,proto3" json:"updated_other_player_positions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	RemovedOtherPlayerPositions []string                   `protobuf:"bytes,21,rep,name=removed_other_player_positions,json=removedOtherPlayerPositions,proto3" json:"removed_other_player_positions,omitempty"`
 	Timestamp                   int64                      `protobuf:"varint,22,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ShopEvents                  []*ShopEvent               `protobuf:"bytes,23,rep,name=shop_events,json=shopEvents,proto3" json:"shop_events,omitempty"`
+	Zone                        string                     `protobuf:"bytes,24,opt,name=zone,proto3" json:"zone,omitempty"`                                        // Zone the player is in, sent when it changes. "none" when they left every zone
+	DebugCounts                 *DebugCounts               `protobuf:"bytes,25,opt,name=debug_counts,json=debugCounts,proto3" json:"debug_counts,omitempty"`       // Only sent to clients that connected with debug enabled
+	JoinedPlayers               []*Player                  `protobuf:"bytes,26,rep,name=joined_players,json=joinedPlayers,proto3" json:"joined_players,omitempty"` // Players who joined since the last delta, when joins aren't sent as PLAYER_JOIN
+	LeftPlayers                 []string                   `protobuf:"bytes,27,rep,name=left_players,json=leftPlayers,proto3" json:"left_players,omitempty"`       // Players who left since the last delta, when leaves aren't sent as PLAYER_LEAVE
+	KillCam                     *KillCam                   `protobuf:"bytes,28,opt,name=kill_cam,json=killCam,proto3" json:"kill_cam,omitempty"`                   // The last moments before the player died, sent once in the delta after their death
+	JammedWeapon                string                     `protobuf:"bytes,29,opt,name=jammed_weapon,json=jammedWeapon,proto3" json:"jammed_weapon,omitempty"`    // Weapon that jammed when the player pulled the trigger, sent once in the next delta
 	unknownFields               protoimpl.UnknownFields
 	sizeCache                   protoimpl.SizeCache
 }
 
 func (x *GameStateDeltaMessage) Reset() {
 	*x = GameStateDeltaMessage{}
-	mi := &file_messages_proto_msgTypes[21]
+	mi := &file_messages_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1565,7 +1904,7 @@ func (x *GameStateDeltaMessage) String() string {
 func (*GameStateDeltaMessage) ProtoMessage() {}
 
 func (x *GameStateDeltaMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[21]
+	mi := &file_messages_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1578,7 +1917,7 @@ func (x *GameStateDeltaMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameStateDeltaMessage.ProtoReflect.Descriptor instead.
 func (*GameStateDeltaMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{21}
+	return file_messages_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *GameStateDeltaMessage) GetAddedPlayers() map[string]*Player {
@@ -1735,6 +2074,55 @@ func (x *GameStateDeltaMessage) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *GameStateDeltaMessage) GetShopEvents() []*ShopEvent {
+	if x != nil {
+		return x.ShopEvents
+	}
+	return nil
+}
+
+func (x *GameStateDeltaMessage) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *GameStateDeltaMessage) GetDebugCounts() *DebugCounts {
+	if x != nil {
+		return x.DebugCounts
+	}
+	return nil
+}
+
+func (x *GameStateDeltaMessage) GetJoinedPlayers() []*Player {
+	if x != nil {
+		return x.JoinedPlayers
+	}
+	return nil
+}
+
+func (x *GameStateDeltaMessage) GetLeftPlayers() []string {
+	if x != nil {
+		return x.LeftPlayers
+	}
+	return nil
+}
+
+func (x *GameStateDeltaMessage) GetKillCam() *KillCam {
+	if x != nil {
+		return x.KillCam
+	}
+	return nil
+}
+
+func (x *GameStateDeltaMessage) GetJammedWeapon() string {
+	if x != nil {
+		return x.JammedWeapon
+	}
+	return ""
+}
+
 type PlayerJoinMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Player        *Player                `protobuf:"bytes,1,opt,name=player,proto3" json:"player,omitempty"`
@@ -1744,7 +2132,7 @@ type PlayerJoinMessage struct {
 
 func (x *PlayerJoinMessage) Reset() {
 	*x = PlayerJoinMessage{}
-	mi := &file_messages_proto_msgTypes[22]
+	mi := &file_messages_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1756,7 +2144,7 @@ func (x *PlayerJoinMessage) String() string {
 func (*PlayerJoinMessage) ProtoMessage() {}
 
 func (x *PlayerJoinMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[22]
+	mi := &file_messages_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1769,7 +2157,7 @@ func (x *PlayerJoinMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerJoinMessage.ProtoReflect.Descriptor instead.
 func (*PlayerJoinMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{22}
+	return file_messages_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *PlayerJoinMessage) GetPlayer() *Player {
@@ -1788,7 +2176,7 @@ type PlayerLeaveMessage struct {
 
 func (x *PlayerLeaveMessage) Reset() {
 	*x = PlayerLeaveMessage{}
-	mi := &file_messages_proto_msgTypes[23]
+	mi := &file_messages_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1800,7 +2188,7 @@ func (x *PlayerLeaveMessage) String() string {
 func (*PlayerLeaveMessage) ProtoMessage() {}
 
 func (x *PlayerLeaveMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[23]
+	mi := &file_messages_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1813,7 +2201,7 @@ func (x *PlayerLeaveMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerLeaveMessage.ProtoReflect.Descriptor instead.
 func (*PlayerLeaveMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{23}
+	return file_messages_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *PlayerLeaveMessage) GetPlayerId() string {
@@ -1831,7 +2219,7 @@ type PlayerRespawnMessage struct {
 
 func (x *PlayerRespawnMessage) Reset() {
 	*x = PlayerRespawnMessage{}
-	mi := &file_messages_proto_msgTypes[24]
+	mi := &file_messages_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1843,7 +2231,7 @@ func (x *PlayerRespawnMessage) String() string {
 func (*PlayerRespawnMessage) ProtoMessage() {}
 
 func (x *PlayerRespawnMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[24]
+	mi := &file_messages_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1856,7 +2244,7 @@ func (x *PlayerRespawnMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerRespawnMessage.ProtoReflect.Descriptor instead.
 func (*PlayerRespawnMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{24}
+	return file_messages_proto_rawDescGZIP(), []int{26}
 }
 
 type ErrorMessage struct {
@@ -1868,7 +2256,7 @@ type ErrorMessage struct {
 
 func (x *ErrorMessage) Reset() {
 	*x = ErrorMessage{}
-	mi := &file_messages_proto_msgTypes[25]
+	mi := &file_messages_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1880,7 +2268,7 @@ func (x *ErrorMessage) String() string {
 func (*ErrorMessage) ProtoMessage() {}
 
 func (x *ErrorMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[25]
+	mi := &file_messages_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1893,7 +2281,7 @@ func (x *ErrorMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorMessage.ProtoReflect.Descriptor instead.
 func (*ErrorMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{25}
+	return file_messages_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ErrorMessage) GetMessage() string {
@@ -1922,7 +2310,7 @@ type GameMessage struct {
 
 func (x *GameMessage) Reset() {
 	*x = GameMessage{}
-	mi := &file_messages_proto_msgTypes[26]
+	mi := &file_messages_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1934,7 +2322,7 @@ func (x *GameMessage) String() string {
 func (*GameMessage) ProtoMessage() {}
 
 func (x *GameMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_messages_proto_msgTypes[26]
+	mi := &file_messages_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1947,7 +2335,7 @@ func (x *GameMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameMessage.ProtoReflect.Descriptor instead.
 func (*GameMessage) Descriptor() ([]byte, []int) {
-	return file_messages_proto_rawDescGZIP(), []int{26}
+	return file_messages_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *GameMessage) GetType() MessageType {
@@ -2058,6 +2446,205 @@ func (*GameMessage_PlayerRespawn) isGameMessage_Payload() {}
 
 func (*GameMessage_Error) isGameMessage_Payload() {}
 
+// Entities the server tracks in the chunks around a player, for debug HUDs
+type DebugCounts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Players       uint32                 `protobuf:"varint,1,opt,name=players,proto3" json:"players,omitempty"`
+	Enemies       uint32                 `protobuf:"varint,2,opt,name=enemies,proto3" json:"enemies,omitempty"`
+	Bullets       uint32                 `protobuf:"varint,3,opt,name=bullets,proto3" json:"bullets,omitempty"`
+	Walls         uint32                 `protobuf:"varint,4,opt,name=walls,proto3" json:"walls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DebugCounts) Reset() {
+	*x = DebugCounts{}
+	mi := &file_messages_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebugCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugCounts) ProtoMessage() {}
+
+func (x *DebugCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugCounts.ProtoReflect.Descriptor instead.
+func (*DebugCounts) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DebugCounts) GetPlayers() uint32 {
+	if x != nil {
+		return x.Players
+	}
+	return 0
+}
+
+func (x *DebugCounts) GetEnemies() uint32 {
+	if x != nil {
+		return x.Enemies
+	}
+	return 0
+}
+
+func (x *DebugCounts) GetBullets() uint32 {
+	if x != nil {
+		return x.Bullets
+	}
+	return 0
+}
+
+func (x *DebugCounts) GetWalls() uint32 {
+	if x != nil {
+		return x.Walls
+	}
+	return 0
+}
+
+// The dying player's recent view, for the client to replay before the death screen
+type KillCam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KillerId      string                 `protobuf:"bytes,1,opt,name=killer_id,json=killerId,proto3" json:"killer_id,omitempty"` // Player or enemy that landed the killing blow, empty when unknown
+	Frames        []*KillCamFrame        `protobuf:"bytes,2,rep,name=frames,proto3" json:"frames,omitempty"`                     // Oldest first, the last one is the moment of death
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillCam) Reset() {
+	*x = KillCam{}
+	mi := &file_messages_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillCam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillCam) ProtoMessage() {}
+
+func (x *KillCam) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillCam.ProtoReflect.Descriptor instead.
+func (*KillCam) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *KillCam) GetKillerId() string {
+	if x != nil {
+		return x.KillerId
+	}
+	return ""
+}
+
+func (x *KillCam) GetFrames() []*KillCamFrame {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+// Where the dying player and their killer were at one tick
+type KillCamFrame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Player        *PositionUpdate        `protobuf:"bytes,2,opt,name=player,proto3" json:"player,omitempty"`
+	Lives         float32                `protobuf:"fixed32,3,opt,name=lives,proto3" json:"lives,omitempty"`
+	Killer        *PositionUpdate        `protobuf:"bytes,4,opt,name=killer,proto3" json:"killer,omitempty"`   // Missing while the killer wasn't near the player
+	Bullets       []*Bullet              `protobuf:"bytes,5,rep,name=bullets,proto3" json:"bullets,omitempty"` // The killer's bullets near the player
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillCamFrame) Reset() {
+	*x = KillCamFrame{}
+	mi := &file_messages_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillCamFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillCamFrame) ProtoMessage() {}
+
+func (x *KillCamFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillCamFrame.ProtoReflect.Descriptor instead.
+func (*KillCamFrame) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *KillCamFrame) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *KillCamFrame) GetPlayer() *PositionUpdate {
+	if x != nil {
+		return x.Player
+	}
+	return nil
+}
+
+func (x *KillCamFrame) GetLives() float32 {
+	if x != nil {
+		return x.Lives
+	}
+	return 0
+}
+
+func (x *KillCamFrame) GetKiller() *PositionUpdate {
+	if x != nil {
+		return x.Killer
+	}
+	return nil
+}
+
+func (x *KillCamFrame) GetBullets() []*Bullet {
+	if x != nil {
+		return x.Bullets
+	}
+	return nil
+}
+
 var File_messages_proto protoreflect.FileDescriptor
 
 const file_messages_proto_rawDesc = "" +
@@ -2068,7 +2655,7 @@ const file_messages_proto_rawDesc = "" +
 	"\x01y\x18\x02 \x01(\x01R\x01y\"?\n" +
 	"\rInventoryItem\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\x05R\x04type\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x9a\x05\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xfe\x06\n" +
 	"\x06Player\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
 	"\busername\x18\x02 \x01(\tR\busername\x12-\n" +
@@ -2085,10 +2672,17 @@ const file_messages_proto_rawDesc = "" +
 	"\x12night_vision_timer\x18\v \x01(\x01R\x10nightVisionTimer\x12\x19\n" +
 	"\bis_alive\x18\f \x01(\bR\aisAlive\x125\n" +
 	"\tinventory\x18\x0e \x03(\v2\x17.protocol.InventoryItemR\tinventory\x12*\n" +
-	"\x11selected_gun_type\x18\x0f \x01(\tR\x0fselectedGunType\x1aJ\n" +
+	"\x11selected_gun_type\x18\x0f \x01(\tR\x0fselectedGunType\x12.\n" +
+	"\x13double_damage_timer\x18\x10 \x01(\x01R\x11doubleDamageTimer\x12(\n" +
+	"\x10rapid_fire_timer\x18\x11 \x01(\x01R\x0erapidFireTimer\x12*\n" +
+	"\x11speed_boost_timer\x18\x12 \x01(\x01R\x0fspeedBoostTimer\x12\x18\n" +
+	"\astamina\x18\x13 \x01(\x01R\astamina\x12\x1f\n" +
+	"\vblind_timer\x18\x14 \x01(\x01R\n" +
+	"blindTimer\x12!\n" +
+	"\freload_timer\x18\x15 \x01(\x01R\vreloadTimer\x1aJ\n" +
 	"\x1cBulletsLeftByWeaponTypeEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xc0\x02\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xeb\x02\n" +
 	"\x06Bullet\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
 	"\bposition\x18\x02 \x01(\v2\x11.protocol.Vector2R\bposition\x12-\n" +
@@ -2103,13 +2697,15 @@ const file_messages_proto_rawDesc = "" +
 	"deleted_at\x18\n" +
 	" \x01(\x03R\tdeletedAt\x12\x1f\n" +
 	"\vweapon_type\x18\b \x01(\tR\n" +
-	"weaponType\"\x95\x01\n" +
+	"weaponType\x12)\n" +
+	"\x06origin\x18\f \x01(\v2\x11.protocol.Vector2R\x06origin\"\xae\x01\n" +
 	"\x04Wall\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
 	"\bposition\x18\x02 \x01(\v2\x11.protocol.Vector2R\bposition\x12\x14\n" +
 	"\x05width\x18\x03 \x01(\x01R\x05width\x12\x16\n" +
 	"\x06height\x18\x04 \x01(\x01R\x06height\x12 \n" +
-	"\vorientation\x18\x05 \x01(\tR\vorientation\"\xc0\x01\n" +
+	"\vorientation\x18\x05 \x01(\tR\vorientation\x12\x17\n" +
+	"\ais_door\x18\x06 \x01(\bR\x06isDoor\"\xbf\x02\n" +
 	"\x05Enemy\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
 	"\bposition\x18\x02 \x01(\v2\x11.protocol.Vector2R\bposition\x12\x1a\n" +
@@ -2117,7 +2713,12 @@ const file_messages_proto_rawDesc = "" +
 	"\x05lives\x18\x04 \x01(\x02R\x05lives\x12\x17\n" +
 	"\awall_id\x18\x05 \x01(\tR\x06wallId\x12\x19\n" +
 	"\bis_alive\x18\x06 \x01(\bR\aisAlive\x12\x12\n" +
-	"\x04type\x18\a \x01(\tR\x04type\"\x9b\x01\n" +
+	"\x04type\x18\a \x01(\tR\x04type\x12\x1b\n" +
+	"\tmax_lives\x18\b \x01(\x02R\bmaxLives\x12\x18\n" +
+	"\aarmored\x18\t \x01(\bR\aarmored\x12\x1c\n" +
+	"\tarchetype\x18\n" +
+	" \x01(\tR\tarchetype\x12(\n" +
+	"\x10death_trace_time\x18\v \x01(\x02R\x0edeathTraceTime\"\x9b\x01\n" +
 	"\x05Bonus\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12-\n" +
 	"\bposition\x18\x02 \x01(\v2\x11.protocol.Vector2R\bposition\x12\x12\n" +
@@ -2137,7 +2738,7 @@ const file_messages_proto_rawDesc = "" +
 	"\x04name\x18\x04 \x01(\tR\x04name\x1aP\n" +
 	"\x0eInventoryEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12(\n" +
-	"\x05value\x18\x02 \x01(\v2\x12.protocol.ShopItemR\x05value:\x028\x01\"\x9d\x03\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.protocol.ShopItemR\x05value:\x028\x01\"\xe9\x03\n" +
 	"\fInputMessage\x12\x18\n" +
 	"\aforward\x18\x01 \x01(\bR\aforward\x12\x1a\n" +
 	"\bbackward\x18\x02 \x01(\bR\bbackward\x12\x12\n" +
@@ -2145,20 +2746,31 @@ const file_messages_proto_rawDesc = "" +
 	"\x05right\x18\x04 \x01(\bR\x05right\x12\x14\n" +
 	"\x05shoot\x18\x05 \x01(\bR\x05shoot\x12>\n" +
 	"\bitem_key\x18\x06 \x03(\v2#.protocol.InputMessage.ItemKeyEntryR\aitemKey\x12W\n" +
-	"\x11purchase_item_key\x18\a \x03(\v2+.protocol.InputMessage.PurchaseItemKeyEntryR\x0fpurchaseItemKey\x1a:\n" +
+	"\x11purchase_item_key\x18\a \x03(\v2+.protocol.InputMessage.PurchaseItemKeyEntryR\x0fpurchaseItemKey\x12\x1a\n" +
+	"\bsequence\x18\b \x01(\rR\bsequence\x12\x16\n" +
+	"\x06sprint\x18\t \x01(\bR\x06sprint\x12\x16\n" +
+	"\x06reload\x18\n" +
+	" \x01(\bR\x06reload\x1a:\n" +
 	"\fItemKeyEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\x1aB\n" +
 	"\x14PurchaseItemKeyEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"H\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"z\n" +
 	"\x0ePositionUpdate\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
 	"\x01y\x18\x02 \x01(\x01R\x01y\x12\x1a\n" +
-	"\brotation\x18\x03 \x01(\x01R\brotation\"k\n" +
+	"\brotation\x18\x03 \x01(\x01R\brotation\x120\n" +
+	"\x14last_processed_input\x18\x04 \x01(\rR\x12lastProcessedInput\"\xb5\x02\n" +
 	"\fTimersUpdate\x12-\n" +
 	"\x12invulnerable_timer\x18\x01 \x01(\x01R\x11invulnerableTimer\x12,\n" +
-	"\x12night_vision_timer\x18\x02 \x01(\x01R\x10nightVisionTimer\">\n" +
+	"\x12night_vision_timer\x18\x02 \x01(\x01R\x10nightVisionTimer\x12.\n" +
+	"\x13double_damage_timer\x18\x03 \x01(\x01R\x11doubleDamageTimer\x12(\n" +
+	"\x10rapid_fire_timer\x18\x04 \x01(\x01R\x0erapidFireTimer\x12*\n" +
+	"\x11speed_boost_timer\x18\x05 \x01(\x01R\x0fspeedBoostTimer\x12\x1f\n" +
+	"\vblind_timer\x18\x06 \x01(\x01R\n" +
+	"blindTimer\x12!\n" +
+	"\freload_timer\x18\a \x01(\x01R\vreloadTimer\">\n" +
 	"\vLivesUpdate\x12\x14\n" +
 	"\x05lives\x18\x01 \x01(\x02R\x05lives\x12\x19\n" +
 	"\bis_alive\x18\x02 \x01(\bR\aisAlive\"t\n" +
@@ -2168,26 +2780,30 @@ const file_messages_proto_rawDesc = "" +
 	"\vScoreUpdate\x12\x14\n" +
 	"\x05score\x18\x01 \x01(\x05R\x05score\x12\x14\n" +
 	"\x05money\x18\x02 \x01(\x05R\x05money\x12\x14\n" +
-	"\x05kills\x18\x03 \x01(\x05R\x05kills\"\xdb\x01\n" +
+	"\x05kills\x18\x03 \x01(\x05R\x05kills\")\n" +
+	"\rStaminaUpdate\x12\x18\n" +
+	"\astamina\x18\x01 \x01(\x01R\astamina\"\xdb\x01\n" +
 	"\x13PlayerBulletsUpdate\x12x\n" +
 	"\x1bbullets_left_by_weapon_type\x18\x01 \x03(\v2:.protocol.PlayerBulletsUpdate.BulletsLeftByWeaponTypeEntryR\x17bulletsLeftByWeaponType\x1aJ\n" +
 	"\x1cBulletsLeftByWeaponTypeEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xcd\x02\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x80\x03\n" +
 	"\fPlayerUpdate\x124\n" +
 	"\bposition\x18\x01 \x01(\v2\x18.protocol.PositionUpdateR\bposition\x12.\n" +
 	"\x06timers\x18\x02 \x01(\v2\x16.protocol.TimersUpdateR\x06timers\x12+\n" +
 	"\x05lives\x18\x03 \x01(\v2\x15.protocol.LivesUpdateR\x05lives\x127\n" +
 	"\tinventory\x18\x04 \x01(\v2\x19.protocol.InventoryUpdateR\tinventory\x12+\n" +
 	"\x05score\x18\x05 \x01(\v2\x15.protocol.ScoreUpdateR\x05score\x12D\n" +
-	"\x0eplayer_bullets\x18\x06 \x01(\v2\x1d.protocol.PlayerBulletsUpdateR\rplayerBullets\"L\n" +
+	"\x0eplayer_bullets\x18\x06 \x01(\v2\x1d.protocol.PlayerBulletsUpdateR\rplayerBullets\x121\n" +
+	"\astamina\x18\a \x01(\v2\x17.protocol.StaminaUpdateR\astamina\"L\n" +
 	"\x0eDeletionUpdate\x12\x1b\n" +
 	"\tis_active\x18\x01 \x01(\bR\bisActive\x12\x1d\n" +
 	"\n" +
-	"deleted_at\x18\x02 \x01(\x03R\tdeletedAt\"p\n" +
+	"deleted_at\x18\x02 \x01(\x03R\tdeletedAt\"\x9a\x01\n" +
 	"\vEnemyUpdate\x124\n" +
 	"\bposition\x18\x01 \x01(\v2\x18.protocol.PositionUpdateR\bposition\x12+\n" +
-	"\x05lives\x18\x02 \x01(\v2\x15.protocol.LivesUpdateR\x05lives\"/\n" +
+	"\x05lives\x18\x02 \x01(\v2\x15.protocol.LivesUpdateR\x05lives\x12(\n" +
+	"\x10death_trace_time\x18\x03 \x01(\x02R\x0edeathTraceTime\"/\n" +
 	"\vBonusUpdate\x12 \n" +
 	"\fpicked_up_by\x18\x01 \x01(\tR\n" +
 	"pickedUpBy\"\xa1\x01\n" +
@@ -2196,7 +2812,10 @@ const file_messages_proto_rawDesc = "" +
 	"\tinventory\x18\x01 \x03(\v2#.protocol.ShopUpdate.InventoryEntryR\tinventory\x1aP\n" +
 	"\x0eInventoryEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12(\n" +
-	"\x05value\x18\x02 \x01(\v2\x12.protocol.ShopItemR\x05value:\x028\x01\"\xc0\x15\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.protocol.ShopItemR\x05value:\x028\x01\"Q\n" +
+	"\tShopEvent\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.protocol.ShopEventTypeR\x04type\x12\x17\n" +
+	"\ashop_id\x18\x02 \x01(\tR\x06shopId\"\xf3\x17\n" +
 	"\x15GameStateDeltaMessage\x12V\n" +
 	"\radded_players\x18\x01 \x03(\v21.protocol.GameStateDeltaMessage.AddedPlayersEntryR\faddedPlayers\x12\\\n" +
 	"\x0fupdated_players\x18\x02 \x03(\v23.protocol.GameStateDeltaMessage.UpdatedPlayersEntryR\x0eupdatedPlayers\x12'\n" +
@@ -2222,7 +2841,15 @@ const file_messages_proto_rawDesc = "" +
 	"\x15removed_players_shops\x18\x13 \x03(\tR\x13removedPlayersShops\x12\x85\x01\n" +
 	"\x1eupdated_other_player_positions\x18\x14 \x03(\v2@.protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntryR\x1bupdatedOtherPlayerPositions\x12C\n" +
 	"\x1eremoved_other_player_positions\x18\x15 \x03(\tR\x1bremovedOtherPlayerPositions\x12\x1c\n" +
-	"\ttimestamp\x18\x16 \x01(\x03R\ttimestamp\x1aQ\n" +
+	"\ttimestamp\x18\x16 \x01(\x03R\ttimestamp\x124\n" +
+	"\vshop_events\x18\x17 \x03(\v2\x13.protocol.ShopEventR\n" +
+	"shopEvents\x12\x12\n" +
+	"\x04zone\x18\x18 \x01(\tR\x04zone\x128\n" +
+	"\fdebug_counts\x18\x19 \x01(\v2\x15.protocol.DebugCountsR\vdebugCounts\x127\n" +
+	"\x0ejoined_players\x18\x1a \x03(\v2\x10.protocol.PlayerR\rjoinedPlayers\x12!\n" +
+	"\fleft_players\x18\x1b \x03(\tR\vleftPlayers\x12,\n" +
+	"\bkill_cam\x18\x1c \x01(\v2\x11.protocol.KillCamR\akillCam\x12#\n" +
+	"\rjammed_weapon\x18\x1d \x01(\tR\fjammedWeapon\x1aQ\n" +
 	"\x11AddedPlayersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
 	"\x05value\x18\x02 \x01(\v2\x10.protocol.PlayerR\x05value:\x028\x01\x1aY\n" +
@@ -2279,7 +2906,21 @@ const file_messages_proto_rawDesc = "" +
 	"\x0eplayer_respawn\x18\b \x01(\v2\x1e.protocol.PlayerRespawnMessageH\x00R\rplayerRespawn\x12.\n" +
 	"\x05error\x18\n" +
 	" \x01(\v2\x16.protocol.ErrorMessageH\x00R\x05errorB\t\n" +
-	"\apayload*\x8d\x01\n" +
+	"\apayload\"q\n" +
+	"\vDebugCounts\x12\x18\n" +
+	"\aplayers\x18\x01 \x01(\rR\aplayers\x12\x18\n" +
+	"\aenemies\x18\x02 \x01(\rR\aenemies\x12\x18\n" +
+	"\abullets\x18\x03 \x01(\rR\abullets\x12\x14\n" +
+	"\x05walls\x18\x04 \x01(\rR\x05walls\"V\n" +
+	"\aKillCam\x12\x1b\n" +
+	"\tkiller_id\x18\x01 \x01(\tR\bkillerId\x12.\n" +
+	"\x06frames\x18\x02 \x03(\v2\x16.protocol.KillCamFrameR\x06frames\"\xd2\x01\n" +
+	"\fKillCamFrame\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x120\n" +
+	"\x06player\x18\x02 \x01(\v2\x18.protocol.PositionUpdateR\x06player\x12\x14\n" +
+	"\x05lives\x18\x03 \x01(\x02R\x05lives\x120\n" +
+	"\x06killer\x18\x04 \x01(\v2\x18.protocol.PositionUpdateR\x06killer\x12*\n" +
+	"\abullets\x18\x05 \x03(\v2\x10.protocol.BulletR\abullets*\x8d\x01\n" +
 	"\vMessageType\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\t\n" +
 	"\x05INPUT\x10\x02\x12\x0e\n" +
@@ -2290,7 +2931,13 @@ const file_messages_proto_rawDesc = "" +
 	"\fPLAYER_LEAVE\x10\a\x12\x12\n" +
 	"\x0ePLAYER_RESPAWN\x10\b\x12\t\n" +
 	"\x05ERROR\x10\n" +
-	"B7Z5github.com/besuhoff/dungeon-game-go/internal/protocolb\x06proto3"
+	"*G\n" +
+	"\rShopEventType\x12\x16\n" +
+	"\x12SHOP_EVENT_UNKNOWN\x10\x00\x12\x0e\n" +
+	"\n" +
+	"SHOP_ENTER\x10\x01\x12\x0e\n" +
+	"\n" +
+	"SHOP_LEAVE\x10\x02B7Z5github.com/besuhoff/dungeon-game-go/internal/protocolb\x06proto3"
 
 var (
 	file_messages_proto_rawDescOnce sync.Once
@@ -2304,123 +2951,140 @@ func file_messages_proto_rawDescGZIP() []byte {
 	return file_messages_proto_rawDescData
 }
 
-var file_messages_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
+var file_messages_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_messages_proto_goTypes = []any{
 	(MessageType)(0),              // 0: protocol.MessageType
-	(*Vector2)(nil),               // 1: protocol.Vector2
-	(*InventoryItem)(nil),         // 2: protocol.InventoryItem
-	(*Player)(nil),                // 3: protocol.Player
-	(*Bullet)(nil),                // 4: protocol.Bullet
-	(*Wall)(nil),                  // 5: protocol.Wall
-	(*Enemy)(nil),                 // 6: protocol.Enemy
-	(*Bonus)(nil),                 // 7: protocol.Bonus
-	(*ShopItem)(nil),              // 8: protocol.ShopItem
-	(*Shop)(nil),                  // 9: protocol.Shop
-	(*InputMessage)(nil),          // 10: protocol.InputMessage
-	(*PositionUpdate)(nil),        // 11: protocol.PositionUpdate
-	(*TimersUpdate)(nil),          // 12: protocol.TimersUpdate
-	(*LivesUpdate)(nil),           // 13: protocol.LivesUpdate
-	(*InventoryUpdate)(nil),       // 14: protocol.InventoryUpdate
-	(*ScoreUpdate)(nil),           // 15: protocol.ScoreUpdate
-	(*PlayerBulletsUpdate)(nil),   // 16: protocol.PlayerBulletsUpdate
-	(*PlayerUpdate)(nil),          // 17: protocol.PlayerUpdate
-	(*DeletionUpdate)(nil),        // 18: protocol.DeletionUpdate
-	(*EnemyUpdate)(nil),           // 19: protocol.EnemyUpdate
-	(*BonusUpdate)(nil),           // 20: protocol.BonusUpdate
-	(*ShopUpdate)(nil),            // 21: protocol.ShopUpdate
-	(*GameStateDeltaMessage)(nil), // 22: protocol.GameStateDeltaMessage
-	(*PlayerJoinMessage)(nil),     // 23: protocol.PlayerJoinMessage
-	(*PlayerLeaveMessage)(nil),    // 24: protocol.PlayerLeaveMessage
-	(*PlayerRespawnMessage)(nil),  // 25: protocol.PlayerRespawnMessage
-	(*ErrorMessage)(nil),          // 26: protocol.ErrorMessage
-	(*GameMessage)(nil),           // 27: protocol.GameMessage
-	nil,                           // 28: protocol.Player.BulletsLeftByWeaponTypeEntry
-	nil,                           // 29: protocol.Shop.InventoryEntry
-	nil,                           // 30: protocol.InputMessage.ItemKeyEntry
-	nil,                           // 31: protocol.InputMessage.PurchaseItemKeyEntry
-	nil,                           // 32: protocol.PlayerBulletsUpdate.BulletsLeftByWeaponTypeEntry
-	nil,                           // 33: protocol.ShopUpdate.InventoryEntry
-	nil,                           // 34: protocol.GameStateDeltaMessage.AddedPlayersEntry
-	nil,                           // 35: protocol.GameStateDeltaMessage.UpdatedPlayersEntry
-	nil,                           // 36: protocol.GameStateDeltaMessage.AddedBulletsEntry
-	nil,                           // 37: protocol.GameStateDeltaMessage.UpdatedBulletsEntry
-	nil,                           // 38: protocol.GameStateDeltaMessage.RemovedBulletsEntry
-	nil,                           // 39: protocol.GameStateDeltaMessage.AddedWallsEntry
-	nil,                           // 40: protocol.GameStateDeltaMessage.AddedEnemiesEntry
-	nil,                           // 41: protocol.GameStateDeltaMessage.UpdatedEnemiesEntry
-	nil,                           // 42: protocol.GameStateDeltaMessage.AddedBonusesEntry
-	nil,                           // 43: protocol.GameStateDeltaMessage.UpdatedBonusesEntry
-	nil,                           // 44: protocol.GameStateDeltaMessage.AddedShopsEntry
-	nil,                           // 45: protocol.GameStateDeltaMessage.UpdatedShopsEntry
-	nil,                           // 46: protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry
+	(ShopEventType)(0),            // 1: protocol.ShopEventType
+	(*Vector2)(nil),               // 2: protocol.Vector2
+	(*InventoryItem)(nil),         // 3: protocol.InventoryItem
+	(*Player)(nil),                // 4: protocol.Player
+	(*Bullet)(nil),                // 5: protocol.Bullet
+	(*Wall)(nil),                  // 6: protocol.Wall
+	(*Enemy)(nil),                 // 7: protocol.Enemy
+	(*Bonus)(nil),                 // 8: protocol.Bonus
+	(*ShopItem)(nil),              // 9: protocol.ShopItem
+	(*Shop)(nil),                  // 10: protocol.Shop
+	(*InputMessage)(nil),          // 11: protocol.InputMessage
+	(*PositionUpdate)(nil),        // 12: protocol.PositionUpdate
+	(*TimersUpdate)(nil),          // 13: protocol.TimersUpdate
+	(*LivesUpdate)(nil),           // 14: protocol.LivesUpdate
+	(*InventoryUpdate)(nil),       // 15: protocol.InventoryUpdate
+	(*ScoreUpdate)(nil),           // 16: protocol.ScoreUpdate
+	(*StaminaUpdate)(nil),         // 17: protocol.StaminaUpdate
+	(*PlayerBulletsUpdate)(nil),   // 18: protocol.PlayerBulletsUpdate
+	(*PlayerUpdate)(nil),          // 19: protocol.PlayerUpdate
+	(*DeletionUpdate)(nil),        // 20: protocol.DeletionUpdate
+	(*EnemyUpdate)(nil),           // 21: protocol.EnemyUpdate
+	(*BonusUpdate)(nil),           // 22: protocol.BonusUpdate
+	(*ShopUpdate)(nil),            // 23: protocol.ShopUpdate
+	(*ShopEvent)(nil),             // 24: protocol.ShopEvent
+	(*GameStateDeltaMessage)(nil), // 25: protocol.GameStateDeltaMessage
+	(*PlayerJoinMessage)(nil),     // 26: protocol.PlayerJoinMessage
+	(*PlayerLeaveMessage)(nil),    // 27: protocol.PlayerLeaveMessage
+	(*PlayerRespawnMessage)(nil),  // 28: protocol.PlayerRespawnMessage
+	(*ErrorMessage)(nil),          // 29: protocol.ErrorMessage
+	(*GameMessage)(nil),           // 30: protocol.GameMessage
+	(*DebugCounts)(nil),           // 31: protocol.DebugCounts
+	(*KillCam)(nil),               // 32: protocol.KillCam
+	(*KillCamFrame)(nil),          // 33: protocol.KillCamFrame
+	nil,                           // 34: protocol.Player.BulletsLeftByWeaponTypeEntry
+	nil,                           // 35: protocol.Shop.InventoryEntry
+	nil,                           // 36: protocol.InputMessage.ItemKeyEntry
+	nil,                           // 37: protocol.InputMessage.PurchaseItemKeyEntry
+	nil,                           // 38: protocol.PlayerBulletsUpdate.BulletsLeftByWeaponTypeEntry
+	nil,                           // 39: protocol.ShopUpdate.InventoryEntry
+	nil,                           // 40: protocol.GameStateDeltaMessage.AddedPlayersEntry
+	nil,                           // 41: protocol.GameStateDeltaMessage.UpdatedPlayersEntry
+	nil,                           // 42: protocol.GameStateDeltaMessage.AddedBulletsEntry
+	nil,                           // 43: protocol.GameStateDeltaMessage.UpdatedBulletsEntry
+	nil,                           // 44: protocol.GameStateDeltaMessage.RemovedBulletsEntry
+	nil,                           // 45: protocol.GameStateDeltaMessage.AddedWallsEntry
+	nil,                           // 46: protocol.GameStateDeltaMessage.AddedEnemiesEntry
+	nil,                           // 47: protocol.GameStateDeltaMessage.UpdatedEnemiesEntry
+	nil,                           // 48: protocol.GameStateDeltaMessage.AddedBonusesEntry
+	nil,                           // 49: protocol.GameStateDeltaMessage.UpdatedBonusesEntry
+	nil,                           // 50: protocol.GameStateDeltaMessage.AddedShopsEntry
+	nil,                           // 51: protocol.GameStateDeltaMessage.UpdatedShopsEntry
+	nil,                           // 52: protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry
 }
 var file_messages_proto_depIdxs = []int32{
-	1,  // 0: protocol.Player.position:type_name -> protocol.Vector2
-	1,  // 1: protocol.Player.velocity:type_name -> protocol.Vector2
-	28, // 2: protocol.Player.bullets_left_by_weapon_type:type_name -> protocol.Player.BulletsLeftByWeaponTypeEntry
-	2,  // 3: protocol.Player.inventory:type_name -> protocol.InventoryItem
-	1,  // 4: protocol.Bullet.position:type_name -> protocol.Vector2
-	1,  // 5: protocol.Bullet.velocity:type_name -> protocol.Vector2
-	1,  // 6: protocol.Wall.position:type_name -> protocol.Vector2
-	1,  // 7: protocol.Enemy.position:type_name -> protocol.Vector2
-	1,  // 8: protocol.Bonus.position:type_name -> protocol.Vector2
-	1,  // 9: protocol.Shop.position:type_name -> protocol.Vector2
-	29, // 10: protocol.Shop.inventory:type_name -> protocol.Shop.InventoryEntry
-	30, // 11: protocol.InputMessage.item_key:type_name -> protocol.InputMessage.ItemKeyEntry
-	31, // 12: protocol.InputMessage.purchase_item_key:type_name -> protocol.InputMessage.PurchaseItemKeyEntry
-	2,  // 13: protocol.InventoryUpdate.inventory:type_name -> protocol.InventoryItem
-	32, // 14: protocol.PlayerBulletsUpdate.bullets_left_by_weapon_type:type_name -> protocol.PlayerBulletsUpdate.BulletsLeftByWeaponTypeEntry
-	11, // 15: protocol.PlayerUpdate.position:type_name -> protocol.PositionUpdate
-	12, // 16: protocol.PlayerUpdate.timers:type_name -> protocol.TimersUpdate
-	13, // 17: protocol.PlayerUpdate.lives:type_name -> protocol.LivesUpdate
-	14, // 18: protocol.PlayerUpdate.inventory:type_name -> protocol.InventoryUpdate
-	15, // 19: protocol.PlayerUpdate.score:type_name -> protocol.ScoreUpdate
-	16, // 20: protocol.PlayerUpdate.player_bullets:type_name -> protocol.PlayerBulletsUpdate
-	11, // 21: protocol.EnemyUpdate.position:type_name -> protocol.PositionUpdate
-	13, // 22: protocol.EnemyUpdate.lives:type_name -> protocol.LivesUpdate
-	33, // 23: protocol.ShopUpdate.inventory:type_name -> protocol.ShopUpdate.InventoryEntry
-	34, // 24: protocol.GameStateDeltaMessage.added_players:type_name -> protocol.GameStateDeltaMessage.AddedPlayersEntry
-	35, // 25: protocol.GameStateDeltaMessage.updated_players:type_name -> protocol.GameStateDeltaMessage.UpdatedPlayersEntry
-	36, // 26: protocol.GameStateDeltaMessage.added_bullets:type_name -> protocol.GameStateDeltaMessage.AddedBulletsEntry
-	37, // 27: protocol.GameStateDeltaMessage.updated_bullets:type_name -> protocol.GameStateDeltaMessage.UpdatedBulletsEntry
-	38, // 28: protocol.GameStateDeltaMessage.removed_bullets:type_name -> protocol.GameStateDeltaMessage.RemovedBulletsEntry
-	39, // 29: protocol.GameStateDeltaMessage.added_walls:type_name -> protocol.GameStateDeltaMessage.AddedWallsEntry
-	40, // 30: protocol.GameStateDeltaMessage.added_enemies:type_name -> protocol.GameStateDeltaMessage.AddedEnemiesEntry
-	41, // 31: protocol.GameStateDeltaMessage.updated_enemies:type_name -> protocol.GameStateDeltaMessage.UpdatedEnemiesEntry
-	42, // 32: protocol.GameStateDeltaMessage.added_bonuses:type_name -> protocol.GameStateDeltaMessage.AddedBonusesEntry
-	43, // 33: protocol.GameStateDeltaMessage.updated_bonuses:type_name -> protocol.GameStateDeltaMessage.UpdatedBonusesEntry
-	44, // 34: protocol.GameStateDeltaMessage.added_shops:type_name -> protocol.GameStateDeltaMessage.AddedShopsEntry
-	45, // 35: protocol.GameStateDeltaMessage.updated_shops:type_name -> protocol.GameStateDeltaMessage.UpdatedShopsEntry
-	46, // 36: protocol.GameStateDeltaMessage.updated_other_player_positions:type_name -> protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry
-	3,  // 37: protocol.PlayerJoinMessage.player:type_name -> protocol.Player
-	0,  // 38: protocol.GameMessage.type:type_name -> protocol.MessageType
-	10, // 39: protocol.GameMessage.input:type_name -> protocol.InputMessage
-	22, // 40: protocol.GameMessage.game_state_delta:type_name -> protocol.GameStateDeltaMessage
-	23, // 41: protocol.GameMessage.player_join:type_name -> protocol.PlayerJoinMessage
-	24, // 42: protocol.GameMessage.player_leave:type_name -> protocol.PlayerLeaveMessage
-	25, // 43: protocol.GameMessage.player_respawn:type_name -> protocol.PlayerRespawnMessage
-	26, // 44: protocol.GameMessage.error:type_name -> protocol.ErrorMessage
-	8,  // 45: protocol.Shop.InventoryEntry.value:type_name -> protocol.ShopItem
-	8,  // 46: protocol.ShopUpdate.InventoryEntry.value:type_name -> protocol.ShopItem
-	3,  // 47: protocol.GameStateDeltaMessage.AddedPlayersEntry.value:type_name -> protocol.Player
-	17, // 48: protocol.GameStateDeltaMessage.UpdatedPlayersEntry.value:type_name -> protocol.PlayerUpdate
-	4,  // 49: protocol.GameStateDeltaMessage.AddedBulletsEntry.value:type_name -> protocol.Bullet
-	11, // 50: protocol.GameStateDeltaMessage.UpdatedBulletsEntry.value:type_name -> protocol.PositionUpdate
-	4,  // 51: protocol.GameStateDeltaMessage.RemovedBulletsEntry.value:type_name -> protocol.Bullet
-	5,  // 52: protocol.GameStateDeltaMessage.AddedWallsEntry.value:type_name -> protocol.Wall
-	6,  // 53: protocol.GameStateDeltaMessage.AddedEnemiesEntry.value:type_name -> protocol.Enemy
-	19, // 54: protocol.GameStateDeltaMessage.UpdatedEnemiesEntry.value:type_name -> protocol.EnemyUpdate
-	7,  // 55: protocol.GameStateDeltaMessage.AddedBonusesEntry.value:type_name -> protocol.Bonus
-	20, // 56: protocol.GameStateDeltaMessage.UpdatedBonusesEntry.value:type_name -> protocol.BonusUpdate
-	9,  // 57: protocol.GameStateDeltaMessage.AddedShopsEntry.value:type_name -> protocol.Shop
-	21, // 58: protocol.GameStateDeltaMessage.UpdatedShopsEntry.value:type_name -> protocol.ShopUpdate
-	1,  // 59: protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry.value:type_name -> protocol.Vector2
-	60, // [60:60] is the sub-list for method output_type
-	60, // [60:60] is the sub-list for method input_type
-	60, // [60:60] is the sub-list for extension type_name
-	60, // [60:60] is the sub-list for extension extendee
-	0,  // [0:60] is the sub-list for field type_name
+	2,  // 0: protocol.Player.position:type_name -> protocol.Vector2
+	2,  // 1: protocol.Player.velocity:type_name -> protocol.Vector2
+	34, // 2: protocol.Player.bullets_left_by_weapon_type:type_name -> protocol.Player.BulletsLeftByWeaponTypeEntry
+	3,  // 3: protocol.Player.inventory:type_name -> protocol.InventoryItem
+	2,  // 4: protocol.Bullet.position:type_name -> protocol.Vector2
+	2,  // 5: protocol.Bullet.velocity:type_name -> protocol.Vector2
+	2,  // 6: protocol.Bullet.origin:type_name -> protocol.Vector2
+	2,  // 7: protocol.Wall.position:type_name -> protocol.Vector2
+	2,  // 8: protocol.Enemy.position:type_name -> protocol.Vector2
+	2,  // 9: protocol.Bonus.position:type_name -> protocol.Vector2
+	2,  // 10: protocol.Shop.position:type_name -> protocol.Vector2
+	35, // 11: protocol.Shop.inventory:type_name -> protocol.Shop.InventoryEntry
+	36, // 12: protocol.InputMessage.item_key:type_name -> protocol.InputMessage.ItemKeyEntry
+	37, // 13: protocol.InputMessage.purchase_item_key:type_name -> protocol.InputMessage.PurchaseItemKeyEntry
+	3,  // 14: protocol.InventoryUpdate.inventory:type_name -> protocol.InventoryItem
+	38, // 15: protocol.PlayerBulletsUpdate.bullets_left_by_weapon_type:type_name -> protocol.PlayerBulletsUpdate.BulletsLeftByWeaponTypeEntry
+	12, // 16: protocol.PlayerUpdate.position:type_name -> protocol.PositionUpdate
+	13, // 17: protocol.PlayerUpdate.timers:type_name -> protocol.TimersUpdate
+	14, // 18: protocol.PlayerUpdate.lives:type_name -> protocol.LivesUpdate
+	15, // 19: protocol.PlayerUpdate.inventory:type_name -> protocol.InventoryUpdate
+	16, // 20: protocol.PlayerUpdate.score:type_name -> protocol.ScoreUpdate
+	18, // 21: protocol.PlayerUpdate.player_bullets:type_name -> protocol.PlayerBulletsUpdate
+	17, // 22: protocol.PlayerUpdate.stamina:type_name -> protocol.StaminaUpdate
+	12, // 23: protocol.EnemyUpdate.position:type_name -> protocol.PositionUpdate
+	14, // 24: protocol.EnemyUpdate.lives:type_name -> protocol.LivesUpdate
+	39, // 25: protocol.ShopUpdate.inventory:type_name -> protocol.ShopUpdate.InventoryEntry
+	1,  // 26: protocol.ShopEvent.type:type_name -> protocol.ShopEventType
+	40, // 27: protocol.GameStateDeltaMessage.added_players:type_name -> protocol.GameStateDeltaMessage.AddedPlayersEntry
+	41, // 28: protocol.GameStateDeltaMessage.updated_players:type_name -> protocol.GameStateDeltaMessage.UpdatedPlayersEntry
+	42, // 29: protocol.GameStateDeltaMessage.added_bullets:type_name -> protocol.GameStateDeltaMessage.AddedBulletsEntry
+	43, // 30: protocol.GameStateDeltaMessage.updated_bullets:type_name -> protocol.GameStateDeltaMessage.UpdatedBulletsEntry
+	44, // 31: protocol.GameStateDeltaMessage.removed_bullets:type_name -> protocol.GameStateDeltaMessage.RemovedBulletsEntry
+	45, // 32: protocol.GameStateDeltaMessage.added_walls:type_name -> protocol.GameStateDeltaMessage.AddedWallsEntry
+	46, // 33: protocol.GameStateDeltaMessage.added_enemies:type_name -> protocol.GameStateDeltaMessage.AddedEnemiesEntry
+	47, // 34: protocol.GameStateDeltaMessage.updated_enemies:type_name -> protocol.GameStateDeltaMessage.UpdatedEnemiesEntry
+	48, // 35: protocol.GameStateDeltaMessage.added_bonuses:type_name -> protocol.GameStateDeltaMessage.AddedBonusesEntry
+	49, // 36: protocol.GameStateDeltaMessage.updated_bonuses:type_name -> protocol.GameStateDeltaMessage.UpdatedBonusesEntry
+	50, // 37: protocol.GameStateDeltaMessage.added_shops:type_name -> protocol.GameStateDeltaMessage.AddedShopsEntry
+	51, // 38: protocol.GameStateDeltaMessage.updated_shops:type_name -> protocol.GameStateDeltaMessage.UpdatedShopsEntry
+	52, // 39: protocol.GameStateDeltaMessage.updated_other_player_positions:type_name -> protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry
+	24, // 40: protocol.GameStateDeltaMessage.shop_events:type_name -> protocol.ShopEvent
+	31, // 41: protocol.GameStateDeltaMessage.debug_counts:type_name -> protocol.DebugCounts
+	4,  // 42: protocol.GameStateDeltaMessage.joined_players:type_name -> protocol.Player
+	32, // 43: protocol.GameStateDeltaMessage.kill_cam:type_name -> protocol.KillCam
+	4,  // 44: protocol.PlayerJoinMessage.player:type_name -> protocol.Player
+	0,  // 45: protocol.GameMessage.type:type_name -> protocol.MessageType
+	11, // 46: protocol.GameMessage.input:type_name -> protocol.InputMessage
+	25, // 47: protocol.GameMessage.game_state_delta:type_name -> protocol.GameStateDeltaMessage
+	26, // 48: protocol.GameMessage.player_join:type_name -> protocol.PlayerJoinMessage
+	27, // 49: protocol.GameMessage.player_leave:type_name -> protocol.PlayerLeaveMessage
+	28, // 50: protocol.GameMessage.player_respawn:type_name -> protocol.PlayerRespawnMessage
+	29, // 51: protocol.GameMessage.error:type_name -> protocol.ErrorMessage
+	33, // 52: protocol.KillCam.frames:type_name -> protocol.KillCamFrame
+	12, // 53: protocol.KillCamFrame.player:type_name -> protocol.PositionUpdate
+	12, // 54: protocol.KillCamFrame.killer:type_name -> protocol.PositionUpdate
+	5,  // 55: protocol.KillCamFrame.bullets:type_name -> protocol.Bullet
+	9,  // 56: protocol.Shop.InventoryEntry.value:type_name -> protocol.ShopItem
+	9,  // 57: protocol.ShopUpdate.InventoryEntry.value:type_name -> protocol.ShopItem
+	4,  // 58: protocol.GameStateDeltaMessage.AddedPlayersEntry.value:type_name -> protocol.Player
+	19, // 59: protocol.GameStateDeltaMessage.UpdatedPlayersEntry.value:type_name -> protocol.PlayerUpdate
+	5,  // 60: protocol.GameStateDeltaMessage.AddedBulletsEntry.value:type_name -> protocol.Bullet
+	12, // 61: protocol.GameStateDeltaMessage.UpdatedBulletsEntry.value:type_name -> protocol.PositionUpdate
+	5,  // 62: protocol.GameStateDeltaMessage.RemovedBulletsEntry.value:type_name -> protocol.Bullet
+	6,  // 63: protocol.GameStateDeltaMessage.AddedWallsEntry.value:type_name -> protocol.Wall
+	7,  // 64: protocol.GameStateDeltaMessage.AddedEnemiesEntry.value:type_name -> protocol.Enemy
+	21, // 65: protocol.GameStateDeltaMessage.UpdatedEnemiesEntry.value:type_name -> protocol.EnemyUpdate
+	8,  // 66: protocol.GameStateDeltaMessage.AddedBonusesEntry.value:type_name -> protocol.Bonus
+	22, // 67: protocol.GameStateDeltaMessage.UpdatedBonusesEntry.value:type_name -> protocol.BonusUpdate
+	10, // 68: protocol.GameStateDeltaMessage.AddedShopsEntry.value:type_name -> protocol.Shop
+	23, // 69: protocol.GameStateDeltaMessage.UpdatedShopsEntry.value:type_name -> protocol.ShopUpdate
+	2,  // 70: protocol.GameStateDeltaMessage.UpdatedOtherPlayerPositionsEntry.value:type_name -> protocol.Vector2
+	71, // [71:71] is the sub-list for method output_type
+	71, // [71:71] is the sub-list for method input_type
+	71, // [71:71] is the sub-list for extension type_name
+	71, // [71:71] is the sub-list for extension extendee
+	0,  // [0:71] is the sub-list for field type_name
 }
 
 func init() { file_messages_proto_init() }
@@ -2428,7 +3092,7 @@ func file_messages_proto_init() {
 	if File_messages_proto != nil {
 		return
 	}
-	file_messages_proto_msgTypes[26].OneofWrappers = []any{
+	file_messages_proto_msgTypes[28].OneofWrappers = []any{
 		(*GameMessage_Input)(nil),
 		(*GameMessage_GameStateDelta)(nil),
 		(*GameMessage_PlayerJoin)(nil),
@@ -2441,8 +3105,8 @@ func file_messages_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_messages_proto_rawDesc), len(file_messages_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   46,
+			NumEnums:      2,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
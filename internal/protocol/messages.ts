@@ -104,6 +104,30 @@ export interface Player {
      * @generated from protobuf field: string selected_gun_type = 15
      */
     selectedGunType: string;
+    /**
+     * @generated from protobuf field: double double_damage_timer = 16
+     */
+    doubleDamageTimer: number;
+    /**
+     * @generated from protobuf field: double rapid_fire_timer = 17
+     */
+    rapidFireTimer: number;
+    /**
+     * @generated from protobuf field: double speed_boost_timer = 18
+     */
+    speedBoostTimer: number;
+    /**
+     * @generated from protobuf field: double stamina = 19
+     */
+    stamina: number;
+    /**
+     * @generated from protobuf field: double blind_timer = 20
+     */
+    blindTimer: number;
+    /**
+     * @generated from protobuf field: double reload_timer = 21
+     */
+    reloadTimer: number;
 }
 /**
  * @generated from protobuf message protocol.Bullet
@@ -149,6 +173,10 @@ export interface Bullet {
      * @generated from protobuf field: string weapon_type = 8
      */
     weaponType: string;
+    /**
+     * @generated from protobuf field: protocol.Vector2 origin = 12
+     */
+    origin?: Vector2;
 }
 /**
  * @generated from protobuf message protocol.Wall
@@ -174,6 +202,10 @@ export interface Wall {
      * @generated from protobuf field: string orientation = 5
      */
     orientation: string;
+    /**
+     * @generated from protobuf field: bool is_door = 6
+     */
+    isDoor: boolean;
 }
 /**
  * @generated from protobuf message protocol.Enemy
@@ -207,6 +239,28 @@ export interface Enemy {
      * @generated from protobuf field: string type = 7
      */
     type: string;
+    /**
+     * @generated from protobuf field: float max_lives = 8
+     */
+    maxLives: number;
+    /**
+     * Only rockets and the railgun damage armored enemies
+     *
+     * @generated from protobuf field: bool armored = 9
+     */
+    armored: boolean;
+    /**
+     * grunt, brute or scout, empty for enemies spawned without archetypes
+     *
+     * @generated from protobuf field: string archetype = 10
+     */
+    archetype: string;
+    /**
+     * Seconds until a dead enemy is removed, for fading it out
+     *
+     * @generated from protobuf field: float death_trace_time = 11
+     */
+    deathTraceTime: number;
 }
 /**
  * @generated from protobuf message protocol.Bonus
@@ -309,6 +363,20 @@ export interface InputMessage {
     purchaseItemKey: {
         [key: number]: boolean;
     };
+    /**
+     * Client-assigned, monotonically increasing input sequence number
+     *
+     * @generated from protobuf field: uint32 sequence = 8
+     */
+    sequence: number;
+    /**
+     * @generated from protobuf field: bool sprint = 9
+     */
+    sprint: boolean;
+    /**
+     * @generated from protobuf field: bool reload = 10
+     */
+    reload: boolean;
 }
 /**
  * @generated from protobuf message protocol.PositionUpdate
@@ -326,6 +394,12 @@ export interface PositionUpdate {
      * @generated from protobuf field: double rotation = 3
      */
     rotation: number;
+    /**
+     * Last input sequence applied by the server (own player only)
+     *
+     * @generated from protobuf field: uint32 last_processed_input = 4
+     */
+    lastProcessedInput: number;
 }
 /**
  * @generated from protobuf message protocol.TimersUpdate
@@ -339,6 +413,26 @@ export interface TimersUpdate {
      * @generated from protobuf field: double night_vision_timer = 2
      */
     nightVisionTimer: number;
+    /**
+     * @generated from protobuf field: double double_damage_timer = 3
+     */
+    doubleDamageTimer: number;
+    /**
+     * @generated from protobuf field: double rapid_fire_timer = 4
+     */
+    rapidFireTimer: number;
+    /**
+     * @generated from protobuf field: double speed_boost_timer = 5
+     */
+    speedBoostTimer: number;
+    /**
+     * @generated from protobuf field: double blind_timer = 6
+     */
+    blindTimer: number;
+    /**
+     * @generated from protobuf field: double reload_timer = 7
+     */
+    reloadTimer: number;
 }
 /**
  * @generated from protobuf message protocol.LivesUpdate
@@ -383,6 +477,15 @@ export interface ScoreUpdate {
      */
     kills: number;
 }
+/**
+ * @generated from protobuf message protocol.StaminaUpdate
+ */
+export interface StaminaUpdate {
+    /**
+     * @generated from protobuf field: double stamina = 1
+     */
+    stamina: number;
+}
 /**
  * @generated from protobuf message protocol.PlayerBulletsUpdate
  */
@@ -422,6 +525,10 @@ export interface PlayerUpdate {
      * @generated from protobuf field: protocol.PlayerBulletsUpdate player_bullets = 6
      */
     playerBullets?: PlayerBulletsUpdate;
+    /**
+     * @generated from protobuf field: protocol.StaminaUpdate stamina = 7
+     */
+    stamina?: StaminaUpdate;
 }
 /**
  * @generated from protobuf message protocol.DeletionUpdate
@@ -448,6 +555,12 @@ export interface EnemyUpdate {
      * @generated from protobuf field: protocol.LivesUpdate lives = 2
      */
     lives?: LivesUpdate;
+    /**
+     * Sent with the lives update of an enemy that died
+     *
+     * @generated from protobuf field: float death_trace_time = 3
+     */
+    deathTraceTime: number;
 }
 /**
  * @generated from protobuf message protocol.BonusUpdate
@@ -469,6 +582,21 @@ export interface ShopUpdate {
         [key: number]: ShopItem;
     };
 }
+/**
+ * Sent when the player walks into or out of a shop's interaction range
+ *
+ * @generated from protobuf message protocol.ShopEvent
+ */
+export interface ShopEvent {
+    /**
+     * @generated from protobuf field: protocol.ShopEventType type = 1
+     */
+    type: ShopEventType;
+    /**
+     * @generated from protobuf field: string shop_id = 2
+     */
+    shopId: string;
+}
 /**
  * @generated from protobuf message protocol.GameStateDeltaMessage
  */
@@ -587,6 +715,44 @@ export interface GameStateDeltaMessage {
      * @generated from protobuf field: int64 timestamp = 22
      */
     timestamp: bigint;
+    /**
+     * @generated from protobuf field: repeated protocol.ShopEvent shop_events = 23
+     */
+    shopEvents: ShopEvent[];
+    /**
+     * @generated from protobuf field: string zone = 24
+     */
+    zone: string;
+    /**
+     * Only sent to clients that connected with debug enabled
+     *
+     * @generated from protobuf field: protocol.DebugCounts debug_counts = 25
+     */
+    debugCounts?: DebugCounts;
+    /**
+     * Players who joined since the last delta, when joins aren't sent as PLAYER_JOIN
+     *
+     * @generated from protobuf field: repeated protocol.Player joined_players = 26
+     */
+    joinedPlayers: Player[];
+    /**
+     * Players who left since the last delta, when leaves aren't sent as PLAYER_LEAVE
+     *
+     * @generated from protobuf field: repeated string left_players = 27
+     */
+    leftPlayers: string[];
+    /**
+     * The last moments before the player died, sent once in the delta after their death
+     *
+     * @generated from protobuf field: protocol.KillCam kill_cam = 28
+     */
+    killCam?: KillCam;
+    /**
+     * Weapon that jammed when the player pulled the trigger, sent once in the next delta
+     *
+     * @generated from protobuf field: string jammed_weapon = 29
+     */
+    jammedWeapon: string;
 }
 /**
  * @generated from protobuf message protocol.PlayerJoinMessage
@@ -673,6 +839,79 @@ export interface GameMessage {
         oneofKind: undefined;
     };
 }
+/**
+ * Entities the server tracks in the chunks around a player, for debug HUDs
+ *
+ * @generated from protobuf message protocol.DebugCounts
+ */
+export interface DebugCounts {
+    /**
+     * @generated from protobuf field: uint32 players = 1
+     */
+    players: number;
+    /**
+     * @generated from protobuf field: uint32 enemies = 2
+     */
+    enemies: number;
+    /**
+     * @generated from protobuf field: uint32 bullets = 3
+     */
+    bullets: number;
+    /**
+     * @generated from protobuf field: uint32 walls = 4
+     */
+    walls: number;
+}
+/**
+ * The dying player's recent view, for the client to replay before the death screen
+ *
+ * @generated from protobuf message protocol.KillCam
+ */
+export interface KillCam {
+    /**
+     * Player or enemy that landed the killing blow, empty when unknown
+     *
+     * @generated from protobuf field: string killer_id = 1
+     */
+    killerId: string;
+    /**
+     * Oldest first, the last one is the moment of death
+     *
+     * @generated from protobuf field: repeated protocol.KillCamFrame frames = 2
+     */
+    frames: KillCamFrame[];
+}
+/**
+ * Where the dying player and their killer were at one tick
+ *
+ * @generated from protobuf message protocol.KillCamFrame
+ */
+export interface KillCamFrame {
+    /**
+     * @generated from protobuf field: int64 timestamp = 1
+     */
+    timestamp: bigint;
+    /**
+     * @generated from protobuf field: protocol.PositionUpdate player = 2
+     */
+    player?: PositionUpdate;
+    /**
+     * @generated from protobuf field: float lives = 3
+     */
+    lives: number;
+    /**
+     * Missing while the killer wasn't near the player
+     *
+     * @generated from protobuf field: protocol.PositionUpdate killer = 4
+     */
+    killer?: PositionUpdate;
+    /**
+     * The killer's bullets near the player
+     *
+     * @generated from protobuf field: repeated protocol.Bullet bullets = 5
+     */
+    bullets: Bullet[];
+}
 /**
  * Message types
  *
@@ -712,6 +951,23 @@ export enum MessageType {
      */
     ERROR = 10
 }
+/**
+ * @generated from protobuf enum protocol.ShopEventType
+ */
+export enum ShopEventType {
+    /**
+     * @generated from protobuf enum value: SHOP_EVENT_UNKNOWN = 0;
+     */
+    SHOP_EVENT_UNKNOWN = 0,
+    /**
+     * @generated from protobuf enum value: SHOP_ENTER = 1;
+     */
+    SHOP_ENTER = 1,
+    /**
+     * @generated from protobuf enum value: SHOP_LEAVE = 2;
+     */
+    SHOP_LEAVE = 2
+}
 // @generated message type with reflection information, may provide speed optimized methods
 class Vector2$Type extends MessageType$<Vector2> {
     constructor() {
@@ -840,7 +1096,13 @@ class Player$Type extends MessageType$<Player> {
             { no: 11, name: "night_vision_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
             { no: 12, name: "is_alive", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
             { no: 14, name: "inventory", kind: "message", repeat: 2 /*RepeatType.UNPACKED*/, T: () => InventoryItem },
-            { no: 15, name: "selected_gun_type", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
+            { no: 15, name: "selected_gun_type", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 16, name: "double_damage_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 17, name: "rapid_fire_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 18, name: "speed_boost_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 19, name: "stamina", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 20, name: "blind_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 21, name: "reload_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ }
         ]);
     }
     create(value?: PartialMessage<Player>): Player {
@@ -858,6 +1120,12 @@ class Player$Type extends MessageType$<Player> {
         message.isAlive = false;
         message.inventory = [];
         message.selectedGunType = "";
+        message.doubleDamageTimer = 0;
+        message.rapidFireTimer = 0;
+        message.speedBoostTimer = 0;
+        message.stamina = 0;
+        message.blindTimer = 0;
+        message.reloadTimer = 0;
         if (value !== undefined)
             reflectionMergePartial<Player>(this, message, value);
         return message;
@@ -912,6 +1180,24 @@ class Player$Type extends MessageType$<Player> {
                 case /* string selected_gun_type */ 15:
                     message.selectedGunType = reader.string();
                     break;
+                case /* double double_damage_timer */ 16:
+                    message.doubleDamageTimer = reader.double();
+                    break;
+                case /* double rapid_fire_timer */ 17:
+                    message.rapidFireTimer = reader.double();
+                    break;
+                case /* double speed_boost_timer */ 18:
+                    message.speedBoostTimer = reader.double();
+                    break;
+                case /* double stamina */ 19:
+                    message.stamina = reader.double();
+                    break;
+                case /* double blind_timer */ 20:
+                    message.blindTimer = reader.double();
+                    break;
+                case /* double reload_timer */ 21:
+                    message.reloadTimer = reader.double();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -985,6 +1271,24 @@ class Player$Type extends MessageType$<Player> {
         /* string selected_gun_type = 15; */
         if (message.selectedGunType !== "")
             writer.tag(15, WireType.LengthDelimited).string(message.selectedGunType);
+        /* double double_damage_timer = 16; */
+        if (message.doubleDamageTimer !== 0)
+            writer.tag(16, WireType.Bit64).double(message.doubleDamageTimer);
+        /* double rapid_fire_timer = 17; */
+        if (message.rapidFireTimer !== 0)
+            writer.tag(17, WireType.Bit64).double(message.rapidFireTimer);
+        /* double speed_boost_timer = 18; */
+        if (message.speedBoostTimer !== 0)
+            writer.tag(18, WireType.Bit64).double(message.speedBoostTimer);
+        /* double stamina = 19; */
+        if (message.stamina !== 0)
+            writer.tag(19, WireType.Bit64).double(message.stamina);
+        /* double blind_timer = 20; */
+        if (message.blindTimer !== 0)
+            writer.tag(20, WireType.Bit64).double(message.blindTimer);
+        /* double reload_timer = 21; */
+        if (message.reloadTimer !== 0)
+            writer.tag(21, WireType.Bit64).double(message.reloadTimer);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1008,7 +1312,8 @@ class Bullet$Type extends MessageType$<Bullet> {
             { no: 11, name: "enemy_type", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
             { no: 7, name: "is_active", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
             { no: 10, name: "deleted_at", kind: "scalar", T: 3 /*ScalarType.INT64*/, L: 0 /*LongType.BIGINT*/ },
-            { no: 8, name: "weapon_type", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
+            { no: 8, name: "weapon_type", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 12, name: "origin", kind: "message", T: () => Vector2 }
         ]);
     }
     create(value?: PartialMessage<Bullet>): Bullet {
@@ -1060,6 +1365,9 @@ class Bullet$Type extends MessageType$<Bullet> {
                 case /* string weapon_type */ 8:
                     message.weaponType = reader.string();
                     break;
+                case /* protocol.Vector2 origin */ 12:
+                    message.origin = Vector2.internalBinaryRead(reader, reader.uint32(), options, message.origin);
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1102,6 +1410,9 @@ class Bullet$Type extends MessageType$<Bullet> {
         /* string enemy_type = 11; */
         if (message.enemyType !== "")
             writer.tag(11, WireType.LengthDelimited).string(message.enemyType);
+        /* protocol.Vector2 origin = 12; */
+        if (message.origin)
+            Vector2.internalBinaryWrite(message.origin, writer.tag(12, WireType.LengthDelimited).fork(), options).join();
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1120,7 +1431,8 @@ class Wall$Type extends MessageType$<Wall> {
             { no: 2, name: "position", kind: "message", T: () => Vector2 },
             { no: 3, name: "width", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
             { no: 4, name: "height", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
-            { no: 5, name: "orientation", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
+            { no: 5, name: "orientation", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 6, name: "is_door", kind: "scalar", T: 8 /*ScalarType.BOOL*/ }
         ]);
     }
     create(value?: PartialMessage<Wall>): Wall {
@@ -1129,6 +1441,7 @@ class Wall$Type extends MessageType$<Wall> {
         message.width = 0;
         message.height = 0;
         message.orientation = "";
+        message.isDoor = false;
         if (value !== undefined)
             reflectionMergePartial<Wall>(this, message, value);
         return message;
@@ -1153,6 +1466,9 @@ class Wall$Type extends MessageType$<Wall> {
                 case /* string orientation */ 5:
                     message.orientation = reader.string();
                     break;
+                case /* bool is_door */ 6:
+                    message.isDoor = reader.bool();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1180,6 +1496,9 @@ class Wall$Type extends MessageType$<Wall> {
         /* string orientation = 5; */
         if (message.orientation !== "")
             writer.tag(5, WireType.LengthDelimited).string(message.orientation);
+        /* bool is_door = 6; */
+        if (message.isDoor !== false)
+            writer.tag(6, WireType.Varint).bool(message.isDoor);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1200,7 +1519,11 @@ class Enemy$Type extends MessageType$<Enemy> {
             { no: 4, name: "lives", kind: "scalar", T: 2 /*ScalarType.FLOAT*/ },
             { no: 5, name: "wall_id", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
             { no: 6, name: "is_alive", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
-            { no: 7, name: "type", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
+            { no: 7, name: "type", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 8, name: "max_lives", kind: "scalar", T: 2 /*ScalarType.FLOAT*/ },
+            { no: 9, name: "armored", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
+            { no: 10, name: "archetype", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 11, name: "death_trace_time", kind: "scalar", T: 2 /*ScalarType.FLOAT*/ }
         ]);
     }
     create(value?: PartialMessage<Enemy>): Enemy {
@@ -1211,6 +1534,10 @@ class Enemy$Type extends MessageType$<Enemy> {
         message.wallId = "";
         message.isAlive = false;
         message.type = "";
+        message.maxLives = 0;
+        message.armored = false;
+        message.archetype = "";
+        message.deathTraceTime = 0;
         if (value !== undefined)
             reflectionMergePartial<Enemy>(this, message, value);
         return message;
@@ -1241,6 +1568,18 @@ class Enemy$Type extends MessageType$<Enemy> {
                 case /* string type */ 7:
                     message.type = reader.string();
                     break;
+                case /* float max_lives */ 8:
+                    message.maxLives = reader.float();
+                    break;
+                case /* bool armored */ 9:
+                    message.armored = reader.bool();
+                    break;
+                case /* string archetype */ 10:
+                    message.archetype = reader.string();
+                    break;
+                case /* float death_trace_time */ 11:
+                    message.deathTraceTime = reader.float();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1274,6 +1613,18 @@ class Enemy$Type extends MessageType$<Enemy> {
         /* string type = 7; */
         if (message.type !== "")
             writer.tag(7, WireType.LengthDelimited).string(message.type);
+        /* float max_lives = 8; */
+        if (message.maxLives !== 0)
+            writer.tag(8, WireType.Bit32).float(message.maxLives);
+        /* bool armored = 9; */
+        if (message.armored !== false)
+            writer.tag(9, WireType.Varint).bool(message.armored);
+        /* string archetype = 10; */
+        if (message.archetype !== "")
+            writer.tag(10, WireType.LengthDelimited).string(message.archetype);
+        /* float death_trace_time = 11; */
+        if (message.deathTraceTime !== 0)
+            writer.tag(11, WireType.Bit32).float(message.deathTraceTime);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1525,7 +1876,10 @@ class InputMessage$Type extends MessageType$<InputMessage> {
             { no: 4, name: "right", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
             { no: 5, name: "shoot", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
             { no: 6, name: "item_key", kind: "map", K: 5 /*ScalarType.INT32*/, V: { kind: "scalar", T: 8 /*ScalarType.BOOL*/ } },
-            { no: 7, name: "purchase_item_key", kind: "map", K: 5 /*ScalarType.INT32*/, V: { kind: "scalar", T: 8 /*ScalarType.BOOL*/ } }
+            { no: 7, name: "purchase_item_key", kind: "map", K: 5 /*ScalarType.INT32*/, V: { kind: "scalar", T: 8 /*ScalarType.BOOL*/ } },
+            { no: 8, name: "sequence", kind: "scalar", T: 13 /*ScalarType.UINT32*/ },
+            { no: 9, name: "sprint", kind: "scalar", T: 8 /*ScalarType.BOOL*/ },
+            { no: 10, name: "reload", kind: "scalar", T: 8 /*ScalarType.BOOL*/ }
         ]);
     }
     create(value?: PartialMessage<InputMessage>): InputMessage {
@@ -1537,6 +1891,9 @@ class InputMessage$Type extends MessageType$<InputMessage> {
         message.shoot = false;
         message.itemKey = {};
         message.purchaseItemKey = {};
+        message.sequence = 0;
+        message.sprint = false;
+        message.reload = false;
         if (value !== undefined)
             reflectionMergePartial<InputMessage>(this, message, value);
         return message;
@@ -1567,6 +1924,15 @@ class InputMessage$Type extends MessageType$<InputMessage> {
                 case /* map<int32, bool> purchase_item_key */ 7:
                     this.binaryReadMap7(message.purchaseItemKey, reader, options);
                     break;
+                case /* uint32 sequence */ 8:
+                    message.sequence = reader.uint32();
+                    break;
+                case /* bool sprint */ 9:
+                    message.sprint = reader.bool();
+                    break;
+                case /* bool reload */ 10:
+                    message.reload = reader.bool();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1632,6 +1998,15 @@ class InputMessage$Type extends MessageType$<InputMessage> {
         /* map<int32, bool> purchase_item_key = 7; */
         for (let k of globalThis.Object.keys(message.purchaseItemKey))
             writer.tag(7, WireType.LengthDelimited).fork().tag(1, WireType.Varint).int32(parseInt(k)).tag(2, WireType.Varint).bool(message.purchaseItemKey[k as any]).join();
+        /* uint32 sequence = 8; */
+        if (message.sequence !== 0)
+            writer.tag(8, WireType.Varint).uint32(message.sequence);
+        /* bool sprint = 9; */
+        if (message.sprint !== false)
+            writer.tag(9, WireType.Varint).bool(message.sprint);
+        /* bool reload = 10; */
+        if (message.reload !== false)
+            writer.tag(10, WireType.Varint).bool(message.reload);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1648,7 +2023,8 @@ class PositionUpdate$Type extends MessageType$<PositionUpdate> {
         super("protocol.PositionUpdate", [
             { no: 1, name: "x", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
             { no: 2, name: "y", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
-            { no: 3, name: "rotation", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ }
+            { no: 3, name: "rotation", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 4, name: "last_processed_input", kind: "scalar", T: 13 /*ScalarType.UINT32*/ }
         ]);
     }
     create(value?: PartialMessage<PositionUpdate>): PositionUpdate {
@@ -1656,6 +2032,7 @@ class PositionUpdate$Type extends MessageType$<PositionUpdate> {
         message.x = 0;
         message.y = 0;
         message.rotation = 0;
+        message.lastProcessedInput = 0;
         if (value !== undefined)
             reflectionMergePartial<PositionUpdate>(this, message, value);
         return message;
@@ -1674,6 +2051,9 @@ class PositionUpdate$Type extends MessageType$<PositionUpdate> {
                 case /* double rotation */ 3:
                     message.rotation = reader.double();
                     break;
+                case /* uint32 last_processed_input */ 4:
+                    message.lastProcessedInput = reader.uint32();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1695,6 +2075,9 @@ class PositionUpdate$Type extends MessageType$<PositionUpdate> {
         /* double rotation = 3; */
         if (message.rotation !== 0)
             writer.tag(3, WireType.Bit64).double(message.rotation);
+        /* uint32 last_processed_input = 4; */
+        if (message.lastProcessedInput !== 0)
+            writer.tag(4, WireType.Varint).uint32(message.lastProcessedInput);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1710,13 +2093,23 @@ class TimersUpdate$Type extends MessageType$<TimersUpdate> {
     constructor() {
         super("protocol.TimersUpdate", [
             { no: 1, name: "invulnerable_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
-            { no: 2, name: "night_vision_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ }
+            { no: 2, name: "night_vision_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 3, name: "double_damage_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 4, name: "rapid_fire_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 5, name: "speed_boost_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 6, name: "blind_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ },
+            { no: 7, name: "reload_timer", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ }
         ]);
     }
     create(value?: PartialMessage<TimersUpdate>): TimersUpdate {
         const message = globalThis.Object.create((this.messagePrototype!));
         message.invulnerableTimer = 0;
         message.nightVisionTimer = 0;
+        message.doubleDamageTimer = 0;
+        message.rapidFireTimer = 0;
+        message.speedBoostTimer = 0;
+        message.blindTimer = 0;
+        message.reloadTimer = 0;
         if (value !== undefined)
             reflectionMergePartial<TimersUpdate>(this, message, value);
         return message;
@@ -1732,6 +2125,21 @@ class TimersUpdate$Type extends MessageType$<TimersUpdate> {
                 case /* double night_vision_timer */ 2:
                     message.nightVisionTimer = reader.double();
                     break;
+                case /* double double_damage_timer */ 3:
+                    message.doubleDamageTimer = reader.double();
+                    break;
+                case /* double rapid_fire_timer */ 4:
+                    message.rapidFireTimer = reader.double();
+                    break;
+                case /* double speed_boost_timer */ 5:
+                    message.speedBoostTimer = reader.double();
+                    break;
+                case /* double blind_timer */ 6:
+                    message.blindTimer = reader.double();
+                    break;
+                case /* double reload_timer */ 7:
+                    message.reloadTimer = reader.double();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -1750,6 +2158,21 @@ class TimersUpdate$Type extends MessageType$<TimersUpdate> {
         /* double night_vision_timer = 2; */
         if (message.nightVisionTimer !== 0)
             writer.tag(2, WireType.Bit64).double(message.nightVisionTimer);
+        /* double double_damage_timer = 3; */
+        if (message.doubleDamageTimer !== 0)
+            writer.tag(3, WireType.Bit64).double(message.doubleDamageTimer);
+        /* double rapid_fire_timer = 4; */
+        if (message.rapidFireTimer !== 0)
+            writer.tag(4, WireType.Bit64).double(message.rapidFireTimer);
+        /* double speed_boost_timer = 5; */
+        if (message.speedBoostTimer !== 0)
+            writer.tag(5, WireType.Bit64).double(message.speedBoostTimer);
+        /* double blind_timer = 6; */
+        if (message.blindTimer !== 0)
+            writer.tag(6, WireType.Bit64).double(message.blindTimer);
+        /* double reload_timer = 7; */
+        if (message.reloadTimer !== 0)
+            writer.tag(7, WireType.Bit64).double(message.reloadTimer);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -1934,6 +2357,53 @@ class ScoreUpdate$Type extends MessageType$<ScoreUpdate> {
  */
 export const ScoreUpdate = new ScoreUpdate$Type();
 // @generated message type with reflection information, may provide speed optimized methods
+class StaminaUpdate$Type extends MessageType$<StaminaUpdate> {
+    constructor() {
+        super("protocol.StaminaUpdate", [
+            { no: 1, name: "stamina", kind: "scalar", T: 1 /*ScalarType.DOUBLE*/ }
+        ]);
+    }
+    create(value?: PartialMessage<StaminaUpdate>): StaminaUpdate {
+        const message = globalThis.Object.create((this.messagePrototype!));
+        message.stamina = 0;
+        if (value !== undefined)
+            reflectionMergePartial<StaminaUpdate>(this, message, value);
+        return message;
+    }
+    internalBinaryRead(reader: IBinaryReader, length: number, options: BinaryReadOptions, target?: StaminaUpdate): StaminaUpdate {
+        let message = target ?? this.create(), end = reader.pos + length;
+        while (reader.pos < end) {
+            let [fieldNo, wireType] = reader.tag();
+            switch (fieldNo) {
+                case /* double stamina */ 1:
+                    message.stamina = reader.double();
+                    break;
+                default:
+                    let u = options.readUnknownField;
+                    if (u === "throw")
+                        throw new globalThis.Error(`Unknown field ${fieldNo} (wire type ${wireType}) for ${this.typeName}`);
+                    let d = reader.skip(wireType);
+                    if (u !== false)
+                        (u === true ? UnknownFieldHandler.onRead : u)(this.typeName, message, fieldNo, wireType, d);
+            }
+        }
+        return message;
+    }
+    internalBinaryWrite(message: StaminaUpdate, writer: IBinaryWriter, options: BinaryWriteOptions): IBinaryWriter {
+        /* double stamina = 1; */
+        if (message.stamina !== 0)
+            writer.tag(1, WireType.Bit64).double(message.stamina);
+        let u = options.writeUnknownFields;
+        if (u !== false)
+            (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
+        return writer;
+    }
+}
+/**
+ * @generated MessageType for protobuf message protocol.StaminaUpdate
+ */
+export const StaminaUpdate = new StaminaUpdate$Type();
+// @generated message type with reflection information, may provide speed optimized methods
 class PlayerBulletsUpdate$Type extends MessageType$<PlayerBulletsUpdate> {
     constructor() {
         super("protocol.PlayerBulletsUpdate", [
@@ -2005,7 +2475,8 @@ class PlayerUpdate$Type extends MessageType$<PlayerUpdate> {
             { no: 3, name: "lives", kind: "message", T: () => LivesUpdate },
             { no: 4, name: "inventory", kind: "message", T: () => InventoryUpdate },
             { no: 5, name: "score", kind: "message", T: () => ScoreUpdate },
-            { no: 6, name: "player_bullets", kind: "message", T: () => PlayerBulletsUpdate }
+            { no: 6, name: "player_bullets", kind: "message", T: () => PlayerBulletsUpdate },
+            { no: 7, name: "stamina", kind: "message", T: () => StaminaUpdate }
         ]);
     }
     create(value?: PartialMessage<PlayerUpdate>): PlayerUpdate {
@@ -2037,6 +2508,9 @@ class PlayerUpdate$Type extends MessageType$<PlayerUpdate> {
                 case /* protocol.PlayerBulletsUpdate player_bullets */ 6:
                     message.playerBullets = PlayerBulletsUpdate.internalBinaryRead(reader, reader.uint32(), options, message.playerBullets);
                     break;
+                case /* protocol.StaminaUpdate stamina */ 7:
+                    message.stamina = StaminaUpdate.internalBinaryRead(reader, reader.uint32(), options, message.stamina);
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -2067,6 +2541,9 @@ class PlayerUpdate$Type extends MessageType$<PlayerUpdate> {
         /* protocol.PlayerBulletsUpdate player_bullets = 6; */
         if (message.playerBullets)
             PlayerBulletsUpdate.internalBinaryWrite(message.playerBullets, writer.tag(6, WireType.LengthDelimited).fork(), options).join();
+        /* protocol.StaminaUpdate stamina = 7; */
+        if (message.stamina)
+            StaminaUpdate.internalBinaryWrite(message.stamina, writer.tag(7, WireType.LengthDelimited).fork(), options).join();
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -2137,11 +2614,13 @@ class EnemyUpdate$Type extends MessageType$<EnemyUpdate> {
     constructor() {
         super("protocol.EnemyUpdate", [
             { no: 1, name: "position", kind: "message", T: () => PositionUpdate },
-            { no: 2, name: "lives", kind: "message", T: () => LivesUpdate }
+            { no: 2, name: "lives", kind: "message", T: () => LivesUpdate },
+            { no: 3, name: "death_trace_time", kind: "scalar", T: 2 /*ScalarType.FLOAT*/ }
         ]);
     }
     create(value?: PartialMessage<EnemyUpdate>): EnemyUpdate {
         const message = globalThis.Object.create((this.messagePrototype!));
+        message.deathTraceTime = 0;
         if (value !== undefined)
             reflectionMergePartial<EnemyUpdate>(this, message, value);
         return message;
@@ -2157,6 +2636,9 @@ class EnemyUpdate$Type extends MessageType$<EnemyUpdate> {
                 case /* protocol.LivesUpdate lives */ 2:
                     message.lives = LivesUpdate.internalBinaryRead(reader, reader.uint32(), options, message.lives);
                     break;
+                case /* float death_trace_time */ 3:
+                    message.deathTraceTime = reader.float();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -2175,6 +2657,9 @@ class EnemyUpdate$Type extends MessageType$<EnemyUpdate> {
         /* protocol.LivesUpdate lives = 2; */
         if (message.lives)
             LivesUpdate.internalBinaryWrite(message.lives, writer.tag(2, WireType.LengthDelimited).fork(), options).join();
+        /* float death_trace_time = 3; */
+        if (message.deathTraceTime !== 0)
+            writer.tag(3, WireType.Bit32).float(message.deathTraceTime);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -2300,6 +2785,61 @@ class ShopUpdate$Type extends MessageType$<ShopUpdate> {
  */
 export const ShopUpdate = new ShopUpdate$Type();
 // @generated message type with reflection information, may provide speed optimized methods
+class ShopEvent$Type extends MessageType$<ShopEvent> {
+    constructor() {
+        super("protocol.ShopEvent", [
+            { no: 1, name: "type", kind: "enum", T: () => ["protocol.ShopEventType", ShopEventType] },
+            { no: 2, name: "shop_id", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
+        ]);
+    }
+    create(value?: PartialMessage<ShopEvent>): ShopEvent {
+        const message = globalThis.Object.create((this.messagePrototype!));
+        message.type = 0;
+        message.shopId = "";
+        if (value !== undefined)
+            reflectionMergePartial<ShopEvent>(this, message, value);
+        return message;
+    }
+    internalBinaryRead(reader: IBinaryReader, length: number, options: BinaryReadOptions, target?: ShopEvent): ShopEvent {
+        let message = target ?? this.create(), end = reader.pos + length;
+        while (reader.pos < end) {
+            let [fieldNo, wireType] = reader.tag();
+            switch (fieldNo) {
+                case /* protocol.ShopEventType type */ 1:
+                    message.type = reader.int32();
+                    break;
+                case /* string shop_id */ 2:
+                    message.shopId = reader.string();
+                    break;
+                default:
+                    let u = options.readUnknownField;
+                    if (u === "throw")
+                        throw new globalThis.Error(`Unknown field ${fieldNo} (wire type ${wireType}) for ${this.typeName}`);
+                    let d = reader.skip(wireType);
+                    if (u !== false)
+                        (u === true ? UnknownFieldHandler.onRead : u)(this.typeName, message, fieldNo, wireType, d);
+            }
+        }
+        return message;
+    }
+    internalBinaryWrite(message: ShopEvent, writer: IBinaryWriter, options: BinaryWriteOptions): IBinaryWriter {
+        /* protocol.ShopEventType type = 1; */
+        if (message.type !== 0)
+            writer.tag(1, WireType.Varint).int32(message.type);
+        /* string shop_id = 2; */
+        if (message.shopId !== "")
+            writer.tag(2, WireType.LengthDelimited).string(message.shopId);
+        let u = options.writeUnknownFields;
+        if (u !== false)
+            (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
+        return writer;
+    }
+}
+/**
+ * @generated MessageType for protobuf message protocol.ShopEvent
+ */
+export const ShopEvent = new ShopEvent$Type();
+// @generated message type with reflection information, may provide speed optimized methods
 class GameStateDeltaMessage$Type extends MessageType$<GameStateDeltaMessage> {
     constructor() {
         super("protocol.GameStateDeltaMessage", [
@@ -2324,7 +2864,14 @@ class GameStateDeltaMessage$Type extends MessageType$<GameStateDeltaMessage> {
             { no: 19, name: "removed_players_shops", kind: "scalar", repeat: 2 /*RepeatType.UNPACKED*/, T: 9 /*ScalarType.STRING*/ },
             { no: 20, name: "updated_other_player_positions", kind: "map", K: 9 /*ScalarType.STRING*/, V: { kind: "message", T: () => Vector2 } },
             { no: 21, name: "removed_other_player_positions", kind: "scalar", repeat: 2 /*RepeatType.UNPACKED*/, T: 9 /*ScalarType.STRING*/ },
-            { no: 22, name: "timestamp", kind: "scalar", T: 3 /*ScalarType.INT64*/, L: 0 /*LongType.BIGINT*/ }
+            { no: 22, name: "timestamp", kind: "scalar", T: 3 /*ScalarType.INT64*/, L: 0 /*LongType.BIGINT*/ },
+            { no: 23, name: "shop_events", kind: "message", repeat: 2 /*RepeatType.UNPACKED*/, T: () => ShopEvent },
+            { no: 24, name: "zone", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 25, name: "debug_counts", kind: "message", T: () => DebugCounts },
+            { no: 26, name: "joined_players", kind: "message", repeat: 2 /*RepeatType.UNPACKED*/, T: () => Player },
+            { no: 27, name: "left_players", kind: "scalar", repeat: 2 /*RepeatType.UNPACKED*/, T: 9 /*ScalarType.STRING*/ },
+            { no: 28, name: "kill_cam", kind: "message", T: () => KillCam },
+            { no: 29, name: "jammed_weapon", kind: "scalar", T: 9 /*ScalarType.STRING*/ }
         ]);
     }
     create(value?: PartialMessage<GameStateDeltaMessage>): GameStateDeltaMessage {
@@ -2351,6 +2898,11 @@ class GameStateDeltaMessage$Type extends MessageType$<GameStateDeltaMessage> {
         message.updatedOtherPlayerPositions = {};
         message.removedOtherPlayerPositions = [];
         message.timestamp = 0n;
+        message.shopEvents = [];
+        message.zone = "";
+        message.joinedPlayers = [];
+        message.leftPlayers = [];
+        message.jammedWeapon = "";
         if (value !== undefined)
             reflectionMergePartial<GameStateDeltaMessage>(this, message, value);
         return message;
@@ -2426,6 +2978,27 @@ class GameStateDeltaMessage$Type extends MessageType$<GameStateDeltaMessage> {
                 case /* int64 timestamp */ 22:
                     message.timestamp = reader.int64().toBigInt();
                     break;
+                case /* repeated protocol.ShopEvent shop_events */ 23:
+                    message.shopEvents.push(ShopEvent.internalBinaryRead(reader, reader.uint32(), options));
+                    break;
+                case /* string zone */ 24:
+                    message.zone = reader.string();
+                    break;
+                case /* protocol.DebugCounts debug_counts */ 25:
+                    message.debugCounts = DebugCounts.internalBinaryRead(reader, reader.uint32(), options, message.debugCounts);
+                    break;
+                case /* repeated protocol.Player joined_players */ 26:
+                    message.joinedPlayers.push(Player.internalBinaryRead(reader, reader.uint32(), options));
+                    break;
+                case /* repeated string left_players */ 27:
+                    message.leftPlayers.push(reader.string());
+                    break;
+                case /* protocol.KillCam kill_cam */ 28:
+                    message.killCam = KillCam.internalBinaryRead(reader, reader.uint32(), options, message.killCam);
+                    break;
+                case /* string jammed_weapon */ 29:
+                    message.jammedWeapon = reader.string();
+                    break;
                 default:
                     let u = options.readUnknownField;
                     if (u === "throw")
@@ -2764,6 +3337,27 @@ class GameStateDeltaMessage$Type extends MessageType$<GameStateDeltaMessage> {
         /* int64 timestamp = 22; */
         if (message.timestamp !== 0n)
             writer.tag(22, WireType.Varint).int64(message.timestamp);
+        /* repeated protocol.ShopEvent shop_events = 23; */
+        for (let i = 0; i < message.shopEvents.length; i++)
+            ShopEvent.internalBinaryWrite(message.shopEvents[i], writer.tag(23, WireType.LengthDelimited).fork(), options).join();
+        /* string zone = 24; */
+        if (message.zone !== "")
+            writer.tag(24, WireType.LengthDelimited).string(message.zone);
+        /* protocol.DebugCounts debug_counts = 25; */
+        if (message.debugCounts)
+            DebugCounts.internalBinaryWrite(message.debugCounts, writer.tag(25, WireType.LengthDelimited).fork(), options).join();
+        /* repeated protocol.Player joined_players = 26; */
+        for (let i = 0; i < message.joinedPlayers.length; i++)
+            Player.internalBinaryWrite(message.joinedPlayers[i], writer.tag(26, WireType.LengthDelimited).fork(), options).join();
+        /* repeated string left_players = 27; */
+        for (let i = 0; i < message.leftPlayers.length; i++)
+            writer.tag(27, WireType.LengthDelimited).string(message.leftPlayers[i]);
+        /* protocol.KillCam kill_cam = 28; */
+        if (message.killCam)
+            KillCam.internalBinaryWrite(message.killCam, writer.tag(28, WireType.LengthDelimited).fork(), options).join();
+        /* string jammed_weapon = 29; */
+        if (message.jammedWeapon !== "")
+            writer.tag(29, WireType.LengthDelimited).string(message.jammedWeapon);
         let u = options.writeUnknownFields;
         if (u !== false)
             (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
@@ -3060,3 +3654,206 @@ class GameMessage$Type extends MessageType$<GameMessage> {
  * @generated MessageType for protobuf message protocol.GameMessage
  */
 export const GameMessage = new GameMessage$Type();
+// @generated message type with reflection information, may provide speed optimized methods
+class DebugCounts$Type extends MessageType$<DebugCounts> {
+    constructor() {
+        super("protocol.DebugCounts", [
+            { no: 1, name: "players", kind: "scalar", T: 13 /*ScalarType.UINT32*/ },
+            { no: 2, name: "enemies", kind: "scalar", T: 13 /*ScalarType.UINT32*/ },
+            { no: 3, name: "bullets", kind: "scalar", T: 13 /*ScalarType.UINT32*/ },
+            { no: 4, name: "walls", kind: "scalar", T: 13 /*ScalarType.UINT32*/ }
+        ]);
+    }
+    create(value?: PartialMessage<DebugCounts>): DebugCounts {
+        const message = globalThis.Object.create((this.messagePrototype!));
+        message.players = 0;
+        message.enemies = 0;
+        message.bullets = 0;
+        message.walls = 0;
+        if (value !== undefined)
+            reflectionMergePartial<DebugCounts>(this, message, value);
+        return message;
+    }
+    internalBinaryRead(reader: IBinaryReader, length: number, options: BinaryReadOptions, target?: DebugCounts): DebugCounts {
+        let message = target ?? this.create(), end = reader.pos + length;
+        while (reader.pos < end) {
+            let [fieldNo, wireType] = reader.tag();
+            switch (fieldNo) {
+                case /* uint32 players */ 1:
+                    message.players = reader.uint32();
+                    break;
+                case /* uint32 enemies */ 2:
+                    message.enemies = reader.uint32();
+                    break;
+                case /* uint32 bullets */ 3:
+                    message.bullets = reader.uint32();
+                    break;
+                case /* uint32 walls */ 4:
+                    message.walls = reader.uint32();
+                    break;
+                default:
+                    let u = options.readUnknownField;
+                    if (u === "throw")
+                        throw new globalThis.Error(`Unknown field ${fieldNo} (wire type ${wireType}) for ${this.typeName}`);
+                    let d = reader.skip(wireType);
+                    if (u !== false)
+                        (u === true ? UnknownFieldHandler.onRead : u)(this.typeName, message, fieldNo, wireType, d);
+            }
+        }
+        return message;
+    }
+    internalBinaryWrite(message: DebugCounts, writer: IBinaryWriter, options: BinaryWriteOptions): IBinaryWriter {
+        /* uint32 players = 1; */
+        if (message.players !== 0)
+            writer.tag(1, WireType.Varint).uint32(message.players);
+        /* uint32 enemies = 2; */
+        if (message.enemies !== 0)
+            writer.tag(2, WireType.Varint).uint32(message.enemies);
+        /* uint32 bullets = 3; */
+        if (message.bullets !== 0)
+            writer.tag(3, WireType.Varint).uint32(message.bullets);
+        /* uint32 walls = 4; */
+        if (message.walls !== 0)
+            writer.tag(4, WireType.Varint).uint32(message.walls);
+        let u = options.writeUnknownFields;
+        if (u !== false)
+            (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
+        return writer;
+    }
+}
+/**
+ * @generated MessageType for protobuf message protocol.DebugCounts
+ */
+export const DebugCounts = new DebugCounts$Type();
+// @generated message type with reflection information, may provide speed optimized methods
+class KillCam$Type extends MessageType$<KillCam> {
+    constructor() {
+        super("protocol.KillCam", [
+            { no: 1, name: "killer_id", kind: "scalar", T: 9 /*ScalarType.STRING*/ },
+            { no: 2, name: "frames", kind: "message", repeat: 2 /*RepeatType.UNPACKED*/, T: () => KillCamFrame }
+        ]);
+    }
+    create(value?: PartialMessage<KillCam>): KillCam {
+        const message = globalThis.Object.create((this.messagePrototype!));
+        message.killerId = "";
+        message.frames = [];
+        if (value !== undefined)
+            reflectionMergePartial<KillCam>(this, message, value);
+        return message;
+    }
+    internalBinaryRead(reader: IBinaryReader, length: number, options: BinaryReadOptions, target?: KillCam): KillCam {
+        let message = target ?? this.create(), end = reader.pos + length;
+        while (reader.pos < end) {
+            let [fieldNo, wireType] = reader.tag();
+            switch (fieldNo) {
+                case /* string killer_id */ 1:
+                    message.killerId = reader.string();
+                    break;
+                case /* repeated protocol.KillCamFrame frames */ 2:
+                    message.frames.push(KillCamFrame.internalBinaryRead(reader, reader.uint32(), options));
+                    break;
+                default:
+                    let u = options.readUnknownField;
+                    if (u === "throw")
+                        throw new globalThis.Error(`Unknown field ${fieldNo} (wire type ${wireType}) for ${this.typeName}`);
+                    let d = reader.skip(wireType);
+                    if (u !== false)
+                        (u === true ? UnknownFieldHandler.onRead : u)(this.typeName, message, fieldNo, wireType, d);
+            }
+        }
+        return message;
+    }
+    internalBinaryWrite(message: KillCam, writer: IBinaryWriter, options: BinaryWriteOptions): IBinaryWriter {
+        /* string killer_id = 1; */
+        if (message.killerId !== "")
+            writer.tag(1, WireType.LengthDelimited).string(message.killerId);
+        /* repeated protocol.KillCamFrame frames = 2; */
+        for (let i = 0; i < message.frames.length; i++)
+            KillCamFrame.internalBinaryWrite(message.frames[i], writer.tag(2, WireType.LengthDelimited).fork(), options).join();
+        let u = options.writeUnknownFields;
+        if (u !== false)
+            (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
+        return writer;
+    }
+}
+/**
+ * @generated MessageType for protobuf message protocol.KillCam
+ */
+export const KillCam = new KillCam$Type();
+// @generated message type with reflection information, may provide speed optimized methods
+class KillCamFrame$Type extends MessageType$<KillCamFrame> {
+    constructor() {
+        super("protocol.KillCamFrame", [
+            { no: 1, name: "timestamp", kind: "scalar", T: 3 /*ScalarType.INT64*/ },
+            { no: 2, name: "player", kind: "message", T: () => PositionUpdate },
+            { no: 3, name: "lives", kind: "scalar", T: 2 /*ScalarType.FLOAT*/ },
+            { no: 4, name: "killer", kind: "message", T: () => PositionUpdate },
+            { no: 5, name: "bullets", kind: "message", repeat: 2 /*RepeatType.UNPACKED*/, T: () => Bullet }
+        ]);
+    }
+    create(value?: PartialMessage<KillCamFrame>): KillCamFrame {
+        const message = globalThis.Object.create((this.messagePrototype!));
+        message.timestamp = 0n;
+        message.lives = 0;
+        message.bullets = [];
+        if (value !== undefined)
+            reflectionMergePartial<KillCamFrame>(this, message, value);
+        return message;
+    }
+    internalBinaryRead(reader: IBinaryReader, length: number, options: BinaryReadOptions, target?: KillCamFrame): KillCamFrame {
+        let message = target ?? this.create(), end = reader.pos + length;
+        while (reader.pos < end) {
+            let [fieldNo, wireType] = reader.tag();
+            switch (fieldNo) {
+                case /* int64 timestamp */ 1:
+                    message.timestamp = reader.int64();
+                    break;
+                case /* protocol.PositionUpdate player */ 2:
+                    message.player = PositionUpdate.internalBinaryRead(reader, reader.uint32(), options, message.player);
+                    break;
+                case /* float lives */ 3:
+                    message.lives = reader.float();
+                    break;
+                case /* protocol.PositionUpdate killer */ 4:
+                    message.killer = PositionUpdate.internalBinaryRead(reader, reader.uint32(), options, message.killer);
+                    break;
+                case /* repeated protocol.Bullet bullets */ 5:
+                    message.bullets.push(Bullet.internalBinaryRead(reader, reader.uint32(), options));
+                    break;
+                default:
+                    let u = options.readUnknownField;
+                    if (u === "throw")
+                        throw new globalThis.Error(`Unknown field ${fieldNo} (wire type ${wireType}) for ${this.typeName}`);
+                    let d = reader.skip(wireType);
+                    if (u !== false)
+                        (u === true ? UnknownFieldHandler.onRead : u)(this.typeName, message, fieldNo, wireType, d);
+            }
+        }
+        return message;
+    }
+    internalBinaryWrite(message: KillCamFrame, writer: IBinaryWriter, options: BinaryWriteOptions): IBinaryWriter {
+        /* int64 timestamp = 1; */
+        if (message.timestamp !== 0n)
+            writer.tag(1, WireType.Varint).int64(message.timestamp);
+        /* protocol.PositionUpdate player = 2; */
+        if (message.player)
+            PositionUpdate.internalBinaryWrite(message.player, writer.tag(2, WireType.LengthDelimited).fork(), options).join();
+        /* float lives = 3; */
+        if (message.lives !== 0)
+            writer.tag(3, WireType.Bit32).float(message.lives);
+        /* protocol.PositionUpdate killer = 4; */
+        if (message.killer)
+            PositionUpdate.internalBinaryWrite(message.killer, writer.tag(4, WireType.LengthDelimited).fork(), options).join();
+        /* repeated protocol.Bullet bullets = 5; */
+        for (let i = 0; i < message.bullets.length; i++)
+            Bullet.internalBinaryWrite(message.bullets[i], writer.tag(5, WireType.LengthDelimited).fork(), options).join();
+        let u = options.writeUnknownFields;
+        if (u !== false)
+            (u == true ? UnknownFieldHandler.onWrite : u)(this.typeName, message, writer);
+        return writer;
+    }
+}
+/**
+ * @generated MessageType for protobuf message protocol.KillCamFrame
+ */
+export const KillCamFrame = new KillCamFrame$Type();
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+// recordJoin counts a player joining the session in its activity. Must be called with session.mu held.
+func (s *Session) recordJoin(userID string, now time.Time) {
+	if s.activity.StartedAt.IsZero() {
+		s.activity.StartedAt = now
+	}
+	if !slices.Contains(s.activity.PlayerIDs, userID) {
+		s.activity.PlayerIDs = append(s.activity.PlayerIDs, userID)
+	}
+	s.activity.PeakPlayers = max(s.activity.PeakPlayers, s.PlayerCount)
+}
+
+// GetSessionActivity returns the activity of a session loaded on this server and how long it has been loaded.
+// Returns false when the session isn't loaded or sessions don't track their activity.
+func (gs *GameServer) GetSessionActivity(sessionID string) (db.SessionActivity, time.Duration, bool) {
+	if !gs.trackActivity {
+		return db.SessionActivity{}, 0, false
+	}
+
+	session, exists := gs.GetLiveSession(sessionID)
+	if !exists {
+		return db.SessionActivity{}, 0, false
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return copyActivity(session.activity), time.Since(session.loadedAt), true
+}
+
+// saveActivity stores the session's activity with its database record
+func (gs *GameServer) saveActivity(session *Session, dbSession *db.GameSession) {
+	if !gs.trackActivity {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	dbSession.Activity = copyActivity(session.activity)
+}
+
+// copyActivity returns activity that shares nothing with the original, safe to hand out of session.mu
+func copyActivity(activity db.SessionActivity) db.SessionActivity {
+	activity.PlayerIDs = slices.Clone(activity.PlayerIDs)
+	return activity
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+)
+
+func TestSessionActivityTracksPeakAndUniquePlayers(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	gs.trackActivity = true
+	gs.reconnectGracePeriod = 0
+	session := &Session{ID: "session", Engine: game.NewEngine("session"), loadedAt: time.Now().Add(-time.Minute)}
+	gs.sessions[session.ID] = session
+
+	startedAt := time.Now()
+	first, _ := connectTestClient(gs, session, "first")
+	session.recordJoin(first.UserID.Hex(), startedAt)
+	second, _ := connectTestClient(gs, session, "second")
+	session.recordJoin(second.UserID.Hex(), startedAt.Add(time.Second))
+
+	// The second player leaves and comes back
+	gs.unregisterClient(second)
+	session.PlayerCount++
+	session.recordJoin(second.UserID.Hex(), startedAt.Add(2*time.Second))
+
+	activity, uptime, loaded := gs.GetSessionActivity(session.ID)
+	if !loaded {
+		t.Fatal("expected the loaded session's activity")
+	}
+	if !activity.StartedAt.Equal(startedAt) {
+		t.Errorf("started at %v, want the first join at %v", activity.StartedAt, startedAt)
+	}
+	if activity.PeakPlayers != 2 {
+		t.Errorf("peak players = %d, want 2", activity.PeakPlayers)
+	}
+	if len(activity.PlayerIDs) != 2 {
+		t.Errorf("unique players = %d, want a rejoining player counted once", len(activity.PlayerIDs))
+	}
+	if uptime < time.Minute {
+		t.Errorf("uptime = %v, want the time since the session was loaded", uptime)
+	}
+
+	dbSession := &db.GameSession{}
+	gs.saveActivity(session, dbSession)
+	if dbSession.Activity.PeakPlayers != 2 || len(dbSession.Activity.PlayerIDs) != 2 {
+		t.Errorf("saved activity = %+v, want the session's activity", dbSession.Activity)
+	}
+}
+
+func TestSessionActivityDisabled(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session"), activity: db.SessionActivity{PeakPlayers: 3}}
+	gs.sessions[session.ID] = session
+
+	if _, _, loaded := gs.GetSessionActivity(session.ID); loaded {
+		t.Error("expected no activity when sessions don't track it")
+	}
+
+	dbSession := &db.GameSession{Activity: db.SessionActivity{PeakPlayers: 5}}
+	gs.saveActivity(session, dbSession)
+	if dbSession.Activity.PeakPlayers != 5 {
+		t.Errorf("expected the stored activity to be left alone, got %+v", dbSession.Activity)
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
+)
+
+// SessionStatsResponse reports the engine stats of a live session, times are in milliseconds
+type SessionStatsResponse struct {
+	SessionID string `json:"session_id"`
+
+	UpdateCount                int64               `json:"update_count"`
+	AvgUpdateTime              UpdateTimesResponse `json:"avg_update_ms"`
+	AvgUpdateTimeLastPeriod    UpdateTimesResponse `json:"avg_update_ms_last_period"`
+	DeltaCalcCount             int64               `json:"delta_calc_count"`
+	AvgDeltaCalcTime           float64             `json:"avg_delta_calc_ms"`
+	AvgUpdatePreviousTime      float64             `json:"avg_update_previous_ms"`
+	AvgDeltaCalcTimeLastPeriod float64             `json:"avg_delta_calc_ms_last_period"`
+
+	Players          int `json:"players"`
+	ConnectedPlayers int `json:"connected_players"`
+	Enemies          int `json:"enemies"`
+	CheckedEnemies   int `json:"checked_enemies"`
+	Bullets          int `json:"bullets"`
+	Bonuses          int `json:"bonuses"`
+
+	// When the last period started, left out before the first update
+	LastReportedAt *time.Time `json:"last_reported_at,omitempty"`
+}
+
+// UpdateTimesResponse splits the time an update took by what was updated, in milliseconds
+type UpdateTimesResponse struct {
+	Total   float64 `json:"total"`
+	Players float64 `json:"players"`
+	Enemies float64 `json:"enemies"`
+	Bullets float64 `json:"bullets"`
+	Bonuses float64 `json:"bonuses"`
+}
+
+// bearsDebugStatsToken reports whether the request is authorized with the configured debug stats token
+func (gs *GameServer) bearsDebugStatsToken(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(gs.debugStatsToken)) == 1
+}
+
+// HandleDebugSessions reports the engine stats of every session loaded on the server. The stats list
+// unlisted sessions too, so they are only served when a token is configured and the request bears it.
+func (gs *GameServer) HandleDebugSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !gs.debugStats || gs.debugStatsToken == "" {
+		utils.WriteJSONError(w, http.StatusNotFound, "Debug stats are disabled")
+		return
+	}
+
+	if !gs.bearsDebugStatsToken(r) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	gs.mu.RLock()
+	sessions := make([]*Session, 0, len(gs.sessions))
+	for _, session := range gs.sessions {
+		sessions = append(sessions, session)
+	}
+	gs.mu.RUnlock()
+
+	response := make([]SessionStatsResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, toSessionStatsResponse(session.ID, session.Engine.Stats()))
+	}
+	sort.Slice(response, func(i, j int) bool { return response[i].SessionID < response[j].SessionID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func toSessionStatsResponse(sessionID string, stats game.EngineStats) SessionStatsResponse {
+	avgDeltaCalcTime := stats.TotalDeltaCalcTime.Average(stats.DeltaCalcCount)
+	avgDeltaCalcTimeLastPeriod := stats.TotalDeltaCalcTimeSinceLastReport.Average(stats.DeltaCalcCountSinceLastReport)
+
+	response := SessionStatsResponse{
+		SessionID:                  sessionID,
+		UpdateCount:                stats.UpdateCount,
+		AvgUpdateTime:              toUpdateTimesResponse(stats.TotalUpdateTime.Average(stats.UpdateCount)),
+		AvgUpdateTimeLastPeriod:    toUpdateTimesResponse(stats.TotalUpdateTimeSinceLastReport.Average(stats.UpdateCountSinceLastReport)),
+		DeltaCalcCount:             stats.DeltaCalcCount,
+		AvgDeltaCalcTime:           milliseconds(avgDeltaCalcTime.Total()),
+		AvgUpdatePreviousTime:      milliseconds(avgDeltaCalcTime.UpdatePrevious),
+		AvgDeltaCalcTimeLastPeriod: milliseconds(avgDeltaCalcTimeLastPeriod.Total()),
+		Players:                    stats.Players,
+		ConnectedPlayers:           stats.ConnectedPlayers,
+		Enemies:                    stats.Enemies,
+		CheckedEnemies:             stats.CheckedEnemies,
+		Bullets:                    stats.Bullets,
+		Bonuses:                    stats.Bonuses,
+	}
+	if !stats.LastReportedAt.IsZero() {
+		response.LastReportedAt = &stats.LastReportedAt
+	}
+	return response
+}
+
+func toUpdateTimesResponse(times game.UpdateTimeStats) UpdateTimesResponse {
+	return UpdateTimesResponse{
+		Total:   milliseconds(times.Total()),
+		Players: milliseconds(times.Players),
+		Enemies: milliseconds(times.Enemies),
+		Bullets: milliseconds(times.Bullets),
+		Bonuses: milliseconds(times.Bonuses),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
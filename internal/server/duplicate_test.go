@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/gorilla/websocket"
+)
+
+// dialTestConn returns the server and client ends of a websocket connection
+func dialTestConn(t *testing.T) (serverConn, clientConn *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { clientConn.Close() })
+
+	return <-conns, clientConn
+}
+
+func TestSecondConnectionTakesOverPlayer(t *testing.T) {
+	// The stale connection must neither wait to reconnect nor take the player out of the game
+	for _, gracePeriod := range []time.Duration{time.Minute, 0} {
+		gs := newReconnectTestServer(t)
+		gs.reconnectGracePeriod = gracePeriod
+		session := &Session{ID: "session", Engine: game.NewEngine("session")}
+		gs.sessions[session.ID] = session
+		connectTestClient(gs, session, "watcher")
+		stale, player := connectTestClient(gs, session, "player")
+		player.Money = 500
+		serverConn, clientConn := dialTestConn(t)
+		stale.Conn = serverConn
+		gs.reservations[session.ID] = map[string]time.Time{stale.UserID.Hex(): time.Now().Add(time.Minute)}
+
+		// The player reloads before the server noticed the old socket is gone
+		again := &WebsocketClient{ID: "player-again", UserID: stale.UserID, Username: stale.Username, SessionID: session.ID, Send: make(chan []byte, 16)}
+		gs.registerClient(again)
+
+		_, _, err := clientConn.ReadMessage()
+		if closeErr, ok := err.(*websocket.CloseError); !ok || closeErr.Text != supersededReason {
+			t.Fatalf("expected the stale connection to be closed as superseded, got %v", err)
+		}
+		if _, registered := gs.clients[again.ID]; !registered {
+			t.Error("expected the new connection to be registered")
+		}
+		if _, reserved := gs.reservations[session.ID]; reserved {
+			t.Error("expected the new connection to use up its reservation")
+		}
+
+		// The stale connection's read pump unregisters it, which must leave the player to the new one
+		gs.unregisterClient(stale)
+		if players := session.Engine.GetAllPlayers(); len(players) != 2 || player.Money != 500 || !player.IsConnected {
+			t.Errorf("grace period %s: expected the new connection to keep the player's character, got %v", gracePeriod, players)
+		}
+		if session.PlayerCount != 2 || len(session.disconnected) != 0 {
+			t.Errorf("grace period %s: expected the takeover not to count as a new player or a drop, count %d, waiting %d", gracePeriod, session.PlayerCount, len(session.disconnected))
+		}
+		if _, registered := gs.clients[stale.ID]; registered {
+			t.Errorf("grace period %s: expected the stale connection to be unregistered", gracePeriod)
+		}
+	}
+}
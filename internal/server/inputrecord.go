@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+// flushRecordedInputs hands the inputs the session recorded since the last flush to the database workers
+func (gs *GameServer) flushRecordedInputs(session *Session) {
+	records, dropped := session.Engine.TakeRecordedInputs()
+	if dropped > 0 {
+		log.Printf("Session %s recorded too many inputs between flushes, dropped %d", session.ID, dropped)
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	gs.dbWorkers.submitOrRetry(func() { gs.writeRecordedInputs(records) })
+}
+
+// writeRecordedInputs stores a batch of recorded inputs
+func (gs *GameServer) writeRecordedInputs(records []*db.InputRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.NewInputRecordRepository().InsertMany(ctx, records); err != nil {
+		log.Printf("Failed to store %d recorded inputs: %v", len(records), err)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+)
+
+func TestUnloadingSessionStoresItsRecordedInputs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unload", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		config.AppConfig = &config.Config{RecordInputs: true, SessionKeepAlive: time.Minute}
+		gs := NewGameServer()
+		session := newIdleTestSession(gs, "idle", time.Now().Add(-time.Hour))
+		session.Engine.UpdatePlayerInput("player", types.InputPayload{Sequence: 1, Forward: true})
+
+		gs.unloadIdleSessions(time.Now())
+		gs.dbWorkers.stop()
+
+		started := mt.GetStartedEvent()
+		if started == nil || started.CommandName != "insert" {
+			mt.Fatalf("expected the recorded inputs to be inserted on unload, got %+v", started)
+		}
+		if collection := started.Command.Lookup("insert").StringValue(); collection != "input_records" {
+			mt.Errorf("inserted into %q, want input_records", collection)
+		}
+		if records, _ := session.Engine.TakeRecordedInputs(); len(records) != 0 {
+			mt.Errorf("expected no inputs left in the engine, got %d", len(records))
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/protocol"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestJoinIsSentInDeltaWhenEnabled(t *testing.T) {
+	config.AppConfig = &config.Config{JoinLeaveInDeltas: true}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+
+	watcher := &WebsocketClient{ID: "watcher", UserID: primitive.NewObjectID(), SessionID: session.ID, UseBinary: true, Send: make(chan []byte, 4)}
+	gs.clients[watcher.ID] = watcher
+	session.Engine.ConnectPlayer(watcher.UserID.Hex(), "watcher")
+	session.Engine.GetGameStateDeltaForPlayer(watcher.UserID.Hex())
+
+	joinerID := primitive.NewObjectID().Hex()
+	joiner := session.Engine.ConnectPlayer(joinerID, "joiner")
+	gs.broadcastPlayerJoinedMessage(session.ID, joiner)
+	if len(watcher.Send) != 0 {
+		t.Fatal("expected no separate join message")
+	}
+
+	gs.broadcastAllSessionStates()
+	if len(watcher.Send) != 1 {
+		t.Fatalf("expected a single delta, got %d messages", len(watcher.Send))
+	}
+	var msg protocol.GameMessage
+	if err := proto.Unmarshal(<-watcher.Send, &msg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	joined := msg.GetGameStateDelta().GetJoinedPlayers()
+	if len(joined) != 1 || joined[0].Id != joinerID {
+		t.Fatalf("expected the joiner in the delta, got %v", joined)
+	}
+
+	session.Engine.DisconnectPlayer(joinerID)
+	if left := session.Engine.GetGameStateDeltaForPlayer(watcher.UserID.Hex()).LeftPlayers; len(left) != 1 || left[0] != joinerID {
+		t.Errorf("expected the leave in the next delta, got %v", left)
+	}
+	if joined := session.Engine.GetGameStateDeltaForPlayer(watcher.UserID.Hex()).JoinedPlayers; len(joined) != 0 {
+		t.Errorf("expected the join to be sent once, got %v", joined)
+	}
+}
+
+func TestJoinIsBroadcastByDefault(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+
+	watcher := &WebsocketClient{ID: "watcher", UserID: primitive.NewObjectID(), SessionID: session.ID, UseBinary: true, Send: make(chan []byte, 4)}
+	gs.clients[watcher.ID] = watcher
+	session.Engine.ConnectPlayer(watcher.UserID.Hex(), "watcher")
+	session.Engine.GetGameStateDeltaForPlayer(watcher.UserID.Hex())
+
+	joiner := session.Engine.ConnectPlayer(primitive.NewObjectID().Hex(), "joiner")
+	gs.broadcastPlayerJoinedMessage(session.ID, joiner)
+
+	var msg protocol.GameMessage
+	if err := proto.Unmarshal(<-watcher.Send, &msg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if msg.Type != protocol.MessageType_PLAYER_JOIN {
+		t.Errorf("expected a PLAYER_JOIN message, got %v", msg.Type)
+	}
+	if joined := session.Engine.GetGameStateDeltaForPlayer(watcher.UserID.Hex()).JoinedPlayers; len(joined) != 0 {
+		t.Errorf("expected no joins in the delta, got %v", joined)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/gorilla/websocket"
+)
+
+func TestKickPlayerClosesConnectionAndRemovesPlayer(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	connectTestClient(gs, session, "host")
+	client, _ := connectTestClient(gs, session, "player")
+	serverConn, clientConn := dialTestConn(t)
+	client.Conn = serverConn
+
+	if !gs.KickPlayer(session.ID, client.UserID.Hex()) {
+		t.Fatal("expected the connected player to be kicked")
+	}
+
+	_, _, err := clientConn.ReadMessage()
+	if closeErr, ok := err.(*websocket.CloseError); !ok || closeErr.Text != kickedReason {
+		t.Fatalf("expected the connection to be closed as kicked, got %v", err)
+	}
+	if players := session.Engine.GetAllPlayers(); len(players) != 1 {
+		t.Errorf("expected the kicked player to be taken out of the game, %d players left", len(players))
+	}
+
+	// The closed connection's read pump unregisters it, without a reconnect grace period
+	gs.unregisterClient(client)
+	if session.PlayerCount != 1 || len(session.disconnected) != 0 {
+		t.Errorf("expected the kicked player to leave right away, count %d, waiting %d", session.PlayerCount, len(session.disconnected))
+	}
+}
+
+func TestKickPlayerWaitingToReconnect(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	connectTestClient(gs, session, "host")
+	client, _ := connectTestClient(gs, session, "player")
+	gs.unregisterClient(client)
+
+	if !gs.KickPlayer(session.ID, client.UserID.Hex()) {
+		t.Fatal("expected the player waiting to reconnect to be kicked")
+	}
+	if players := session.Engine.GetAllPlayers(); len(players) != 1 {
+		t.Errorf("expected the kicked player to be taken out of the game, %d players left", len(players))
+	}
+
+	// The game loop removes them at its next tick, without waiting out the grace period
+	gs.removeDisconnectedPlayers(time.Now())
+	if session.PlayerCount != 1 || len(session.disconnected) != 0 {
+		t.Errorf("expected the kicked player to leave, count %d, waiting %d", session.PlayerCount, len(session.disconnected))
+	}
+
+	if gs.KickPlayer(session.ID, client.UserID.Hex()) {
+		t.Error("expected a player no longer in the session not to be found")
+	}
+	if gs.KickPlayer("other", client.UserID.Hex()) {
+		t.Error("expected nobody to be kicked from a session that isn't loaded")
+	}
+}
+
+func TestKickedLastPlayerLeavesSessionToNewPlayer(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	client, _ := connectTestClient(gs, session, "player")
+	gs.unregisterClient(client)
+
+	if !gs.KickPlayer(session.ID, client.UserID.Hex()) {
+		t.Fatal("expected the player waiting to reconnect to be kicked")
+	}
+	if _, loaded := gs.sessions[session.ID]; !loaded || session.PlayerCount != 1 {
+		t.Fatalf("expected the kick to leave the session to the game loop, loaded %v, count %d", loaded, session.PlayerCount)
+	}
+
+	// Someone joins before the next tick, so the session must not be unloaded under them
+	connectTestClient(gs, session, "newcomer")
+	gs.removeDisconnectedPlayers(time.Now())
+
+	if loaded := gs.sessions[session.ID]; loaded != session || session.PlayerCount != 1 {
+		t.Errorf("expected the newcomer to keep the session, loaded %v, count %d", loaded == session, session.PlayerCount)
+	}
+	if players := session.Engine.GetAllPlayers(); len(players) != 1 || players[0].Username != "newcomer" {
+		t.Errorf("expected only the newcomer left in the game, got %v", players)
+	}
+}
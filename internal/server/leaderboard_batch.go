@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+// leaderboardBatch collects leaderboard updates between flushes, so deaths
+// reach the database in one bulk write instead of a write each
+type leaderboardBatch struct {
+	mu        sync.Mutex
+	entries   []*db.LeaderboardEntry
+	interval  time.Duration
+	lastFlush time.Time
+}
+
+func newLeaderboardBatch(interval time.Duration) *leaderboardBatch {
+	return &leaderboardBatch{
+		interval:  interval,
+		lastFlush: time.Now(),
+	}
+}
+
+func (b *leaderboardBatch) add(entry *db.LeaderboardEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = append(b.entries, entry)
+}
+
+// due reports whether the flush interval has passed since the last flush
+func (b *leaderboardBatch) due() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return time.Since(b.lastFlush) >= b.interval
+}
+
+// take empties the batch and returns the entries collected so far
+func (b *leaderboardBatch) take() []*db.LeaderboardEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := b.entries
+	b.entries = nil
+	b.lastFlush = time.Now()
+	return entries
+}
+
+// flushLeaderboard hands the pending batch to the database workers
+func (gs *GameServer) flushLeaderboard() {
+	entries := gs.leaderboardBatch.take()
+	if len(entries) == 0 {
+		return
+	}
+
+	gs.dbWorkers.submitOrRetry(func() { gs.writeLeaderboardBatch(entries) })
+}
+
+// writeLeaderboardBatch records a batch of deaths in the leaderboard
+func (gs *GameServer) writeLeaderboardBatch(entries []*db.LeaderboardEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	leaderboardRepo := db.NewLeaderboardRepository()
+	if err := leaderboardRepo.UpsertEntries(ctx, entries); err != nil {
+		log.Printf("Failed to write %d leaderboard updates: %v", len(entries), err)
+	} else {
+		log.Printf("Leaderboard updated with %d deaths", len(entries))
+	}
+}
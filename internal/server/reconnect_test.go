@@ -0,0 +1,116 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newReconnectTestServer(t *testing.T) *GameServer {
+	t.Helper()
+
+	// Users keep their current session, so players leaving don't touch the database
+	config.AppConfig = &config.Config{ReconnectGracePeriod: time.Minute, AutoRejoinSession: true, JoinReservationTTL: time.Minute}
+	gs := NewGameServer()
+	t.Cleanup(gs.dbWorkers.stop)
+	return gs
+}
+
+// connectTestClient puts a client into the loaded session the way registerClient does for a new player
+func connectTestClient(gs *GameServer, session *Session, id string) (*WebsocketClient, *types.Player) {
+	client := &WebsocketClient{ID: id, UserID: primitive.NewObjectID(), Username: id, SessionID: session.ID, Send: make(chan []byte, 16)}
+	gs.clients[client.ID] = client
+	session.PlayerCount++
+	return client, session.Engine.ConnectPlayer(client.UserID.Hex(), client.Username)
+}
+
+func TestDroppedPlayerReconnectsWithinGracePeriod(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	client, player := connectTestClient(gs, session, "player")
+	player.Money = 500
+
+	gs.unregisterClient(client)
+	if player.IsConnected || session.PlayerCount != 1 {
+		t.Fatalf("expected the player to stay in the session disconnected while they may reconnect, connected %v, count %d", player.IsConnected, session.PlayerCount)
+	}
+
+	again := &WebsocketClient{ID: "player-again", UserID: client.UserID, Username: client.Username, SessionID: session.ID, Send: make(chan []byte, 16)}
+	gs.registerClient(again)
+
+	if players := session.Engine.GetAllPlayers(); len(players) != 1 || players[0].ID != player.ID || players[0].Money != 500 || !players[0].IsConnected {
+		t.Errorf("expected the reconnecting player to get their character back, got %v", players)
+	}
+	if session.PlayerCount != 1 || len(session.disconnected) != 0 {
+		t.Errorf("expected the reconnect not to count as a new player, count %d, waiting %d", session.PlayerCount, len(session.disconnected))
+	}
+
+	// The old connection's grace period is over, it must not take the player away
+	gs.removeDisconnectedPlayers(time.Now().Add(2 * time.Minute))
+	if !player.IsConnected || session.PlayerCount != 1 {
+		t.Errorf("expected the reconnected player to stay, connected %v, count %d", player.IsConnected, session.PlayerCount)
+	}
+}
+
+func TestReconnectCannotSwitchTeam(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	client := &WebsocketClient{ID: "player", UserID: primitive.NewObjectID(), Username: "player", SessionID: session.ID, Send: make(chan []byte, 16), Team: "red"}
+	gs.clients[client.ID] = client
+	session.PlayerCount++
+	session.Engine.ConnectPlayerOnTeam(client.UserID.Hex(), client.Username, client.Team)
+	gs.unregisterClient(client)
+
+	again := &WebsocketClient{ID: "player-again", UserID: client.UserID, Username: client.Username, SessionID: session.ID, Send: make(chan []byte, 16), Team: "blue"}
+	gs.registerClient(again)
+
+	if players := session.Engine.GetAllPlayers(); len(players) != 1 || players[0].Team != "red" {
+		t.Errorf("expected the reconnecting player to stay on the team they joined, got %v", players)
+	}
+}
+
+func TestDroppedPlayerIsRemovedAfterGracePeriod(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	connectTestClient(gs, session, "watcher")
+	client, player := connectTestClient(gs, session, "player")
+
+	droppedAt := time.Now()
+	gs.unregisterClient(client)
+
+	gs.removeDisconnectedPlayers(droppedAt.Add(30 * time.Second))
+	if player.IsConnected || session.PlayerCount != 2 || len(session.disconnected) != 1 {
+		t.Fatalf("expected the player to stay disconnected within the grace period, connected %v, count %d", player.IsConnected, session.PlayerCount)
+	}
+
+	gs.removeDisconnectedPlayers(droppedAt.Add(2 * time.Minute))
+	if player.IsConnected || session.PlayerCount != 1 || len(session.disconnected) != 0 {
+		t.Errorf("expected the player to leave once the grace period is over, connected %v, count %d", player.IsConnected, session.PlayerCount)
+	}
+	if gs.GetSessionEngine(session.ID) == nil {
+		t.Error("expected the session with a player left to stay loaded")
+	}
+}
+
+func TestDroppedPlayerKeepsTheirSlot(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	client, _ := connectTestClient(gs, session, "player")
+
+	gs.unregisterClient(client)
+
+	if gs.ReserveSlot(session.ID, primitive.NewObjectID().Hex(), 1) {
+		t.Error("expected the slot of a player waiting to reconnect to stay taken")
+	}
+	if !gs.ReserveSlot(session.ID, client.UserID.Hex(), 1) {
+		t.Error("expected the player waiting to reconnect to get their slot back")
+	}
+}
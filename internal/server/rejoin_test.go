@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/auth"
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSessionIDToJoinPrefersRequestedSession(t *testing.T) {
+	config.AppConfig = &config.Config{AutoRejoinSession: true}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	user := &db.User{CurrentSession: "last"}
+	sessionID, rejoining, err := gs.sessionIDToJoin("requested", user)
+	if err != nil || rejoining || sessionID != "requested" {
+		t.Fatalf("expected the requested session, got %q (rejoining %v, err %v)", sessionID, rejoining, err)
+	}
+}
+
+func TestSessionIDToJoinRejoinsLastSession(t *testing.T) {
+	config.AppConfig = &config.Config{AutoRejoinSession: true}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	sessionID, rejoining, err := gs.sessionIDToJoin("", &db.User{CurrentSession: "last"})
+	if err != nil || !rejoining || sessionID != "last" {
+		t.Fatalf("expected to rejoin the last session, got %q (rejoining %v, err %v)", sessionID, rejoining, err)
+	}
+
+	if _, _, err := gs.sessionIDToJoin("", &db.User{}); err != errNoSessionToRejoin {
+		t.Fatalf("expected errNoSessionToRejoin for a user without a session, got %v", err)
+	}
+}
+
+func TestSessionIDToJoinWithoutAutoRejoin(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	sessionID, rejoining, err := gs.sessionIDToJoin("", &db.User{CurrentSession: "last"})
+	if err != nil || rejoining || sessionID != "" {
+		t.Fatalf("expected no rejoin when disabled, got %q (rejoining %v, err %v)", sessionID, rejoining, err)
+	}
+}
+
+func TestStaleSessionIsForgotten(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("session gone", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		config.AppConfig = &config.Config{AutoRejoinSession: true, SecretKey: "test-secret", AccessTokenExpireMinutes: 5}
+		gs := NewGameServer()
+		defer gs.dbWorkers.stop()
+
+		userID := primitive.NewObjectID()
+		staleID := primitive.NewObjectID().Hex()
+		token, err := auth.GenerateToken(userID)
+		if err != nil {
+			mt.Fatalf("GenerateToken() error = %v", err)
+		}
+		connect := func() *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			gs.HandleWebSocket(rec, httptest.NewRequest(http.MethodGet, "/ws?token="+token, nil))
+			return rec
+		}
+
+		stored := bson.D{{Key: "_id", Value: userID}, {Key: "username", Value: "player"}, {Key: "is_active", Value: true}, {Key: "current_session", Value: staleID}}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch, stored),
+			mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		if rec := connect(); rec.Code != http.StatusGone {
+			mt.Fatalf("status = %d, want %d for a session that's gone", rec.Code, http.StatusGone)
+		}
+
+		mt.GetStartedEvent() // user lookup
+		mt.GetStartedEvent() // session lookup
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, err := update.LookupErr("u", "$unset", "current_session"); err != nil {
+			mt.Fatalf("expected the stale session to be unset, got %v", update.Lookup("u"))
+		}
+
+		// The next connection sees the user as stored now, and is asked for a session instead of being sent away again
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch, stored[:3]))
+		if rec := connect(); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "no session to rejoin") {
+			mt.Errorf("status = %d (%s), want %d asking for a session", rec.Code, strings.TrimSpace(rec.Body.String()), http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"time"
+)
+
+// ReserveSlot holds a place in the session for a user who joined over HTTP but
+// hasn't connected yet. Live connections and unexpired reservations together
+// can't exceed maxPlayers; a maxPlayers of 0 or less means no limit. Users
+// already connected or holding a reservation always get their slot back.
+func (gs *GameServer) ReserveSlot(sessionID, userID string, maxPlayers int) bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	reservations := gs.reservations[sessionID]
+	for reservedUserID, expiresAt := range reservations {
+		if now.After(expiresAt) {
+			delete(reservations, reservedUserID)
+		}
+	}
+
+	// Players waiting to reconnect keep their slot
+	connectedUsers := gs.connectedUsers(sessionID)
+	if session, loaded := gs.sessions[sessionID]; loaded {
+		session.mu.Lock()
+		for disconnectedUserID := range session.disconnected {
+			connectedUsers[disconnectedUserID] = true
+		}
+		session.mu.Unlock()
+	}
+	if connectedUsers[userID] {
+		return true
+	}
+
+	if _, reserved := reservations[userID]; !reserved && maxPlayers > 0 {
+		taken := len(connectedUsers)
+		for reservedUserID := range reservations {
+			if !connectedUsers[reservedUserID] {
+				taken++
+			}
+		}
+		if taken >= maxPlayers {
+			return false
+		}
+	}
+
+	if reservations == nil {
+		reservations = make(map[string]time.Time)
+		gs.reservations[sessionID] = reservations
+	}
+	reservations[userID] = now.Add(gs.reservationTTL)
+	gs.leaveWaitlist(sessionID, userID)
+
+	return true
+}
+
+// connectedUsers returns the IDs of users with a live connection to the session. Must be called with gs.mu held.
+func (gs *GameServer) connectedUsers(sessionID string) map[string]bool {
+	users := make(map[string]bool)
+	for _, client := range gs.clients {
+		if client.SessionID == sessionID {
+			users[client.UserID.Hex()] = true
+		}
+	}
+	return users
+}
+
+// releaseReservation drops the user's reservation once they are connected. Must be called with gs.mu held.
+func (gs *GameServer) releaseReservation(sessionID, userID string) {
+	gs.leaveWaitlist(sessionID, userID)
+
+	reservations, exists := gs.reservations[sessionID]
+	if !exists {
+		return
+	}
+
+	delete(reservations, userID)
+	if len(reservations) == 0 {
+		delete(gs.reservations, sessionID)
+	}
+}
+
+// cancelReservation gives the slot back when a connection is refused after the user reserved it
+func (gs *GameServer) cancelReservation(sessionID, userID string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	gs.releaseReservation(sessionID, userID)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/besuhoff/dungeon-game-go/internal/auth"
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+)
+
+func newTestGameServer(t *testing.T) *GameServer {
+	t.Helper()
+
+	config.AppConfig = &config.Config{JoinReservationTTL: time.Minute}
+	gs := NewGameServer()
+	t.Cleanup(gs.dbWorkers.stop)
+	return gs
+}
+
+func TestReserveSlotConcurrentJoinsRespectCapacity(t *testing.T) {
+	gs := newTestGameServer(t)
+	const maxPlayers = 4
+
+	var granted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if gs.ReserveSlot("session", fmt.Sprintf("user-%d", i), maxPlayers) {
+				granted.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := granted.Load(); got != maxPlayers {
+		t.Errorf("granted %d reservations, want %d", got, maxPlayers)
+	}
+}
+
+func TestReserveSlotCountsConnectedPlayers(t *testing.T) {
+	gs := newTestGameServer(t)
+
+	connectedUser := primitive.NewObjectID()
+	gs.clients["client"] = &WebsocketClient{ID: "client", UserID: connectedUser, SessionID: "session"}
+
+	if !gs.ReserveSlot("session", "joining", 2) {
+		t.Fatal("expected a slot next to the connected player")
+	}
+	if gs.ReserveSlot("session", "late", 2) {
+		t.Error("expected the session to be full with one connected and one reserved player")
+	}
+	if !gs.ReserveSlot("session", "joining", 2) {
+		t.Error("expected a user to keep their own reservation")
+	}
+	if !gs.ReserveSlot("session", connectedUser.Hex(), 2) {
+		t.Error("expected an already connected user to always get a slot")
+	}
+}
+
+func TestReserveSlotExpiresStaleReservations(t *testing.T) {
+	gs := newTestGameServer(t)
+	gs.reservationTTL = -time.Second
+
+	if !gs.ReserveSlot("session", "never-connects", 1) {
+		t.Fatal("expected the first reservation to succeed")
+	}
+	if !gs.ReserveSlot("session", "next", 1) {
+		t.Error("expected an expired reservation to free its slot")
+	}
+}
+
+func TestRegisterReleasesReservation(t *testing.T) {
+	gs := newTestGameServer(t)
+	userID := primitive.NewObjectID()
+
+	if !gs.ReserveSlot("session", userID.Hex(), 1) {
+		t.Fatal("expected the reservation to succeed")
+	}
+
+	gs.mu.Lock()
+	gs.clients["client"] = &WebsocketClient{ID: "client", UserID: userID, SessionID: "session"}
+	gs.releaseReservation("session", userID.Hex())
+	gs.mu.Unlock()
+
+	if _, exists := gs.reservations["session"]; exists {
+		t.Error("expected the reservation to be released once the user is connected")
+	}
+	if gs.ReserveSlot("session", "other", 1) {
+		t.Error("expected the connected user to keep occupying the only slot")
+	}
+}
+
+func TestRefusedConnectionCancelsReservation(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("upgrade fails", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		gs := newTestGameServer(t)
+		config.AppConfig.SecretKey = "test-secret"
+		config.AppConfig.AccessTokenExpireMinutes = 5
+
+		userID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "dungeon_game.users", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: userID}, {Key: "username", Value: "player"}, {Key: "is_active", Value: true}}),
+			mtest.CreateCursorResponse(0, "dungeon_game.game_sessions", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: sessionID}, {Key: "name", Value: "session"}, {Key: "max_players", Value: 1}, {Key: "is_active", Value: true}}),
+		)
+		token, err := auth.GenerateToken(userID)
+		if err != nil {
+			mt.Fatalf("GenerateToken() error = %v", err)
+		}
+
+		// A plain HTTP request can't be upgraded, so the connection is refused after the slot was reserved
+		rec := httptest.NewRecorder()
+		gs.HandleWebSocket(rec, httptest.NewRequest(http.MethodGet, "/ws?token="+token+"&sessionId="+sessionID.Hex(), nil))
+
+		if rec.Code != http.StatusBadRequest {
+			mt.Fatalf("status = %d, want the failed upgrade's %d", rec.Code, http.StatusBadRequest)
+		}
+		if _, exists := gs.reservations[sessionID.Hex()]; exists {
+			mt.Error("expected the refused connection to give its reservation back")
+		}
+		if !gs.ReserveSlot(sessionID.Hex(), "other", 1) {
+			mt.Error("expected the only slot to be free again")
+		}
+	})
+}
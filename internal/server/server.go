@@ -2,10 +2,15 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,8 +23,18 @@ import (
 	"github.com/besuhoff/dungeon-game-go/internal/game"
 	"github.com/besuhoff/dungeon-game-go/internal/protocol"
 	"github.com/besuhoff/dungeon-game-go/internal/types"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
 )
 
+// errNoSessionToRejoin is returned for connections without a session ID from users who aren't in a session
+var errNoSessionToRejoin = errors.New("no session ID given and no session to rejoin")
+
+// supersededReason closes a connection the user replaced with a newer one to the same session
+const supersededReason = "Connected from another tab"
+
+// kickedReason closes the connection of a player the host kicked out of the session
+const kickedReason = "Kicked by the host"
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins in development
@@ -28,13 +43,34 @@ var upgrader = websocket.Upgrader{
 
 // Session represents a game session with its engine
 type Session struct {
-	ID                string
-	Name              string
-	Engine            *game.Engine
-	PlayerCount       int
-	mu                sync.Mutex
-	lastSaveTime      time.Time
-	deadPlayerTracked map[string]bool // Track which player deaths have been recorded
+	ID           string
+	Name         string
+	Engine       *game.Engine
+	PlayerCount  int
+	mu           sync.Mutex
+	lastSaveTime time.Time
+
+	// When recorded inputs were last handed to the database, only used by the game loop
+	lastInputFlush time.Time
+
+	// When the last player left, zero while anyone is connected
+	idleSince time.Time
+
+	// Players whose connection dropped and who still count as in the session while they may reconnect: userID -> disconnect
+	disconnected map[string]disconnect
+
+	// When the session was loaded on this server, and how busy it has been over its lifetime
+	loadedAt time.Time
+	activity db.SessionActivity
+}
+
+// disconnect is a dropped connection waiting out the reconnect grace period
+type disconnect struct {
+	at       time.Time
+	userID   primitive.ObjectID
+	username string
+	// Kicked by the host, left for the game loop to remove at its next tick
+	kicked bool
 }
 
 // GameServer manages the game and all clients
@@ -47,11 +83,53 @@ type GameServer struct {
 	shutdown   chan struct{}
 	mu         sync.RWMutex
 	running    bool
+
+	// Set once Shutdown starts, new connections are refused from then on
+	shuttingDown atomic.Bool
+
+	// Runs session saves and leaderboard updates off the game loop
+	dbWorkers *dbWorkerPool
+
+	// Pending leaderboard updates, nil when each death is written right away
+	leaderboardBatch *leaderboardBatch
+
+	// Slots held for users who joined a session but haven't connected yet: sessionID -> userID -> expiry
+	reservations   map[string]map[string]time.Time
+	reservationTTL time.Duration
+
+	// Users waiting for a slot in a full session, in order: sessionID -> entries. A zero TTL turns waitlists off
+	waitlists   map[string][]*waitlistEntry
+	waitlistTTL time.Duration
+
+	// Sessions saved without a stored record get a random name instead of one made from their ID
+	autoSessionNames bool
+
+	// How many sessions may be loaded at once, 0 for no limit
+	maxLoadedSessions int
+
+	// How long a session stays loaded after its last player leaves, so quick rejoins find it warm
+	sessionKeepAlive time.Duration
+
+	// Connections without a session ID rejoin the user's last session
+	autoRejoinSession bool
+
+	// Joins and leaves reach the other players in their deltas instead of PLAYER_JOIN and PLAYER_LEAVE messages
+	joinLeaveInDeltas bool
+
+	// How long a player whose connection dropped stays in the session waiting to reconnect, 0 removes them right away
+	reconnectGracePeriod time.Duration
+
+	// Sessions keep track of when they started, their peak player count and how many players they had
+	trackActivity bool
+
+	// Serve the engine stats of every live session to requests bearing the token
+	debugStats      bool
+	debugStatsToken string
 }
 
 // NewGameServer creates a new game server
 func NewGameServer() *GameServer {
-	return &GameServer{
+	gs := &GameServer{
 		clients:    make(map[string]*WebsocketClient),
 		sessions:   make(map[string]*Session),
 		register:   make(chan *WebsocketClient),
@@ -59,13 +137,42 @@ func NewGameServer() *GameServer {
 		broadcast:  make(chan []byte, 256),
 		shutdown:   make(chan struct{}),
 		running:    false,
+		dbWorkers:  newDBWorkerPool(config.AppConfig.DBWorkers),
+
+		reservations:   make(map[string]map[string]time.Time),
+		reservationTTL: config.AppConfig.JoinReservationTTL,
+
+		waitlists:   make(map[string][]*waitlistEntry),
+		waitlistTTL: config.AppConfig.SessionWaitlistTTL,
+
+		autoSessionNames: config.AppConfig.AutoSessionNames,
+
+		maxLoadedSessions: config.AppConfig.MaxLoadedSessions,
+		sessionKeepAlive:  config.AppConfig.SessionKeepAlive,
+
+		autoRejoinSession: config.AppConfig.AutoRejoinSession,
+
+		joinLeaveInDeltas: config.AppConfig.JoinLeaveInDeltas,
+
+		reconnectGracePeriod: config.AppConfig.ReconnectGracePeriod,
+
+		trackActivity: config.AppConfig.SessionActivityEnabled,
+		debugStats:    config.AppConfig.DebugStatsEnabled,
+
+		debugStatsToken: config.AppConfig.DebugStatsToken,
+	}
+
+	if config.AppConfig.LeaderboardFlush > 0 {
+		gs.leaderboardBatch = newLeaderboardBatch(config.AppConfig.LeaderboardFlush)
 	}
+
+	return gs
 }
 
 // Run starts the game server loop
 func (gs *GameServer) Run() {
 	gs.running = true
-	ticker := time.NewTicker(config.GameLoopInterval)
+	ticker := time.NewTicker(config.AppConfig.GameLoopInterval)
 	defer ticker.Stop()
 
 	for {
@@ -84,10 +191,17 @@ func (gs *GameServer) Run() {
 			gs.broadcastMessage(message)
 
 		case <-ticker.C:
+			// Writes that found the database workers busy get another go
+			gs.dbWorkers.retryPending()
+
 			// Update all active sessions
+			sessionSaved := false
 			gs.mu.RLock()
 			for _, session := range gs.sessions {
-				session.Engine.Update()
+				// Sessions kept loaded or waiting for reconnects don't tick while nobody is playing
+				if !session.Engine.IsIdle() {
+					session.Engine.Update()
+				}
 
 				// Check if session needs saving (with mutex protection)
 				session.mu.Lock()
@@ -99,60 +213,55 @@ func (gs *GameServer) Run() {
 				session.mu.Unlock()
 
 				if needsSave {
-					// Save asynchronously to avoid blocking the game loop
-					go gs.saveSessionToDatabase(session)
+					sessionSaved = true
+
+					// Save asynchronously to avoid blocking the game loop, waiting for the workers when they're busy
+					gs.dbWorkers.submitOrRetry(func() { gs.saveSessionToDatabase(session) })
 				}
 
-				// Check for player deaths and update leaderboard
-				for _, player := range session.Engine.GetAllPlayers() {
-					session.mu.Lock()
-					isTracked := session.deadPlayerTracked[player.ID]
-					session.mu.Unlock()
-
-					if !player.IsAlive && !isTracked {
-						log.Printf("Player %s (ID: %s) died! Score: %d, Kills: %d", player.Username, player.ID, player.Score, player.Kills)
-
-						// Mark this death as tracked to avoid duplicate entries
-						session.mu.Lock()
-						session.deadPlayerTracked[player.ID] = true
-						session.mu.Unlock()
-
-						// Update player score in leaderboard
-						go func(p *types.Player, sessID, sessName string) {
-							ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-							defer cancel()
-
-							userID, err := primitive.ObjectIDFromHex(p.ID)
-							if err != nil {
-								log.Printf("Updating leaderboard: invalid player ID %s: %v", p.ID, err)
-								return
-							}
-
-							leaderboardRepo := db.NewLeaderboardRepository()
-							entry := &db.LeaderboardEntry{
-								UserID:      userID,
-								Username:    p.Username,
-								SessionID:   sessID,
-								SessionName: sessName,
-								Score:       p.Score,
-								Kills:       p.Kills,
-							}
-							if err := leaderboardRepo.UpsertEntry(ctx, entry); err != nil {
-								log.Printf("Failed to update leaderboard entry for player %s: %v", p.Username, err)
-							} else {
-								log.Printf("Leaderboard updated for player %s: score=%d, kills=%d", p.Username, p.Score, p.Kills)
-							}
-						}(player, session.ID, session.Name)
-					} else if player.IsAlive {
-						// Reset tracking when player respawns
-						session.mu.Lock()
-						delete(session.deadPlayerTracked, player.ID)
-						session.mu.Unlock()
+				// Record player deaths in the leaderboard, each death is reported by the engine once
+				for _, player := range session.Engine.TakeDeaths() {
+					log.Printf("Player %s (ID: %s) died! Score: %d, Kills: %d", player.Username, player.ID, player.Score, player.Kills)
+
+					if gs.leaderboardBatch != nil {
+						if entry, err := newLeaderboardEntry(player, session.ID, session.Name); err == nil {
+							gs.leaderboardBatch.add(entry)
+						} else {
+							log.Printf("Updating leaderboard: %v", err)
+						}
+						continue
 					}
+
+					// Update player score in leaderboard, waiting for the workers when they're busy
+					gs.dbWorkers.submitOrRetry(func() { gs.updateLeaderboard(player, session.ID, session.Name) })
+				}
+
+				// The engine marks achievements as earned when it reports them, so the grants must not be lost
+				for _, grant := range session.Engine.TakeAchievements() {
+					gs.dbWorkers.submitOrRetry(func() { gs.grantAchievement(grant) })
+				}
+
+				// Recorded inputs are stored in batches
+				if time.Since(session.lastInputFlush) >= config.InputRecordFlushInterval {
+					session.lastInputFlush = time.Now()
+					gs.flushRecordedInputs(session)
 				}
 			}
 			gs.mu.RUnlock()
 
+			if gs.reconnectGracePeriod > 0 {
+				gs.removeDisconnectedPlayers(time.Now())
+			}
+
+			if gs.sessionKeepAlive > 0 {
+				gs.unloadIdleSessions(time.Now())
+			}
+
+			// Batched leaderboard updates go out on their interval and along with session saves
+			if gs.leaderboardBatch != nil && (sessionSaved || gs.leaderboardBatch.due()) {
+				gs.flushLeaderboard()
+			}
+
 			// Broadcast game state for each session
 			gs.broadcastAllSessionStates()
 		}
@@ -163,12 +272,24 @@ func (gs *GameServer) Run() {
 func (gs *GameServer) Shutdown() {
 	log.Println("Starting graceful shutdown...")
 
+	// Refuse new connections before the loop that registers them goes away
+	gs.shuttingDown.Store(true)
+
 	// Signal the Run loop to stop
 	close(gs.shutdown)
 
 	// Give it a moment to process
 	time.Sleep(100 * time.Millisecond)
 
+	// Let pending database writes finish before the final saves below
+	gs.dbWorkers.stop()
+
+	if gs.leaderboardBatch != nil {
+		if entries := gs.leaderboardBatch.take(); len(entries) > 0 {
+			gs.writeLeaderboardBatch(entries)
+		}
+	}
+
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
@@ -189,9 +310,14 @@ func (gs *GameServer) Shutdown() {
 	sessionRepo := db.NewGameSessionRepository()
 
 	for sessionID, session := range gs.sessions {
+		if records, _ := session.Engine.TakeRecordedInputs(); len(records) > 0 {
+			gs.writeRecordedInputs(records)
+		}
+
 		if sessionObjID, err := primitive.ObjectIDFromHex(sessionID); err == nil {
 			if dbSession, err := sessionRepo.FindByID(ctx, sessionObjID); err == nil {
 				session.Engine.SaveToSession(dbSession)
+				gs.saveActivity(session, dbSession)
 				sessionRepo.Update(ctx, dbSession)
 				log.Printf("Saved session %s", sessionID)
 			}
@@ -201,21 +327,110 @@ func (gs *GameServer) Shutdown() {
 	log.Println("Graceful shutdown complete")
 }
 
+// hasRoomForSession reports whether the session is loaded or can be loaded without
+// going over the limit, counting idle sessions as room since they can be unloaded.
+// Must be called with gs.mu held.
+func (gs *GameServer) hasRoomForSession(sessionID string) bool {
+	if _, loaded := gs.sessions[sessionID]; loaded {
+		return true
+	}
+	return gs.maxLoadedSessions <= 0 || len(gs.sessions) < gs.maxLoadedSessions || gs.oldestIdleSession() != nil
+}
+
+// oldestIdleSession returns the session that has been without players the longest,
+// or nil if every session has players. Must be called with gs.mu held.
+func (gs *GameServer) oldestIdleSession() *Session {
+	var oldest *Session
+	var oldestIdleSince time.Time
+	for _, session := range gs.sessions {
+		session.mu.Lock()
+		idleSince := session.idleSince
+		session.mu.Unlock()
+
+		if !idleSince.IsZero() && (oldest == nil || idleSince.Before(oldestIdleSince)) {
+			oldest = session
+			oldestIdleSince = idleSince
+		}
+	}
+	return oldest
+}
+
+// unloadIdleSessions unloads sessions nobody rejoined within the keep-alive period.
+// They were saved when their last player left.
+func (gs *GameServer) unloadIdleSessions(now time.Time) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, session := range gs.sessions {
+		session.mu.Lock()
+		expired := !session.idleSince.IsZero() && now.Sub(session.idleSince) >= gs.sessionKeepAlive
+		session.mu.Unlock()
+
+		if expired {
+			log.Printf("Session %s idle for %s, unloading", session.ID, gs.sessionKeepAlive)
+			gs.unloadSession(session)
+		}
+	}
+}
+
+// unloadSession removes the session from memory and clears its engine. Must be called with gs.mu held.
+func (gs *GameServer) unloadSession(session *Session) {
+	delete(gs.sessions, session.ID)
+	gs.flushRecordedInputs(session)
+	session.Engine.Clear()
+}
+
 func (gs *GameServer) registerClient(client *WebsocketClient) {
 	gs.mu.Lock()
 
+	// Another session may have been loaded since the connection was accepted
+	if !gs.hasRoomForSession(client.SessionID) {
+		gs.releaseReservation(client.SessionID, client.UserID.Hex())
+		gs.mu.Unlock()
+
+		log.Printf("Refused player %s (%s): %d sessions already loaded", client.Username, client.UserID.Hex(), gs.maxLoadedSessions)
+		client.Conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Server is at session capacity"),
+			time.Now().Add(time.Second))
+		client.Conn.Close()
+		return
+	}
+
+	// A user plays a session from one connection at a time. The newest one takes over the player,
+	// e.g. after a reload before the server noticed the old socket is gone.
+	var superseded []*WebsocketClient
+	for _, other := range gs.clients {
+		if other.SessionID == client.SessionID && other.UserID == client.UserID {
+			other.superseded = true
+			superseded = append(superseded, other)
+		}
+	}
+	takingOver := false
+	for _, other := range superseded {
+		takingOver = takingOver || !other.kicked
+	}
+
 	gs.clients[client.ID] = client
+	gs.releaseReservation(client.SessionID, client.UserID.Hex())
 
 	// Get or create session
 	session, exists := gs.sessions[client.SessionID]
 	if !exists {
+		// Make room by unloading the session that has been idle the longest
+		if gs.maxLoadedSessions > 0 && len(gs.sessions) >= gs.maxLoadedSessions {
+			if idle := gs.oldestIdleSession(); idle != nil {
+				log.Printf("Unloading idle session %s to make room for session %s", idle.ID, client.SessionID)
+				gs.unloadSession(idle)
+			}
+		}
+
 		// Create new session
 		session = &Session{
-			ID:                client.SessionID,
-			Name:              client.SessionName,
-			Engine:            game.NewEngine(client.SessionID),
-			PlayerCount:       0,
-			deadPlayerTracked: make(map[string]bool),
+			ID:          client.SessionID,
+			Name:        client.SessionName,
+			Engine:      game.NewEngine(client.SessionID),
+			PlayerCount: 0,
+			loadedAt:    time.Now(),
 		}
 		gs.sessions[client.SessionID] = session
 
@@ -227,6 +442,7 @@ func (gs *GameServer) registerClient(client *WebsocketClient) {
 			if dbSession, err := sessionRepo.FindByID(ctx, sessionID); err == nil {
 				log.Printf("Loading existing session %s from database", client.SessionID)
 				session.Engine.LoadFromSession(dbSession)
+				session.activity = dbSession.Activity
 				session.lastSaveTime = time.Now()
 			} else {
 				log.Printf("Creating new session %s", client.SessionID)
@@ -234,23 +450,47 @@ func (gs *GameServer) registerClient(client *WebsocketClient) {
 		}
 	}
 
+	// A player reconnecting within the grace period never left the session
 	session.mu.Lock()
-	session.PlayerCount++
+	pending, waiting := session.disconnected[client.UserID.Hex()]
+	reconnecting := takingOver || waiting && !pending.kicked
+	if waiting {
+		// A kicked player joining again before the game loop removed them takes their slot back too
+		delete(session.disconnected, client.UserID.Hex())
+	} else if len(superseded) == 0 {
+		session.PlayerCount++
+		if gs.trackActivity {
+			session.recordJoin(client.UserID.Hex(), time.Now())
+		}
+	}
+	session.idleSince = time.Time{}
 	playerCount := session.PlayerCount
 	session.mu.Unlock()
 
 	// Unlock before calling methods that need to acquire locks
 	gs.mu.Unlock()
 
-	// Add player to game engine
-	player := session.Engine.ConnectPlayer(client.UserID.Hex(), client.Username)
+	// The superseded connections' read pumps unregister them, leaving the player to this one
+	for _, other := range superseded {
+		other.Conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, supersededReason),
+			time.Now().Add(time.Second))
+		other.Conn.Close()
+	}
+
+	// Add player to game engine, a reconnecting player gets their character back and
+	// keeps the team they first joined with
+	player := session.Engine.ConnectPlayerOnTeam(client.UserID.Hex(), client.Username, client.Team)
+
+	if reconnecting {
+		log.Printf("Player %s (%s) reconnected to session %s", client.Username, client.UserID.Hex(), client.SessionID)
+		return
+	}
 
 	// Update user's current session in database
 	ctx := context.Background()
-	userRepo := db.NewUserRepository()
-	if user, err := userRepo.FindByID(ctx, client.UserID); err == nil {
-		user.CurrentSession = client.SessionID
-		userRepo.Update(ctx, user)
+	if err := db.NewUserRepository().SetCurrentSession(ctx, client.UserID, client.SessionID); err != nil {
+		log.Printf("Failed to set current session of %s: %v", client.UserID.Hex(), err)
 	}
 
 	gs.broadcastPlayerJoinedMessage(client.SessionID, player)
@@ -259,6 +499,90 @@ func (gs *GameServer) registerClient(client *WebsocketClient) {
 		client.Username, client.UserID.Hex(), client.SessionID, playerCount)
 }
 
+// updateLeaderboard records a player's death in the leaderboard
+func (gs *GameServer) updateLeaderboard(p *types.Player, sessID, sessName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entry, err := newLeaderboardEntry(p, sessID, sessName)
+	if err != nil {
+		log.Printf("Updating leaderboard: %v", err)
+		return
+	}
+
+	leaderboardRepo := db.NewLeaderboardRepository()
+	if err := leaderboardRepo.UpsertEntry(ctx, entry); err != nil {
+		log.Printf("Failed to update leaderboard entry for player %s: %v", p.Username, err)
+	} else {
+		log.Printf("Leaderboard updated for player %s: score=%d, kills=%d", p.Username, p.Score, p.Kills)
+	}
+}
+
+// grantAchievement stores an achievement a player reached, users who already have it are left as they are
+func (gs *GameServer) grantAchievement(grant game.AchievementGrant) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, err := primitive.ObjectIDFromHex(grant.PlayerID)
+	if err != nil {
+		log.Printf("Granting achievement: invalid player ID %s: %v", grant.PlayerID, err)
+		return
+	}
+
+	granted, err := db.NewUserRepository().GrantAchievement(ctx, userID, grant.AchievementID)
+	if err != nil {
+		log.Printf("Failed to grant achievement %s to player %s: %v", grant.AchievementID, grant.PlayerID, err)
+	} else if granted {
+		log.Printf("Player %s earned achievement %s", grant.PlayerID, grant.AchievementID)
+	}
+}
+
+// newLeaderboardEntry builds the leaderboard entry recording a player's death
+func newLeaderboardEntry(p *types.Player, sessID, sessName string) (*db.LeaderboardEntry, error) {
+	userID, err := primitive.ObjectIDFromHex(p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid player ID %s: %w", p.ID, err)
+	}
+
+	return &db.LeaderboardEntry{
+		UserID:      userID,
+		Username:    p.Username,
+		SessionID:   sessID,
+		SessionName: sessName,
+		Score:       p.Score,
+		Kills:       p.Kills,
+	}, nil
+}
+
+// GetSessionEngine returns the engine of a session running on this server, or nil if it isn't loaded
+func (gs *GameServer) GetSessionEngine(sessionID string) *game.Engine {
+	if session, exists := gs.GetLiveSession(sessionID); exists {
+		return session.Engine
+	}
+	return nil
+}
+
+// GetLiveSession returns the session if it's loaded on this server
+func (gs *GameServer) GetLiveSession(sessionID string) (*Session, bool) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	session, exists := gs.sessions[sessionID]
+	return session, exists
+}
+
+// GetLivePlayerCount returns how many players are in the session, and whether it's loaded on this server
+func (gs *GameServer) GetLivePlayerCount(sessionID string) (int, bool) {
+	session, exists := gs.GetLiveSession(sessionID)
+	if !exists {
+		return 0, false
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.PlayerCount, true
+}
+
 func (gs *GameServer) saveSessionToDatabase(session *Session) {
 	ctx := context.Background()
 	sessionRepo := db.NewGameSessionRepository()
@@ -267,9 +591,13 @@ func (gs *GameServer) saveSessionToDatabase(session *Session) {
 		dbSession, err := sessionRepo.FindByID(ctx, sessionObjectID)
 		if err != nil {
 			// Create new session
+			name := "Session " + session.ID[:8]
+			if gs.autoSessionNames {
+				name = game.NewSessionName()
+			}
 			dbSession = &db.GameSession{
 				ID:         sessionObjectID,
-				Name:       "Session " + session.ID[:8],
+				Name:       name,
 				MaxPlayers: 10,
 				IsActive:   true,
 			}
@@ -278,6 +606,7 @@ func (gs *GameServer) saveSessionToDatabase(session *Session) {
 
 		// Save engine state to session
 		session.Engine.SaveToSession(dbSession)
+		gs.saveActivity(session, dbSession)
 		sessionRepo.Update(ctx, dbSession)
 
 		log.Printf("Session %s saved to database", session.ID)
@@ -290,6 +619,8 @@ func (gs *GameServer) unregisterClient(client *WebsocketClient) {
 	if exists {
 		delete(gs.clients, client.ID)
 	}
+	kicked := client.kicked
+	superseded := client.superseded
 
 	session, sessionExists := gs.sessions[client.SessionID]
 	gs.mu.Unlock()
@@ -298,12 +629,109 @@ func (gs *GameServer) unregisterClient(client *WebsocketClient) {
 		return
 	}
 
-	if !sessionExists {
+	// A newer connection of the user has taken the player over
+	if !sessionExists || superseded {
 		return
 	}
 
+	// Give a dropped connection the chance to come back before the player leaves,
+	// their character stays in the game without input meanwhile
+	if gs.reconnectGracePeriod > 0 && !kicked && !gs.shuttingDown.Load() {
+		session.mu.Lock()
+		if session.disconnected == nil {
+			session.disconnected = make(map[string]disconnect)
+		}
+		session.disconnected[client.UserID.Hex()] = disconnect{at: time.Now(), userID: client.UserID, username: client.Username}
+		session.mu.Unlock()
+
+		session.Engine.SuspendPlayer(client.UserID.Hex())
+		log.Printf("Player %s (%s) lost connection to session %s, waiting %s for them to reconnect",
+			client.Username, client.UserID.Hex(), client.SessionID, gs.reconnectGracePeriod)
+		return
+	}
+
+	gs.removePlayer(session, client.UserID, client.Username)
+}
+
+// KickPlayer takes the player out of the session for good and closes their connection,
+// reporting whether they were in the session running on this server
+func (gs *GameServer) KickPlayer(sessionID, playerID string) bool {
+	gs.mu.Lock()
+	session, loaded := gs.sessions[sessionID]
+	var clients []*WebsocketClient
+	for _, client := range gs.clients {
+		if client.SessionID == sessionID && client.UserID.Hex() == playerID {
+			client.kicked = true
+			clients = append(clients, client)
+		}
+	}
+	gs.mu.Unlock()
+
+	if !loaded {
+		return false
+	}
+
+	// A player waiting to reconnect has no connection left to close. They're left for the game loop to
+	// remove, as it also registers players and would otherwise let one join a session being unloaded.
+	session.mu.Lock()
+	pending, waiting := session.disconnected[playerID]
+	if waiting {
+		pending.at = time.Time{}
+		pending.kicked = true
+		session.disconnected[playerID] = pending
+	}
+	session.mu.Unlock()
+
+	found := len(clients) > 0 || waiting
+	for _, player := range session.Engine.GetAllPlayers() {
+		found = found || player.ID == playerID
+	}
+	session.Engine.RemovePlayer(playerID)
+
+	// Closing the connection makes its read pump unregister the client, which takes care of the player count
+	for _, client := range clients {
+		client.Conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, kickedReason),
+			time.Now().Add(time.Second))
+		client.Conn.Close()
+	}
+	if found {
+		log.Printf("Player %s was kicked from session %s", playerID, sessionID)
+	}
+	return found
+}
+
+// removeDisconnectedPlayers takes the players who didn't reconnect within the grace period out of their sessions
+func (gs *GameServer) removeDisconnectedPlayers(now time.Time) {
+	type expiredDisconnect struct {
+		session *Session
+		disconnect
+	}
+	var expired []expiredDisconnect
+
+	gs.mu.RLock()
+	for _, session := range gs.sessions {
+		session.mu.Lock()
+		for userID, d := range session.disconnected {
+			if now.Sub(d.at) >= gs.reconnectGracePeriod {
+				delete(session.disconnected, userID)
+				expired = append(expired, expiredDisconnect{session: session, disconnect: d})
+			}
+		}
+		session.mu.Unlock()
+	}
+	gs.mu.RUnlock()
+
+	for _, d := range expired {
+		gs.removePlayer(d.session, d.userID, d.username)
+	}
+}
+
+// removePlayer takes a player whose connection is gone out of the session, saving and
+// unloading the session once its last player has left
+func (gs *GameServer) removePlayer(session *Session, userID primitive.ObjectID, username string) {
 	// Remove player from game engine
-	session.Engine.DisconnectPlayer(client.UserID.Hex())
+	session.Engine.DisconnectPlayer(userID.Hex())
 
 	// Decrement player count
 	session.mu.Lock()
@@ -311,34 +739,50 @@ func (gs *GameServer) unregisterClient(client *WebsocketClient) {
 	playerCount := session.PlayerCount
 	session.mu.Unlock()
 
-	// Clear user's current session in database
-	ctx := context.Background()
-	userRepo := db.NewUserRepository()
-	if user, err := userRepo.FindByID(ctx, client.UserID); err == nil {
-		user.CurrentSession = ""
-		userRepo.Update(ctx, user)
+	// The slot goes to the first user waiting for one, unless the session is about to be unloaded
+	unloading := playerCount == 0 && gs.sessionKeepAlive <= 0
+	if !unloading {
+		gs.freeSlot(session.ID)
+	}
+
+	// Clear user's current session in database, unless it's kept for rejoining after a refresh
+	if !gs.autoRejoinSession {
+		if err := db.NewUserRepository().ClearCurrentSession(context.Background(), userID, session.ID); err != nil {
+			log.Printf("Failed to clear current session of user %s: %v", userID.Hex(), err)
+		}
 	}
 
 	// If this was the last player, save session to database and clear from memory
 	if playerCount == 0 {
-		log.Printf("Last player left session %s, saving to database", client.SessionID)
+		log.Printf("Last player left session %s, saving to database", session.ID)
 
 		// Save session to database
 		gs.saveSessionToDatabase(session)
 
-		// Remove session from memory
-		gs.mu.Lock()
-		delete(gs.sessions, client.SessionID)
-		gs.mu.Unlock()
-
-		// Clear engine state
-		session.Engine.Clear()
+		if gs.sessionKeepAlive > 0 {
+			// Keep the engine warm for a quick rejoin, the Run loop unloads it once idle for too long
+			session.mu.Lock()
+			if session.PlayerCount == 0 {
+				session.idleSince = time.Now()
+			}
+			session.mu.Unlock()
+		} else {
+			// Remove session from memory, users waiting for it can join the reloaded session right away
+			gs.mu.Lock()
+			delete(gs.sessions, session.ID)
+			delete(gs.waitlists, session.ID)
+			gs.mu.Unlock()
+
+			// Clear engine state, keeping the inputs recorded since the last flush
+			gs.flushRecordedInputs(session)
+			session.Engine.Clear()
+		}
 	} else {
-		gs.broadcastPlayerLeftMessage(client.SessionID, client.UserID.Hex())
+		gs.broadcastPlayerLeftMessage(session.ID, userID.Hex())
 	}
 
 	log.Printf("Player %s (%s) left session %s (remaining: %d)",
-		client.Username, client.UserID.Hex(), client.SessionID, playerCount)
+		username, userID.Hex(), session.ID, playerCount)
 }
 
 func (gs *GameServer) broadcastMessage(message []byte) {
@@ -355,6 +799,10 @@ func (gs *GameServer) broadcastMessage(message []byte) {
 }
 
 func (gs *GameServer) broadcastPlayerJoinedMessage(sessionID string, player *types.Player) {
+	if gs.joinLeaveInDeltas {
+		return
+	}
+
 	msg := &protocol.GameMessage{
 		Type: protocol.MessageType_PLAYER_JOIN,
 		Payload: &protocol.GameMessage_PlayerJoin{
@@ -368,6 +816,10 @@ func (gs *GameServer) broadcastPlayerJoinedMessage(sessionID string, player *typ
 }
 
 func (gs *GameServer) broadcastPlayerLeftMessage(sessionID string, playerID string) {
+	if gs.joinLeaveInDeltas {
+		return
+	}
+
 	msg := &protocol.GameMessage{
 		Type: protocol.MessageType_PLAYER_LEAVE,
 		Payload: &protocol.GameMessage_PlayerLeave{
@@ -408,8 +860,16 @@ func (gs *GameServer) broadcastAllSessionStates() {
 		gs.mu.RLock()
 		for _, client := range gs.clients {
 			if client.SessionID == sessionID {
+				// Slow clients skip ticks, their next delta covers the skipped ones
+				if !client.throttle.due(len(client.Send), cap(client.Send)) {
+					continue
+				}
+
 				// Get player-specific delta (filtered to surrounding chunks)
 				delta := session.Engine.GetGameStateDeltaForPlayer(client.UserID.Hex())
+				if client.debug {
+					delta.DebugCounts = session.Engine.DebugCounts(client.UserID.Hex())
+				}
 
 				// Only send if there are changes
 				if !protocol.IsGameStateDeltaEmpty(delta) {
@@ -421,8 +881,62 @@ func (gs *GameServer) broadcastAllSessionStates() {
 	}
 }
 
+// sessionIDToJoin returns the session a connection joins: the requested one or, with auto
+// rejoin enabled, the user's last session when none was requested. rejoining reports the latter.
+func (gs *GameServer) sessionIDToJoin(requested string, user *db.User) (sessionID string, rejoining bool, err error) {
+	if requested != "" || !gs.autoRejoinSession {
+		return requested, false, nil
+	}
+	if user.CurrentSession == "" {
+		return "", false, errNoSessionToRejoin
+	}
+	return user.CurrentSession, true, nil
+}
+
+// MetricsResponse reports the server's goroutine and database worker usage
+type MetricsResponse struct {
+	Goroutines int           `json:"goroutines"`
+	Clients    int           `json:"clients"`
+	Sessions   int           `json:"sessions"`
+	DBWorkers  DBWorkerStats `json:"db_workers"`
+}
+
+// HandleMetrics reports goroutine and database worker queue usage, to requests bearing the debug stats token
+func (gs *GameServer) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if gs.debugStatsToken == "" {
+		utils.WriteJSONError(w, http.StatusNotFound, "Metrics are disabled")
+		return
+	}
+	if !gs.bearsDebugStatsToken(r) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	gs.mu.RLock()
+	response := MetricsResponse{
+		Goroutines: runtime.NumGoroutine(),
+		Clients:    len(gs.clients),
+		Sessions:   len(gs.sessions),
+		DBWorkers:  gs.dbWorkers.stats(),
+	}
+	gs.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // HandleWebSocket handles WebSocket connections
 func (gs *GameServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if gs.shuttingDown.Load() {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	// Extract and validate JWT token from query parameters
 	token := r.URL.Query().Get("token")
 	if token == "" {
@@ -438,25 +952,25 @@ func (gs *GameServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate JWT token
-	userID, err := auth.ValidateToken(token)
+	// Validate JWT token and fetch its user from the database
+	ctx := context.Background()
+	user, err := auth.AuthenticateActiveUser(ctx, token)
+	if errors.Is(err, auth.ErrUserInactive) {
+		http.Error(w, "Forbidden: user is inactive", http.StatusForbidden)
+		return
+	}
 	if err != nil {
-		log.Printf("Token validation error: %v", err)
-		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+		log.Printf("Authentication error: %v", err)
+		http.Error(w, "Unauthorized: invalid token or user not found", http.StatusUnauthorized)
 		return
 	}
 
-	// Fetch user from database
-	ctx := context.Background()
-	userRepo := db.NewUserRepository()
-	user, err := userRepo.FindByID(ctx, userID)
+	sessionID, rejoining, err := gs.sessionIDToJoin(r.URL.Query().Get("sessionId"), user)
 	if err != nil {
-		log.Printf("User lookup error: %v", err)
-		http.Error(w, "Unauthorized: user not found", http.StatusUnauthorized)
+		http.Error(w, "Session ID required, no session to rejoin", http.StatusBadRequest)
 		return
 	}
 
-	sessionID := r.URL.Query().Get("sessionId")
 	sessionRepo := db.NewGameSessionRepository()
 	sessionObjID, err := primitive.ObjectIDFromHex(sessionID)
 	if err != nil {
@@ -464,21 +978,61 @@ func (gs *GameServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	team := r.URL.Query().Get("team")
+	if team != "" {
+		if err := game.ValidateTeam(team); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	session, err := sessionRepo.FindByID(ctx, sessionObjID)
+	if rejoining && (err != nil || !session.IsActive) {
+		// Forget the stale session so the next connection doesn't try it again
+		if err := db.NewUserRepository().ClearCurrentSession(ctx, user.ID, sessionID); err != nil {
+			log.Printf("Failed to clear current session of user %s: %v", user.ID.Hex(), err)
+		}
+		http.Error(w, "Your last session no longer exists", http.StatusGone)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
 
+	gs.mu.RLock()
+	hasRoom := gs.hasRoomForSession(sessionID)
+	gs.mu.RUnlock()
+	if !hasRoom {
+		http.Error(w, "Server is at session capacity", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !gs.ReserveSlot(sessionID, user.ID.Hex(), session.MaxPlayers) {
+		http.Error(w, "Session is full", http.StatusBadRequest)
+		return
+	}
+
+	// Shutdown may have started while the user and session were looked up
+	if gs.shuttingDown.Load() {
+		gs.cancelReservation(sessionID, user.ID.Hex())
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	// Upgrade to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		gs.cancelReservation(sessionID, user.ID.Hex())
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
 
-	// Check if client wants binary protocol (via query parameter)
+	// Check if client wants binary protocol (via query parameter), falling back to the user's preference
 	useBinary := r.URL.Query().Get("protocol") == "binary"
+	if r.URL.Query().Get("protocol") == "" {
+		useBinary = user.Settings.PreferredProtocol == "binary"
+	}
 
 	client := &WebsocketClient{
 		ID:          uuid.New().String(),
@@ -490,6 +1044,9 @@ func (gs *GameServer) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		Send:        make(chan []byte, 256),
 		Server:      gs,
 		UseBinary:   useBinary,
+		Team:        team,
+		throttle:    newDeltaThrottle(config.AppConfig.DeltaThrottleThreshold, config.AppConfig.DeltaThrottleMaxInterval),
+		debug:       config.AppConfig.ClientDebugEnabled && r.URL.Query().Get("debug") == "true",
 	}
 
 	log.Printf("New client connected (ID: %s, User: %s, Session: %s, Binary: %v)",
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+)
+
+func TestHandleWebSocketRefusedDuringShutdown(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	gs := NewGameServer()
+	gs.shuttingDown.Store(true)
+
+	req := httptest.NewRequest(http.MethodGet, "/ws?token=any&sessionId=any", nil)
+	rec := httptest.NewRecorder()
+
+	gs.HandleWebSocket(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("HandleWebSocket() status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandleMetricsReportsDBWorkers(t *testing.T) {
+	config.AppConfig = &config.Config{DBWorkers: 3, DebugStatsToken: "debug-token"}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer debug-token")
+	gs.HandleMetrics(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleMetrics() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var metrics MetricsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&metrics); err != nil {
+		t.Fatalf("metrics body is not valid JSON: %v", err)
+	}
+	if metrics.Goroutines <= 0 {
+		t.Errorf("goroutines = %d, want a positive count", metrics.Goroutines)
+	}
+	if metrics.DBWorkers.Workers != 3 || metrics.DBWorkers.QueueSize != 3*dbWorkerQueueFactor {
+		t.Errorf("db workers = %+v, want 3 workers with a queue of %d", metrics.DBWorkers, 3*dbWorkerQueueFactor)
+	}
+}
+
+func TestHandleMetricsRequiresToken(t *testing.T) {
+	tests := []struct {
+		token  string
+		header string
+		want   int
+	}{
+		{"", "", http.StatusNotFound},
+		{"", "Bearer ", http.StatusNotFound},
+		{"debug-token", "", http.StatusUnauthorized},
+		{"debug-token", "Bearer wrong-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		config.AppConfig = &config.Config{DebugStatsToken: tt.token}
+		gs := NewGameServer()
+		defer gs.dbWorkers.stop()
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		gs.HandleMetrics(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("token %q, Authorization %q: HandleMetrics() status = %d, want %d", tt.token, tt.header, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleDebugSessionsReportsEngineStats(t *testing.T) {
+	config.AppConfig = &config.Config{DebugStatsEnabled: true, DebugStatsToken: "debug-token"}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	player := session.Engine.ConnectPlayer("player", "player")
+	session.Engine.Update()
+	session.Engine.GetGameStateDeltaForPlayer(player.ID)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/sessions", nil)
+	req.Header.Set("Authorization", "Bearer debug-token")
+	gs.HandleDebugSessions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleDebugSessions() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var stats []SessionStatsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("stats body is not valid JSON: %v", err)
+	}
+	if len(stats) != 1 || stats[0].SessionID != "session" {
+		t.Fatalf("stats = %+v, want the loaded session", stats)
+	}
+	if stats[0].UpdateCount != 1 || stats[0].DeltaCalcCount != 1 {
+		t.Errorf("update count %d, delta calc count %d, want both counted without debug mode", stats[0].UpdateCount, stats[0].DeltaCalcCount)
+	}
+	if stats[0].Players != 1 || stats[0].ConnectedPlayers != 1 || stats[0].LastReportedAt == nil {
+		t.Errorf("stats = %+v, want the connected player and a report time", stats[0])
+	}
+}
+
+func TestHandleDebugSessionsDisabled(t *testing.T) {
+	for _, cfg := range []*config.Config{{}, {DebugStatsEnabled: true}} {
+		config.AppConfig = cfg
+		gs := NewGameServer()
+		defer gs.dbWorkers.stop()
+
+		rec := httptest.NewRecorder()
+		gs.HandleDebugSessions(rec, httptest.NewRequest(http.MethodGet, "/api/v1/debug/sessions", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("enabled %v: HandleDebugSessions() status = %d, want %d", cfg.DebugStatsEnabled, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestHandleDebugSessionsRequiresToken(t *testing.T) {
+	config.AppConfig = &config.Config{DebugStatsEnabled: true, DebugStatsToken: "debug-token"}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	for _, header := range []string{"", "Bearer wrong-token", "debug-token-but-longer"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/sessions", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		gs.HandleDebugSessions(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: HandleDebugSessions() status = %d, want %d", header, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestHasRoomForSessionRespectsLimit(t *testing.T) {
+	config.AppConfig = &config.Config{MaxLoadedSessions: 2}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	gs.sessions["first"] = &Session{ID: "first"}
+	if !gs.hasRoomForSession("second") {
+		t.Error("expected room for a second session")
+	}
+
+	gs.sessions["second"] = &Session{ID: "second"}
+	if gs.hasRoomForSession("third") {
+		t.Error("expected no room for a third session")
+	}
+	if !gs.hasRoomForSession("first") {
+		t.Error("expected a loaded session to always have room")
+	}
+
+	gs.maxLoadedSessions = 0
+	if !gs.hasRoomForSession("third") {
+		t.Error("expected no limit when the maximum is 0")
+	}
+}
+
+func newIdleTestSession(gs *GameServer, id string, idleSince time.Time) *Session {
+	session := &Session{ID: id, Engine: game.NewEngine(id), idleSince: idleSince}
+	session.Engine.ConnectPlayer("player", "Player")
+	session.Engine.DisconnectPlayer("player")
+	gs.sessions[id] = session
+	return session
+}
+
+func TestIdleSessionsStayLoadedWithinKeepAlive(t *testing.T) {
+	config.AppConfig = &config.Config{SessionKeepAlive: time.Minute}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	leftAt := time.Now()
+	session := newIdleTestSession(gs, "idle", leftAt)
+	gs.sessions["busy"] = &Session{ID: "busy", Engine: game.NewEngine("busy"), PlayerCount: 1}
+
+	gs.unloadIdleSessions(leftAt.Add(30 * time.Second))
+	if gs.GetSessionEngine("idle") == nil {
+		t.Fatal("expected the session to stay loaded within the keep-alive period")
+	}
+	if len(session.Engine.GetAllPlayers()) != 1 {
+		t.Error("expected the warm session to keep its players")
+	}
+
+	gs.unloadIdleSessions(leftAt.Add(time.Minute))
+	if gs.GetSessionEngine("idle") != nil {
+		t.Error("expected the session to be unloaded after the keep-alive period")
+	}
+	if len(session.Engine.GetAllPlayers()) != 0 {
+		t.Error("expected the unloaded session's engine to be cleared")
+	}
+	if gs.GetSessionEngine("busy") == nil {
+		t.Error("expected a session with players to stay loaded")
+	}
+}
+
+func TestIdleSessionsMakeRoomForNewSessions(t *testing.T) {
+	config.AppConfig = &config.Config{MaxLoadedSessions: 2, SessionKeepAlive: time.Minute}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	now := time.Now()
+	newIdleTestSession(gs, "newer", now)
+	older := newIdleTestSession(gs, "older", now.Add(-time.Second))
+
+	if !gs.hasRoomForSession("third") {
+		t.Error("expected idle sessions to count as room")
+	}
+	if gs.oldestIdleSession() != older {
+		t.Error("expected the session idle the longest to be unloaded first")
+	}
+
+	for _, session := range gs.sessions {
+		session.idleSince = time.Time{}
+	}
+	if gs.hasRoomForSession("third") {
+		t.Error("expected no room once every session has players")
+	}
+}
+
+func TestGetLivePlayerCount(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	gs := NewGameServer()
+	defer gs.dbWorkers.stop()
+
+	gs.sessions["session"] = &Session{ID: "session", Engine: game.NewEngine("session"), PlayerCount: 3}
+
+	if count, loaded := gs.GetLivePlayerCount("session"); !loaded || count != 3 {
+		t.Errorf("GetLivePlayerCount() = %d, %v; want 3 players in a loaded session", count, loaded)
+	}
+	if _, loaded := gs.GetLivePlayerCount("other"); loaded {
+		t.Error("expected a session that isn't loaded to be reported as such")
+	}
+}
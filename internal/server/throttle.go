@@ -0,0 +1,54 @@
+package server
+
+import "github.com/besuhoff/dungeon-game-go/internal/config"
+
+// deltaThrottle adapts how often a client gets game state deltas to how fast it
+// drains its send buffer. Deltas are computed against the last state the client
+// was sent, so the delta sent after skipped ticks covers all of them.
+type deltaThrottle struct {
+	threshold   float64 // Share of the send buffer counting as near-full
+	maxInterval int
+
+	interval   int // Ticks between deltas, 1 sends every tick
+	skipped    int // Ticks since the last delta
+	clearSends int // Deltas sent in a row while the buffer had room
+}
+
+// newDeltaThrottle returns a throttle for a client, or nil when throttling is disabled
+func newDeltaThrottle(threshold float64, maxInterval int) *deltaThrottle {
+	if threshold <= 0 || maxInterval <= 1 {
+		return nil
+	}
+	return &deltaThrottle{threshold: threshold, maxInterval: maxInterval, interval: 1}
+}
+
+// due reports whether the client should get a delta this tick. A near-full buffer
+// skips the tick and sends deltas less often, a buffer that keeps having room
+// brings the rate back up.
+func (t *deltaThrottle) due(buffered, capacity int) bool {
+	if t == nil {
+		return true
+	}
+
+	t.skipped++
+	if t.skipped < t.interval {
+		return false
+	}
+
+	if capacity > 0 && float64(buffered)/float64(capacity) >= t.threshold {
+		t.interval = min(t.interval*2, t.maxInterval)
+		t.skipped = 0
+		t.clearSends = 0
+		return false
+	}
+
+	t.skipped = 0
+	if t.interval > 1 {
+		t.clearSends++
+		if t.clearSends >= config.ThrottleRecoveryDeltas {
+			t.interval /= 2
+			t.clearSends = 0
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+)
+
+func TestDeltaThrottleDisabled(t *testing.T) {
+	throttle := newDeltaThrottle(0, 4)
+	if throttle != nil {
+		t.Fatal("expected no throttle without a threshold")
+	}
+	for i := 0; i < 5; i++ {
+		if !throttle.due(256, 256) {
+			t.Fatal("expected a disabled throttle to send every tick, even with a full buffer")
+		}
+	}
+}
+
+func TestDeltaThrottleSlowsDownNearFullBuffer(t *testing.T) {
+	throttle := newDeltaThrottle(0.5, 4)
+
+	if !throttle.due(10, 100) {
+		t.Fatal("expected a delta while the buffer has room")
+	}
+	if throttle.due(60, 100) {
+		t.Fatal("expected the tick to be skipped with a near-full buffer")
+	}
+	if throttle.interval != 2 {
+		t.Fatalf("expected deltas every 2 ticks, got every %d", throttle.interval)
+	}
+
+	// Every other tick while the buffer drains
+	if throttle.due(10, 100) {
+		t.Fatal("expected the tick after a skipped one to be skipped too")
+	}
+	if !throttle.due(10, 100) {
+		t.Fatal("expected a coalesced delta after the interval passed")
+	}
+
+	// The interval stops growing at the configured maximum
+	for i := 0; i < 20; i++ {
+		throttle.due(100, 100)
+	}
+	if throttle.interval != 4 {
+		t.Fatalf("expected the interval capped at 4 ticks, got %d", throttle.interval)
+	}
+}
+
+func TestDeltaThrottleRecoversWhenBufferDrains(t *testing.T) {
+	throttle := newDeltaThrottle(0.5, 4)
+	throttle.interval = 4
+
+	sent := 0
+	for sent < config.ThrottleRecoveryDeltas {
+		if throttle.due(0, 100) {
+			sent++
+		}
+	}
+	if throttle.interval != 2 {
+		t.Fatalf("expected deltas every 2 ticks after %d clear deltas, got every %d", sent, throttle.interval)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import "time"
+
+// waitlistEntry is a user waiting for a slot in a full session
+type waitlistEntry struct {
+	userID    string
+	expiresAt time.Time
+
+	// Set once a freed slot was reserved for the user, they have until expiresAt to take it
+	notified bool
+}
+
+// JoinWaitlist puts the user at the end of the session's waitlist, or keeps their place if
+// they are already on it, and returns their position. Returns false when waitlists are off.
+func (gs *GameServer) JoinWaitlist(sessionID, userID string) (int, bool) {
+	if gs.waitlistTTL <= 0 {
+		return 0, false
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	gs.pruneWaitlist(sessionID, now)
+	if _, _, found := gs.waitlistPosition(sessionID, userID, now); !found {
+		gs.waitlists[sessionID] = append(gs.waitlists[sessionID], &waitlistEntry{userID: userID, expiresAt: now.Add(gs.waitlistTTL)})
+	}
+
+	position, _, _ := gs.waitlistPosition(sessionID, userID, now)
+	return position, true
+}
+
+// WaitlistStatus returns the user's position on the session's waitlist, or whether a slot is
+// reserved for them and they can join now. Polling keeps the user on the waitlist.
+// Returns false when the user isn't on it.
+func (gs *GameServer) WaitlistStatus(sessionID, userID string) (int, bool, bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	gs.pruneWaitlist(sessionID, now)
+	return gs.waitlistPosition(sessionID, userID, now)
+}
+
+// waitlistPosition finds the user on the waitlist and extends their entry while they still wait.
+// Must be called with gs.mu held.
+func (gs *GameServer) waitlistPosition(sessionID, userID string, now time.Time) (position int, ready, found bool) {
+	for _, entry := range gs.waitlists[sessionID] {
+		if entry.notified {
+			if entry.userID == userID {
+				return 0, true, true
+			}
+			continue
+		}
+
+		position++
+		if entry.userID == userID {
+			entry.expiresAt = now.Add(gs.waitlistTTL)
+			return position, false, true
+		}
+	}
+	return 0, false, false
+}
+
+// pruneWaitlist drops users who stopped polling and hands the slots nobody took to the next
+// users in line. Must be called with gs.mu held.
+func (gs *GameServer) pruneWaitlist(sessionID string, now time.Time) {
+	freed := 0
+	var waitlist []*waitlistEntry
+	for _, entry := range gs.waitlists[sessionID] {
+		if now.After(entry.expiresAt) {
+			if entry.notified {
+				freed++
+			}
+			continue
+		}
+		waitlist = append(waitlist, entry)
+	}
+	gs.setWaitlist(sessionID, waitlist)
+
+	gs.promoteWaitlist(sessionID, freed, now)
+}
+
+// promoteWaitlist reserves freed slots for the first users waiting for one. Must be called with gs.mu held.
+func (gs *GameServer) promoteWaitlist(sessionID string, slots int, now time.Time) {
+	for _, entry := range gs.waitlists[sessionID] {
+		if slots <= 0 {
+			return
+		}
+		if entry.notified {
+			continue
+		}
+
+		reservations := gs.reservations[sessionID]
+		if reservations == nil {
+			reservations = make(map[string]time.Time)
+			gs.reservations[sessionID] = reservations
+		}
+		entry.notified = true
+		entry.expiresAt = now.Add(gs.reservationTTL)
+		reservations[entry.userID] = entry.expiresAt
+		slots--
+	}
+}
+
+// freeSlot passes a slot someone left on to the waitlist
+func (gs *GameServer) freeSlot(sessionID string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	gs.pruneWaitlist(sessionID, now)
+	gs.promoteWaitlist(sessionID, 1, now)
+}
+
+// leaveWaitlist takes the user off the waitlist once they got their slot. Must be called with gs.mu held.
+func (gs *GameServer) leaveWaitlist(sessionID, userID string) {
+	var waitlist []*waitlistEntry
+	for _, entry := range gs.waitlists[sessionID] {
+		if entry.userID != userID {
+			waitlist = append(waitlist, entry)
+		}
+	}
+	gs.setWaitlist(sessionID, waitlist)
+}
+
+// setWaitlist stores the session's waitlist, dropping it once empty. Must be called with gs.mu held.
+func (gs *GameServer) setWaitlist(sessionID string, waitlist []*waitlistEntry) {
+	if len(waitlist) == 0 {
+		delete(gs.waitlists, sessionID)
+		return
+	}
+	gs.waitlists[sessionID] = waitlist
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestWaitlistGetsSlotWhenPlayerLeaves(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	gs.reconnectGracePeriod = 0
+	gs.waitlistTTL = time.Minute
+	session := &Session{ID: "session", Engine: game.NewEngine("session")}
+	gs.sessions[session.ID] = session
+	connectTestClient(gs, session, "watcher")
+	client, _ := connectTestClient(gs, session, "player")
+
+	if gs.ReserveSlot(session.ID, "first", 2) {
+		t.Fatal("expected the session to be full")
+	}
+	if position, ok := gs.JoinWaitlist(session.ID, "first"); !ok || position != 1 {
+		t.Fatalf("expected the first user in line, got position %d, ok %v", position, ok)
+	}
+	if position, _ := gs.JoinWaitlist(session.ID, "second"); position != 2 {
+		t.Fatalf("expected the second user behind the first, got position %d", position)
+	}
+	if position, _ := gs.JoinWaitlist(session.ID, "first"); position != 1 {
+		t.Errorf("expected joining again to keep the user's place, got position %d", position)
+	}
+
+	gs.unregisterClient(client)
+
+	if _, ready, found := gs.WaitlistStatus(session.ID, "first"); !found || !ready {
+		t.Fatalf("expected the freed slot to be reserved for the first user, ready %v, found %v", ready, found)
+	}
+	if position, ready, _ := gs.WaitlistStatus(session.ID, "second"); ready || position != 1 {
+		t.Errorf("expected the second user to move up and keep waiting, position %d, ready %v", position, ready)
+	}
+	if gs.ReserveSlot(session.ID, "someone-else", 2) {
+		t.Error("expected the freed slot to be held for the waitlist")
+	}
+
+	if !gs.ReserveSlot(session.ID, "first", 2) {
+		t.Fatal("expected the notified user to get their slot")
+	}
+	if _, _, found := gs.WaitlistStatus(session.ID, "first"); found {
+		t.Error("expected the user to leave the waitlist once they joined")
+	}
+}
+
+func TestWaitlistIsDroppedWhenLastPlayerLeaves(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// Saving the session on its way out needs a database, even with nothing to save
+	mt.Run("unload", func(mt *mtest.T) {
+		previous := db.Database
+		db.Database = mt.DB
+		defer func() { db.Database = previous }()
+
+		gs := newReconnectTestServer(mt.T)
+		gs.reconnectGracePeriod = 0
+		gs.waitlistTTL = time.Minute
+		session := &Session{ID: "session", Engine: game.NewEngine("session")}
+		gs.sessions[session.ID] = session
+		client, _ := connectTestClient(gs, session, "player")
+		gs.JoinWaitlist(session.ID, "first")
+
+		gs.unregisterClient(client)
+
+		if gs.GetSessionEngine(session.ID) != nil {
+			mt.Fatal("expected the empty session to be unloaded")
+		}
+		if _, ready, found := gs.WaitlistStatus(session.ID, "first"); found || ready {
+			mt.Errorf("expected no slot to be promoted in an unloaded session, ready %v, found %v", ready, found)
+		}
+		if !gs.ReserveSlot(session.ID, "someone-else", 1) {
+			mt.Error("expected nothing to be held in the unloaded session")
+		}
+	})
+}
+
+func TestWaitlistEntriesExpire(t *testing.T) {
+	gs := newReconnectTestServer(t)
+	gs.waitlistTTL = time.Minute
+
+	gs.JoinWaitlist("session", "gone")
+	gs.JoinWaitlist("session", "polling")
+	gs.waitlists["session"][0].expiresAt = time.Now().Add(-time.Second)
+
+	if _, _, found := gs.WaitlistStatus("session", "gone"); found {
+		t.Error("expected a user who stopped polling to drop off the waitlist")
+	}
+	if position, _, _ := gs.WaitlistStatus("session", "polling"); position != 1 {
+		t.Errorf("expected the next user to move up, got position %d", position)
+	}
+
+	// A reserved slot nobody took goes to the next user in line
+	gs.JoinWaitlist("session", "next")
+	gs.freeSlot("session")
+	gs.waitlists["session"][0].expiresAt = time.Now().Add(-time.Second)
+
+	if _, ready, _ := gs.WaitlistStatus("session", "next"); !ready {
+		t.Error("expected the untaken slot to be handed on")
+	}
+}
+
+func TestWaitlistDisabled(t *testing.T) {
+	gs := newReconnectTestServer(t)
+
+	if _, ok := gs.JoinWaitlist("session", "user"); ok {
+		t.Error("expected no waitlist without a TTL")
+	}
+}
@@ -22,7 +22,21 @@ type WebsocketClient struct {
 	Conn        *websocket.Conn
 	Send        chan []byte
 	Server      *GameServer
-	UseBinary   bool // Whether client prefers binary protocol
+	UseBinary   bool   // Whether client prefers binary protocol
+	Team        string // Team the player picked on joining, ignored once they're in the session
+
+	// Sends deltas less often while the client can't keep up, nil when disabled. Only used by the game loop.
+	throttle *deltaThrottle
+
+	// Deltas carry entity counts around the player for the client's debug HUD
+	debug bool
+
+	// Set when the host kicked the player, who then leaves without a reconnect grace period. Guarded by the server's mu.
+	kicked bool
+
+	// Set when a newer connection of the user to the same session took the player over,
+	// this one then leaves without touching the player. Guarded by the server's mu.
+	superseded bool
 }
 
 // Client methods
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// dbWorkerQueueFactor sets how many pending jobs each worker may have queued
+const dbWorkerQueueFactor = 64
+
+// dbWorkerPendingFactor sets how many jobs per worker may wait for room in a full queue,
+// so a stalled database doesn't grow the backlog without limit
+const dbWorkerPendingFactor = 256
+
+// dbWorkerPool runs database writes on a fixed number of goroutines, so a burst
+// of game events can't spawn an unbounded number of concurrent writers
+type dbWorkerPool struct {
+	jobs       chan func()
+	wg         sync.WaitGroup
+	workers    int
+	maxPending int
+
+	busy    atomic.Int64
+	dropped atomic.Int64
+
+	// Guards submissions against a concurrent stop closing the jobs channel
+	mu      sync.RWMutex
+	stopped bool
+
+	// Jobs that found the queue full, waiting to be queued again
+	pendingMu sync.Mutex
+	pending   []func()
+}
+
+func newDBWorkerPool(workers int) *dbWorkerPool {
+	workers = max(workers, 1)
+	pool := &dbWorkerPool{
+		jobs:       make(chan func(), workers*dbWorkerQueueFactor),
+		workers:    workers,
+		maxPending: workers * dbWorkerPendingFactor,
+	}
+
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+func (p *dbWorkerPool) work() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		p.busy.Add(1)
+		job()
+		p.busy.Add(-1)
+	}
+}
+
+// submitOrRetry queues the job without blocking the caller. When the queue is full the job
+// waits in the pool until retryPending finds room for it, unless too many jobs are waiting
+// already, in which case it's dropped and counted. After stop it runs right away.
+func (p *dbWorkerPool) submitOrRetry(job func()) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		job()
+		return
+	}
+
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+
+	// Jobs already waiting go first
+	if len(p.pending) == 0 {
+		select {
+		case p.jobs <- job:
+			return
+		default:
+		}
+	}
+	if len(p.pending) >= p.maxPending {
+		p.dropped.Add(1)
+		return
+	}
+	p.pending = append(p.pending, job)
+}
+
+// retryPending queues as many of the waiting jobs as the queue has room for
+func (p *dbWorkerPool) retryPending() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		return
+	}
+
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+
+	for len(p.pending) > 0 {
+		select {
+		case p.jobs <- p.pending[0]:
+			p.pending[0] = nil
+			p.pending = p.pending[1:]
+		default:
+			return
+		}
+	}
+}
+
+// stop waits for the queued jobs to finish, runs the ones still waiting for
+// room and shuts the workers down
+func (p *dbWorkerPool) stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	p.pendingMu.Lock()
+	pending := p.pending
+	p.pending = nil
+	p.pendingMu.Unlock()
+
+	for _, job := range pending {
+		job()
+	}
+}
+
+// DBWorkerStats describes the database worker pool load
+type DBWorkerStats struct {
+	Workers    int   `json:"workers"`
+	Busy       int64 `json:"busy"`
+	QueueDepth int   `json:"queue_depth"`
+	QueueSize  int   `json:"queue_size"`
+	Pending    int   `json:"pending"`
+	Dropped    int64 `json:"dropped"`
+}
+
+func (p *dbWorkerPool) stats() DBWorkerStats {
+	p.pendingMu.Lock()
+	pending := len(p.pending)
+	p.pendingMu.Unlock()
+
+	return DBWorkerStats{
+		Workers:    p.workers,
+		Busy:       p.busy.Load(),
+		QueueDepth: len(p.jobs),
+		QueueSize:  cap(p.jobs),
+		Pending:    pending,
+		Dropped:    p.dropped.Load(),
+	}
+}
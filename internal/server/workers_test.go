@@ -0,0 +1,148 @@
+package server
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDBWorkerPoolBoundsConcurrency(t *testing.T) {
+	pool := newDBWorkerPool(2)
+
+	var running, maxRunning atomic.Int64
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+
+	job := func() {
+		current := running.Add(1)
+		for {
+			seen := maxRunning.Load()
+			if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+	}
+
+	for i := 0; i < 2; i++ {
+		pool.submitOrRetry(func() {
+			started.Done()
+			job()
+		})
+	}
+	started.Wait()
+
+	for i := 0; i < 10; i++ {
+		pool.submitOrRetry(job)
+	}
+
+	if stats := pool.stats(); stats.Busy != 2 || stats.QueueDepth != 10 || stats.Pending != 0 {
+		t.Errorf("stats = %+v, want 2 busy workers and 10 queued jobs", stats)
+	}
+
+	close(release)
+	pool.stop()
+
+	if got := maxRunning.Load(); got != 2 {
+		t.Errorf("max concurrent jobs = %d, want 2", got)
+	}
+}
+
+func TestDBWorkerPoolDropsWhenTooManyJobsWait(t *testing.T) {
+	pool := newDBWorkerPool(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.submitOrRetry(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	for i := 0; i < dbWorkerQueueFactor+dbWorkerPendingFactor; i++ {
+		pool.submitOrRetry(func() {})
+	}
+	if stats := pool.stats(); stats.Pending != dbWorkerPendingFactor || stats.Dropped != 0 {
+		t.Fatalf("stats = %+v, want %d jobs waiting and none dropped", stats, dbWorkerPendingFactor)
+	}
+
+	pool.submitOrRetry(func() {})
+	if stats := pool.stats(); stats.Pending != dbWorkerPendingFactor || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want the job over the limit dropped", stats)
+	}
+
+	close(release)
+	pool.stop()
+}
+
+func TestDBWorkerPoolRetriesWaitingJobs(t *testing.T) {
+	pool := newDBWorkerPool(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.submitOrRetry(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	for i := 0; i < dbWorkerQueueFactor; i++ {
+		pool.submitOrRetry(func() {})
+	}
+
+	var ran atomic.Int64
+	pool.submitOrRetry(func() { ran.Add(1) })
+	if stats := pool.stats(); stats.Pending != 1 || stats.Dropped != 0 {
+		t.Fatalf("stats = %+v, want the job waiting for room instead of being dropped", stats)
+	}
+
+	// Still no room, the job keeps waiting
+	pool.retryPending()
+	if pending := pool.stats().Pending; pending != 1 {
+		t.Fatalf("pending = %d, want 1 while the queue is full", pending)
+	}
+
+	close(release)
+	for pool.stats().QueueDepth > 0 {
+		runtime.Gosched()
+	}
+	pool.retryPending()
+	pool.stop()
+
+	if ran.Load() != 1 {
+		t.Error("expected the waiting job to run once the queue had room")
+	}
+}
+
+func TestDBWorkerPoolRunsWaitingJobsOnStop(t *testing.T) {
+	pool := newDBWorkerPool(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool.submitOrRetry(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	for i := 0; i < dbWorkerQueueFactor; i++ {
+		pool.submitOrRetry(func() {})
+	}
+
+	var ran atomic.Int64
+	pool.submitOrRetry(func() { ran.Add(1) })
+	close(release)
+	pool.stop()
+
+	if ran.Load() != 1 {
+		t.Error("expected stop() to run the job still waiting for room")
+	}
+
+	pool.submitOrRetry(func() { ran.Add(1) })
+	if ran.Load() != 2 {
+		t.Error("expected jobs that must not be lost to run right away after stop()")
+	}
+}
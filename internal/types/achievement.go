@@ -0,0 +1,28 @@
+package types
+
+// Achievement is a milestone a player earns once and keeps across sessions
+type Achievement struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Kills       int     `json:"-"` // Kills in a single life needed to earn it
+	Survival    float64 `json:"-"` // Seconds alive in a single life needed to earn it
+}
+
+const (
+	AchievementFirstKill = "first_kill"
+	AchievementKills100  = "kills_100"
+	AchievementSurvive10 = "survive_10m"
+)
+
+// Achievements lists every achievement in the order clients show them
+var Achievements = []Achievement{
+	{ID: AchievementFirstKill, Name: "First Blood", Description: "Get your first kill", Kills: 1},
+	{ID: AchievementKills100, Name: "Centurion", Description: "Get 100 kills in a single life", Kills: 100},
+	{ID: AchievementSurvive10, Name: "Survivor", Description: "Stay alive for 10 minutes", Survival: 10 * 60},
+}
+
+// IsEarnedBy reports whether the player meets the achievement's requirements, given how long they have been alive
+func (a Achievement) IsEarnedBy(p *Player, survival float64) bool {
+	return (a.Kills == 0 || p.Kills >= a.Kills) && (a.Survival == 0 || survival >= a.Survival)
+}
@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// DamageContributors records when each player last damaged an entity
+type DamageContributors map[string]time.Time
+
+// Record marks the player as having just damaged the entity
+func (d DamageContributors) Record(playerID string, at time.Time) {
+	d[playerID] = at
+}
+
+// Prune drops contributions older than the window
+func (d DamageContributors) Prune(window time.Duration, now time.Time) {
+	for playerID, at := range d {
+		if now.Sub(at) > window {
+			delete(d, playerID)
+		}
+	}
+}
+
+// Recent returns the players who damaged the entity within the window
+func (d DamageContributors) Recent(window time.Duration, now time.Time) []string {
+	playerIDs := []string{}
+	for playerID, at := range d {
+		if now.Sub(at) <= window {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	return playerIDs
+}
@@ -10,6 +10,12 @@ const (
 	BonusTypeAidKit  = "aid_kit"
 	BonusTypeGoggles = "goggles"
 	BonusTypeChest   = "chest"
+	BonusTypeKey     = "key"
+	BonusTypePortal  = "portal" // not picked up, takes players touching it to its destination
+
+	BonusTypeDoubleDamage = "double_damage"
+	BonusTypeRapidFire    = "rapid_fire"
+	BonusTypeSpeedBoost   = "speed_boost"
 )
 
 // Bonus represents a pickup item
@@ -21,6 +27,10 @@ type Bonus struct {
 	DroppedAt  time.Time       `json:"-"`
 	PickedUpAt time.Time       `json:"-"`
 	Inventory  []InventoryItem `json:"inventory"`
+
+	// Portals only
+	Destination *Vector2  `json:"-"`
+	ExpiresAt   time.Time `json:"-"` // Zero for portals that never close
 }
 
 func (b *Bonus) IsVisibleToPlayer(player *Player) bool {
@@ -43,14 +53,27 @@ func (b *Bonus) IsVisibleToPlayer(player *Player) bool {
 		bonusSize = config.GogglesSize
 	case BonusTypeChest:
 		bonusSize = config.ChestSize
+	case BonusTypeKey:
+		bonusSize = config.KeySize
+	case BonusTypePortal:
+		bonusSize = config.PortalSize
+	case BonusTypeDoubleDamage, BonusTypeRapidFire, BonusTypeSpeedBoost:
+		bonusSize = config.PowerUpSize
 	}
 	return distance <= detectionDistance+bonusSize
 }
 
+func (b *Bonus) IsPowerUp() bool {
+	return b.Type == BonusTypeDoubleDamage || b.Type == BonusTypeRapidFire || b.Type == BonusTypeSpeedBoost
+}
+
 func (b *Bonus) Clone() *Bonus {
 	clone := *b
 	clone.Position = &Vector2{X: b.Position.X, Y: b.Position.Y}
 	clone.Inventory = make([]InventoryItem, len(b.Inventory))
 	copy(clone.Inventory, b.Inventory)
+	if b.Destination != nil {
+		clone.Destination = &Vector2{X: b.Destination.X, Y: b.Destination.Y}
+	}
 	return &clone
 }
@@ -11,6 +11,7 @@ import (
 type Bullet struct {
 	ScreenObject
 	Velocity   *Vector2  `json:"velocity"`
+	Origin     *Vector2  `json:"origin,omitempty"` // Where the bullet was fired from, set when trails are enabled
 	OwnerID    string    `json:"ownerId"`
 	IsEnemy    bool      `json:"isEnemy"`
 	EnemyType  string    `json:"enemyType"`
@@ -70,5 +71,8 @@ func (b *Bullet) Clone() *Bullet {
 	clone := *b
 	clone.Position = &Vector2{X: b.Position.X, Y: b.Position.Y}
 	clone.Velocity = &Vector2{X: b.Velocity.X, Y: b.Velocity.Y}
+	if b.Origin != nil {
+		clone.Origin = &Vector2{X: b.Origin.X, Y: b.Origin.Y}
+	}
 	return &clone
 }
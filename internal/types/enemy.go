@@ -11,15 +11,65 @@ import (
 // Enemy represents an enemy in the game
 type Enemy struct {
 	ScreenObject
-	Type       string    `json:"type"`
-	Rotation   float64   `json:"rotation"` // rotation in degrees
-	Lives      float32   `json:"lives"`
-	WallID     string    `json:"wallId"`
-	Direction  int8      `json:"-"` // patrol direction: 1 or -1
-	ShootDelay float64   `json:"-"`
-	LastShot   time.Time `json:"-"`
-	IsAlive    bool      `json:"isAlive"`
-	DeadTimer  float64   `json:"-"`
+	Type     string  `json:"type"`
+	Rotation float64 `json:"rotation"` // rotation in degrees
+	Lives    float32 `json:"lives"`
+	WallID   string  `json:"wallId"`
+	// Guards walk between WallID and SecondWallID instead of along a single wall
+	SecondWallID string    `json:"secondWallId,omitempty"`
+	Direction    int8      `json:"-"` // patrol direction: 1 or -1
+	ShootDelay   float64   `json:"-"`
+	MeleeDelay   float64   `json:"-"` // seconds until the enemy can hit a player within reach again
+	LastShot     time.Time `json:"-"`
+	IsAlive      bool      `json:"isAlive"`
+	DeadTimer    float64   `json:"-"`
+	// Armored enemies only take damage from armor-piercing weapons
+	Armored bool `json:"armored,omitempty"`
+	// Archetype scales the lives, speed and reward of the enemy's type, empty for a grunt
+	Archetype string `json:"archetype,omitempty"`
+	// Players who recently hurt the enemy, for assist rewards
+	DamageContributors DamageContributors `json:"-"`
+	// Summoners count down to their next minion and keep track of their living minions,
+	// minions remember who summoned them
+	SummonTimer float64 `json:"-"`
+	Minions     int     `json:"-"`
+	SummonerID  string  `json:"-"`
+	// Money a thief grabbed from a player, dropped when it dies
+	StolenMoney int `json:"-"`
+	// Roaming enemies lost the wall they patrolled and wander around instead
+	Roaming bool `json:"-"`
+	Poison
+}
+
+const (
+	EnemyArchetypeGrunt = "grunt"
+	EnemyArchetypeBrute = "brute"
+	EnemyArchetypeScout = "scout"
+)
+
+// EnemyArchetype holds the multipliers an archetype applies to the enemy's type
+type EnemyArchetype struct {
+	LivesMultiplier  float32
+	SpeedMultiplier  float64
+	RewardMultiplier float64
+}
+
+var EnemyArchetypes = map[string]EnemyArchetype{
+	EnemyArchetypeGrunt: {LivesMultiplier: 1, SpeedMultiplier: 1, RewardMultiplier: 1},
+	EnemyArchetypeBrute: {LivesMultiplier: config.EnemyBruteLivesMultiplier, SpeedMultiplier: config.EnemyBruteSpeedMultiplier, RewardMultiplier: config.EnemyBruteRewardMultiplier},
+	EnemyArchetypeScout: {LivesMultiplier: config.EnemyScoutLivesMultiplier, SpeedMultiplier: config.EnemyScoutSpeedMultiplier, RewardMultiplier: config.EnemyScoutRewardMultiplier},
+}
+
+// RollEnemyArchetype picks an archetype for a roll between 0 and 1
+func RollEnemyArchetype(roll float64) string {
+	switch {
+	case roll < config.EnemyBruteChance:
+		return EnemyArchetypeBrute
+	case roll < config.EnemyBruteChance+config.EnemyScoutChance:
+		return EnemyArchetypeScout
+	default:
+		return EnemyArchetypeGrunt
+	}
 }
 
 func EnemiesEqual(a, b *Enemy) bool {
@@ -39,6 +89,11 @@ func (a *Enemy) Equal(b *Enemy) bool {
 		a.Rotation == b.Rotation && a.Lives == b.Lives && a.IsAlive == b.IsAlive
 }
 
+// IsVulnerableTo reports whether the enemy takes damage from weaponType
+func (e *Enemy) IsVulnerableTo(weaponType string) bool {
+	return !e.Armored || ArmorPiercingWeaponTypes[weaponType]
+}
+
 func (e *Enemy) DistanceToPoint(point *Vector2) float64 {
 	dx := e.Position.X - point.X
 	dy := e.Position.Y - point.Y
@@ -56,9 +111,25 @@ func (e *Enemy) getGunPoint() *Vector2 {
 	return enemyGunPoint
 }
 
-func (e *Enemy) Shoot() *Bullet {
+// Shoot fires count bullets fanned out over config.EnemyBulletSpreadAngle, or a
+// single aimed one when count is below 2. Towers always fire a single rocket.
+func (e *Enemy) Shoot(count int) []*Bullet {
+	if count < 2 || e.Type == EnemyTypeTower {
+		return []*Bullet{e.shootAt(e.Rotation)}
+	}
+
+	bullets := make([]*Bullet, count)
+	for i := range bullets {
+		angleOffset := (float64(i) - float64(count-1)/2) * (config.EnemyBulletSpreadAngle / float64(count-1))
+		bullets[i] = e.shootAt(e.Rotation + angleOffset)
+	}
+	return bullets
+}
+
+// shootAt fires a single bullet from the enemy's gun in the given direction, in degrees
+func (e *Enemy) shootAt(rotation float64) *Bullet {
 	enemyGunPoint := e.getGunPoint()
-	rotationRad := e.Rotation * math.Pi / 180.0
+	rotationRad := rotation * math.Pi / 180.0
 	bulletSpeed, exists := EnemyBulletSpeedByType[e.Type]
 	if !exists {
 		bulletSpeed = config.EnemySoldierBulletSpeed
@@ -115,10 +186,37 @@ func (e *Enemy) Size() float64 {
 	return size
 }
 
+// archetype returns the enemy's archetype, enemies without one are grunts
+func (e *Enemy) archetype() EnemyArchetype {
+	archetype, exists := EnemyArchetypes[e.Archetype]
+	if !exists {
+		return EnemyArchetypes[EnemyArchetypeGrunt]
+	}
+	return archetype
+}
+
+// MaxLives returns the lives of the enemy's type and archetype at full health
+func (e *Enemy) MaxLives() float32 {
+	lives, exists := EnemyLivesByType[e.Type]
+	if !exists {
+		lives = config.EnemySoldierLives
+	}
+	return lives * e.archetype().LivesMultiplier
+}
+
 func (e *Enemy) Reward() float64 {
 	reward, exists := EnemyRewardByType[e.Type]
 	if !exists {
-		return config.EnemySoldierReward
+		reward = config.EnemySoldierReward
+	}
+	return reward * e.archetype().RewardMultiplier
+}
+
+// Speed returns how fast the enemy walks, in units per second
+func (e *Enemy) Speed() float64 {
+	speed, exists := EnemySpeedByType[e.Type]
+	if !exists {
+		speed = config.EnemySoldierSpeed
 	}
-	return reward
+	return speed * e.archetype().SpeedMultiplier
 }
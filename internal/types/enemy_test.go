@@ -0,0 +1,65 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+)
+
+func TestEnemyShootsSpread(t *testing.T) {
+	enemy := &Enemy{
+		ScreenObject: ScreenObject{ID: "enemy", Position: &Vector2{X: 100, Y: 100}},
+		Type:         EnemyTypeSoldier,
+		Rotation:     30,
+	}
+
+	if bullets := enemy.Shoot(1); len(bullets) != 1 {
+		t.Fatalf("expected a single bullet without spread, got %d", len(bullets))
+	}
+
+	bullets := enemy.Shoot(3)
+	if len(bullets) != 3 {
+		t.Fatalf("expected 3 bullets, got %d", len(bullets))
+	}
+
+	// Bullet directions in degrees, measured the same way as the enemy's rotation
+	var angles []float64
+	for _, bullet := range bullets {
+		angles = append(angles, math.Atan2(-bullet.Velocity.X, bullet.Velocity.Y)*180/math.Pi)
+	}
+	half := config.EnemyBulletSpreadAngle / 2
+	for i, want := range []float64{30 - half, 30, 30 + half} {
+		if math.Abs(angles[i]-want) > 1e-9 {
+			t.Errorf("bullet %d: expected direction %.1f, got %.1f", i, want, angles[i])
+		}
+	}
+}
+
+func TestTowerIgnoresSpread(t *testing.T) {
+	tower := &Enemy{
+		ScreenObject: ScreenObject{ID: "tower", Position: &Vector2{X: 100, Y: 100}},
+		Type:         EnemyTypeTower,
+	}
+
+	bullets := tower.Shoot(3)
+	if len(bullets) != 1 || bullets[0].WeaponType != WeaponTypeRocketLauncher {
+		t.Errorf("expected a tower to fire a single rocket, got %d bullets", len(bullets))
+	}
+}
+
+func TestEnemyArchetypeScalesType(t *testing.T) {
+	grunt := &Enemy{Type: EnemyTypeLieutenant}
+	brute := &Enemy{Type: EnemyTypeLieutenant, Archetype: EnemyArchetypeBrute}
+	scout := &Enemy{Type: EnemyTypeLieutenant, Archetype: EnemyArchetypeScout}
+
+	if grunt.MaxLives() != config.EnemyLieutenantLives || grunt.Reward() != config.EnemyLieutenantReward || grunt.Speed() != config.EnemySoldierSpeed {
+		t.Errorf("expected an enemy without an archetype to keep its type's stats, got %.1f lives, %.0f reward, %.0f speed", grunt.MaxLives(), grunt.Reward(), grunt.Speed())
+	}
+	if brute.MaxLives() != config.EnemyLieutenantLives*config.EnemyBruteLivesMultiplier || brute.Reward() != config.EnemyLieutenantReward*config.EnemyBruteRewardMultiplier {
+		t.Errorf("expected a brute lieutenant to have more lives and reward, got %.1f lives, %.0f reward", brute.MaxLives(), brute.Reward())
+	}
+	if scout.Speed() <= grunt.Speed() || brute.Speed() >= grunt.Speed() {
+		t.Errorf("expected scouts faster and brutes slower than grunts, got %.0f and %.0f", scout.Speed(), brute.Speed())
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"log"
 	"maps"
 	"math"
 	"math/rand"
@@ -29,10 +30,24 @@ type Player struct {
 	RechargeAccumulator     float64          `json:"-"`
 	InvulnerableTimer       float64          `json:"invulnerableTimer"`
 	NightVisionTimer        float64          `json:"nightVisionTimer"`
+	DoubleDamageTimer       float64          `json:"doubleDamageTimer"`
+	RapidFireTimer          float64          `json:"rapidFireTimer"`
+	SpeedBoostTimer         float64          `json:"speedBoostTimer"`
+	Stamina                 float64          `json:"stamina"`
+	BlindTimer              float64          `json:"blindTimer"`
+	ReloadTimer             float64          `json:"reloadTimer"`
+	IsSprinting             bool             `json:"-"`
+	IsMoving                bool             `json:"-"` // position changed during the last tick
 	IsAlive                 bool             `json:"isAlive"`
 	IsConnected             bool             `json:"-"`
 	Inventory               []InventoryItem  `json:"inventory"`
 	SelectedGunType         string           `json:"selectedGunType"`
+	Team                    string           `json:"team,omitempty"` // empty when the player isn't on a team
+	LastProcessedInput      uint32           `json:"-"`              // last input sequence applied by the engine
+	SpawnWeaponsGranted     bool             `json:"-"`              // set once the player got the configured spawn weapons
+	// Other players who recently hurt this one, for assist rewards
+	DamageContributors DamageContributors `json:"-"`
+	Poison
 }
 
 func PlayersEqual(a, b *Player) bool {
@@ -47,11 +62,18 @@ func PlayersEqual(a, b *Player) bool {
 	return a.Equal(b)
 }
 
+// IsTeammateOf reports whether both players are on the same team
+func (p *Player) IsTeammateOf(other *Player) bool {
+	return p.Team != "" && p.Team == other.Team
+}
+
 // Helper functions to compare entities
 func (p *Player) Equal(b *Player) bool {
 	basicPropsEqual := p.Position.X == b.Position.X && p.Position.Y == b.Position.Y &&
 		p.Rotation == b.Rotation && p.Lives == b.Lives && p.Score == b.Score &&
 		p.Money == b.Money && p.Kills == b.Kills && p.NightVisionTimer == b.NightVisionTimer &&
+		p.DoubleDamageTimer == b.DoubleDamageTimer && p.RapidFireTimer == b.RapidFireTimer && p.SpeedBoostTimer == b.SpeedBoostTimer &&
+		p.Stamina == b.Stamina && p.BlindTimer == b.BlindTimer && p.ReloadTimer == b.ReloadTimer &&
 		p.IsAlive == b.IsAlive && p.SelectedGunType == b.SelectedGunType
 
 	if !basicPropsEqual {
@@ -108,6 +130,14 @@ func (p *Player) Respawn(spawnPoint *Vector2) bool {
 	p.Position = &Vector2{X: spawnPoint.X, Y: spawnPoint.Y}
 	p.InvulnerableTimer = config.PlayerSpawnInvulnerabilityTime
 	p.NightVisionTimer = 0
+	p.DoubleDamageTimer = 0
+	p.RapidFireTimer = 0
+	p.SpeedBoostTimer = 0
+	p.Stamina = config.PlayerMaxStamina
+	p.IsSprinting = false
+	p.BlindTimer = 0
+	p.ReloadTimer = 0
+	p.CurePoison()
 	p.Kills = 0
 	p.Money = 0
 	p.Score = 0
@@ -176,6 +206,11 @@ func (p *Player) GetInventoryItemQuantity(itemID InventoryItemID) int32 {
 }
 
 func (p *Player) AddInventoryItem(itemID InventoryItemID, quantity int32) bool {
+	if !KnownInventoryItems[itemID] {
+		log.Printf("Ignoring unknown inventory item %d for player %s", itemID, p.ID)
+		return false
+	}
+
 	for i, item := range p.Inventory {
 		if item.Type == itemID {
 			p.Inventory[i].Quantity += quantity
@@ -239,6 +274,65 @@ func (p *Player) UseGoggles() bool {
 	return true
 }
 
+// PowerUpEffects sets how long picked up power-ups last and how strong they are
+type PowerUpEffects struct {
+	Duration             float64 // Seconds
+	DamageMultiplier     float32
+	ShootDelayMultiplier float64
+	SpeedMultiplier      float64
+}
+
+// UpdatePowerUps counts down active power-up timers
+func (p *Player) UpdatePowerUps(deltaTime float64) {
+	p.DoubleDamageTimer = math.Max(0, p.DoubleDamageTimer-deltaTime)
+	p.RapidFireTimer = math.Max(0, p.RapidFireTimer-deltaTime)
+	p.SpeedBoostTimer = math.Max(0, p.SpeedBoostTimer-deltaTime)
+}
+
+func (p *Player) DamageMultiplier(effects PowerUpEffects) float32 {
+	if p.DoubleDamageTimer > 0 {
+		return effects.DamageMultiplier
+	}
+	return 1
+}
+
+func (p *Player) ShootDelayMultiplier(effects PowerUpEffects) float64 {
+	if p.RapidFireTimer > 0 {
+		return effects.ShootDelayMultiplier
+	}
+	return 1
+}
+
+func (p *Player) Speed(effects PowerUpEffects) float64 {
+	speed := config.PlayerSpeed
+	if p.SpeedBoostTimer > 0 {
+		speed *= effects.SpeedMultiplier
+	}
+	if p.IsSprinting {
+		speed *= config.SprintSpeedMultiplier
+	}
+	return speed
+}
+
+// Blind applies a flash to the player, goggles soften it
+func (p *Player) Blind(duration float64) {
+	if p.NightVisionTimer > 0 {
+		duration *= config.NightVisionBlindFactor
+	}
+	p.BlindTimer = math.Max(p.BlindTimer, duration)
+}
+
+// UpdateStamina drains stamina while the player sprints and regenerates it otherwise
+func (p *Player) UpdateStamina(wantsSprint bool, deltaTime float64) {
+	p.IsSprinting = wantsSprint && p.Stamina > 0
+	if p.IsSprinting {
+		p.Stamina = math.Max(0, p.Stamina-config.SprintStaminaDrainRate*deltaTime)
+		return
+	}
+
+	p.Stamina = math.Min(config.PlayerMaxStamina, p.Stamina+config.SprintStaminaRegenRate*deltaTime)
+}
+
 func (p *Player) Recharge(deltaTime float64) bool {
 	maxBullets, exists := MaxBulletsByWeaponType[p.SelectedGunType]
 	if !exists {
@@ -276,7 +370,34 @@ func (p *Player) Recharge(deltaTime float64) bool {
 	return false
 }
 
-func (p *Player) SelectGunType(itemID InventoryItemID) bool {
+// Reload tops up the magazine of the selected weapon from reserve ammo at once instead of a
+// bullet at a time. The player can't fire until the weapon's reload time passes. Returns false
+// when there's nothing to reload or the weapon has no magazine fed from the inventory.
+func (p *Player) Reload() bool {
+	reloadTime, exists := ReloadTimeByWeaponType[p.SelectedGunType]
+	if !exists || p.ReloadTimer > 0 {
+		return false
+	}
+
+	ammoID := InventoryAmmoIDByWeaponType[p.SelectedGunType]
+	missing := MaxBulletsByWeaponType[p.SelectedGunType] - p.BulletsLeftByWeaponType[p.SelectedGunType]
+	bullets := min(missing, p.GetInventoryItemQuantity(ammoID))
+	if bullets <= 0 {
+		return false
+	}
+
+	p.UseInventoryItem(ammoID, bullets)
+	p.BulletsLeftByWeaponType[p.SelectedGunType] += bullets
+	p.RechargeAccumulator = 0
+	p.ReloadTimer = reloadTime
+	return true
+}
+
+// SelectGunType switches to a weapon the player carries and the session allows
+func (p *Player) SelectGunType(itemID InventoryItemID, allowed WeaponSet) bool {
+	if !allowed.AllowsItem(itemID) {
+		return false
+	}
 	if itemID == InventoryItemBlaster || p.HasInventoryItem(itemID) {
 		p.SelectedGunType = WeaponTypeByInventoryItem[itemID]
 		return true
@@ -349,7 +470,16 @@ func (p *Player) DropInventory() *Bonus {
 	return bonus
 }
 
-func (p *Player) PickupBonus(bonus *Bonus) {
+func (p *Player) PickupBonus(bonus *Bonus, effects PowerUpEffects) {
+	switch bonus.Type {
+	case BonusTypeDoubleDamage:
+		p.DoubleDamageTimer = effects.Duration
+	case BonusTypeRapidFire:
+		p.RapidFireTimer = effects.Duration
+	case BonusTypeSpeedBoost:
+		p.SpeedBoostTimer = effects.Duration
+	}
+
 	for _, inventoryItem := range bonus.Inventory {
 		if inventoryItem.Type == InventoryItemMoney {
 			p.Money += int(inventoryItem.Quantity)
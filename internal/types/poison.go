@@ -0,0 +1,41 @@
+package types
+
+import (
+	"math"
+
+	"github.com/besuhoff/dungeon-game-go/internal/config"
+)
+
+// Poison deals damage over time after a poisoned hit, independent of further hits
+type Poison struct {
+	PoisonTimer  float64 `json:"-"` // seconds left
+	PoisonDamage float32 `json:"-"` // damage per second
+	PoisonedBy   string  `json:"-"` // credited with the kill when the poison finishes the target
+}
+
+// ApplyPoison poisons for duration seconds. Hits stack: each one restarts the timer and
+// adds damagePerSecond, up to config.PoisonMaxStacks hits' worth.
+func (p *Poison) ApplyPoison(by string, damagePerSecond float32, duration float64) {
+	p.PoisonTimer = duration
+	p.PoisonDamage = min(p.PoisonDamage+damagePerSecond, damagePerSecond*config.PoisonMaxStacks)
+	p.PoisonedBy = by
+}
+
+// TickPoison advances the poison by deltaTime, returning the damage it dealt meanwhile
+func (p *Poison) TickPoison(deltaTime float64) float32 {
+	if p.PoisonTimer <= 0 {
+		return 0
+	}
+
+	damage := p.PoisonDamage * float32(math.Min(deltaTime, p.PoisonTimer))
+	p.PoisonTimer -= deltaTime
+	if p.PoisonTimer <= 0 {
+		p.CurePoison()
+	}
+	return damage
+}
+
+// CurePoison ends the poison
+func (p *Poison) CurePoison() {
+	*p = Poison{}
+}
@@ -18,12 +18,17 @@ type ShopInventoryItem struct {
 type Shop struct {
 	ScreenObject
 
-	Name      string
-	Inventory map[InventoryItemID]*ShopInventoryItem
+	Name       string
+	Inventory  map[InventoryItemID]*ShopInventoryItem
+	Discovered bool // seen by at least one player
 }
 
-func GenerateShop(position *Vector2) *Shop {
-	shopName := ShopNames[rand.Intn(len(ShopNames))]
+// GenerateShop creates a shop at position, stocking it with items drawn from rng.
+// With a priceVariation above 0 all prices of the shop are scaled by one
+// multiplier picked from [1-priceVariation, 1+priceVariation]. Weapons and ammo
+// the session doesn't allow are never stocked.
+func GenerateShop(position *Vector2, rng *rand.Rand, priceVariation float64, allowed WeaponSet) *Shop {
+	shopName := ShopNames[rng.Intn(len(ShopNames))]
 
 	shop := &Shop{
 		ScreenObject: ScreenObject{
@@ -34,21 +39,21 @@ func GenerateShop(position *Vector2) *Shop {
 		Inventory: make(map[InventoryItemID]*ShopInventoryItem),
 	}
 
-	weaponItems := []InventoryItemID{InventoryItemShotgun, InventoryItemRocketLauncher, InventoryItemRailgun}
+	weaponItems := []InventoryItemID{InventoryItemShotgun, InventoryItemRocketLauncher, InventoryItemRailgun, InventoryItemKnife}
 	ammoItems := []InventoryItemID{InventoryItemShotgunAmmo, InventoryItemRocket, InventoryItemRailgunAmmo}
 
 	for _, itemID := range weaponItems {
-		if rand.Float64() < config.ShopWeaponProbability {
+		if rng.Float64() < config.ShopWeaponProbability && allowed.AllowsItem(itemID) {
 			shop.Inventory[itemID] = &ShopInventoryItem{
 				Price:    ShopItemPrice[itemID],
 				PackSize: 1,
-				Quantity: config.ShopWeaponMinQuantity + rand.Intn(config.ShopWeaponMaxQuantity-config.ShopWeaponMinQuantity+1),
+				Quantity: config.ShopWeaponMinQuantity + rng.Intn(config.ShopWeaponMaxQuantity-config.ShopWeaponMinQuantity+1),
 			}
 		}
 	}
 
 	for _, itemID := range ammoItems {
-		if rand.Float64() >= config.ShopAmmoProbability {
+		if rng.Float64() >= config.ShopAmmoProbability && allowed.AllowsItem(itemID) {
 
 			packSize, exists := ShopItemPackSize[itemID]
 			if !exists {
@@ -58,24 +63,32 @@ func GenerateShop(position *Vector2) *Shop {
 			shop.Inventory[itemID] = &ShopInventoryItem{
 				Price:    ShopItemPrice[itemID],
 				PackSize: packSize,
-				Quantity: config.ShopAmmoMinQuantity + rand.Intn(config.ShopAmmoMaxQuantity-config.ShopAmmoMinQuantity+1),
+				Quantity: config.ShopAmmoMinQuantity + rng.Intn(config.ShopAmmoMaxQuantity-config.ShopAmmoMinQuantity+1),
 			}
 		}
 	}
 
-	if rand.Float64() < config.ShopAidKitProbability {
+	if rng.Float64() < config.ShopAidKitProbability {
 		shop.Inventory[InventoryItemAidKit] = &ShopInventoryItem{
 			Price:    ShopItemPrice[InventoryItemAidKit],
 			PackSize: 1,
-			Quantity: config.ShopAidKitMinQuantity + rand.Intn(config.ShopAidKitMaxQuantity-config.ShopAidKitMinQuantity+1),
+			Quantity: config.ShopAidKitMinQuantity + rng.Intn(config.ShopAidKitMaxQuantity-config.ShopAidKitMinQuantity+1),
 		}
 	}
 
-	if rand.Float64() < config.ShopGogglesProbability {
+	if rng.Float64() < config.ShopGogglesProbability {
 		shop.Inventory[InventoryItemGoggles] = &ShopInventoryItem{
 			Price:    ShopItemPrice[InventoryItemGoggles],
 			PackSize: 1,
-			Quantity: config.ShopGogglesMinQuantity + rand.Intn(config.ShopGogglesMaxQuantity-config.ShopGogglesMinQuantity+1),
+			Quantity: config.ShopGogglesMinQuantity + rng.Intn(config.ShopGogglesMaxQuantity-config.ShopGogglesMinQuantity+1),
+		}
+	}
+
+	// Drawn last so that the stock of a seeded shop doesn't depend on the variation
+	if priceVariation > 0 {
+		multiplier := 1 - priceVariation + rng.Float64()*2*priceVariation
+		for _, item := range shop.Inventory {
+			item.Price = max(int(math.Round(float64(item.Price)*multiplier)), 1)
 		}
 	}
 
@@ -139,9 +152,9 @@ func (s *Shop) Clone() *Shop {
 	return &clone
 }
 
-func (s *Shop) PurchaseInventoryItem(player *Player, itemID InventoryItemID) bool {
+func (s *Shop) PurchaseInventoryItem(player *Player, itemID InventoryItemID, allowed WeaponSet) bool {
 	item, exists := s.Inventory[itemID]
-	if !exists || item.Quantity <= 0 {
+	if !exists || item.Quantity <= 0 || !KnownInventoryItems[itemID] || !allowed.AllowsItem(itemID) {
 		return false
 	}
 
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func shopPrices(shop *Shop) map[InventoryItemID]int {
+	prices := make(map[InventoryItemID]int)
+	for itemID, item := range shop.Inventory {
+		prices[itemID] = item.Price
+	}
+	return prices
+}
+
+func TestShopPriceVariationStaysWithinBand(t *testing.T) {
+	const variation = 0.25
+	varied := false
+
+	for seed := int64(0); seed < 200; seed++ {
+		shop := GenerateShop(&Vector2{}, rand.New(rand.NewSource(seed)), variation, nil)
+
+		for itemID, item := range shop.Inventory {
+			base := float64(ShopItemPrice[itemID])
+			low, high := base*(1-variation)-0.5, base*(1+variation)+0.5
+			if price := float64(item.Price); price < low || price > high {
+				t.Fatalf("seed %d: item %d price %d outside [%.1f, %.1f]", seed, itemID, item.Price, low, high)
+			}
+			if item.Price != ShopItemPrice[itemID] {
+				varied = true
+			}
+		}
+	}
+
+	if !varied {
+		t.Error("expected some shops to differ from the base prices")
+	}
+}
+
+func TestShopPriceVariationIsDeterministic(t *testing.T) {
+	first := GenerateShop(&Vector2{}, rand.New(rand.NewSource(42)), 0.3, nil)
+	second := GenerateShop(&Vector2{}, rand.New(rand.NewSource(42)), 0.3, nil)
+
+	if !reflect.DeepEqual(shopPrices(first), shopPrices(second)) {
+		t.Errorf("same seed priced shops differently: %v vs %v", shopPrices(first), shopPrices(second))
+	}
+
+	// The variation only changes prices, not what the shop stocks
+	base := GenerateShop(&Vector2{}, rand.New(rand.NewSource(42)), 0, nil)
+	if len(base.Inventory) != len(first.Inventory) {
+		t.Errorf("expected the same stock with and without variation, got %d and %d items", len(base.Inventory), len(first.Inventory))
+	}
+	for itemID, item := range base.Inventory {
+		if item.Price != ShopItemPrice[itemID] {
+			t.Errorf("expected base price %d for item %d without variation, got %d", ShopItemPrice[itemID], itemID, item.Price)
+		}
+	}
+}
+
+func TestShopRespectsAllowedWeapons(t *testing.T) {
+	allowed := NewWeaponSet([]string{WeaponTypeBlaster, WeaponTypeShotgun})
+	disallowed := []InventoryItemID{InventoryItemRocketLauncher, InventoryItemRocket, InventoryItemRailgun, InventoryItemRailgunAmmo}
+
+	for seed := int64(0); seed < 100; seed++ {
+		shop := GenerateShop(&Vector2{}, rand.New(rand.NewSource(seed)), 0, allowed)
+		for _, itemID := range disallowed {
+			if _, stocked := shop.Inventory[itemID]; stocked {
+				t.Fatalf("seed %d: expected item %d not to be stocked", seed, itemID)
+			}
+		}
+	}
+
+	shop := &Shop{Inventory: map[InventoryItemID]*ShopInventoryItem{
+		InventoryItemRocketLauncher: {Price: 10, PackSize: 1, Quantity: 1},
+	}}
+	player := &Player{Money: 100}
+	if shop.PurchaseInventoryItem(player, InventoryItemRocketLauncher, allowed) {
+		t.Error("expected a disallowed weapon not to be sold")
+	}
+	if !shop.PurchaseInventoryItem(player, InventoryItemRocketLauncher, nil) {
+		t.Error("expected the weapon to be sold when every weapon is allowed")
+	}
+
+	if player.SelectGunType(InventoryItemRocketLauncher, allowed) {
+		t.Error("expected a disallowed weapon not to be selectable")
+	}
+	if !player.SelectGunType(InventoryItemRocketLauncher, nil) || player.SelectedGunType != WeaponTypeRocketLauncher {
+		t.Error("expected a carried weapon to be selectable when every weapon is allowed")
+	}
+}
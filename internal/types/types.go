@@ -28,6 +28,9 @@ type InputPayload struct {
 	Shoot           bool           `json:"shoot"`
 	ItemKey         map[int32]bool `json:"item_key,omitempty"`
 	PurchaseItemKey map[int32]bool `json:"purchase_item_key,omitempty"`
+	Sequence        uint32         `json:"sequence,omitempty"`
+	Sprint          bool           `json:"sprint,omitempty"`
+	Reload          bool           `json:"reload,omitempty"`
 }
 
 type CollisionObject struct {
@@ -43,6 +46,7 @@ const (
 	InventoryItemShotgun        InventoryItemID = 2
 	InventoryItemRocketLauncher InventoryItemID = 3
 	InventoryItemRailgun        InventoryItemID = 4
+	InventoryItemKnife          InventoryItemID = 5
 
 	InventoryItemShotgunAmmo InventoryItemID = 22
 	InventoryItemRocket      InventoryItemID = 23
@@ -50,21 +54,45 @@ const (
 
 	InventoryItemGoggles InventoryItemID = 7
 	InventoryItemAidKit  InventoryItemID = 8
+	InventoryItemKey     InventoryItemID = 9
 
 	InventoryItemMoney InventoryItemID = 100
 )
 
+// KnownInventoryItems holds every valid inventory item ID, any other ID comes from bad data
+var KnownInventoryItems = map[InventoryItemID]bool{
+	InventoryItemBlaster:        true,
+	InventoryItemShotgun:        true,
+	InventoryItemRocketLauncher: true,
+	InventoryItemRailgun:        true,
+	InventoryItemKnife:          true,
+	InventoryItemShotgunAmmo:    true,
+	InventoryItemRocket:         true,
+	InventoryItemRailgunAmmo:    true,
+	InventoryItemGoggles:        true,
+	InventoryItemAidKit:         true,
+	InventoryItemKey:            true,
+	InventoryItemMoney:          true,
+}
+
 const (
 	WeaponTypeBlaster        = "blaster"
 	WeaponTypeShotgun        = "shotgun"
 	WeaponTypeRocketLauncher = "rocket_launcher"
 	WeaponTypeRailgun        = "railgun"
+	WeaponTypeKnife          = "knife" // melee, swings instead of firing bullets
 )
 
 const (
 	EnemyTypeSoldier    = "pr"
 	EnemyTypeLieutenant = "lt"
 	EnemyTypeTower      = "tw"
+	EnemyTypeFlasher    = "fl"
+	EnemyTypeSummoner   = "su"
+	EnemyTypeMinion     = "mn"
+	EnemyTypeKeyholder  = "kh"
+	EnemyTypeGatekeeper = "gk"
+	EnemyTypeThief      = "th"
 )
 
 var WeaponTypeByInventoryItem = map[InventoryItemID]string{
@@ -72,6 +100,15 @@ var WeaponTypeByInventoryItem = map[InventoryItemID]string{
 	InventoryItemShotgun:        WeaponTypeShotgun,
 	InventoryItemRocketLauncher: WeaponTypeRocketLauncher,
 	InventoryItemRailgun:        WeaponTypeRailgun,
+	InventoryItemKnife:          WeaponTypeKnife,
+}
+
+var InventoryItemByWeaponType = map[string]InventoryItemID{
+	WeaponTypeBlaster:        InventoryItemBlaster,
+	WeaponTypeShotgun:        InventoryItemShotgun,
+	WeaponTypeRocketLauncher: InventoryItemRocketLauncher,
+	WeaponTypeRailgun:        InventoryItemRailgun,
+	WeaponTypeKnife:          InventoryItemKnife,
 }
 
 var InventoryAmmoIDByWeaponType = map[string]InventoryItemID{
@@ -85,6 +122,11 @@ var BulletRechargeTimeByWeaponType = map[string]float64{
 	WeaponTypeShotgun: config.ShotgunBulletRechargeTime,
 }
 
+// ReloadTimeByWeaponType is how long a manual reload keeps weapons with a magazine fed from the inventory from firing
+var ReloadTimeByWeaponType = map[string]float64{
+	WeaponTypeShotgun: config.ShotgunReloadTime,
+}
+
 var MaxBulletsByWeaponType = map[string]int32{
 	WeaponTypeBlaster: config.BlasterMaxBullets,
 	WeaponTypeShotgun: config.ShotgunMaxBullets,
@@ -95,6 +137,13 @@ var ShootDelayByWeaponType = map[string]float64{
 	WeaponTypeShotgun:        config.ShotgunShootDelay,
 	WeaponTypeRocketLauncher: config.RocketLauncherShootDelay,
 	WeaponTypeRailgun:        config.RailgunShootDelay,
+	WeaponTypeKnife:          config.MeleeShootDelay,
+}
+
+// ArmorPiercingWeaponTypes are the weapons that can hurt armored enemies
+var ArmorPiercingWeaponTypes = map[string]bool{
+	WeaponTypeRocketLauncher: true,
+	WeaponTypeRailgun:        true,
 }
 
 var DamageByWeaponType = map[string]float32{
@@ -102,6 +151,7 @@ var DamageByWeaponType = map[string]float32{
 	WeaponTypeShotgun:        config.ShotgunDamage,
 	WeaponTypeRocketLauncher: config.RocketLauncherDamage,
 	WeaponTypeRailgun:        config.RailgunDamage,
+	WeaponTypeKnife:          config.MeleeDamage,
 }
 
 var BulletLifetimeByWeaponType = map[string]time.Duration{
@@ -114,6 +164,7 @@ var ShopItemPrice = map[InventoryItemID]int{
 	InventoryItemShotgun:        500,
 	InventoryItemRocketLauncher: 1000,
 	InventoryItemRailgun:        1500,
+	InventoryItemKnife:          300,
 	InventoryItemShotgunAmmo:    20,
 	InventoryItemRocket:         30,
 	InventoryItemRailgunAmmo:    30,
@@ -150,38 +201,110 @@ var ShopNames = []string{
 	"The Battle Bodega",
 }
 
+// SessionNames are given to sessions created without a name
+var SessionNames = []string{
+	"The Rusty Catacombs",
+	"Bunker of Bad Ideas",
+	"The Echoing Halls",
+	"Lieutenant's Lair",
+	"The Flickering Maze",
+	"Tower Row",
+	"The Long Dark",
+	"Corridor of Regret",
+	"The Forgotten Vault",
+	"Shotgun Alley",
+	"The Quiet Crypt",
+	"Rocket Garden",
+	"The Crooked Cellar",
+	"Goggle Gulch",
+	"The Last Torch",
+	"Railgun Ridge",
+	"The Damp Depths",
+	"Ammo Cave",
+	"The Winding Warren",
+	"Flasher's Folly",
+}
+
 var EnemySizeByType = map[string]float64{
 	EnemyTypeSoldier:    config.EnemySoldierSize,
 	EnemyTypeLieutenant: config.EnemySoldierSize,
 	EnemyTypeTower:      config.EnemyTowerSize,
+	EnemyTypeFlasher:    config.EnemySoldierSize,
+	EnemyTypeSummoner:   config.EnemySummonerSize,
+	EnemyTypeMinion:     config.EnemyMinionSize,
+	EnemyTypeKeyholder:  config.EnemySoldierSize,
+	EnemyTypeGatekeeper: config.EnemyGatekeeperSize,
+	EnemyTypeThief:      config.EnemySoldierSize,
 }
 
 var EnemyLivesByType = map[string]float32{
 	EnemyTypeSoldier:    config.EnemySoldierLives,
 	EnemyTypeLieutenant: config.EnemyLieutenantLives,
 	EnemyTypeTower:      config.EnemyTowerLives,
+	EnemyTypeFlasher:    config.EnemyFlasherLives,
+	EnemyTypeSummoner:   config.EnemySummonerLives,
+	EnemyTypeMinion:     config.EnemyMinionLives,
+	EnemyTypeKeyholder:  config.EnemyKeyholderLives,
+	EnemyTypeGatekeeper: config.EnemyGatekeeperLives,
+	EnemyTypeThief:      config.EnemyThiefLives,
 }
 
 var EnemyShootDelayByType = map[string]float64{
 	EnemyTypeSoldier:    config.EnemySoldierShootDelay,
 	EnemyTypeLieutenant: config.EnemyLieutenantShootDelay,
 	EnemyTypeTower:      config.EnemyTowerShootDelay,
+	EnemyTypeFlasher:    config.EnemyFlasherShootDelay,
+	EnemyTypeSummoner:   config.EnemySummonerShootDelay,
+	EnemyTypeMinion:     config.EnemyMinionShootDelay,
+	EnemyTypeKeyholder:  config.EnemyKeyholderShootDelay,
+	EnemyTypeGatekeeper: config.EnemyGatekeeperShootDelay,
+	EnemyTypeThief:      config.EnemySoldierShootDelay,
 }
 
 var EnemyBulletSpeedByType = map[string]float64{
 	EnemyTypeSoldier:    config.EnemySoldierBulletSpeed,
 	EnemyTypeLieutenant: config.EnemySoldierBulletSpeed,
 	EnemyTypeTower:      config.EnemyTowerBulletSpeed,
+	EnemyTypeFlasher:    config.EnemySoldierBulletSpeed,
+	EnemyTypeSummoner:   config.EnemySoldierBulletSpeed,
+	EnemyTypeMinion:     config.EnemySoldierBulletSpeed,
+	EnemyTypeKeyholder:  config.EnemySoldierBulletSpeed,
+	EnemyTypeGatekeeper: config.EnemySoldierBulletSpeed,
+	EnemyTypeThief:      config.EnemySoldierBulletSpeed,
+}
+
+var EnemySpeedByType = map[string]float64{
+	EnemyTypeSoldier:    config.EnemySoldierSpeed,
+	EnemyTypeLieutenant: config.EnemySoldierSpeed,
+	EnemyTypeTower:      config.EnemySoldierSpeed,
+	EnemyTypeFlasher:    config.EnemySoldierSpeed,
+	EnemyTypeSummoner:   config.EnemySoldierSpeed,
+	EnemyTypeMinion:     config.EnemySoldierSpeed,
+	EnemyTypeKeyholder:  config.EnemySoldierSpeed,
+	EnemyTypeGatekeeper: config.EnemySoldierSpeed,
+	EnemyTypeThief:      config.EnemyThiefSpeed,
 }
 
 var EnemyRewardByType = map[string]float64{
 	EnemyTypeSoldier:    config.EnemySoldierReward,
 	EnemyTypeLieutenant: config.EnemyLieutenantReward,
 	EnemyTypeTower:      config.EnemyTowerReward,
+	EnemyTypeFlasher:    config.EnemyFlasherReward,
+	EnemyTypeSummoner:   config.EnemySummonerReward,
+	EnemyTypeMinion:     config.EnemyMinionReward,
+	EnemyTypeKeyholder:  config.EnemyKeyholderReward,
+	EnemyTypeGatekeeper: config.EnemyGatekeeperReward,
+	EnemyTypeThief:      config.EnemyThiefReward,
 }
 
 var EnemyGunEndOffestByType = map[string]*Vector2{
 	EnemyTypeSoldier:    {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
 	EnemyTypeLieutenant: {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
 	EnemyTypeTower:      {X: config.EnemyTowerGunEndOffsetX, Y: config.EnemyTowerGunEndOffsetY},
+	EnemyTypeFlasher:    {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
+	EnemyTypeSummoner:   {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
+	EnemyTypeMinion:     {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
+	EnemyTypeKeyholder:  {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
+	EnemyTypeGatekeeper: {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
+	EnemyTypeThief:      {X: config.EnemySoldierGunEndOffsetX, Y: config.EnemySoldierGunEndOffsetY},
 }
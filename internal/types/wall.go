@@ -12,6 +12,8 @@ type Wall struct {
 	Width       float64 `json:"width"`
 	Height      float64 `json:"height"`
 	Orientation string  `json:"orientation"` // "vertical" or "horizontal"
+	// Locked doors are removed once a player carrying a key touches them
+	IsDoor bool `json:"isDoor,omitempty"`
 }
 
 func (wall *Wall) GetTopLeft() Vector2 {
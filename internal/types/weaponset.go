@@ -0,0 +1,36 @@
+package types
+
+// WeaponSet restricts the weapons usable in a session. A nil set allows every weapon.
+type WeaponSet map[string]bool
+
+// NewWeaponSet builds a set of the given weapon types, nil when the list is empty
+func NewWeaponSet(weaponTypes []string) WeaponSet {
+	if len(weaponTypes) == 0 {
+		return nil
+	}
+
+	set := make(WeaponSet, len(weaponTypes))
+	for _, weaponType := range weaponTypes {
+		set[weaponType] = true
+	}
+	return set
+}
+
+// Allows reports whether the weapon type may be used
+func (s WeaponSet) Allows(weaponType string) bool {
+	return s == nil || s[weaponType]
+}
+
+// AllowsItem reports whether an inventory item may be used or bought. Weapons and
+// their ammo follow the weapon, any other item is always allowed.
+func (s WeaponSet) AllowsItem(itemID InventoryItemID) bool {
+	if weaponType, isWeapon := WeaponTypeByInventoryItem[itemID]; isWeapon {
+		return s.Allows(weaponType)
+	}
+	for weaponType, ammoID := range InventoryAmmoIDByWeaponType {
+		if ammoID == itemID {
+			return s.Allows(weaponType)
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONError(t *testing.T) {
+	tests := []struct {
+		status   int
+		message  string
+		wantCode string
+	}{
+		{http.StatusBadRequest, "Invalid session ID", "bad_request"},
+		{http.StatusUnauthorized, "Unauthorized", "unauthorized"},
+		{http.StatusNotFound, "Session not found", "not_found"},
+		{http.StatusMethodNotAllowed, "Method not allowed", "method_not_allowed"},
+		{http.StatusInternalServerError, "Database error", "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteJSONError(rec, tt.status, tt.message)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("error body is not valid JSON: %v", err)
+			}
+			if body.Error.Code != tt.wantCode || body.Error.Message != tt.message {
+				t.Errorf("error = %+v, want code %q and message %q", body.Error, tt.wantCode, tt.message)
+			}
+		})
+	}
+}
+
+func TestErrorCodeForUnknownStatus(t *testing.T) {
+	if code := ErrorCode(599); code != "error" {
+		t.Errorf("ErrorCode(599) = %q, want %q", code, "error")
+	}
+}
@@ -12,6 +12,20 @@ func CheckRectCollision(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
 }
 
 func CutLineSegmentBeforeRect(x1, y1, x2, y2, rx, ry, rw, rh float64) (float64, float64) {
+	return CutLineSegmentBeforeRectWithTolerance(x1, y1, x2, y2, rx, ry, rw, rh, 0)
+}
+
+// CutLineSegmentBeforeRectWithTolerance works like CutLineSegmentBeforeRect, but shrinks
+// the rectangle by tolerance on every side, so segments grazing an edge pass through
+func CutLineSegmentBeforeRectWithTolerance(x1, y1, x2, y2, rx, ry, rw, rh, tolerance float64) (float64, float64) {
+	if tolerance > 0 {
+		// Keep at least half of each dimension, so thin rectangles still block
+		tolX := math.Min(tolerance, rw/4)
+		tolY := math.Min(tolerance, rh/4)
+		rx, rw = rx+tolX, rw-2*tolX
+		ry, rh = ry+tolY, rh-2*tolY
+	}
+
 	// Liang-Barsky algorithm to find intersection point
 	dx := x2 - x1
 	dy := y2 - y1
@@ -102,6 +116,24 @@ func ClosestPointOnLineSegment(ax, ay, bx, by, px, py float64) (float64, float64
 	return ax + abx*t, ay + aby*t
 }
 
+// NormalizeRotation wraps an angle in degrees into the 0-360 range every rotation is kept in
+func NormalizeRotation(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	// Tiny negative angles round up to 360 when wrapped
+	if degrees >= 360 {
+		degrees = 0
+	}
+	return degrees
+}
+
+// RotationTowards returns the rotation facing along (dx, dy): 0 faces +Y and angles grow towards -X
+func RotationTowards(dx, dy float64) float64 {
+	return NormalizeRotation(math.Atan2(-dx, dy) * 180 / math.Pi)
+}
+
 func ChunkXYFromPosition(posX, posY float64) (int, int) {
 	chunkSize := config.ChunkSize
 	return int(math.Floor(posX / chunkSize)), int(math.Floor(posY / chunkSize))
@@ -275,3 +275,105 @@ func TestClosestPointOnLineSegment(t *testing.T) {
 		})
 	}
 }
+
+func TestCutLineSegmentBeforeRectWithTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		x1, y1    float64
+		x2, y2    float64
+		rx, ry    float64
+		rw, rh    float64
+		tolerance float64
+		expectedX float64
+		expectedY float64
+	}{
+		{
+			name: "grazing edge stops without tolerance",
+			x1:   0, y1: 0.5,
+			x2: 40, y2: 0.5,
+			rx: 10, ry: 0, rw: 30, rh: 30,
+			tolerance: 0,
+			expectedX: 10, expectedY: 0.5,
+		},
+		{
+			name: "grazing edge passes with tolerance",
+			x1:   0, y1: 0.5,
+			x2: 40, y2: 0.5,
+			rx: 10, ry: 0, rw: 30, rh: 30,
+			tolerance: 1,
+			expectedX: 40, expectedY: 0.5,
+		},
+		{
+			name: "deeper hit still stops with tolerance",
+			x1:   0, y1: 5,
+			x2: 40, y2: 5,
+			rx: 10, ry: 0, rw: 30, rh: 30,
+			tolerance: 1,
+			expectedX: 11, expectedY: 5,
+		},
+		{
+			name: "tolerance never removes thin rectangle",
+			x1:   0, y1: 15,
+			x2: 20, y2: 15,
+			rx: 10, ry: 0, rw: 2, rh: 30,
+			tolerance: 5,
+			expectedX: 10.5, expectedY: 15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ix, iy := CutLineSegmentBeforeRectWithTolerance(tt.x1, tt.y1, tt.x2, tt.y2, tt.rx, tt.ry, tt.rw, tt.rh, tt.tolerance)
+
+			epsilon := 1e-9
+			if math.Abs(ix-tt.expectedX) > epsilon || math.Abs(iy-tt.expectedY) > epsilon {
+				t.Errorf("CutLineSegmentBeforeRectWithTolerance() = (%v, %v), want (%v, %v)", ix, iy, tt.expectedX, tt.expectedY)
+			}
+		})
+	}
+}
+
+func TestNormalizeRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		degrees  float64
+		expected float64
+	}{
+		{name: "already in range", degrees: 45, expected: 45},
+		{name: "full turn wraps to zero", degrees: 360, expected: 0},
+		{name: "negative angle", degrees: -90, expected: 270},
+		{name: "several turns", degrees: 725, expected: 5},
+		{name: "several turns backwards", degrees: -725, expected: 355},
+		{name: "tiny negative angle", degrees: -1e-15, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NormalizeRotation(tt.degrees)
+			if math.Abs(result-tt.expected) > 1e-9 || result < 0 || result >= 360 {
+				t.Errorf("NormalizeRotation(%v) = %v, want %v", tt.degrees, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRotationTowards(t *testing.T) {
+	tests := []struct {
+		name     string
+		dx, dy   float64
+		expected float64
+	}{
+		{name: "down", dx: 0, dy: 1, expected: 0},
+		{name: "left", dx: -1, dy: 0, expected: 90},
+		{name: "up", dx: 0, dy: -1, expected: 180},
+		{name: "right", dx: 1, dy: 0, expected: 270},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := RotationTowards(tt.dx, tt.dy); math.Abs(result-tt.expected) > 1e-9 {
+				t.Errorf("RotationTowards(%v, %v) = %v, want %v", tt.dx, tt.dy, result, tt.expected)
+			}
+		})
+	}
+}
@@ -15,8 +15,10 @@ import (
 	"github.com/besuhoff/dungeon-game-go/internal/auth"
 	"github.com/besuhoff/dungeon-game-go/internal/config"
 	"github.com/besuhoff/dungeon-game-go/internal/db"
+	"github.com/besuhoff/dungeon-game-go/internal/game"
 	"github.com/besuhoff/dungeon-game-go/internal/handlers"
 	"github.com/besuhoff/dungeon-game-go/internal/server"
+	"github.com/besuhoff/dungeon-game-go/internal/utils"
 )
 
 var (
@@ -64,6 +66,11 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Reject tick rates letting entities move through walls in a single tick
+	if err := game.ValidateTickSafety(cfg.GameLoopInterval, cfg.WallThickness, cfg.SprintEnabled, cfg.PowerUpSpeedMultiplier); err != nil {
+		log.Fatal("Unsafe GAME_LOOP_INTERVAL_MS: ", err)
+	}
+
 	// Connect to MongoDB
 	if err := db.Connect(cfg.MongoDBURL); err != nil {
 		log.Fatal("Failed to connect to MongoDB: ", err)
@@ -80,40 +87,77 @@ func main() {
 
 	// Setup auth handlers
 	googleAuth := auth.NewGoogleAuthHandler()
-	sessionHandler := handlers.NewSessionHandler()
+	sessionHandler := handlers.NewSessionHandler(gameServer)
 	leaderboardHandler := handlers.NewLeaderboardHandler()
 
+	// Every endpoint counts requests on its own, creating and joining sessions get the stricter limit
+	limit := func(next http.HandlerFunc) http.HandlerFunc {
+		return handlers.NewRateLimiter(cfg.RateLimitPerMinute, cfg.RateLimitTrustProxy).Wrap(next)
+	}
+	limitJoin := func(next http.HandlerFunc) http.HandlerFunc {
+		return handlers.NewRateLimiter(cfg.RateLimitJoinPerMinute, cfg.RateLimitTrustProxy).Wrap(next)
+	}
+
 	// Setup HTTP routes
 	http.HandleFunc("/ws", gameServer.HandleWebSocket)
 
 	// Auth endpoints
-	http.HandleFunc("/api/v1/auth/google/url", corsMiddleware(googleAuth.HandleGetAuthURL))
-	http.HandleFunc("/api/v1/auth/google/callback", googleAuth.HandleCallback)
-	http.HandleFunc("/api/v1/auth/user", corsMiddleware(googleAuth.HandleGetUser))
+	http.HandleFunc("/api/v1/auth/google/url", corsMiddleware(limit(googleAuth.HandleGetAuthURL)))
+	http.HandleFunc("/api/v1/auth/google/callback", limit(googleAuth.HandleCallback))
+	http.HandleFunc("/api/v1/auth/user", corsMiddleware(limit(googleAuth.HandleGetUser)))
+	http.HandleFunc("/api/v1/auth/user/settings", corsMiddleware(limit(googleAuth.HandleUserSettings)))
+	http.HandleFunc("/api/v1/me/settings", corsMiddleware(limit(googleAuth.HandleUserSettings)))
+	http.HandleFunc("/api/v1/me/achievements", corsMiddleware(limit(googleAuth.HandleUserAchievements)))
 
 	// Session endpoints
+	createSession := limitJoin(sessionHandler.HandleCreateSession)
+	listSessions := limit(sessionHandler.HandleListSessions)
 	http.HandleFunc("/api/v1/sessions", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			sessionHandler.HandleCreateSession(w, r)
+			createSession(w, r)
 		case http.MethodGet:
-			sessionHandler.HandleListSessions(w, r)
+			listSessions(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
 	}))
+	joinSession := limitJoin(sessionHandler.HandleJoinSession)
+	getShops := limit(sessionHandler.HandleGetShops)
+	bank := limit(sessionHandler.HandleBank)
+	deleteSession := limit(sessionHandler.HandleDeleteSession)
+	getSession := limit(sessionHandler.HandleGetSession)
+	kickPlayer := limit(sessionHandler.HandleKickPlayer)
+	getWaitlist := limit(sessionHandler.HandleGetWaitlist)
 	http.HandleFunc("/api/v1/sessions/", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, "/join") {
-			sessionHandler.HandleJoinSession(w, r)
+			joinSession(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/shops") {
+			getShops(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/bank") {
+			bank(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/kick") {
+			kickPlayer(w, r)
+		} else if strings.HasSuffix(r.URL.Path, "/waitlist") {
+			getWaitlist(w, r)
 		} else if r.Method == http.MethodDelete {
-			sessionHandler.HandleDeleteSession(w, r)
+			deleteSession(w, r)
+		} else if r.Method == http.MethodGet {
+			getSession(w, r)
 		} else {
-			http.Error(w, "Not found", http.StatusNotFound)
+			utils.WriteJSONError(w, http.StatusNotFound, "Not found")
 		}
 	}))
 
 	// Leaderboard endpoints
-	http.HandleFunc("/api/v1/leaderboard/global", corsMiddleware(leaderboardHandler.HandleGetGlobalLeaderboard))
+	http.HandleFunc("/api/v1/leaderboard/global", corsMiddleware(limit(leaderboardHandler.HandleGetGlobalLeaderboard)))
+	http.HandleFunc("/api/v1/leaderboard/users", corsMiddleware(limit(leaderboardHandler.HandleGetUsersLeaderboard)))
+	http.HandleFunc("/api/v1/leaderboard/me", corsMiddleware(limit(leaderboardHandler.HandleGetMyStats)))
+	http.HandleFunc("/api/v1/leaderboard/session/", corsMiddleware(limit(leaderboardHandler.HandleGetSessionLeaderboard)))
+
+	// Runtime metrics
+	http.HandleFunc("/metrics", limit(gameServer.HandleMetrics))
+	http.HandleFunc("/api/v1/debug/sessions", corsMiddleware(limit(gameServer.HandleDebugSessions)))
 
 	// Health check
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {